@@ -0,0 +1,2455 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: memory/v1/memory.proto
+
+package memoryv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Mirrors types.RegisterConversationInput.
+type RegisterConversationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Agent         string                 `protobuf:"bytes,1,opt,name=agent,proto3" json:"agent,omitempty"`
+	User          string                 `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterConversationRequest) Reset() {
+	*x = RegisterConversationRequest{}
+	mi := &file_memory_v1_memory_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterConversationRequest) ProtoMessage() {}
+
+func (x *RegisterConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterConversationRequest.ProtoReflect.Descriptor instead.
+func (*RegisterConversationRequest) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RegisterConversationRequest) GetAgent() string {
+	if x != nil {
+		return x.Agent
+	}
+	return ""
+}
+
+func (x *RegisterConversationRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+// Mirrors types.RegisterConversationOutput.
+type RegisterConversationResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RegisterConversationResponse) Reset() {
+	*x = RegisterConversationResponse{}
+	mi := &file_memory_v1_memory_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterConversationResponse) ProtoMessage() {}
+
+func (x *RegisterConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterConversationResponse.ProtoReflect.Descriptor instead.
+func (*RegisterConversationResponse) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RegisterConversationResponse) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+// Mirrors types.Conversation.
+type Conversation struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Agent          string                 `protobuf:"bytes,2,opt,name=agent,proto3" json:"agent,omitempty"`
+	User           string                 `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+	Status         string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	MemoryCount    int32                  `protobuf:"varint,6,opt,name=memory_count,json=memoryCount,proto3" json:"memory_count,omitempty"`
+	LastActivityAt *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=last_activity_at,json=lastActivityAt,proto3" json:"last_activity_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Conversation) Reset() {
+	*x = Conversation{}
+	mi := &file_memory_v1_memory_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Conversation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Conversation) ProtoMessage() {}
+
+func (x *Conversation) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Conversation.ProtoReflect.Descriptor instead.
+func (*Conversation) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Conversation) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Conversation) GetAgent() string {
+	if x != nil {
+		return x.Agent
+	}
+	return ""
+}
+
+func (x *Conversation) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *Conversation) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Conversation) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Conversation) GetMemoryCount() int32 {
+	if x != nil {
+		return x.MemoryCount
+	}
+	return 0
+}
+
+func (x *Conversation) GetLastActivityAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastActivityAt
+	}
+	return nil
+}
+
+// Mirrors types.ListConversationsInput.
+type ListConversationsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Agent string                 `protobuf:"bytes,1,opt,name=agent,proto3" json:"agent,omitempty"`
+	User  string                 `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	// Empty lists active and closed conversations.
+	Status        string `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Limit         int32  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor        string `protobuf:"bytes,5,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConversationsRequest) Reset() {
+	*x = ListConversationsRequest{}
+	mi := &file_memory_v1_memory_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConversationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConversationsRequest) ProtoMessage() {}
+
+func (x *ListConversationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConversationsRequest.ProtoReflect.Descriptor instead.
+func (*ListConversationsRequest) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListConversationsRequest) GetAgent() string {
+	if x != nil {
+		return x.Agent
+	}
+	return ""
+}
+
+func (x *ListConversationsRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *ListConversationsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListConversationsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListConversationsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+// Mirrors types.ListConversationsOutput.
+type ListConversationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Conversations []*Conversation        `protobuf:"bytes,1,rep,name=conversations,proto3" json:"conversations,omitempty"`
+	// Empty on the last page.
+	NextCursor    string `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListConversationsResponse) Reset() {
+	*x = ListConversationsResponse{}
+	mi := &file_memory_v1_memory_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListConversationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConversationsResponse) ProtoMessage() {}
+
+func (x *ListConversationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConversationsResponse.ProtoReflect.Descriptor instead.
+func (*ListConversationsResponse) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListConversationsResponse) GetConversations() []*Conversation {
+	if x != nil {
+		return x.Conversations
+	}
+	return nil
+}
+
+func (x *ListConversationsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+// Mirrors types.GetConversationInput.
+type GetConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetConversationRequest) Reset() {
+	*x = GetConversationRequest{}
+	mi := &file_memory_v1_memory_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConversationRequest) ProtoMessage() {}
+
+func (x *GetConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConversationRequest.ProtoReflect.Descriptor instead.
+func (*GetConversationRequest) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetConversationRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+// Mirrors types.GetConversationOutput.
+type GetConversationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Conversation  *Conversation          `protobuf:"bytes,1,opt,name=conversation,proto3" json:"conversation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConversationResponse) Reset() {
+	*x = GetConversationResponse{}
+	mi := &file_memory_v1_memory_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConversationResponse) ProtoMessage() {}
+
+func (x *GetConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConversationResponse.ProtoReflect.Descriptor instead.
+func (*GetConversationResponse) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetConversationResponse) GetConversation() *Conversation {
+	if x != nil {
+		return x.Conversation
+	}
+	return nil
+}
+
+// Mirrors types.CloseConversationInput.
+type CloseConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CloseConversationRequest) Reset() {
+	*x = CloseConversationRequest{}
+	mi := &file_memory_v1_memory_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseConversationRequest) ProtoMessage() {}
+
+func (x *CloseConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseConversationRequest.ProtoReflect.Descriptor instead.
+func (*CloseConversationRequest) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CloseConversationRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+// Mirrors types.CloseConversationOutput.
+type CloseConversationResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	Status         string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CloseConversationResponse) Reset() {
+	*x = CloseConversationResponse{}
+	mi := &file_memory_v1_memory_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseConversationResponse) ProtoMessage() {}
+
+func (x *CloseConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseConversationResponse.ProtoReflect.Descriptor instead.
+func (*CloseConversationResponse) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CloseConversationResponse) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *CloseConversationResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+// Mirrors types.ArchiveConversationInput.
+type ArchiveConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ArchiveConversationRequest) Reset() {
+	*x = ArchiveConversationRequest{}
+	mi := &file_memory_v1_memory_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArchiveConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchiveConversationRequest) ProtoMessage() {}
+
+func (x *ArchiveConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchiveConversationRequest.ProtoReflect.Descriptor instead.
+func (*ArchiveConversationRequest) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ArchiveConversationRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+// Mirrors types.ArchiveConversationOutput.
+type ArchiveConversationResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	Status         string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ArchiveConversationResponse) Reset() {
+	*x = ArchiveConversationResponse{}
+	mi := &file_memory_v1_memory_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArchiveConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchiveConversationResponse) ProtoMessage() {}
+
+func (x *ArchiveConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchiveConversationResponse.ProtoReflect.Descriptor instead.
+func (*ArchiveConversationResponse) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ArchiveConversationResponse) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *ArchiveConversationResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+// Mirrors types.DeleteConversationInput.
+type DeleteConversationRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DeleteConversationRequest) Reset() {
+	*x = DeleteConversationRequest{}
+	mi := &file_memory_v1_memory_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteConversationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteConversationRequest) ProtoMessage() {}
+
+func (x *DeleteConversationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteConversationRequest.ProtoReflect.Descriptor instead.
+func (*DeleteConversationRequest) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DeleteConversationRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+// Mirrors types.DeleteConversationOutput.
+type DeleteConversationResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	Memories       int32                  `protobuf:"varint,2,opt,name=memories,proto3" json:"memories,omitempty"`
+	VectorMemories int32                  `protobuf:"varint,3,opt,name=vector_memories,json=vectorMemories,proto3" json:"vector_memories,omitempty"`
+	CacheEntries   int32                  `protobuf:"varint,4,opt,name=cache_entries,json=cacheEntries,proto3" json:"cache_entries,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DeleteConversationResponse) Reset() {
+	*x = DeleteConversationResponse{}
+	mi := &file_memory_v1_memory_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteConversationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteConversationResponse) ProtoMessage() {}
+
+func (x *DeleteConversationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteConversationResponse.ProtoReflect.Descriptor instead.
+func (*DeleteConversationResponse) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeleteConversationResponse) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *DeleteConversationResponse) GetMemories() int32 {
+	if x != nil {
+		return x.Memories
+	}
+	return 0
+}
+
+func (x *DeleteConversationResponse) GetVectorMemories() int32 {
+	if x != nil {
+		return x.VectorMemories
+	}
+	return 0
+}
+
+func (x *DeleteConversationResponse) GetCacheEntries() int32 {
+	if x != nil {
+		return x.CacheEntries
+	}
+	return 0
+}
+
+// Mirrors types.StoreSemanticMemoryInput.
+type StoreSemanticMemoryRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	Query          string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	Response       string                 `protobuf:"bytes,3,opt,name=response,proto3" json:"response,omitempty"`
+	Metadata       *structpb.Struct       `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Tags           []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	Scopes         []string               `protobuf:"bytes,6,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StoreSemanticMemoryRequest) Reset() {
+	*x = StoreSemanticMemoryRequest{}
+	mi := &file_memory_v1_memory_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoreSemanticMemoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoreSemanticMemoryRequest) ProtoMessage() {}
+
+func (x *StoreSemanticMemoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoreSemanticMemoryRequest.ProtoReflect.Descriptor instead.
+func (*StoreSemanticMemoryRequest) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *StoreSemanticMemoryRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *StoreSemanticMemoryRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *StoreSemanticMemoryRequest) GetResponse() string {
+	if x != nil {
+		return x.Response
+	}
+	return ""
+}
+
+func (x *StoreSemanticMemoryRequest) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *StoreSemanticMemoryRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *StoreSemanticMemoryRequest) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+// Mirrors types.StoreSemanticMemoryOutput.
+type StoreSemanticMemoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MemoryId      string                 `protobuf:"bytes,1,opt,name=memory_id,json=memoryId,proto3" json:"memory_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StoreSemanticMemoryResponse) Reset() {
+	*x = StoreSemanticMemoryResponse{}
+	mi := &file_memory_v1_memory_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoreSemanticMemoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoreSemanticMemoryResponse) ProtoMessage() {}
+
+func (x *StoreSemanticMemoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoreSemanticMemoryResponse.ProtoReflect.Descriptor instead.
+func (*StoreSemanticMemoryResponse) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *StoreSemanticMemoryResponse) GetMemoryId() string {
+	if x != nil {
+		return x.MemoryId
+	}
+	return ""
+}
+
+// Mirrors types.SemanticMemoryEntry.
+type SemanticMemoryEntry struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Query    string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Response string                 `protobuf:"bytes,2,opt,name=response,proto3" json:"response,omitempty"`
+	// Unset means now.
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Metadata      *structpb.Struct       `protobuf:"bytes,4,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Tags          []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SemanticMemoryEntry) Reset() {
+	*x = SemanticMemoryEntry{}
+	mi := &file_memory_v1_memory_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SemanticMemoryEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SemanticMemoryEntry) ProtoMessage() {}
+
+func (x *SemanticMemoryEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SemanticMemoryEntry.ProtoReflect.Descriptor instead.
+func (*SemanticMemoryEntry) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SemanticMemoryEntry) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SemanticMemoryEntry) GetResponse() string {
+	if x != nil {
+		return x.Response
+	}
+	return ""
+}
+
+func (x *SemanticMemoryEntry) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *SemanticMemoryEntry) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *SemanticMemoryEntry) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+// Mirrors types.StoreManySemanticMemoryInput.
+type StoreManySemanticMemoryRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	Memories       []*SemanticMemoryEntry `protobuf:"bytes,2,rep,name=memories,proto3" json:"memories,omitempty"`
+	Scopes         []string               `protobuf:"bytes,3,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StoreManySemanticMemoryRequest) Reset() {
+	*x = StoreManySemanticMemoryRequest{}
+	mi := &file_memory_v1_memory_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoreManySemanticMemoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoreManySemanticMemoryRequest) ProtoMessage() {}
+
+func (x *StoreManySemanticMemoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoreManySemanticMemoryRequest.ProtoReflect.Descriptor instead.
+func (*StoreManySemanticMemoryRequest) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *StoreManySemanticMemoryRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *StoreManySemanticMemoryRequest) GetMemories() []*SemanticMemoryEntry {
+	if x != nil {
+		return x.Memories
+	}
+	return nil
+}
+
+func (x *StoreManySemanticMemoryRequest) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+// Mirrors types.StoreManySemanticMemoryOutput.
+type StoreManySemanticMemoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MemoryIds     []string               `protobuf:"bytes,1,rep,name=memory_ids,json=memoryIds,proto3" json:"memory_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StoreManySemanticMemoryResponse) Reset() {
+	*x = StoreManySemanticMemoryResponse{}
+	mi := &file_memory_v1_memory_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoreManySemanticMemoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoreManySemanticMemoryResponse) ProtoMessage() {}
+
+func (x *StoreManySemanticMemoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoreManySemanticMemoryResponse.ProtoReflect.Descriptor instead.
+func (*StoreManySemanticMemoryResponse) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *StoreManySemanticMemoryResponse) GetMemoryIds() []string {
+	if x != nil {
+		return x.MemoryIds
+	}
+	return nil
+}
+
+// Mirrors types.MemoryFilter.
+type MemoryFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tags          []string               `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	Metadata      *structpb.Struct       `protobuf:"bytes,2,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MemoryFilter) Reset() {
+	*x = MemoryFilter{}
+	mi := &file_memory_v1_memory_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MemoryFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemoryFilter) ProtoMessage() {}
+
+func (x *MemoryFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemoryFilter.ProtoReflect.Descriptor instead.
+func (*MemoryFilter) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *MemoryFilter) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *MemoryFilter) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+// Mirrors types.RetrieveSemanticMemoryInput.
+type RetrieveSemanticMemoryRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	Query          string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	TopK           int32                  `protobuf:"varint,3,opt,name=top_k,json=topK,proto3" json:"top_k,omitempty"`
+	// Unset keeps every match.
+	MinScore       *float32      `protobuf:"fixed32,4,opt,name=min_score,json=minScore,proto3,oneof" json:"min_score,omitempty"`
+	Filter         *MemoryFilter `protobuf:"bytes,5,opt,name=filter,proto3" json:"filter,omitempty"`
+	Scope          string        `protobuf:"bytes,6,opt,name=scope,proto3" json:"scope,omitempty"`
+	MaxTokens      int32         `protobuf:"varint,7,opt,name=max_tokens,json=maxTokens,proto3" json:"max_tokens,omitempty"`
+	TruncateOldest bool          `protobuf:"varint,8,opt,name=truncate_oldest,json=truncateOldest,proto3" json:"truncate_oldest,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RetrieveSemanticMemoryRequest) Reset() {
+	*x = RetrieveSemanticMemoryRequest{}
+	mi := &file_memory_v1_memory_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetrieveSemanticMemoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetrieveSemanticMemoryRequest) ProtoMessage() {}
+
+func (x *RetrieveSemanticMemoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetrieveSemanticMemoryRequest.ProtoReflect.Descriptor instead.
+func (*RetrieveSemanticMemoryRequest) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RetrieveSemanticMemoryRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *RetrieveSemanticMemoryRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *RetrieveSemanticMemoryRequest) GetTopK() int32 {
+	if x != nil {
+		return x.TopK
+	}
+	return 0
+}
+
+func (x *RetrieveSemanticMemoryRequest) GetMinScore() float32 {
+	if x != nil && x.MinScore != nil {
+		return *x.MinScore
+	}
+	return 0
+}
+
+func (x *RetrieveSemanticMemoryRequest) GetFilter() *MemoryFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *RetrieveSemanticMemoryRequest) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *RetrieveSemanticMemoryRequest) GetMaxTokens() int32 {
+	if x != nil {
+		return x.MaxTokens
+	}
+	return 0
+}
+
+func (x *RetrieveSemanticMemoryRequest) GetTruncateOldest() bool {
+	if x != nil {
+		return x.TruncateOldest
+	}
+	return false
+}
+
+// Mirrors types.RetrieveSemanticMemoryOutput.
+type RetrieveSemanticMemoryResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Memories        []*Memory              `protobuf:"bytes,1,rep,name=memories,proto3" json:"memories,omitempty"`
+	SimilarMemories []*SemanticMemory      `protobuf:"bytes,2,rep,name=similar_memories,json=similarMemories,proto3" json:"similar_memories,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RetrieveSemanticMemoryResponse) Reset() {
+	*x = RetrieveSemanticMemoryResponse{}
+	mi := &file_memory_v1_memory_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetrieveSemanticMemoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetrieveSemanticMemoryResponse) ProtoMessage() {}
+
+func (x *RetrieveSemanticMemoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetrieveSemanticMemoryResponse.ProtoReflect.Descriptor instead.
+func (*RetrieveSemanticMemoryResponse) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RetrieveSemanticMemoryResponse) GetMemories() []*Memory {
+	if x != nil {
+		return x.Memories
+	}
+	return nil
+}
+
+func (x *RetrieveSemanticMemoryResponse) GetSimilarMemories() []*SemanticMemory {
+	if x != nil {
+		return x.SimilarMemories
+	}
+	return nil
+}
+
+// Mirrors types.Memory.
+type Memory struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Query         string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	Response      string                 `protobuf:"bytes,3,opt,name=response,proto3" json:"response,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Metadata      *structpb.Struct       `protobuf:"bytes,5,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Tags          []string               `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty"`
+	Truncated     bool                   `protobuf:"varint,7,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Memory) Reset() {
+	*x = Memory{}
+	mi := &file_memory_v1_memory_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Memory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Memory) ProtoMessage() {}
+
+func (x *Memory) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Memory.ProtoReflect.Descriptor instead.
+func (*Memory) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Memory) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Memory) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *Memory) GetResponse() string {
+	if x != nil {
+		return x.Response
+	}
+	return ""
+}
+
+func (x *Memory) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Memory) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Memory) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Memory) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+// Mirrors types.SemanticMemory.
+type SemanticMemory struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Query          string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	Response       string                 `protobuf:"bytes,3,opt,name=response,proto3" json:"response,omitempty"`
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Score          float32                `protobuf:"fixed32,5,opt,name=score,proto3" json:"score,omitempty"`
+	Distance       float32                `protobuf:"fixed32,6,opt,name=distance,proto3" json:"distance,omitempty"`
+	Rank           int32                  `protobuf:"varint,7,opt,name=rank,proto3" json:"rank,omitempty"`
+	Metadata       *structpb.Struct       `protobuf:"bytes,8,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Tags           []string               `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	ConversationId string                 `protobuf:"bytes,10,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SemanticMemory) Reset() {
+	*x = SemanticMemory{}
+	mi := &file_memory_v1_memory_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SemanticMemory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SemanticMemory) ProtoMessage() {}
+
+func (x *SemanticMemory) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SemanticMemory.ProtoReflect.Descriptor instead.
+func (*SemanticMemory) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SemanticMemory) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SemanticMemory) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SemanticMemory) GetResponse() string {
+	if x != nil {
+		return x.Response
+	}
+	return ""
+}
+
+func (x *SemanticMemory) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *SemanticMemory) GetScore() float32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *SemanticMemory) GetDistance() float32 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+func (x *SemanticMemory) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *SemanticMemory) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *SemanticMemory) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *SemanticMemory) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+// Mirrors types.UpdateSemanticMemoryInput.
+type UpdateSemanticMemoryRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	MemoryId       string                 `protobuf:"bytes,2,opt,name=memory_id,json=memoryId,proto3" json:"memory_id,omitempty"`
+	Query          string                 `protobuf:"bytes,3,opt,name=query,proto3" json:"query,omitempty"`
+	Response       string                 `protobuf:"bytes,4,opt,name=response,proto3" json:"response,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UpdateSemanticMemoryRequest) Reset() {
+	*x = UpdateSemanticMemoryRequest{}
+	mi := &file_memory_v1_memory_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSemanticMemoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSemanticMemoryRequest) ProtoMessage() {}
+
+func (x *UpdateSemanticMemoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSemanticMemoryRequest.ProtoReflect.Descriptor instead.
+func (*UpdateSemanticMemoryRequest) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *UpdateSemanticMemoryRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *UpdateSemanticMemoryRequest) GetMemoryId() string {
+	if x != nil {
+		return x.MemoryId
+	}
+	return ""
+}
+
+func (x *UpdateSemanticMemoryRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *UpdateSemanticMemoryRequest) GetResponse() string {
+	if x != nil {
+		return x.Response
+	}
+	return ""
+}
+
+// Mirrors types.UpdateSemanticMemoryOutput.
+type UpdateSemanticMemoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MemoryId      string                 `protobuf:"bytes,1,opt,name=memory_id,json=memoryId,proto3" json:"memory_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateSemanticMemoryResponse) Reset() {
+	*x = UpdateSemanticMemoryResponse{}
+	mi := &file_memory_v1_memory_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSemanticMemoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSemanticMemoryResponse) ProtoMessage() {}
+
+func (x *UpdateSemanticMemoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSemanticMemoryResponse.ProtoReflect.Descriptor instead.
+func (*UpdateSemanticMemoryResponse) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *UpdateSemanticMemoryResponse) GetMemoryId() string {
+	if x != nil {
+		return x.MemoryId
+	}
+	return ""
+}
+
+// Mirrors types.DeleteSemanticMemoryInput.
+type DeleteSemanticMemoryRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	MemoryId       string                 `protobuf:"bytes,2,opt,name=memory_id,json=memoryId,proto3" json:"memory_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DeleteSemanticMemoryRequest) Reset() {
+	*x = DeleteSemanticMemoryRequest{}
+	mi := &file_memory_v1_memory_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSemanticMemoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSemanticMemoryRequest) ProtoMessage() {}
+
+func (x *DeleteSemanticMemoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSemanticMemoryRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSemanticMemoryRequest) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *DeleteSemanticMemoryRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *DeleteSemanticMemoryRequest) GetMemoryId() string {
+	if x != nil {
+		return x.MemoryId
+	}
+	return ""
+}
+
+// Mirrors types.DeleteSemanticMemoryOutput.
+type DeleteSemanticMemoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MemoryId      string                 `protobuf:"bytes,1,opt,name=memory_id,json=memoryId,proto3" json:"memory_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSemanticMemoryResponse) Reset() {
+	*x = DeleteSemanticMemoryResponse{}
+	mi := &file_memory_v1_memory_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSemanticMemoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSemanticMemoryResponse) ProtoMessage() {}
+
+func (x *DeleteSemanticMemoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSemanticMemoryResponse.ProtoReflect.Descriptor instead.
+func (*DeleteSemanticMemoryResponse) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *DeleteSemanticMemoryResponse) GetMemoryId() string {
+	if x != nil {
+		return x.MemoryId
+	}
+	return ""
+}
+
+// Mirrors types.ReindexSemanticMemoryInput.
+type ReindexSemanticMemoryRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Empty reindexes every conversation.
+	ConversationId string `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ReindexSemanticMemoryRequest) Reset() {
+	*x = ReindexSemanticMemoryRequest{}
+	mi := &file_memory_v1_memory_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReindexSemanticMemoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReindexSemanticMemoryRequest) ProtoMessage() {}
+
+func (x *ReindexSemanticMemoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReindexSemanticMemoryRequest.ProtoReflect.Descriptor instead.
+func (*ReindexSemanticMemoryRequest) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ReindexSemanticMemoryRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+// Mirrors types.ReindexSemanticMemoryOutput.
+type ReindexSemanticMemoryResponse struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Conversations []*ReindexedConversation `protobuf:"bytes,1,rep,name=conversations,proto3" json:"conversations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReindexSemanticMemoryResponse) Reset() {
+	*x = ReindexSemanticMemoryResponse{}
+	mi := &file_memory_v1_memory_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReindexSemanticMemoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReindexSemanticMemoryResponse) ProtoMessage() {}
+
+func (x *ReindexSemanticMemoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReindexSemanticMemoryResponse.ProtoReflect.Descriptor instead.
+func (*ReindexSemanticMemoryResponse) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ReindexSemanticMemoryResponse) GetConversations() []*ReindexedConversation {
+	if x != nil {
+		return x.Conversations
+	}
+	return nil
+}
+
+// Mirrors types.ReindexedConversation.
+type ReindexedConversation struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	Memories       int32                  `protobuf:"varint,2,opt,name=memories,proto3" json:"memories,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ReindexedConversation) Reset() {
+	*x = ReindexedConversation{}
+	mi := &file_memory_v1_memory_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReindexedConversation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReindexedConversation) ProtoMessage() {}
+
+func (x *ReindexedConversation) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReindexedConversation.ProtoReflect.Descriptor instead.
+func (*ReindexedConversation) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ReindexedConversation) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *ReindexedConversation) GetMemories() int32 {
+	if x != nil {
+		return x.Memories
+	}
+	return 0
+}
+
+// Mirrors types.EmbeddingCacheStatsInput.
+type EmbeddingCacheStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EmbeddingCacheStatsRequest) Reset() {
+	*x = EmbeddingCacheStatsRequest{}
+	mi := &file_memory_v1_memory_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmbeddingCacheStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmbeddingCacheStatsRequest) ProtoMessage() {}
+
+func (x *EmbeddingCacheStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmbeddingCacheStatsRequest.ProtoReflect.Descriptor instead.
+func (*EmbeddingCacheStatsRequest) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{30}
+}
+
+// Mirrors types.EmbeddingCacheStatsOutput.
+type EmbeddingCacheStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Enabled       bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	MemoryHits    int64                  `protobuf:"varint,2,opt,name=memory_hits,json=memoryHits,proto3" json:"memory_hits,omitempty"`
+	StoreHits     int64                  `protobuf:"varint,3,opt,name=store_hits,json=storeHits,proto3" json:"store_hits,omitempty"`
+	Misses        int64                  `protobuf:"varint,4,opt,name=misses,proto3" json:"misses,omitempty"`
+	StoreErrors   int64                  `protobuf:"varint,5,opt,name=store_errors,json=storeErrors,proto3" json:"store_errors,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EmbeddingCacheStatsResponse) Reset() {
+	*x = EmbeddingCacheStatsResponse{}
+	mi := &file_memory_v1_memory_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmbeddingCacheStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmbeddingCacheStatsResponse) ProtoMessage() {}
+
+func (x *EmbeddingCacheStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmbeddingCacheStatsResponse.ProtoReflect.Descriptor instead.
+func (*EmbeddingCacheStatsResponse) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *EmbeddingCacheStatsResponse) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *EmbeddingCacheStatsResponse) GetMemoryHits() int64 {
+	if x != nil {
+		return x.MemoryHits
+	}
+	return 0
+}
+
+func (x *EmbeddingCacheStatsResponse) GetStoreHits() int64 {
+	if x != nil {
+		return x.StoreHits
+	}
+	return 0
+}
+
+func (x *EmbeddingCacheStatsResponse) GetMisses() int64 {
+	if x != nil {
+		return x.Misses
+	}
+	return 0
+}
+
+func (x *EmbeddingCacheStatsResponse) GetStoreErrors() int64 {
+	if x != nil {
+		return x.StoreErrors
+	}
+	return 0
+}
+
+// Mirrors types.EraseUserInput.
+type EraseUserRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	User            string                 `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	ConversationIds []string               `protobuf:"bytes,2,rep,name=conversation_ids,json=conversationIds,proto3" json:"conversation_ids,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *EraseUserRequest) Reset() {
+	*x = EraseUserRequest{}
+	mi := &file_memory_v1_memory_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EraseUserRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EraseUserRequest) ProtoMessage() {}
+
+func (x *EraseUserRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EraseUserRequest.ProtoReflect.Descriptor instead.
+func (*EraseUserRequest) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *EraseUserRequest) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *EraseUserRequest) GetConversationIds() []string {
+	if x != nil {
+		return x.ConversationIds
+	}
+	return nil
+}
+
+// Mirrors types.EraseUserOutput.
+type EraseUserResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          string                 `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Conversations []*ErasedConversation  `protobuf:"bytes,2,rep,name=conversations,proto3" json:"conversations,omitempty"`
+	ErasedAt      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=erased_at,json=erasedAt,proto3" json:"erased_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EraseUserResponse) Reset() {
+	*x = EraseUserResponse{}
+	mi := &file_memory_v1_memory_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EraseUserResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EraseUserResponse) ProtoMessage() {}
+
+func (x *EraseUserResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EraseUserResponse.ProtoReflect.Descriptor instead.
+func (*EraseUserResponse) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *EraseUserResponse) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *EraseUserResponse) GetConversations() []*ErasedConversation {
+	if x != nil {
+		return x.Conversations
+	}
+	return nil
+}
+
+func (x *EraseUserResponse) GetErasedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ErasedAt
+	}
+	return nil
+}
+
+// Mirrors types.ErasedConversation.
+type ErasedConversation struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`
+	Agent          string                 `protobuf:"bytes,2,opt,name=agent,proto3" json:"agent,omitempty"`
+	// Unset for conversations that were already deleted.
+	CreatedAt      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Memories       int32                  `protobuf:"varint,4,opt,name=memories,proto3" json:"memories,omitempty"`
+	VectorMemories int32                  `protobuf:"varint,5,opt,name=vector_memories,json=vectorMemories,proto3" json:"vector_memories,omitempty"`
+	CacheEntries   int32                  `protobuf:"varint,6,opt,name=cache_entries,json=cacheEntries,proto3" json:"cache_entries,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ErasedConversation) Reset() {
+	*x = ErasedConversation{}
+	mi := &file_memory_v1_memory_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErasedConversation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErasedConversation) ProtoMessage() {}
+
+func (x *ErasedConversation) ProtoReflect() protoreflect.Message {
+	mi := &file_memory_v1_memory_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErasedConversation.ProtoReflect.Descriptor instead.
+func (*ErasedConversation) Descriptor() ([]byte, []int) {
+	return file_memory_v1_memory_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ErasedConversation) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *ErasedConversation) GetAgent() string {
+	if x != nil {
+		return x.Agent
+	}
+	return ""
+}
+
+func (x *ErasedConversation) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *ErasedConversation) GetMemories() int32 {
+	if x != nil {
+		return x.Memories
+	}
+	return 0
+}
+
+func (x *ErasedConversation) GetVectorMemories() int32 {
+	if x != nil {
+		return x.VectorMemories
+	}
+	return 0
+}
+
+func (x *ErasedConversation) GetCacheEntries() int32 {
+	if x != nil {
+		return x.CacheEntries
+	}
+	return 0
+}
+
+var File_memory_v1_memory_proto protoreflect.FileDescriptor
+
+const file_memory_v1_memory_proto_rawDesc = "" +
+	"\n" +
+	"\x16memory/v1/memory.proto\x12\tmemory.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"G\n" +
+	"\x1bRegisterConversationRequest\x12\x14\n" +
+	"\x05agent\x18\x01 \x01(\tR\x05agent\x12\x12\n" +
+	"\x04user\x18\x02 \x01(\tR\x04user\"G\n" +
+	"\x1cRegisterConversationResponse\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\"\x84\x02\n" +
+	"\fConversation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05agent\x18\x02 \x01(\tR\x05agent\x12\x12\n" +
+	"\x04user\x18\x03 \x01(\tR\x04user\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12!\n" +
+	"\fmemory_count\x18\x06 \x01(\x05R\vmemoryCount\x12D\n" +
+	"\x10last_activity_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\x0elastActivityAt\"\x8a\x01\n" +
+	"\x18ListConversationsRequest\x12\x14\n" +
+	"\x05agent\x18\x01 \x01(\tR\x05agent\x12\x12\n" +
+	"\x04user\x18\x02 \x01(\tR\x04user\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\x05 \x01(\tR\x06cursor\"{\n" +
+	"\x19ListConversationsResponse\x12=\n" +
+	"\rconversations\x18\x01 \x03(\v2\x17.memory.v1.ConversationR\rconversations\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"A\n" +
+	"\x16GetConversationRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\"V\n" +
+	"\x17GetConversationResponse\x12;\n" +
+	"\fconversation\x18\x01 \x01(\v2\x17.memory.v1.ConversationR\fconversation\"C\n" +
+	"\x18CloseConversationRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\"\\\n" +
+	"\x19CloseConversationResponse\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"E\n" +
+	"\x1aArchiveConversationRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\"^\n" +
+	"\x1bArchiveConversationResponse\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"D\n" +
+	"\x19DeleteConversationRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\"\xaf\x01\n" +
+	"\x1aDeleteConversationResponse\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x1a\n" +
+	"\bmemories\x18\x02 \x01(\x05R\bmemories\x12'\n" +
+	"\x0fvector_memories\x18\x03 \x01(\x05R\x0evectorMemories\x12#\n" +
+	"\rcache_entries\x18\x04 \x01(\x05R\fcacheEntries\"\xd8\x01\n" +
+	"\x1aStoreSemanticMemoryRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\x12\x1a\n" +
+	"\bresponse\x18\x03 \x01(\tR\bresponse\x123\n" +
+	"\bmetadata\x18\x04 \x01(\v2\x17.google.protobuf.StructR\bmetadata\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x12\x16\n" +
+	"\x06scopes\x18\x06 \x03(\tR\x06scopes\":\n" +
+	"\x1bStoreSemanticMemoryResponse\x12\x1b\n" +
+	"\tmemory_id\x18\x01 \x01(\tR\bmemoryId\"\xcb\x01\n" +
+	"\x13SemanticMemoryEntry\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1a\n" +
+	"\bresponse\x18\x02 \x01(\tR\bresponse\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x123\n" +
+	"\bmetadata\x18\x04 \x01(\v2\x17.google.protobuf.StructR\bmetadata\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\"\x9d\x01\n" +
+	"\x1eStoreManySemanticMemoryRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12:\n" +
+	"\bmemories\x18\x02 \x03(\v2\x1e.memory.v1.SemanticMemoryEntryR\bmemories\x12\x16\n" +
+	"\x06scopes\x18\x03 \x03(\tR\x06scopes\"@\n" +
+	"\x1fStoreManySemanticMemoryResponse\x12\x1d\n" +
+	"\n" +
+	"memory_ids\x18\x01 \x03(\tR\tmemoryIds\"W\n" +
+	"\fMemoryFilter\x12\x12\n" +
+	"\x04tags\x18\x01 \x03(\tR\x04tags\x123\n" +
+	"\bmetadata\x18\x02 \x01(\v2\x17.google.protobuf.StructR\bmetadata\"\xb2\x02\n" +
+	"\x1dRetrieveSemanticMemoryRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\x12\x13\n" +
+	"\x05top_k\x18\x03 \x01(\x05R\x04topK\x12 \n" +
+	"\tmin_score\x18\x04 \x01(\x02H\x00R\bminScore\x88\x01\x01\x12/\n" +
+	"\x06filter\x18\x05 \x01(\v2\x17.memory.v1.MemoryFilterR\x06filter\x12\x14\n" +
+	"\x05scope\x18\x06 \x01(\tR\x05scope\x12\x1d\n" +
+	"\n" +
+	"max_tokens\x18\a \x01(\x05R\tmaxTokens\x12'\n" +
+	"\x0ftruncate_oldest\x18\b \x01(\bR\x0etruncateOldestB\f\n" +
+	"\n" +
+	"_min_score\"\x95\x01\n" +
+	"\x1eRetrieveSemanticMemoryResponse\x12-\n" +
+	"\bmemories\x18\x01 \x03(\v2\x11.memory.v1.MemoryR\bmemories\x12D\n" +
+	"\x10similar_memories\x18\x02 \x03(\v2\x19.memory.v1.SemanticMemoryR\x0fsimilarMemories\"\xec\x01\n" +
+	"\x06Memory\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\x12\x1a\n" +
+	"\bresponse\x18\x03 \x01(\tR\bresponse\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x123\n" +
+	"\bmetadata\x18\x05 \x01(\v2\x17.google.protobuf.StructR\bmetadata\x12\x12\n" +
+	"\x04tags\x18\x06 \x03(\tR\x04tags\x12\x1c\n" +
+	"\ttruncated\x18\a \x01(\bR\ttruncated\"\xc5\x02\n" +
+	"\x0eSemanticMemory\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\x12\x1a\n" +
+	"\bresponse\x18\x03 \x01(\tR\bresponse\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x14\n" +
+	"\x05score\x18\x05 \x01(\x02R\x05score\x12\x1a\n" +
+	"\bdistance\x18\x06 \x01(\x02R\bdistance\x12\x12\n" +
+	"\x04rank\x18\a \x01(\x05R\x04rank\x123\n" +
+	"\bmetadata\x18\b \x01(\v2\x17.google.protobuf.StructR\bmetadata\x12\x12\n" +
+	"\x04tags\x18\t \x03(\tR\x04tags\x12'\n" +
+	"\x0fconversation_id\x18\n" +
+	" \x01(\tR\x0econversationId\"\x95\x01\n" +
+	"\x1bUpdateSemanticMemoryRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x1b\n" +
+	"\tmemory_id\x18\x02 \x01(\tR\bmemoryId\x12\x14\n" +
+	"\x05query\x18\x03 \x01(\tR\x05query\x12\x1a\n" +
+	"\bresponse\x18\x04 \x01(\tR\bresponse\";\n" +
+	"\x1cUpdateSemanticMemoryResponse\x12\x1b\n" +
+	"\tmemory_id\x18\x01 \x01(\tR\bmemoryId\"c\n" +
+	"\x1bDeleteSemanticMemoryRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x1b\n" +
+	"\tmemory_id\x18\x02 \x01(\tR\bmemoryId\";\n" +
+	"\x1cDeleteSemanticMemoryResponse\x12\x1b\n" +
+	"\tmemory_id\x18\x01 \x01(\tR\bmemoryId\"G\n" +
+	"\x1cReindexSemanticMemoryRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\"g\n" +
+	"\x1dReindexSemanticMemoryResponse\x12F\n" +
+	"\rconversations\x18\x01 \x03(\v2 .memory.v1.ReindexedConversationR\rconversations\"\\\n" +
+	"\x15ReindexedConversation\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x1a\n" +
+	"\bmemories\x18\x02 \x01(\x05R\bmemories\"\x1c\n" +
+	"\x1aEmbeddingCacheStatsRequest\"\xb2\x01\n" +
+	"\x1bEmbeddingCacheStatsResponse\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x1f\n" +
+	"\vmemory_hits\x18\x02 \x01(\x03R\n" +
+	"memoryHits\x12\x1d\n" +
+	"\n" +
+	"store_hits\x18\x03 \x01(\x03R\tstoreHits\x12\x16\n" +
+	"\x06misses\x18\x04 \x01(\x03R\x06misses\x12!\n" +
+	"\fstore_errors\x18\x05 \x01(\x03R\vstoreErrors\"Q\n" +
+	"\x10EraseUserRequest\x12\x12\n" +
+	"\x04user\x18\x01 \x01(\tR\x04user\x12)\n" +
+	"\x10conversation_ids\x18\x02 \x03(\tR\x0fconversationIds\"\xa5\x01\n" +
+	"\x11EraseUserResponse\x12\x12\n" +
+	"\x04user\x18\x01 \x01(\tR\x04user\x12C\n" +
+	"\rconversations\x18\x02 \x03(\v2\x1d.memory.v1.ErasedConversationR\rconversations\x127\n" +
+	"\terased_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\berasedAt\"\xf8\x01\n" +
+	"\x12ErasedConversation\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x14\n" +
+	"\x05agent\x18\x02 \x01(\tR\x05agent\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x1a\n" +
+	"\bmemories\x18\x04 \x01(\x05R\bmemories\x12'\n" +
+	"\x0fvector_memories\x18\x05 \x01(\x05R\x0evectorMemories\x12#\n" +
+	"\rcache_entries\x18\x06 \x01(\x05R\fcacheEntries2\xc2\n" +
+	"\n" +
+	"\x15SemanticMemoryService\x12g\n" +
+	"\x14RegisterConversation\x12&.memory.v1.RegisterConversationRequest\x1a'.memory.v1.RegisterConversationResponse\x12V\n" +
+	"\x05Store\x12%.memory.v1.StoreSemanticMemoryRequest\x1a&.memory.v1.StoreSemanticMemoryResponse\x12b\n" +
+	"\tStoreMany\x12).memory.v1.StoreManySemanticMemoryRequest\x1a*.memory.v1.StoreManySemanticMemoryResponse\x12_\n" +
+	"\bRetrieve\x12(.memory.v1.RetrieveSemanticMemoryRequest\x1a).memory.v1.RetrieveSemanticMemoryResponse\x12Y\n" +
+	"\x06Update\x12&.memory.v1.UpdateSemanticMemoryRequest\x1a'.memory.v1.UpdateSemanticMemoryResponse\x12Y\n" +
+	"\x06Delete\x12&.memory.v1.DeleteSemanticMemoryRequest\x1a'.memory.v1.DeleteSemanticMemoryResponse\x12\\\n" +
+	"\aReindex\x12'.memory.v1.ReindexSemanticMemoryRequest\x1a(.memory.v1.ReindexSemanticMemoryResponse\x12d\n" +
+	"\x13EmbeddingCacheStats\x12%.memory.v1.EmbeddingCacheStatsRequest\x1a&.memory.v1.EmbeddingCacheStatsResponse\x12F\n" +
+	"\tEraseUser\x12\x1b.memory.v1.EraseUserRequest\x1a\x1c.memory.v1.EraseUserResponse\x12^\n" +
+	"\x11ListConversations\x12#.memory.v1.ListConversationsRequest\x1a$.memory.v1.ListConversationsResponse\x12X\n" +
+	"\x0fGetConversation\x12!.memory.v1.GetConversationRequest\x1a\".memory.v1.GetConversationResponse\x12^\n" +
+	"\x11CloseConversation\x12#.memory.v1.CloseConversationRequest\x1a$.memory.v1.CloseConversationResponse\x12d\n" +
+	"\x13ArchiveConversation\x12%.memory.v1.ArchiveConversationRequest\x1a&.memory.v1.ArchiveConversationResponse\x12a\n" +
+	"\x12DeleteConversation\x12$.memory.v1.DeleteConversationRequest\x1a%.memory.v1.DeleteConversationResponseB9Z7github.com/haren7/minimal-memory/api/memory/v1;memoryv1b\x06proto3"
+
+var (
+	file_memory_v1_memory_proto_rawDescOnce sync.Once
+	file_memory_v1_memory_proto_rawDescData []byte
+)
+
+func file_memory_v1_memory_proto_rawDescGZIP() []byte {
+	file_memory_v1_memory_proto_rawDescOnce.Do(func() {
+		file_memory_v1_memory_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_memory_v1_memory_proto_rawDesc), len(file_memory_v1_memory_proto_rawDesc)))
+	})
+	return file_memory_v1_memory_proto_rawDescData
+}
+
+var file_memory_v1_memory_proto_msgTypes = make([]protoimpl.MessageInfo, 35)
+var file_memory_v1_memory_proto_goTypes = []any{
+	(*RegisterConversationRequest)(nil),     // 0: memory.v1.RegisterConversationRequest
+	(*RegisterConversationResponse)(nil),    // 1: memory.v1.RegisterConversationResponse
+	(*Conversation)(nil),                    // 2: memory.v1.Conversation
+	(*ListConversationsRequest)(nil),        // 3: memory.v1.ListConversationsRequest
+	(*ListConversationsResponse)(nil),       // 4: memory.v1.ListConversationsResponse
+	(*GetConversationRequest)(nil),          // 5: memory.v1.GetConversationRequest
+	(*GetConversationResponse)(nil),         // 6: memory.v1.GetConversationResponse
+	(*CloseConversationRequest)(nil),        // 7: memory.v1.CloseConversationRequest
+	(*CloseConversationResponse)(nil),       // 8: memory.v1.CloseConversationResponse
+	(*ArchiveConversationRequest)(nil),      // 9: memory.v1.ArchiveConversationRequest
+	(*ArchiveConversationResponse)(nil),     // 10: memory.v1.ArchiveConversationResponse
+	(*DeleteConversationRequest)(nil),       // 11: memory.v1.DeleteConversationRequest
+	(*DeleteConversationResponse)(nil),      // 12: memory.v1.DeleteConversationResponse
+	(*StoreSemanticMemoryRequest)(nil),      // 13: memory.v1.StoreSemanticMemoryRequest
+	(*StoreSemanticMemoryResponse)(nil),     // 14: memory.v1.StoreSemanticMemoryResponse
+	(*SemanticMemoryEntry)(nil),             // 15: memory.v1.SemanticMemoryEntry
+	(*StoreManySemanticMemoryRequest)(nil),  // 16: memory.v1.StoreManySemanticMemoryRequest
+	(*StoreManySemanticMemoryResponse)(nil), // 17: memory.v1.StoreManySemanticMemoryResponse
+	(*MemoryFilter)(nil),                    // 18: memory.v1.MemoryFilter
+	(*RetrieveSemanticMemoryRequest)(nil),   // 19: memory.v1.RetrieveSemanticMemoryRequest
+	(*RetrieveSemanticMemoryResponse)(nil),  // 20: memory.v1.RetrieveSemanticMemoryResponse
+	(*Memory)(nil),                          // 21: memory.v1.Memory
+	(*SemanticMemory)(nil),                  // 22: memory.v1.SemanticMemory
+	(*UpdateSemanticMemoryRequest)(nil),     // 23: memory.v1.UpdateSemanticMemoryRequest
+	(*UpdateSemanticMemoryResponse)(nil),    // 24: memory.v1.UpdateSemanticMemoryResponse
+	(*DeleteSemanticMemoryRequest)(nil),     // 25: memory.v1.DeleteSemanticMemoryRequest
+	(*DeleteSemanticMemoryResponse)(nil),    // 26: memory.v1.DeleteSemanticMemoryResponse
+	(*ReindexSemanticMemoryRequest)(nil),    // 27: memory.v1.ReindexSemanticMemoryRequest
+	(*ReindexSemanticMemoryResponse)(nil),   // 28: memory.v1.ReindexSemanticMemoryResponse
+	(*ReindexedConversation)(nil),           // 29: memory.v1.ReindexedConversation
+	(*EmbeddingCacheStatsRequest)(nil),      // 30: memory.v1.EmbeddingCacheStatsRequest
+	(*EmbeddingCacheStatsResponse)(nil),     // 31: memory.v1.EmbeddingCacheStatsResponse
+	(*EraseUserRequest)(nil),                // 32: memory.v1.EraseUserRequest
+	(*EraseUserResponse)(nil),               // 33: memory.v1.EraseUserResponse
+	(*ErasedConversation)(nil),              // 34: memory.v1.ErasedConversation
+	(*timestamppb.Timestamp)(nil),           // 35: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),                 // 36: google.protobuf.Struct
+}
+var file_memory_v1_memory_proto_depIdxs = []int32{
+	35, // 0: memory.v1.Conversation.created_at:type_name -> google.protobuf.Timestamp
+	35, // 1: memory.v1.Conversation.last_activity_at:type_name -> google.protobuf.Timestamp
+	2,  // 2: memory.v1.ListConversationsResponse.conversations:type_name -> memory.v1.Conversation
+	2,  // 3: memory.v1.GetConversationResponse.conversation:type_name -> memory.v1.Conversation
+	36, // 4: memory.v1.StoreSemanticMemoryRequest.metadata:type_name -> google.protobuf.Struct
+	35, // 5: memory.v1.SemanticMemoryEntry.created_at:type_name -> google.protobuf.Timestamp
+	36, // 6: memory.v1.SemanticMemoryEntry.metadata:type_name -> google.protobuf.Struct
+	15, // 7: memory.v1.StoreManySemanticMemoryRequest.memories:type_name -> memory.v1.SemanticMemoryEntry
+	36, // 8: memory.v1.MemoryFilter.metadata:type_name -> google.protobuf.Struct
+	18, // 9: memory.v1.RetrieveSemanticMemoryRequest.filter:type_name -> memory.v1.MemoryFilter
+	21, // 10: memory.v1.RetrieveSemanticMemoryResponse.memories:type_name -> memory.v1.Memory
+	22, // 11: memory.v1.RetrieveSemanticMemoryResponse.similar_memories:type_name -> memory.v1.SemanticMemory
+	35, // 12: memory.v1.Memory.created_at:type_name -> google.protobuf.Timestamp
+	36, // 13: memory.v1.Memory.metadata:type_name -> google.protobuf.Struct
+	35, // 14: memory.v1.SemanticMemory.created_at:type_name -> google.protobuf.Timestamp
+	36, // 15: memory.v1.SemanticMemory.metadata:type_name -> google.protobuf.Struct
+	29, // 16: memory.v1.ReindexSemanticMemoryResponse.conversations:type_name -> memory.v1.ReindexedConversation
+	34, // 17: memory.v1.EraseUserResponse.conversations:type_name -> memory.v1.ErasedConversation
+	35, // 18: memory.v1.EraseUserResponse.erased_at:type_name -> google.protobuf.Timestamp
+	35, // 19: memory.v1.ErasedConversation.created_at:type_name -> google.protobuf.Timestamp
+	0,  // 20: memory.v1.SemanticMemoryService.RegisterConversation:input_type -> memory.v1.RegisterConversationRequest
+	13, // 21: memory.v1.SemanticMemoryService.Store:input_type -> memory.v1.StoreSemanticMemoryRequest
+	16, // 22: memory.v1.SemanticMemoryService.StoreMany:input_type -> memory.v1.StoreManySemanticMemoryRequest
+	19, // 23: memory.v1.SemanticMemoryService.Retrieve:input_type -> memory.v1.RetrieveSemanticMemoryRequest
+	23, // 24: memory.v1.SemanticMemoryService.Update:input_type -> memory.v1.UpdateSemanticMemoryRequest
+	25, // 25: memory.v1.SemanticMemoryService.Delete:input_type -> memory.v1.DeleteSemanticMemoryRequest
+	27, // 26: memory.v1.SemanticMemoryService.Reindex:input_type -> memory.v1.ReindexSemanticMemoryRequest
+	30, // 27: memory.v1.SemanticMemoryService.EmbeddingCacheStats:input_type -> memory.v1.EmbeddingCacheStatsRequest
+	32, // 28: memory.v1.SemanticMemoryService.EraseUser:input_type -> memory.v1.EraseUserRequest
+	3,  // 29: memory.v1.SemanticMemoryService.ListConversations:input_type -> memory.v1.ListConversationsRequest
+	5,  // 30: memory.v1.SemanticMemoryService.GetConversation:input_type -> memory.v1.GetConversationRequest
+	7,  // 31: memory.v1.SemanticMemoryService.CloseConversation:input_type -> memory.v1.CloseConversationRequest
+	9,  // 32: memory.v1.SemanticMemoryService.ArchiveConversation:input_type -> memory.v1.ArchiveConversationRequest
+	11, // 33: memory.v1.SemanticMemoryService.DeleteConversation:input_type -> memory.v1.DeleteConversationRequest
+	1,  // 34: memory.v1.SemanticMemoryService.RegisterConversation:output_type -> memory.v1.RegisterConversationResponse
+	14, // 35: memory.v1.SemanticMemoryService.Store:output_type -> memory.v1.StoreSemanticMemoryResponse
+	17, // 36: memory.v1.SemanticMemoryService.StoreMany:output_type -> memory.v1.StoreManySemanticMemoryResponse
+	20, // 37: memory.v1.SemanticMemoryService.Retrieve:output_type -> memory.v1.RetrieveSemanticMemoryResponse
+	24, // 38: memory.v1.SemanticMemoryService.Update:output_type -> memory.v1.UpdateSemanticMemoryResponse
+	26, // 39: memory.v1.SemanticMemoryService.Delete:output_type -> memory.v1.DeleteSemanticMemoryResponse
+	28, // 40: memory.v1.SemanticMemoryService.Reindex:output_type -> memory.v1.ReindexSemanticMemoryResponse
+	31, // 41: memory.v1.SemanticMemoryService.EmbeddingCacheStats:output_type -> memory.v1.EmbeddingCacheStatsResponse
+	33, // 42: memory.v1.SemanticMemoryService.EraseUser:output_type -> memory.v1.EraseUserResponse
+	4,  // 43: memory.v1.SemanticMemoryService.ListConversations:output_type -> memory.v1.ListConversationsResponse
+	6,  // 44: memory.v1.SemanticMemoryService.GetConversation:output_type -> memory.v1.GetConversationResponse
+	8,  // 45: memory.v1.SemanticMemoryService.CloseConversation:output_type -> memory.v1.CloseConversationResponse
+	10, // 46: memory.v1.SemanticMemoryService.ArchiveConversation:output_type -> memory.v1.ArchiveConversationResponse
+	12, // 47: memory.v1.SemanticMemoryService.DeleteConversation:output_type -> memory.v1.DeleteConversationResponse
+	34, // [34:48] is the sub-list for method output_type
+	20, // [20:34] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
+}
+
+func init() { file_memory_v1_memory_proto_init() }
+func file_memory_v1_memory_proto_init() {
+	if File_memory_v1_memory_proto != nil {
+		return
+	}
+	file_memory_v1_memory_proto_msgTypes[19].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_memory_v1_memory_proto_rawDesc), len(file_memory_v1_memory_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   35,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_memory_v1_memory_proto_goTypes,
+		DependencyIndexes: file_memory_v1_memory_proto_depIdxs,
+		MessageInfos:      file_memory_v1_memory_proto_msgTypes,
+	}.Build()
+	File_memory_v1_memory_proto = out.File
+	file_memory_v1_memory_proto_goTypes = nil
+	file_memory_v1_memory_proto_depIdxs = nil
+}
@@ -0,0 +1,619 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.1
+// - protoc             (unknown)
+// source: memory/v1/memory.proto
+
+package memoryv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SemanticMemoryService_RegisterConversation_FullMethodName = "/memory.v1.SemanticMemoryService/RegisterConversation"
+	SemanticMemoryService_Store_FullMethodName                = "/memory.v1.SemanticMemoryService/Store"
+	SemanticMemoryService_StoreMany_FullMethodName            = "/memory.v1.SemanticMemoryService/StoreMany"
+	SemanticMemoryService_Retrieve_FullMethodName             = "/memory.v1.SemanticMemoryService/Retrieve"
+	SemanticMemoryService_Update_FullMethodName               = "/memory.v1.SemanticMemoryService/Update"
+	SemanticMemoryService_Delete_FullMethodName               = "/memory.v1.SemanticMemoryService/Delete"
+	SemanticMemoryService_Reindex_FullMethodName              = "/memory.v1.SemanticMemoryService/Reindex"
+	SemanticMemoryService_EmbeddingCacheStats_FullMethodName  = "/memory.v1.SemanticMemoryService/EmbeddingCacheStats"
+	SemanticMemoryService_EraseUser_FullMethodName            = "/memory.v1.SemanticMemoryService/EraseUser"
+	SemanticMemoryService_ListConversations_FullMethodName    = "/memory.v1.SemanticMemoryService/ListConversations"
+	SemanticMemoryService_GetConversation_FullMethodName      = "/memory.v1.SemanticMemoryService/GetConversation"
+	SemanticMemoryService_CloseConversation_FullMethodName    = "/memory.v1.SemanticMemoryService/CloseConversation"
+	SemanticMemoryService_ArchiveConversation_FullMethodName  = "/memory.v1.SemanticMemoryService/ArchiveConversation"
+	SemanticMemoryService_DeleteConversation_FullMethodName   = "/memory.v1.SemanticMemoryService/DeleteConversation"
+)
+
+// SemanticMemoryServiceClient is the client API for SemanticMemoryService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SemanticMemoryService mirrors clients.SemanticMemoryClient.
+type SemanticMemoryServiceClient interface {
+	RegisterConversation(ctx context.Context, in *RegisterConversationRequest, opts ...grpc.CallOption) (*RegisterConversationResponse, error)
+	Store(ctx context.Context, in *StoreSemanticMemoryRequest, opts ...grpc.CallOption) (*StoreSemanticMemoryResponse, error)
+	StoreMany(ctx context.Context, in *StoreManySemanticMemoryRequest, opts ...grpc.CallOption) (*StoreManySemanticMemoryResponse, error)
+	Retrieve(ctx context.Context, in *RetrieveSemanticMemoryRequest, opts ...grpc.CallOption) (*RetrieveSemanticMemoryResponse, error)
+	Update(ctx context.Context, in *UpdateSemanticMemoryRequest, opts ...grpc.CallOption) (*UpdateSemanticMemoryResponse, error)
+	Delete(ctx context.Context, in *DeleteSemanticMemoryRequest, opts ...grpc.CallOption) (*DeleteSemanticMemoryResponse, error)
+	Reindex(ctx context.Context, in *ReindexSemanticMemoryRequest, opts ...grpc.CallOption) (*ReindexSemanticMemoryResponse, error)
+	EmbeddingCacheStats(ctx context.Context, in *EmbeddingCacheStatsRequest, opts ...grpc.CallOption) (*EmbeddingCacheStatsResponse, error)
+	EraseUser(ctx context.Context, in *EraseUserRequest, opts ...grpc.CallOption) (*EraseUserResponse, error)
+	ListConversations(ctx context.Context, in *ListConversationsRequest, opts ...grpc.CallOption) (*ListConversationsResponse, error)
+	GetConversation(ctx context.Context, in *GetConversationRequest, opts ...grpc.CallOption) (*GetConversationResponse, error)
+	CloseConversation(ctx context.Context, in *CloseConversationRequest, opts ...grpc.CallOption) (*CloseConversationResponse, error)
+	ArchiveConversation(ctx context.Context, in *ArchiveConversationRequest, opts ...grpc.CallOption) (*ArchiveConversationResponse, error)
+	DeleteConversation(ctx context.Context, in *DeleteConversationRequest, opts ...grpc.CallOption) (*DeleteConversationResponse, error)
+}
+
+type semanticMemoryServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSemanticMemoryServiceClient(cc grpc.ClientConnInterface) SemanticMemoryServiceClient {
+	return &semanticMemoryServiceClient{cc}
+}
+
+func (c *semanticMemoryServiceClient) RegisterConversation(ctx context.Context, in *RegisterConversationRequest, opts ...grpc.CallOption) (*RegisterConversationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterConversationResponse)
+	err := c.cc.Invoke(ctx, SemanticMemoryService_RegisterConversation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *semanticMemoryServiceClient) Store(ctx context.Context, in *StoreSemanticMemoryRequest, opts ...grpc.CallOption) (*StoreSemanticMemoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StoreSemanticMemoryResponse)
+	err := c.cc.Invoke(ctx, SemanticMemoryService_Store_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *semanticMemoryServiceClient) StoreMany(ctx context.Context, in *StoreManySemanticMemoryRequest, opts ...grpc.CallOption) (*StoreManySemanticMemoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StoreManySemanticMemoryResponse)
+	err := c.cc.Invoke(ctx, SemanticMemoryService_StoreMany_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *semanticMemoryServiceClient) Retrieve(ctx context.Context, in *RetrieveSemanticMemoryRequest, opts ...grpc.CallOption) (*RetrieveSemanticMemoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RetrieveSemanticMemoryResponse)
+	err := c.cc.Invoke(ctx, SemanticMemoryService_Retrieve_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *semanticMemoryServiceClient) Update(ctx context.Context, in *UpdateSemanticMemoryRequest, opts ...grpc.CallOption) (*UpdateSemanticMemoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateSemanticMemoryResponse)
+	err := c.cc.Invoke(ctx, SemanticMemoryService_Update_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *semanticMemoryServiceClient) Delete(ctx context.Context, in *DeleteSemanticMemoryRequest, opts ...grpc.CallOption) (*DeleteSemanticMemoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteSemanticMemoryResponse)
+	err := c.cc.Invoke(ctx, SemanticMemoryService_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *semanticMemoryServiceClient) Reindex(ctx context.Context, in *ReindexSemanticMemoryRequest, opts ...grpc.CallOption) (*ReindexSemanticMemoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReindexSemanticMemoryResponse)
+	err := c.cc.Invoke(ctx, SemanticMemoryService_Reindex_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *semanticMemoryServiceClient) EmbeddingCacheStats(ctx context.Context, in *EmbeddingCacheStatsRequest, opts ...grpc.CallOption) (*EmbeddingCacheStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EmbeddingCacheStatsResponse)
+	err := c.cc.Invoke(ctx, SemanticMemoryService_EmbeddingCacheStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *semanticMemoryServiceClient) EraseUser(ctx context.Context, in *EraseUserRequest, opts ...grpc.CallOption) (*EraseUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EraseUserResponse)
+	err := c.cc.Invoke(ctx, SemanticMemoryService_EraseUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *semanticMemoryServiceClient) ListConversations(ctx context.Context, in *ListConversationsRequest, opts ...grpc.CallOption) (*ListConversationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListConversationsResponse)
+	err := c.cc.Invoke(ctx, SemanticMemoryService_ListConversations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *semanticMemoryServiceClient) GetConversation(ctx context.Context, in *GetConversationRequest, opts ...grpc.CallOption) (*GetConversationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetConversationResponse)
+	err := c.cc.Invoke(ctx, SemanticMemoryService_GetConversation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *semanticMemoryServiceClient) CloseConversation(ctx context.Context, in *CloseConversationRequest, opts ...grpc.CallOption) (*CloseConversationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CloseConversationResponse)
+	err := c.cc.Invoke(ctx, SemanticMemoryService_CloseConversation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *semanticMemoryServiceClient) ArchiveConversation(ctx context.Context, in *ArchiveConversationRequest, opts ...grpc.CallOption) (*ArchiveConversationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ArchiveConversationResponse)
+	err := c.cc.Invoke(ctx, SemanticMemoryService_ArchiveConversation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *semanticMemoryServiceClient) DeleteConversation(ctx context.Context, in *DeleteConversationRequest, opts ...grpc.CallOption) (*DeleteConversationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteConversationResponse)
+	err := c.cc.Invoke(ctx, SemanticMemoryService_DeleteConversation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SemanticMemoryServiceServer is the server API for SemanticMemoryService service.
+// All implementations must embed UnimplementedSemanticMemoryServiceServer
+// for forward compatibility.
+//
+// SemanticMemoryService mirrors clients.SemanticMemoryClient.
+type SemanticMemoryServiceServer interface {
+	RegisterConversation(context.Context, *RegisterConversationRequest) (*RegisterConversationResponse, error)
+	Store(context.Context, *StoreSemanticMemoryRequest) (*StoreSemanticMemoryResponse, error)
+	StoreMany(context.Context, *StoreManySemanticMemoryRequest) (*StoreManySemanticMemoryResponse, error)
+	Retrieve(context.Context, *RetrieveSemanticMemoryRequest) (*RetrieveSemanticMemoryResponse, error)
+	Update(context.Context, *UpdateSemanticMemoryRequest) (*UpdateSemanticMemoryResponse, error)
+	Delete(context.Context, *DeleteSemanticMemoryRequest) (*DeleteSemanticMemoryResponse, error)
+	Reindex(context.Context, *ReindexSemanticMemoryRequest) (*ReindexSemanticMemoryResponse, error)
+	EmbeddingCacheStats(context.Context, *EmbeddingCacheStatsRequest) (*EmbeddingCacheStatsResponse, error)
+	EraseUser(context.Context, *EraseUserRequest) (*EraseUserResponse, error)
+	ListConversations(context.Context, *ListConversationsRequest) (*ListConversationsResponse, error)
+	GetConversation(context.Context, *GetConversationRequest) (*GetConversationResponse, error)
+	CloseConversation(context.Context, *CloseConversationRequest) (*CloseConversationResponse, error)
+	ArchiveConversation(context.Context, *ArchiveConversationRequest) (*ArchiveConversationResponse, error)
+	DeleteConversation(context.Context, *DeleteConversationRequest) (*DeleteConversationResponse, error)
+	mustEmbedUnimplementedSemanticMemoryServiceServer()
+}
+
+// UnimplementedSemanticMemoryServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSemanticMemoryServiceServer struct{}
+
+func (UnimplementedSemanticMemoryServiceServer) RegisterConversation(context.Context, *RegisterConversationRequest) (*RegisterConversationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RegisterConversation not implemented")
+}
+func (UnimplementedSemanticMemoryServiceServer) Store(context.Context, *StoreSemanticMemoryRequest) (*StoreSemanticMemoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Store not implemented")
+}
+func (UnimplementedSemanticMemoryServiceServer) StoreMany(context.Context, *StoreManySemanticMemoryRequest) (*StoreManySemanticMemoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method StoreMany not implemented")
+}
+func (UnimplementedSemanticMemoryServiceServer) Retrieve(context.Context, *RetrieveSemanticMemoryRequest) (*RetrieveSemanticMemoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Retrieve not implemented")
+}
+func (UnimplementedSemanticMemoryServiceServer) Update(context.Context, *UpdateSemanticMemoryRequest) (*UpdateSemanticMemoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Update not implemented")
+}
+func (UnimplementedSemanticMemoryServiceServer) Delete(context.Context, *DeleteSemanticMemoryRequest) (*DeleteSemanticMemoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedSemanticMemoryServiceServer) Reindex(context.Context, *ReindexSemanticMemoryRequest) (*ReindexSemanticMemoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Reindex not implemented")
+}
+func (UnimplementedSemanticMemoryServiceServer) EmbeddingCacheStats(context.Context, *EmbeddingCacheStatsRequest) (*EmbeddingCacheStatsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EmbeddingCacheStats not implemented")
+}
+func (UnimplementedSemanticMemoryServiceServer) EraseUser(context.Context, *EraseUserRequest) (*EraseUserResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EraseUser not implemented")
+}
+func (UnimplementedSemanticMemoryServiceServer) ListConversations(context.Context, *ListConversationsRequest) (*ListConversationsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListConversations not implemented")
+}
+func (UnimplementedSemanticMemoryServiceServer) GetConversation(context.Context, *GetConversationRequest) (*GetConversationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetConversation not implemented")
+}
+func (UnimplementedSemanticMemoryServiceServer) CloseConversation(context.Context, *CloseConversationRequest) (*CloseConversationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CloseConversation not implemented")
+}
+func (UnimplementedSemanticMemoryServiceServer) ArchiveConversation(context.Context, *ArchiveConversationRequest) (*ArchiveConversationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ArchiveConversation not implemented")
+}
+func (UnimplementedSemanticMemoryServiceServer) DeleteConversation(context.Context, *DeleteConversationRequest) (*DeleteConversationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteConversation not implemented")
+}
+func (UnimplementedSemanticMemoryServiceServer) mustEmbedUnimplementedSemanticMemoryServiceServer() {}
+func (UnimplementedSemanticMemoryServiceServer) testEmbeddedByValue()                               {}
+
+// UnsafeSemanticMemoryServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SemanticMemoryServiceServer will
+// result in compilation errors.
+type UnsafeSemanticMemoryServiceServer interface {
+	mustEmbedUnimplementedSemanticMemoryServiceServer()
+}
+
+func RegisterSemanticMemoryServiceServer(s grpc.ServiceRegistrar, srv SemanticMemoryServiceServer) {
+	// If the following call panics, it indicates UnimplementedSemanticMemoryServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SemanticMemoryService_ServiceDesc, srv)
+}
+
+func _SemanticMemoryService_RegisterConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SemanticMemoryServiceServer).RegisterConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SemanticMemoryService_RegisterConversation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SemanticMemoryServiceServer).RegisterConversation(ctx, req.(*RegisterConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SemanticMemoryService_Store_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StoreSemanticMemoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SemanticMemoryServiceServer).Store(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SemanticMemoryService_Store_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SemanticMemoryServiceServer).Store(ctx, req.(*StoreSemanticMemoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SemanticMemoryService_StoreMany_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StoreManySemanticMemoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SemanticMemoryServiceServer).StoreMany(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SemanticMemoryService_StoreMany_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SemanticMemoryServiceServer).StoreMany(ctx, req.(*StoreManySemanticMemoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SemanticMemoryService_Retrieve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetrieveSemanticMemoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SemanticMemoryServiceServer).Retrieve(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SemanticMemoryService_Retrieve_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SemanticMemoryServiceServer).Retrieve(ctx, req.(*RetrieveSemanticMemoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SemanticMemoryService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSemanticMemoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SemanticMemoryServiceServer).Update(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SemanticMemoryService_Update_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SemanticMemoryServiceServer).Update(ctx, req.(*UpdateSemanticMemoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SemanticMemoryService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSemanticMemoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SemanticMemoryServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SemanticMemoryService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SemanticMemoryServiceServer).Delete(ctx, req.(*DeleteSemanticMemoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SemanticMemoryService_Reindex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReindexSemanticMemoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SemanticMemoryServiceServer).Reindex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SemanticMemoryService_Reindex_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SemanticMemoryServiceServer).Reindex(ctx, req.(*ReindexSemanticMemoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SemanticMemoryService_EmbeddingCacheStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EmbeddingCacheStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SemanticMemoryServiceServer).EmbeddingCacheStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SemanticMemoryService_EmbeddingCacheStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SemanticMemoryServiceServer).EmbeddingCacheStats(ctx, req.(*EmbeddingCacheStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SemanticMemoryService_EraseUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EraseUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SemanticMemoryServiceServer).EraseUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SemanticMemoryService_EraseUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SemanticMemoryServiceServer).EraseUser(ctx, req.(*EraseUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SemanticMemoryService_ListConversations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListConversationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SemanticMemoryServiceServer).ListConversations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SemanticMemoryService_ListConversations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SemanticMemoryServiceServer).ListConversations(ctx, req.(*ListConversationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SemanticMemoryService_GetConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SemanticMemoryServiceServer).GetConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SemanticMemoryService_GetConversation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SemanticMemoryServiceServer).GetConversation(ctx, req.(*GetConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SemanticMemoryService_CloseConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloseConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SemanticMemoryServiceServer).CloseConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SemanticMemoryService_CloseConversation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SemanticMemoryServiceServer).CloseConversation(ctx, req.(*CloseConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SemanticMemoryService_ArchiveConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ArchiveConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SemanticMemoryServiceServer).ArchiveConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SemanticMemoryService_ArchiveConversation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SemanticMemoryServiceServer).ArchiveConversation(ctx, req.(*ArchiveConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SemanticMemoryService_DeleteConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SemanticMemoryServiceServer).DeleteConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SemanticMemoryService_DeleteConversation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SemanticMemoryServiceServer).DeleteConversation(ctx, req.(*DeleteConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SemanticMemoryService_ServiceDesc is the grpc.ServiceDesc for SemanticMemoryService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SemanticMemoryService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "memory.v1.SemanticMemoryService",
+	HandlerType: (*SemanticMemoryServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegisterConversation",
+			Handler:    _SemanticMemoryService_RegisterConversation_Handler,
+		},
+		{
+			MethodName: "Store",
+			Handler:    _SemanticMemoryService_Store_Handler,
+		},
+		{
+			MethodName: "StoreMany",
+			Handler:    _SemanticMemoryService_StoreMany_Handler,
+		},
+		{
+			MethodName: "Retrieve",
+			Handler:    _SemanticMemoryService_Retrieve_Handler,
+		},
+		{
+			MethodName: "Update",
+			Handler:    _SemanticMemoryService_Update_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _SemanticMemoryService_Delete_Handler,
+		},
+		{
+			MethodName: "Reindex",
+			Handler:    _SemanticMemoryService_Reindex_Handler,
+		},
+		{
+			MethodName: "EmbeddingCacheStats",
+			Handler:    _SemanticMemoryService_EmbeddingCacheStats_Handler,
+		},
+		{
+			MethodName: "EraseUser",
+			Handler:    _SemanticMemoryService_EraseUser_Handler,
+		},
+		{
+			MethodName: "ListConversations",
+			Handler:    _SemanticMemoryService_ListConversations_Handler,
+		},
+		{
+			MethodName: "GetConversation",
+			Handler:    _SemanticMemoryService_GetConversation_Handler,
+		},
+		{
+			MethodName: "CloseConversation",
+			Handler:    _SemanticMemoryService_CloseConversation_Handler,
+		},
+		{
+			MethodName: "ArchiveConversation",
+			Handler:    _SemanticMemoryService_ArchiveConversation_Handler,
+		},
+		{
+			MethodName: "DeleteConversation",
+			Handler:    _SemanticMemoryService_DeleteConversation_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "memory/v1/memory.proto",
+}
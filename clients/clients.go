@@ -9,11 +9,58 @@ import (
 type ShortTermMemoryClient interface {
 	Store(ctx context.Context, input types.StoreShortTermMemoryInput) (types.StoreShortTermMemoryOutput, error)
 	Retrieve(ctx context.Context, input types.RetrieveShortTermMemoryInput) (types.RetrieveShortTermMemoryOutput, error)
+	Update(ctx context.Context, input types.UpdateShortTermMemoryInput) (types.UpdateShortTermMemoryOutput, error)
+	Delete(ctx context.Context, input types.DeleteShortTermMemoryInput) (types.DeleteShortTermMemoryOutput, error)
 	RegisterConversation(ctx context.Context, input types.RegisterConversationInput) (types.RegisterConversationOutput, error)
+	// ListConversations pages through conversations newest first, each with the
+	// number of memories this client holds for it and its last activity.
+	ListConversations(ctx context.Context, input types.ListConversationsInput) (types.ListConversationsOutput, error)
+	GetConversation(ctx context.Context, input types.GetConversationInput) (types.GetConversationOutput, error)
+	// CloseConversation and ArchiveConversation stop a conversation from taking
+	// new memories, its memories can still be read, updated and deleted.
+	// Archived conversations are left out of listings unless asked for.
+	CloseConversation(ctx context.Context, input types.CloseConversationInput) (types.CloseConversationOutput, error)
+	ArchiveConversation(ctx context.Context, input types.ArchiveConversationInput) (types.ArchiveConversationOutput, error)
+	// DeleteConversation deletes a conversation along with the memories this client holds for it.
+	DeleteConversation(ctx context.Context, input types.DeleteConversationInput) (types.DeleteConversationOutput, error)
+	// EraseUser deletes the conversations of a user and drops their cached
+	// memories, it returns a report of what was removed.
+	EraseUser(ctx context.Context, input types.EraseUserInput) (types.EraseUserOutput, error)
 }
 
 type SemanticMemoryClient interface {
 	Store(ctx context.Context, input types.StoreSemanticMemoryInput) (types.StoreSemanticMemoryOutput, error)
+	// StoreMany embeds and stores a batch of memories in one go, it is meant for backfilling history.
+	StoreMany(ctx context.Context, input types.StoreManySemanticMemoryInput) (types.StoreManySemanticMemoryOutput, error)
 	Retrieve(ctx context.Context, input types.RetrieveSemanticMemoryInput) (types.RetrieveSemanticMemoryOutput, error)
+	// Update replaces the query and response of a memory and re-embeds it, the
+	// memory keeps its id and creation time.
+	Update(ctx context.Context, input types.UpdateSemanticMemoryInput) (types.UpdateSemanticMemoryOutput, error)
+	// Delete removes a memory from the database and the vector index.
+	Delete(ctx context.Context, input types.DeleteSemanticMemoryInput) (types.DeleteSemanticMemoryOutput, error)
 	RegisterConversation(ctx context.Context, input types.RegisterConversationInput) (types.RegisterConversationOutput, error)
+	// ListConversations pages through conversations newest first, each with the
+	// number of memories this client holds for it and its last activity.
+	ListConversations(ctx context.Context, input types.ListConversationsInput) (types.ListConversationsOutput, error)
+	GetConversation(ctx context.Context, input types.GetConversationInput) (types.GetConversationOutput, error)
+	// CloseConversation and ArchiveConversation stop a conversation from taking
+	// new memories, its memories can still be read, updated and deleted.
+	// Archived conversations are left out of listings unless asked for.
+	CloseConversation(ctx context.Context, input types.CloseConversationInput) (types.CloseConversationOutput, error)
+	ArchiveConversation(ctx context.Context, input types.ArchiveConversationInput) (types.ArchiveConversationOutput, error)
+	// DeleteConversation deletes a conversation along with the memories this client holds for it.
+	DeleteConversation(ctx context.Context, input types.DeleteConversationInput) (types.DeleteConversationOutput, error)
+	// Reindex rebuilds the vector indexes from the memories stored in DuckDB, it
+	// restores search after an index is lost. Memories stored while a
+	// conversation is rebuilt can be missed, so it is meant for maintenance.
+	Reindex(ctx context.Context, input types.ReindexSemanticMemoryInput) (types.ReindexSemanticMemoryOutput, error)
+	// EmbeddingCacheStats returns the hit and miss counters of the embedding
+	// cache since the client was created.
+	EmbeddingCacheStats(ctx context.Context, input types.EmbeddingCacheStatsInput) (types.EmbeddingCacheStatsOutput, error)
+	// EraseUser deletes the conversations of a user along with their memories
+	// and vector indexes and empties the embedding cache, it returns a report of
+	// what was removed. A failed erase can be retried, conversations are deleted
+	// after their memories. Snapshots in a bucket are not rewritten, a later
+	// snapshot pull restores the user, erase them with the cli's erase-user.
+	EraseUser(ctx context.Context, input types.EraseUserInput) (types.EraseUserOutput, error)
 }
@@ -1,9 +1,221 @@
 package clients
 
+import (
+	"time"
+
+	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/persistence"
+	"github.com/haren7/minimal-memory/internal/persistence/rdbms"
+	"github.com/haren7/minimal-memory/internal/persistence/vector"
+	"github.com/haren7/minimal-memory/internal/tokenizer"
+)
+
+const defaultDuckDBPath = "memory.db"
+
+// EmbeddingProvider is the embedding package's provider, exported here since
+// that package is internal.
+type EmbeddingProvider = embedding.Provider
+
+const (
+	// EmbeddingProviderOpenAI embeds through the OpenAI API, or any compatible
+	// endpoint set with OpenAIBaseURL, and requires OpenAIApiKey unless a base URL is set.
+	EmbeddingProviderOpenAI = embedding.ProviderOpenAI
+	// EmbeddingProviderLocal embeds fully in-process with a hashed n-gram embedder,
+	// no network access or API key is needed.
+	EmbeddingProviderLocal = embedding.ProviderLocal
+)
+
+type VectorBackend string
+
+const (
+	// VectorBackendFaiss searches per conversation FAISS indexes, it needs cgo
+	// and is left out of builds with the nofaiss tag.
+	VectorBackendFaiss VectorBackend = "faiss"
+	// VectorBackendBruteForce compares every vector of a conversation in pure
+	// Go, it is exact and needs no native libraries.
+	VectorBackendBruteForce VectorBackend = "bruteforce"
+	// VectorBackendChromem keeps a chromem-go collection per conversation,
+	// chromem always compares normalized vectors.
+	VectorBackendChromem VectorBackend = "chromem"
+)
+
+type VectorMetric string
+
+const (
+	// VectorMetricL2 compares raw vectors by squared euclidean distance, scores are in (0, 1].
+	VectorMetricL2 VectorMetric = "l2"
+	// VectorMetricInnerProduct compares raw vectors by inner product, scores are unbounded.
+	VectorMetricInnerProduct VectorMetric = "ip"
+	// VectorMetricCosine compares normalized vectors, scores are the cosine similarity in [-1, 1].
+	VectorMetricCosine VectorMetric = "cosine"
+)
+
 type ShortTermMemoryClientConfig struct {
+	// DuckDBPath is the database file conversations are stored in, defaults to memory.db.
+	DuckDBPath string
+	// Namespace is the tenant the client serves, see SemanticMemoryClientConfig.
+	Namespace string
+	// MaxWindow is the number of memories kept per conversation, storing more
+	// drops the oldest. Defaults to 100.
+	MaxWindow int
+	// MaxConversations bounds the conversations kept in memory, the least
+	// recently used one is dropped to make room. Zero keeps every conversation.
+	MaxConversations int
+	// IdleTTL drops the memories of conversations not stored to or retrieved
+	// from for that long. Zero keeps them.
+	IdleTTL time.Duration
+	// Tokenizer counts the tokens of a MaxTokens retrieval.
+	Tokenizer TokenizerConfig
 }
 
 type SemanticMemoryClientConfig struct {
 	ContextWindowSize int
 	OpenAIApiKey      string
+	// DuckDBPath is the database file memories are stored in, defaults to memory.db.
+	DuckDBPath string
+	// Namespace is the tenant the client serves, up to 63 lowercase letters,
+	// digits, '_' or '-'. Each namespace has its own DuckDB schema and vector
+	// indexes, empty is the default namespace. Create one client per tenant.
+	Namespace string
+	// EmbeddingProvider selects how memories are embedded, defaults to EmbeddingProviderOpenAI.
+	EmbeddingProvider EmbeddingProvider
+	// LocalEmbeddingDim is the vector size used by EmbeddingProviderLocal, defaults to 512.
+	LocalEmbeddingDim int
+	// OpenAIBaseURL points EmbeddingProviderOpenAI at a compatible endpoint such
+	// as Azure OpenAI, vLLM, Ollama or LocalAI.
+	OpenAIBaseURL string
+	// OpenAIEmbeddingModel defaults to text-embedding-3-small.
+	OpenAIEmbeddingModel string
+	// OpenAIEmbeddingDimensions requests shortened embeddings, zero keeps the model default.
+	OpenAIEmbeddingDimensions int
+	OpenAIOrganization        string
+	// OpenAIAzure authenticates against Azure OpenAI, OpenAIBaseURL is then the resource endpoint.
+	OpenAIAzure bool
+	// EmbeddingCacheSize is the number of embeddings kept in memory, zero uses
+	// 1024 and a negative size disables the cache.
+	EmbeddingCacheSize int
+	// EmbeddingCachePersistent also keeps embeddings in DuckDB so they survive restarts.
+	EmbeddingCachePersistent bool
+	// EmbeddingResilience configures retries, rate limits and the circuit
+	// breaker around the embedding provider, the zero value uses the defaults.
+	EmbeddingResilience EmbeddingResilienceConfig
+	// VectorBackend selects where memory vectors are kept, defaults to VectorBackendFaiss.
+	VectorBackend VectorBackend
+	// VectorMetric is how memories are compared on every backend, defaults to
+	// VectorMetricL2. Existing indexes keep the metric they were built with,
+	// Reindex rebuilds them with this one.
+	VectorMetric VectorMetric
+	// FaissIndex selects the faiss index type, the zero value keeps an exact
+	// index per conversation.
+	FaissIndex FaissIndexConfig
+	// Tokenizer counts the tokens of a MaxTokens retrieval.
+	Tokenizer TokenizerConfig
+}
+
+type TokenizerConfig struct {
+	// Model is the OpenAI model whose encoding is used, e.g. gpt-4o for
+	// o200k_base or gpt-4 for cl100k_base. Defaults to cl100k_base.
+	Model string
+	// RanksFile is the tiktoken file of that encoding, such as
+	// cl100k_base.tiktoken. Without it token counts are estimated on the high
+	// side from the length of each word.
+	RanksFile string
+}
+
+func (r TokenizerConfig) tokenizerConfig() tokenizer.Config {
+	return tokenizer.Config{Model: r.Model, RanksFile: r.RanksFile}
+}
+
+type FaissIndexConfig struct {
+	// Factory is a faiss index factory string, e.g. "HNSW32", "IVF256,Flat" or
+	// "IVF256,PQ16", defaults to "IDMap,Flat".
+	Factory string
+	// MigrateAt is the number of memories at which a conversation moves from an
+	// exact index to Factory, trained on the memories stored so far. Factories
+	// that need training (IVF, PQ) default it to 1000.
+	MigrateAt int
+	// NProbe is the number of IVF lists searched and EfSearch the HNSW search
+	// depth, zero keeps the faiss defaults.
+	NProbe   int
+	EfSearch int
+}
+
+func (r FaissIndexConfig) faissConfig(metric VectorMetric, namespace persistence.Namespace) vector.FaissConfig {
+	return vector.FaissConfig{
+		Namespace: namespace,
+		Factory:   r.Factory,
+		Metric:    vector.Metric(metric),
+		MigrateAt: r.MigrateAt,
+		NProbe:    r.NProbe,
+		EfSearch:  r.EfSearch,
+	}
+}
+
+type EmbeddingResilienceConfig struct {
+	// MaxRetries after the first attempt, zero uses 3 and a negative value disables retries.
+	MaxRetries int
+	// InitialBackoff and MaxBackoff bound the jittered exponential backoff, defaulting to 500ms and 30s.
+	// A Retry-After header from the provider takes precedence up to MaxBackoff, a longer one
+	// fails the call with the provider error instead of waiting.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// RequestsPerMinute and TokensPerMinute throttle embedding calls, zero means unlimited.
+	RequestsPerMinute int
+	TokensPerMinute   int
+	// BreakerThreshold consecutive failures open the circuit breaker, zero uses 5 and a
+	// negative value disables it. An open breaker fails calls fast for BreakerCooldown, default 30s.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+func (r EmbeddingResilienceConfig) resilienceConfig() embedding.ResilienceConfig {
+	return embedding.ResilienceConfig{
+		MaxRetries:        r.MaxRetries,
+		InitialBackoff:    r.InitialBackoff,
+		MaxBackoff:        r.MaxBackoff,
+		RequestsPerMinute: r.RequestsPerMinute,
+		TokensPerMinute:   r.TokensPerMinute,
+		BreakerThreshold:  r.BreakerThreshold,
+		BreakerCooldown:   r.BreakerCooldown,
+	}
+}
+
+func (r SemanticMemoryClientConfig) embeddingConfig() embedding.Config {
+	provider := r.EmbeddingProvider
+	if provider == "" {
+		provider = EmbeddingProviderOpenAI
+	}
+	return embedding.Config{
+		Provider: provider,
+		OpenAI: embedding.OpenAIConfig{
+			ApiKey:       r.OpenAIApiKey,
+			BaseURL:      r.OpenAIBaseURL,
+			Model:        r.OpenAIEmbeddingModel,
+			Dimensions:   r.OpenAIEmbeddingDimensions,
+			Organization: r.OpenAIOrganization,
+			Azure:        r.OpenAIAzure,
+		},
+		LocalDim: r.LocalEmbeddingDim,
+	}
+}
+
+// openDuckDB opens the tables of namespace in the database at path.
+func openDuckDB(path string, namespace string) (*rdbms.DuckDBClient, error) {
+	duckdbClient, err := rdbms.NewDuckDBClient(duckDBPathOrDefault(path))
+	if err != nil || namespace == "" {
+		return duckdbClient, err
+	}
+	namespaced, err := duckdbClient.Namespace(persistence.Namespace(namespace))
+	if err != nil {
+		duckdbClient.GetDB().Close()
+		return nil, err
+	}
+	return namespaced, nil
+}
+
+func duckDBPathOrDefault(path string) string {
+	if path == "" {
+		return defaultDuckDBPath
+	}
+	return path
 }
@@ -0,0 +1,192 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/haren7/minimal-memory/internal/conversation"
+	"github.com/haren7/minimal-memory/internal/memory"
+	"github.com/haren7/minimal-memory/internal/persistence"
+	"github.com/haren7/minimal-memory/types"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultConversationLimit = 50
+	maxConversationLimit     = 500
+)
+
+// memoryStatsFunc is the Stats method of the memory service of a client, the
+// conversation helpers below report memories from it.
+type memoryStatsFunc func(ctx context.Context, conversationIDs []uuid.UUID) (map[uuid.UUID]memory.Stats, error)
+
+func listConversations(ctx context.Context, conversationService conversation.ConversationServiceInterface, memoryStats memoryStatsFunc, input types.ListConversationsInput) (types.ListConversationsOutput, error) {
+	var statuses []string
+	switch input.Status {
+	case "":
+		statuses = []string{conversation.StatusActive, conversation.StatusClosed}
+	case types.ConversationStatusActive, types.ConversationStatusClosed, types.ConversationStatusArchived:
+		statuses = []string{input.Status}
+	default:
+		log.Printf("[ERROR] ListConversations: Unknown status %q", input.Status)
+		return types.ListConversationsOutput{}, fmt.Errorf("%w: unknown status %q", ErrInvalidInput, input.Status)
+	}
+	if input.Limit < 0 {
+		log.Printf("[ERROR] ListConversations: Limit must not be negative (limit: %d)", input.Limit)
+		return types.ListConversationsOutput{}, fmt.Errorf("%w: limit must not be negative", ErrInvalidInput)
+	}
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultConversationLimit
+	}
+	limit = min(limit, maxConversationLimit)
+	filter := conversation.Filter{Agent: input.Agent, User: input.User, Statuses: statuses}
+	conversations, next, err := conversationService.List(ctx, filter, input.Cursor, limit)
+	if errors.Is(err, conversation.ErrInvalidCursor) {
+		log.Printf("[ERROR] ListConversations: Invalid cursor - %q", input.Cursor)
+		return types.ListConversationsOutput{}, fmt.Errorf("%w: invalid cursor", ErrInvalidInput)
+	}
+	if err != nil {
+		log.Printf("[ERROR] ListConversations: Failed to list conversations (agent: %q, user: %q, status: %q) - %v", input.Agent, input.User, input.Status, err)
+		return types.ListConversationsOutput{}, fmt.Errorf("error listing conversations")
+	}
+	conversationIDs := make([]uuid.UUID, len(conversations))
+	for i, conversation := range conversations {
+		conversationIDs[i] = conversation.ID
+	}
+	stats, err := memoryStats(ctx, conversationIDs)
+	if err != nil {
+		log.Printf("[ERROR] ListConversations: Failed to fetch memory stats (count: %d) - %v", len(conversationIDs), err)
+		return types.ListConversationsOutput{}, fmt.Errorf("error listing conversations")
+	}
+	output := types.ListConversationsOutput{Conversations: make([]types.Conversation, len(conversations)), NextCursor: next}
+	for i, conversation := range conversations {
+		output.Conversations[i] = toConversation(conversation, stats[conversation.ID])
+	}
+	return output, nil
+}
+
+func getConversation(ctx context.Context, conversationService conversation.ConversationServiceInterface, memoryStats memoryStatsFunc, input types.GetConversationInput) (types.GetConversationOutput, error) {
+	conversationID, err := parseConversationID("GetConversation", input.ConversationID)
+	if err != nil {
+		return types.GetConversationOutput{}, err
+	}
+	existing, err := fetchConversation(ctx, conversationService, "GetConversation", conversationID)
+	if err != nil {
+		return types.GetConversationOutput{}, err
+	}
+	stats, err := memoryStats(ctx, []uuid.UUID{conversationID})
+	if err != nil {
+		log.Printf("[ERROR] GetConversation: Failed to fetch memory stats (conversationID: %s) - %v", conversationID, err)
+		return types.GetConversationOutput{}, fmt.Errorf("error fetching conversation")
+	}
+	return types.GetConversationOutput{
+		Conversation: toConversation(existing, stats[conversationID]),
+	}, nil
+}
+
+// setConversationStatus moves a conversation to status, method names the caller in logs.
+func setConversationStatus(ctx context.Context, conversationService conversation.ConversationServiceInterface, method, rawConversationID, status string) (uuid.UUID, error) {
+	conversationID, err := parseConversationID(method, rawConversationID)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	err = conversationService.SetStatus(ctx, conversationID, status)
+	if errors.Is(err, persistence.ErrNotFound) {
+		log.Printf("[ERROR] %s: Conversation does not exist (conversationID: %s)", method, conversationID)
+		return uuid.UUID{}, ErrConversationNotFound
+	}
+	if err != nil {
+		log.Printf("[ERROR] %s: Failed to set conversation status (conversationID: %s, status: %q) - %v", method, conversationID, status, err)
+		return uuid.UUID{}, fmt.Errorf("error updating conversation")
+	}
+	return conversationID, nil
+}
+
+// writableConversation fails for conversations that do not exist or no longer
+// take new memories, method names the caller in logs.
+func writableConversation(ctx context.Context, conversationService conversation.ConversationServiceInterface, method string, conversationID uuid.UUID) error {
+	existing, err := fetchConversation(ctx, conversationService, method, conversationID)
+	if err != nil {
+		return err
+	}
+	if existing.Status != conversation.StatusActive {
+		log.Printf("[ERROR] %s: Conversation is %s (conversationID: %s)", method, existing.Status, conversationID)
+		return fmt.Errorf("%w: conversation is %s", ErrConversationClosed, existing.Status)
+	}
+	return nil
+}
+
+func fetchConversation(ctx context.Context, conversationService conversation.ConversationServiceInterface, method string, conversationID uuid.UUID) (conversation.Conversation, error) {
+	existing, err := conversationService.Get(ctx, conversationID)
+	if errors.Is(err, persistence.ErrNotFound) {
+		log.Printf("[ERROR] %s: Conversation does not exist (conversationID: %s)", method, conversationID)
+		return conversation.Conversation{}, ErrConversationNotFound
+	}
+	if err != nil {
+		log.Printf("[ERROR] %s: Failed to fetch conversation (conversationID: %s) - %v", method, conversationID, err)
+		return conversation.Conversation{}, fmt.Errorf("error fetching conversation")
+	}
+	return existing, nil
+}
+
+func parseConversationID(method, rawConversationID string) (uuid.UUID, error) {
+	if rawConversationID == "" {
+		log.Printf("[ERROR] %s: Conversation ID is required but was empty", method)
+		return uuid.UUID{}, fmt.Errorf("%w: conversation id is required", ErrInvalidInput)
+	}
+	conversationID, err := uuid.Parse(rawConversationID)
+	if err != nil {
+		log.Printf("[ERROR] %s: Invalid conversation ID format - %q, error: %v", method, rawConversationID, err)
+		return uuid.UUID{}, fmt.Errorf("%w: invalid conversation id", ErrInvalidInput)
+	}
+	return conversationID, nil
+}
+
+// parseMemoryIDs parses the ids of a memory and checks that its conversation exists,
+// method names the caller in logs.
+func parseMemoryIDs(ctx context.Context, conversationService conversation.ConversationServiceInterface, method, rawConversationID, rawMemoryID string) (uuid.UUID, uuid.UUID, error) {
+	if rawConversationID == "" || rawMemoryID == "" {
+		log.Printf("[ERROR] %s: Conversation ID and memory ID are required but one or both were empty (conversationID: %q, memoryID: %q)", method, rawConversationID, rawMemoryID)
+		return uuid.UUID{}, uuid.UUID{}, fmt.Errorf("%w: conversation id and memory id are required", ErrInvalidInput)
+	}
+	conversationID, err := uuid.Parse(rawConversationID)
+	if err != nil {
+		log.Printf("[ERROR] %s: Invalid conversation ID format - %q, error: %v", method, rawConversationID, err)
+		return uuid.UUID{}, uuid.UUID{}, fmt.Errorf("%w: invalid conversation id", ErrInvalidInput)
+	}
+	memoryID, err := uuid.Parse(rawMemoryID)
+	if err != nil {
+		log.Printf("[ERROR] %s: Invalid memory ID format - %q, error: %v", method, rawMemoryID, err)
+		return uuid.UUID{}, uuid.UUID{}, fmt.Errorf("%w: invalid memory id", ErrInvalidInput)
+	}
+	exists, err := conversationService.Exists(ctx, conversationID)
+	if err != nil {
+		log.Printf("[ERROR] %s: Failed to check if conversation exists (conversationID: %s) - %v", method, conversationID, err)
+		return uuid.UUID{}, uuid.UUID{}, fmt.Errorf("error checking if conversation exists")
+	}
+	if !exists {
+		log.Printf("[ERROR] %s: Conversation does not exist (conversationID: %s)", method, conversationID)
+		return uuid.UUID{}, uuid.UUID{}, ErrConversationNotFound
+	}
+	return conversationID, memoryID, nil
+}
+
+func toConversation(conversation conversation.Conversation, stats memory.Stats) types.Conversation {
+	lastActivityAt := conversation.CreatedAt
+	if stats.LastCreatedAt.After(lastActivityAt) {
+		lastActivityAt = stats.LastCreatedAt
+	}
+	return types.Conversation{
+		ID:             conversation.ID.String(),
+		Agent:          conversation.Agent,
+		User:           conversation.User,
+		Status:         conversation.Status,
+		CreatedAt:      conversation.CreatedAt,
+		MemoryCount:    stats.Memories,
+		LastActivityAt: lastActivityAt,
+	}
+}
@@ -0,0 +1,54 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/haren7/minimal-memory/internal/conversation"
+	"github.com/haren7/minimal-memory/types"
+
+	"github.com/google/uuid"
+)
+
+// conversationsToErase returns the conversations of input.User followed by the
+// already deleted ones in input.ConversationIDs, which only carry their ID.
+// Listed conversations that still exist and belong to someone else are rejected
+// so that a caller cannot erase the memories of another user.
+func conversationsToErase(ctx context.Context, conversationService conversation.ConversationServiceInterface, input types.EraseUserInput) ([]conversation.Conversation, error) {
+	if input.User == "" {
+		log.Printf("[ERROR] EraseUser: User is required but was empty")
+		return nil, fmt.Errorf("%w: user is required", ErrInvalidInput)
+	}
+	conversations, err := conversationService.FetchByUser(ctx, input.User)
+	if err != nil {
+		log.Printf("[ERROR] EraseUser: Failed to fetch conversations - %v", err)
+		return nil, fmt.Errorf("error fetching conversations")
+	}
+	owned := func(conversationID uuid.UUID) bool {
+		return slices.ContainsFunc(conversations, func(conversation conversation.Conversation) bool { return conversation.ID == conversationID })
+	}
+	var deleted []conversation.Conversation
+	for _, rawConversationID := range input.ConversationIDs {
+		conversationID, err := uuid.Parse(rawConversationID)
+		if err != nil {
+			log.Printf("[ERROR] EraseUser: Invalid conversation ID format - %q, error: %v", rawConversationID, err)
+			return nil, fmt.Errorf("%w: invalid conversation id", ErrInvalidInput)
+		}
+		if owned(conversationID) || slices.ContainsFunc(deleted, func(conversation conversation.Conversation) bool { return conversation.ID == conversationID }) {
+			continue
+		}
+		exists, err := conversationService.Exists(ctx, conversationID)
+		if err != nil {
+			log.Printf("[ERROR] EraseUser: Failed to check if conversation exists (conversationID: %s) - %v", conversationID, err)
+			return nil, fmt.Errorf("error checking if conversation exists")
+		}
+		if exists {
+			log.Printf("[ERROR] EraseUser: Conversation belongs to another user (conversationID: %s)", conversationID)
+			return nil, fmt.Errorf("%w: conversation %s does not belong to the user", ErrInvalidInput, conversationID)
+		}
+		deleted = append(deleted, conversation.Conversation{ID: conversationID})
+	}
+	return append(conversations, deleted...), nil
+}
@@ -0,0 +1,21 @@
+package clients
+
+import "errors"
+
+// Errors returned by the clients can be matched with errors.Is so that
+// callers (such as the HTTP server) can tell bad input apart from missing
+// resources and internal failures.
+var (
+	ErrInvalidInput         = errors.New("invalid input")
+	ErrConversationNotFound = errors.New("conversation does not exist")
+	ErrMemoryNotFound       = errors.New("memory does not exist")
+	// ErrConversationClosed is returned when storing into a closed or archived conversation.
+	ErrConversationClosed = errors.New("conversation does not take new memories")
+	ErrUnsupported        = errors.New("not supported by this configuration")
+	// ErrNamespaceNotServed is returned for a namespace Tenants was not configured to serve.
+	ErrNamespaceNotServed = errors.New("namespace is not served")
+)
+
+// MaxTopK bounds the similar memories one retrieval asks for, the indexes
+// allocate their results up front.
+const MaxTopK = 1000
@@ -0,0 +1,17 @@
+//go:build cgo && !nofaiss
+
+package clients
+
+import (
+	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/persistence"
+	"github.com/haren7/minimal-memory/internal/persistence/vector"
+)
+
+func newFaissMemoryRepo(config SemanticMemoryClientConfig, embeddingService embedding.ServiceInterface, memoryRepo persistence.MemoryRepoInterface) (persistence.VectorMemoryRepoInterface, error) {
+	faiss, err := vector.NewFaissClient(config.FaissIndex.faissConfig(config.VectorMetric, persistence.Namespace(config.Namespace)))
+	if err != nil {
+		return nil, err
+	}
+	return vector.NewFaissMemoryRepo(faiss, embeddingService, memoryRepo), nil
+}
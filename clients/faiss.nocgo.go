@@ -0,0 +1,16 @@
+//go:build !cgo || nofaiss
+
+package clients
+
+import (
+	"fmt"
+
+	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/persistence"
+)
+
+// newFaissMemoryRepo fails in builds without FAISS, they are left with the
+// pure Go backends.
+func newFaissMemoryRepo(config SemanticMemoryClientConfig, embeddingService embedding.ServiceInterface, memoryRepo persistence.MemoryRepoInterface) (persistence.VectorMemoryRepoInterface, error) {
+	return nil, fmt.Errorf("%w: this build has no faiss, use the bruteforce or chromem vector backend", ErrUnsupported)
+}
@@ -0,0 +1,387 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	memoryv1 "github.com/haren7/minimal-memory/api/memory/v1"
+	"github.com/haren7/minimal-memory/types"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type grpcSemanticMemoryClient struct {
+	client memoryv1.SemanticMemoryServiceClient
+}
+
+// NewSemanticMemoryGRPCClient returns a SemanticMemoryClient backed by a remote
+// minimal-memory gRPC server, so it can be dropped in for the in-process client.
+func NewSemanticMemoryGRPCClient(conn grpc.ClientConnInterface) SemanticMemoryClient {
+	return &grpcSemanticMemoryClient{
+		client: memoryv1.NewSemanticMemoryServiceClient(conn),
+	}
+}
+
+func (r *grpcSemanticMemoryClient) Store(ctx context.Context, input types.StoreSemanticMemoryInput) (types.StoreSemanticMemoryOutput, error) {
+	metadata, err := toStruct(input.Metadata)
+	if err != nil {
+		return types.StoreSemanticMemoryOutput{}, err
+	}
+	resp, err := r.client.Store(ctx, &memoryv1.StoreSemanticMemoryRequest{
+		ConversationId: input.ConversationID,
+		Query:          input.Query,
+		Response:       input.Response,
+		Metadata:       metadata,
+		Tags:           input.Tags,
+		Scopes:         input.Scopes,
+	})
+	if err != nil {
+		return types.StoreSemanticMemoryOutput{}, fromStatus(err)
+	}
+	return types.StoreSemanticMemoryOutput{
+		MemoryID: resp.GetMemoryId(),
+	}, nil
+}
+
+func (r *grpcSemanticMemoryClient) StoreMany(ctx context.Context, input types.StoreManySemanticMemoryInput) (types.StoreManySemanticMemoryOutput, error) {
+	memories := make([]*memoryv1.SemanticMemoryEntry, len(input.Memories))
+	for i, entry := range input.Memories {
+		metadata, err := toStruct(entry.Metadata)
+		if err != nil {
+			return types.StoreManySemanticMemoryOutput{}, fmt.Errorf("%w, memory %d", err, i)
+		}
+		memories[i] = &memoryv1.SemanticMemoryEntry{
+			Query:    entry.Query,
+			Response: entry.Response,
+			Metadata: metadata,
+			Tags:     entry.Tags,
+		}
+		if !entry.CreatedAt.IsZero() {
+			memories[i].CreatedAt = timestamppb.New(entry.CreatedAt)
+		}
+	}
+	resp, err := r.client.StoreMany(ctx, &memoryv1.StoreManySemanticMemoryRequest{
+		ConversationId: input.ConversationID,
+		Memories:       memories,
+		Scopes:         input.Scopes,
+	})
+	if err != nil {
+		return types.StoreManySemanticMemoryOutput{}, fromStatus(err)
+	}
+	return types.StoreManySemanticMemoryOutput{
+		MemoryIDs: resp.GetMemoryIds(),
+	}, nil
+}
+
+func (r *grpcSemanticMemoryClient) Retrieve(ctx context.Context, input types.RetrieveSemanticMemoryInput) (types.RetrieveSemanticMemoryOutput, error) {
+	// the request has int32 fields, larger values would wrap past the server's checks
+	if input.TopK > MaxTopK {
+		return types.RetrieveSemanticMemoryOutput{}, fmt.Errorf("%w: top k must be at most %d", ErrInvalidInput, MaxTopK)
+	}
+	if input.MaxTokens > math.MaxInt32 {
+		return types.RetrieveSemanticMemoryOutput{}, fmt.Errorf("%w: max tokens must be at most %d", ErrInvalidInput, math.MaxInt32)
+	}
+	filterMetadata, err := toStruct(input.Filter.Metadata)
+	if err != nil {
+		return types.RetrieveSemanticMemoryOutput{}, err
+	}
+	resp, err := r.client.Retrieve(ctx, &memoryv1.RetrieveSemanticMemoryRequest{
+		ConversationId: input.ConversationID,
+		Query:          input.Query,
+		TopK:           int32(input.TopK),
+		MinScore:       input.MinScore,
+		Filter: &memoryv1.MemoryFilter{
+			Tags:     input.Filter.Tags,
+			Metadata: filterMetadata,
+		},
+		Scope:          input.Scope,
+		MaxTokens:      int32(input.MaxTokens),
+		TruncateOldest: input.TruncateOldest,
+	})
+	if err != nil {
+		return types.RetrieveSemanticMemoryOutput{}, fromStatus(err)
+	}
+	var memories []types.Memory
+	for _, memory := range resp.GetMemories() {
+		memories = append(memories, types.Memory{
+			ID:        memory.GetId(),
+			Query:     memory.GetQuery(),
+			Response:  memory.GetResponse(),
+			CreatedAt: memory.GetCreatedAt().AsTime(),
+			Metadata:  fromStruct(memory.GetMetadata()),
+			Tags:      memory.GetTags(),
+			Truncated: memory.GetTruncated(),
+		})
+	}
+	var similarMemories []types.SemanticMemory
+	for _, memory := range resp.GetSimilarMemories() {
+		similarMemories = append(similarMemories, types.SemanticMemory{
+			ID:             memory.GetId(),
+			ConversationID: memory.GetConversationId(),
+			Query:          memory.GetQuery(),
+			Response:       memory.GetResponse(),
+			CreatedAt:      memory.GetCreatedAt().AsTime(),
+			Metadata:       fromStruct(memory.GetMetadata()),
+			Tags:           memory.GetTags(),
+			Rank:           int(memory.GetRank()),
+			Score:          memory.GetScore(),
+			Distance:       memory.GetDistance(),
+		})
+	}
+	return types.RetrieveSemanticMemoryOutput{
+		Memories:        memories,
+		SimilarMemories: similarMemories,
+	}, nil
+}
+
+func (r *grpcSemanticMemoryClient) Update(ctx context.Context, input types.UpdateSemanticMemoryInput) (types.UpdateSemanticMemoryOutput, error) {
+	resp, err := r.client.Update(ctx, &memoryv1.UpdateSemanticMemoryRequest{
+		ConversationId: input.ConversationID,
+		MemoryId:       input.MemoryID,
+		Query:          input.Query,
+		Response:       input.Response,
+	})
+	if err != nil {
+		return types.UpdateSemanticMemoryOutput{}, fromStatus(err)
+	}
+	return types.UpdateSemanticMemoryOutput{
+		MemoryID: resp.GetMemoryId(),
+	}, nil
+}
+
+func (r *grpcSemanticMemoryClient) Delete(ctx context.Context, input types.DeleteSemanticMemoryInput) (types.DeleteSemanticMemoryOutput, error) {
+	resp, err := r.client.Delete(ctx, &memoryv1.DeleteSemanticMemoryRequest{
+		ConversationId: input.ConversationID,
+		MemoryId:       input.MemoryID,
+	})
+	if err != nil {
+		return types.DeleteSemanticMemoryOutput{}, fromStatus(err)
+	}
+	return types.DeleteSemanticMemoryOutput{
+		MemoryID: resp.GetMemoryId(),
+	}, nil
+}
+
+func (r *grpcSemanticMemoryClient) RegisterConversation(ctx context.Context, input types.RegisterConversationInput) (types.RegisterConversationOutput, error) {
+	resp, err := r.client.RegisterConversation(ctx, &memoryv1.RegisterConversationRequest{
+		Agent: input.Agent,
+		User:  input.User,
+	})
+	if err != nil {
+		return types.RegisterConversationOutput{}, fromStatus(err)
+	}
+	return types.RegisterConversationOutput{
+		ConversationID: resp.GetConversationId(),
+	}, nil
+}
+
+func (r *grpcSemanticMemoryClient) Reindex(ctx context.Context, input types.ReindexSemanticMemoryInput) (types.ReindexSemanticMemoryOutput, error) {
+	resp, err := r.client.Reindex(ctx, &memoryv1.ReindexSemanticMemoryRequest{
+		ConversationId: input.ConversationID,
+	})
+	if err != nil {
+		return types.ReindexSemanticMemoryOutput{}, fromStatus(err)
+	}
+	conversations := make([]types.ReindexedConversation, len(resp.GetConversations()))
+	for i, conversation := range resp.GetConversations() {
+		conversations[i] = types.ReindexedConversation{
+			ConversationID: conversation.GetConversationId(),
+			Memories:       int(conversation.GetMemories()),
+		}
+	}
+	return types.ReindexSemanticMemoryOutput{
+		Conversations: conversations,
+	}, nil
+}
+
+func (r *grpcSemanticMemoryClient) EmbeddingCacheStats(ctx context.Context, input types.EmbeddingCacheStatsInput) (types.EmbeddingCacheStatsOutput, error) {
+	resp, err := r.client.EmbeddingCacheStats(ctx, &memoryv1.EmbeddingCacheStatsRequest{})
+	if err != nil {
+		return types.EmbeddingCacheStatsOutput{}, fromStatus(err)
+	}
+	return types.EmbeddingCacheStatsOutput{
+		Enabled:     resp.GetEnabled(),
+		MemoryHits:  resp.GetMemoryHits(),
+		StoreHits:   resp.GetStoreHits(),
+		Misses:      resp.GetMisses(),
+		StoreErrors: resp.GetStoreErrors(),
+	}, nil
+}
+
+func (r *grpcSemanticMemoryClient) EraseUser(ctx context.Context, input types.EraseUserInput) (types.EraseUserOutput, error) {
+	resp, err := r.client.EraseUser(ctx, &memoryv1.EraseUserRequest{
+		User:            input.User,
+		ConversationIds: input.ConversationIDs,
+	})
+	if err != nil {
+		return types.EraseUserOutput{}, fromStatus(err)
+	}
+	conversations := make([]types.ErasedConversation, len(resp.GetConversations()))
+	for i, conversation := range resp.GetConversations() {
+		conversations[i] = types.ErasedConversation{
+			ConversationID: conversation.GetConversationId(),
+			Agent:          conversation.GetAgent(),
+			Memories:       int(conversation.GetMemories()),
+			VectorMemories: int(conversation.GetVectorMemories()),
+			CacheEntries:   int(conversation.GetCacheEntries()),
+		}
+		if conversation.GetCreatedAt() != nil {
+			conversations[i].CreatedAt = conversation.GetCreatedAt().AsTime()
+		}
+	}
+	return types.EraseUserOutput{
+		User:          resp.GetUser(),
+		Conversations: conversations,
+		ErasedAt:      resp.GetErasedAt().AsTime(),
+	}, nil
+}
+
+func (r *grpcSemanticMemoryClient) ListConversations(ctx context.Context, input types.ListConversationsInput) (types.ListConversationsOutput, error) {
+	// the request has an int32 limit, a larger one would wrap past the server's checks
+	if input.Limit > math.MaxInt32 {
+		return types.ListConversationsOutput{}, fmt.Errorf("%w: limit must be at most %d", ErrInvalidInput, math.MaxInt32)
+	}
+	resp, err := r.client.ListConversations(ctx, &memoryv1.ListConversationsRequest{
+		Agent:  input.Agent,
+		User:   input.User,
+		Status: input.Status,
+		Limit:  int32(input.Limit),
+		Cursor: input.Cursor,
+	})
+	if err != nil {
+		return types.ListConversationsOutput{}, fromStatus(err)
+	}
+	conversations := make([]types.Conversation, len(resp.GetConversations()))
+	for i, conversation := range resp.GetConversations() {
+		conversations[i] = fromConversationMessage(conversation)
+	}
+	return types.ListConversationsOutput{
+		Conversations: conversations,
+		NextCursor:    resp.GetNextCursor(),
+	}, nil
+}
+
+func (r *grpcSemanticMemoryClient) GetConversation(ctx context.Context, input types.GetConversationInput) (types.GetConversationOutput, error) {
+	resp, err := r.client.GetConversation(ctx, &memoryv1.GetConversationRequest{
+		ConversationId: input.ConversationID,
+	})
+	if err != nil {
+		return types.GetConversationOutput{}, fromStatus(err)
+	}
+	return types.GetConversationOutput{
+		Conversation: fromConversationMessage(resp.GetConversation()),
+	}, nil
+}
+
+func (r *grpcSemanticMemoryClient) CloseConversation(ctx context.Context, input types.CloseConversationInput) (types.CloseConversationOutput, error) {
+	resp, err := r.client.CloseConversation(ctx, &memoryv1.CloseConversationRequest{
+		ConversationId: input.ConversationID,
+	})
+	if err != nil {
+		return types.CloseConversationOutput{}, fromStatus(err)
+	}
+	return types.CloseConversationOutput{
+		ConversationID: resp.GetConversationId(),
+		Status:         resp.GetStatus(),
+	}, nil
+}
+
+func (r *grpcSemanticMemoryClient) ArchiveConversation(ctx context.Context, input types.ArchiveConversationInput) (types.ArchiveConversationOutput, error) {
+	resp, err := r.client.ArchiveConversation(ctx, &memoryv1.ArchiveConversationRequest{
+		ConversationId: input.ConversationID,
+	})
+	if err != nil {
+		return types.ArchiveConversationOutput{}, fromStatus(err)
+	}
+	return types.ArchiveConversationOutput{
+		ConversationID: resp.GetConversationId(),
+		Status:         resp.GetStatus(),
+	}, nil
+}
+
+func (r *grpcSemanticMemoryClient) DeleteConversation(ctx context.Context, input types.DeleteConversationInput) (types.DeleteConversationOutput, error) {
+	resp, err := r.client.DeleteConversation(ctx, &memoryv1.DeleteConversationRequest{
+		ConversationId: input.ConversationID,
+	})
+	if err != nil {
+		return types.DeleteConversationOutput{}, fromStatus(err)
+	}
+	return types.DeleteConversationOutput{
+		ConversationID: resp.GetConversationId(),
+		Memories:       int(resp.GetMemories()),
+		VectorMemories: int(resp.GetVectorMemories()),
+		CacheEntries:   int(resp.GetCacheEntries()),
+	}, nil
+}
+
+func fromConversationMessage(conversation *memoryv1.Conversation) types.Conversation {
+	return types.Conversation{
+		ID:             conversation.GetId(),
+		Agent:          conversation.GetAgent(),
+		User:           conversation.GetUser(),
+		Status:         conversation.GetStatus(),
+		CreatedAt:      conversation.GetCreatedAt().AsTime(),
+		MemoryCount:    int(conversation.GetMemoryCount()),
+		LastActivityAt: conversation.GetLastActivityAt().AsTime(),
+	}
+}
+
+// toStruct goes through JSON so that any metadata the in-process client
+// accepts converts, structpb alone rejects values such as []string.
+func toStruct(metadata map[string]any) (*structpb.Struct, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("%w: metadata must be encodable as json", ErrInvalidInput)
+	}
+	var decoded map[string]any
+	err = json.Unmarshal(encoded, &decoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: metadata must be a json object", ErrInvalidInput)
+	}
+	return structpb.NewStruct(decoded)
+}
+
+func fromStruct(metadata *structpb.Struct) map[string]any {
+	if metadata == nil {
+		return nil
+	}
+	return metadata.AsMap()
+}
+
+// fromStatus maps gRPC status codes back onto the client sentinel errors so
+// callers can keep using errors.Is regardless of transport.
+func fromStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch st.Code() {
+	case codes.InvalidArgument:
+		return fmt.Errorf("%w: %s", ErrInvalidInput, strings.TrimPrefix(st.Message(), ErrInvalidInput.Error()+": "))
+	case codes.NotFound:
+		// the not found errors share the code, the server sends the sentinel text as the message
+		if st.Message() == ErrMemoryNotFound.Error() {
+			return ErrMemoryNotFound
+		}
+		if st.Message() == ErrNamespaceNotServed.Error() {
+			return ErrNamespaceNotServed
+		}
+		return ErrConversationNotFound
+	case codes.FailedPrecondition:
+		return fmt.Errorf("%w: %s", ErrConversationClosed, strings.TrimPrefix(st.Message(), ErrConversationClosed.Error()+": "))
+	case codes.Unimplemented:
+		return fmt.Errorf("%w: %s", ErrUnsupported, strings.TrimPrefix(st.Message(), ErrUnsupported.Error()+": "))
+	default:
+		return fmt.Errorf("grpc: %s", st.Message())
+	}
+}
@@ -0,0 +1,33 @@
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/haren7/minimal-memory/internal/memory"
+	"github.com/haren7/minimal-memory/types"
+)
+
+// checkMetadata rejects metadata that cannot be stored as JSON and empty tags.
+func checkMetadata(method string, metadata map[string]any, tags []string) error {
+	_, err := json.Marshal(metadata)
+	if err != nil {
+		log.Printf("[ERROR] %s: Metadata cannot be encoded as JSON - %v", method, err)
+		return fmt.Errorf("%w: metadata must be encodable as json", ErrInvalidInput)
+	}
+	if slices.Contains(tags, "") {
+		log.Printf("[ERROR] %s: Tags must not be empty (tags: %q)", method, tags)
+		return fmt.Errorf("%w: tags must not be empty", ErrInvalidInput)
+	}
+	return nil
+}
+
+func toFilter(method string, filter types.MemoryFilter) (memory.Filter, error) {
+	err := checkMetadata(method, filter.Metadata, filter.Tags)
+	if err != nil {
+		return memory.Filter{}, err
+	}
+	return memory.Filter{Tags: filter.Tags, Metadata: filter.Metadata}, nil
+}
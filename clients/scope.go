@@ -0,0 +1,33 @@
+package clients
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/haren7/minimal-memory/internal/memory"
+)
+
+// toScope parses the scope of a similarity search, empty is the conversation.
+func toScope(method, scope string) (memory.Scope, error) {
+	if scope == "" {
+		return memory.ScopeConversation, nil
+	}
+	if !memory.Scope(scope).IsValid() {
+		log.Printf("[ERROR] %s: Unknown scope - %q", method, scope)
+		return "", fmt.Errorf("%w: scope must be conversation, user or agent", ErrInvalidInput)
+	}
+	return memory.Scope(scope), nil
+}
+
+// toScopes parses the scopes a memory is shared with.
+func toScopes(method string, scopes []string) ([]memory.Scope, error) {
+	var parsed []memory.Scope
+	for _, scope := range scopes {
+		if !memory.Scope(scope).IsValid() {
+			log.Printf("[ERROR] %s: Unknown scope - %q", method, scope)
+			return nil, fmt.Errorf("%w: scopes must be conversation, user or agent", ErrInvalidInput)
+		}
+		parsed = append(parsed, memory.Scope(scope))
+	}
+	return parsed, nil
+}
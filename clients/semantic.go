@@ -2,15 +2,20 @@ package clients
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"time"
 
 	"github.com/haren7/minimal-memory/internal/conversation"
 	"github.com/haren7/minimal-memory/internal/embedding"
 	"github.com/haren7/minimal-memory/internal/memory"
+	"github.com/haren7/minimal-memory/internal/persistence"
 	"github.com/haren7/minimal-memory/internal/persistence/rdbms"
 	"github.com/haren7/minimal-memory/internal/persistence/vector"
 	"github.com/haren7/minimal-memory/internal/summarizer"
+	"github.com/haren7/minimal-memory/internal/tokenizer"
 	"github.com/haren7/minimal-memory/types"
 
 	"github.com/google/uuid"
@@ -19,61 +24,140 @@ import (
 type semanticMemoryClient struct {
 	memoryService       memory.SemanticServiceInterface
 	conversationService conversation.ConversationServiceInterface
-	config              SemanticMemoryClientConfig
+	tokenizer           tokenizer.Tokenizer
+	// embeddingCache is nil when the cache is disabled
+	embeddingCache     embedding.CachedServiceInterface
+	embeddingCacheRepo persistence.EmbeddingCacheRepoInterface
+	config             SemanticMemoryClientConfig
 }
 
 func NewSemanticMemoryClient(config SemanticMemoryClientConfig) (SemanticMemoryClient, error) {
-	if config.OpenAIApiKey == "" {
+	embeddingService, err := newEmbeddingService(config)
+	if err != nil {
+		return nil, err
+	}
+	err = persistence.Namespace(config.Namespace).Validate()
+	if err != nil {
+		log.Printf("[ERROR] NewSemanticMemoryClient: Invalid namespace - %v", err)
+		return nil, fmt.Errorf("error invalid namespace")
+	}
+	duckdbClient, err := openDuckDB(config.DuckDBPath, config.Namespace)
+	if err != nil {
+		log.Printf("[ERROR] NewSemanticMemoryClient: Failed to connect to DuckDB (namespace: %q) - %v", config.Namespace, err)
+		return nil, fmt.Errorf("error connecting to duckdb")
+	}
+	return newSemanticMemoryClient(config, embeddingService, duckdbClient)
+}
+
+// newEmbeddingService returns the provider of config behind its retries and
+// rate limits, the limits hold for every client sharing it.
+func newEmbeddingService(config SemanticMemoryClientConfig) (embedding.ServiceInterface, error) {
+	embeddingConfig := config.embeddingConfig()
+	if embeddingConfig.Provider == embedding.ProviderOpenAI && config.OpenAIApiKey == "" && config.OpenAIBaseURL == "" {
 		log.Printf("[ERROR] NewSemanticMemoryClient: OpenAI API key is required but was not provided")
 		return nil, fmt.Errorf("error openai api key is required")
 	}
-	embeddingService := embedding.NewOpenAIService(config.OpenAIApiKey)
+	embeddingService, err := embedding.NewService(embeddingConfig)
+	if err != nil {
+		log.Printf("[ERROR] NewSemanticMemoryClient: Failed to create embedding service - %v", err)
+		return nil, fmt.Errorf("error creating embedding service")
+	}
+	return embedding.NewResilientService(embeddingService, config.EmbeddingResilience.resilienceConfig()), nil
+}
+
+// newSemanticMemoryClient serves the namespace of duckdbClient, config.Namespace
+// and config.DuckDBPath are not read.
+func newSemanticMemoryClient(config SemanticMemoryClientConfig, embeddingService embedding.ServiceInterface, duckdbClient *rdbms.DuckDBClient) (SemanticMemoryClient, error) {
+	config.Namespace = string(duckdbClient.GetNamespace())
 	summarizerService := summarizer.NewNoOpService()
-	duckdbClient, err := rdbms.NewDuckDBClient()
+	tokenizer, err := tokenizer.New(config.Tokenizer.tokenizerConfig())
 	if err != nil {
-		log.Printf("[ERROR] NewSemanticMemoryClient: Failed to connect to DuckDB - %v", err)
-		return nil, fmt.Errorf("error connecting to duckdb")
+		log.Printf("[ERROR] NewSemanticMemoryClient: Failed to create tokenizer (model: %q) - %v", config.Tokenizer.Model, err)
+		return nil, fmt.Errorf("error creating tokenizer")
 	}
-	conversationRepo := rdbms.NewConversationRepo(duckdbClient.GetDB())
+	embeddingCacheRepo := rdbms.NewEmbeddingCacheRepo(duckdbClient)
+	var embeddingCache embedding.CachedServiceInterface
+	if config.EmbeddingCacheSize >= 0 {
+		var cacheRepo persistence.EmbeddingCacheRepoInterface
+		if config.EmbeddingCachePersistent {
+			cacheRepo = embeddingCacheRepo
+		}
+		embeddingCache = embedding.NewCachedService(embeddingService, config.EmbeddingCacheSize, cacheRepo)
+		embeddingService = embeddingCache
+	}
+	conversationRepo := rdbms.NewConversationRepo(duckdbClient)
 	conversationService := conversation.NewConversationService(conversationRepo)
-	memoryRepo := rdbms.NewMemoryRepo(duckdbClient.GetDB())
-	faissMemoryRepo := rdbms.NewFaissMemoryRepo(duckdbClient.GetDB())
-	// chromemDB := vector.NewChromem()
-	// vectorMemoryRepo := vector.NewChromemMemoryRepo(chromemDB, embeddingService)
-	faiss := vector.NewFaissClient()
-	vectorMemoryRepo := vector.NewFaissMemoryRepo(faiss, embeddingService, faissMemoryRepo)
+	memoryRepo := rdbms.NewMemoryRepo(duckdbClient)
+	faissMemoryRepo := rdbms.NewFaissMemoryRepo(duckdbClient)
+	vectorMemoryRepo, err := newVectorMemoryRepo(config, embeddingService, faissMemoryRepo)
+	if err != nil {
+		log.Printf("[ERROR] NewSemanticMemoryClient: Failed to create vector backend (backend: %q) - %v", config.VectorBackend, err)
+		return nil, fmt.Errorf("error creating vector backend")
+	}
 	memoryService := memory.NewSemanticService(vectorMemoryRepo, memoryRepo, conversationRepo, summarizerService)
 	return &semanticMemoryClient{
 		config:              config,
 		memoryService:       memoryService,
 		conversationService: conversationService,
+		tokenizer:           tokenizer,
+		embeddingCache:      embeddingCache,
+		embeddingCacheRepo:  embeddingCacheRepo,
 	}, nil
 }
 
+func newVectorMemoryRepo(config SemanticMemoryClientConfig, embeddingService embedding.ServiceInterface, memoryRepo persistence.MemoryRepoInterface) (persistence.VectorMemoryRepoInterface, error) {
+	switch config.VectorBackend {
+	case "", VectorBackendFaiss:
+		return newFaissMemoryRepo(config, embeddingService, memoryRepo)
+	case VectorBackendBruteForce:
+		bruteForce, err := vector.NewBruteForceClient(vector.Metric(config.VectorMetric), persistence.Namespace(config.Namespace))
+		if err != nil {
+			return nil, err
+		}
+		return vector.NewBruteForceMemoryRepo(bruteForce, embeddingService, memoryRepo), nil
+	case VectorBackendChromem:
+		chromem, err := vector.NewChromem(persistence.Namespace(config.Namespace))
+		if err != nil {
+			return nil, err
+		}
+		return vector.NewChromemMemoryRepo(chromem, embeddingService, memoryRepo, vector.Metric(config.VectorMetric))
+	}
+	return nil, fmt.Errorf("unknown vector backend %q", config.VectorBackend)
+}
+
 func (r *semanticMemoryClient) Store(ctx context.Context, input types.StoreSemanticMemoryInput) (types.StoreSemanticMemoryOutput, error) {
 	if input.Query == "" || input.Response == "" {
 		log.Printf("[ERROR] Store: Query and response are required but one or both were empty (query: %q, response: %q)", input.Query, input.Response)
-		return types.StoreSemanticMemoryOutput{}, fmt.Errorf("query and response are required")
+		return types.StoreSemanticMemoryOutput{}, fmt.Errorf("%w: query and response are required", ErrInvalidInput)
 	}
 	if input.ConversationID == "" {
 		log.Printf("[ERROR] Store: Conversation ID is required but was empty")
-		return types.StoreSemanticMemoryOutput{}, fmt.Errorf("conversation id is required")
+		return types.StoreSemanticMemoryOutput{}, fmt.Errorf("%w: conversation id is required", ErrInvalidInput)
 	}
 	conversationID, err := uuid.Parse(input.ConversationID)
 	if err != nil {
 		log.Printf("[ERROR] Store: Invalid conversation ID format - %q, error: %v", input.ConversationID, err)
-		return types.StoreSemanticMemoryOutput{}, fmt.Errorf("invalid conversation id")
+		return types.StoreSemanticMemoryOutput{}, fmt.Errorf("%w: invalid conversation id", ErrInvalidInput)
 	}
-	exists, err := r.conversationService.Exists(ctx, conversationID)
+	err = checkMetadata("Store", input.Metadata, input.Tags)
 	if err != nil {
-		log.Printf("[ERROR] Store: Failed to check if conversation exists (conversationID: %s) - %v", conversationID, err)
-		return types.StoreSemanticMemoryOutput{}, fmt.Errorf("error checking if conversation exists")
+		return types.StoreSemanticMemoryOutput{}, err
 	}
-	if !exists {
-		log.Printf("[ERROR] Store: Conversation does not exist (conversationID: %s)", conversationID)
-		return types.StoreSemanticMemoryOutput{}, fmt.Errorf("conversation does not exist")
+	scopes, err := toScopes("Store", input.Scopes)
+	if err != nil {
+		return types.StoreSemanticMemoryOutput{}, err
 	}
-	id, err := r.memoryService.Store(ctx, conversationID, input.Query, input.Response)
+	err = writableConversation(ctx, r.conversationService, "Store", conversationID)
+	if err != nil {
+		return types.StoreSemanticMemoryOutput{}, err
+	}
+	id, err := r.memoryService.Store(ctx, conversationID, memory.MemoryInput{
+		Query:    input.Query,
+		Response: input.Response,
+		Metadata: input.Metadata,
+		Tags:     input.Tags,
+		Scopes:   scopes,
+	})
 	if err != nil {
 		log.Printf("[ERROR] Store: Failed to store memory (conversationID: %s) - %v", conversationID, err)
 		return types.StoreSemanticMemoryOutput{}, fmt.Errorf("error storing memory")
@@ -83,15 +167,84 @@ func (r *semanticMemoryClient) Store(ctx context.Context, input types.StoreSeman
 	}, nil
 }
 
+func (r *semanticMemoryClient) StoreMany(ctx context.Context, input types.StoreManySemanticMemoryInput) (types.StoreManySemanticMemoryOutput, error) {
+	if len(input.Memories) == 0 {
+		log.Printf("[ERROR] StoreMany: Memories are required but none were given")
+		return types.StoreManySemanticMemoryOutput{}, fmt.Errorf("%w: memories are required", ErrInvalidInput)
+	}
+	for i, entry := range input.Memories {
+		if entry.Query == "" || entry.Response == "" {
+			log.Printf("[ERROR] StoreMany: Query and response are required but one or both were empty (index: %d, query: %q, response: %q)", i, entry.Query, entry.Response)
+			return types.StoreManySemanticMemoryOutput{}, fmt.Errorf("%w: query and response are required, memory %d", ErrInvalidInput, i)
+		}
+		err := checkMetadata("StoreMany", entry.Metadata, entry.Tags)
+		if err != nil {
+			return types.StoreManySemanticMemoryOutput{}, fmt.Errorf("%w, memory %d", err, i)
+		}
+	}
+	if input.ConversationID == "" {
+		log.Printf("[ERROR] StoreMany: Conversation ID is required but was empty")
+		return types.StoreManySemanticMemoryOutput{}, fmt.Errorf("%w: conversation id is required", ErrInvalidInput)
+	}
+	conversationID, err := uuid.Parse(input.ConversationID)
+	if err != nil {
+		log.Printf("[ERROR] StoreMany: Invalid conversation ID format - %q, error: %v", input.ConversationID, err)
+		return types.StoreManySemanticMemoryOutput{}, fmt.Errorf("%w: invalid conversation id", ErrInvalidInput)
+	}
+	scopes, err := toScopes("StoreMany", input.Scopes)
+	if err != nil {
+		return types.StoreManySemanticMemoryOutput{}, err
+	}
+	err = writableConversation(ctx, r.conversationService, "StoreMany", conversationID)
+	if err != nil {
+		return types.StoreManySemanticMemoryOutput{}, err
+	}
+	memories := make([]memory.MemoryInput, len(input.Memories))
+	for i, entry := range input.Memories {
+		memories[i] = memory.MemoryInput{
+			Query:     entry.Query,
+			Response:  entry.Response,
+			CreatedAt: entry.CreatedAt,
+			Metadata:  entry.Metadata,
+			Tags:      entry.Tags,
+			Scopes:    scopes,
+		}
+	}
+	ids, err := r.memoryService.StoreMany(ctx, conversationID, memories)
+	if err != nil {
+		log.Printf("[ERROR] StoreMany: Failed to store memories (conversationID: %s, count: %d) - %v", conversationID, len(memories), err)
+		return types.StoreManySemanticMemoryOutput{}, fmt.Errorf("error storing memories")
+	}
+	memoryIDs := make([]string, len(ids))
+	for i, id := range ids {
+		memoryIDs[i] = id.String()
+	}
+	return types.StoreManySemanticMemoryOutput{
+		MemoryIDs: memoryIDs,
+	}, nil
+}
+
 func (r *semanticMemoryClient) Retrieve(ctx context.Context, input types.RetrieveSemanticMemoryInput) (types.RetrieveSemanticMemoryOutput, error) {
 	if input.ConversationID == "" {
 		log.Printf("[ERROR] Retrieve: Conversation ID is required but was empty")
-		return types.RetrieveSemanticMemoryOutput{}, fmt.Errorf("conversation id is required")
+		return types.RetrieveSemanticMemoryOutput{}, fmt.Errorf("%w: conversation id is required", ErrInvalidInput)
 	}
 	conversationID, err := uuid.Parse(input.ConversationID)
 	if err != nil {
 		log.Printf("[ERROR] Retrieve: Invalid conversation ID format - %q, error: %v", input.ConversationID, err)
-		return types.RetrieveSemanticMemoryOutput{}, fmt.Errorf("invalid conversation id")
+		return types.RetrieveSemanticMemoryOutput{}, fmt.Errorf("%w: invalid conversation id", ErrInvalidInput)
+	}
+	filter, err := toFilter("Retrieve", input.Filter)
+	if err != nil {
+		return types.RetrieveSemanticMemoryOutput{}, err
+	}
+	scope, err := toScope("Retrieve", input.Scope)
+	if err != nil {
+		return types.RetrieveSemanticMemoryOutput{}, err
+	}
+	if input.MaxTokens < 0 {
+		log.Printf("[ERROR] Retrieve: Max tokens must not be negative (maxTokens: %d)", input.MaxTokens)
+		return types.RetrieveSemanticMemoryOutput{}, fmt.Errorf("%w: max tokens must not be negative", ErrInvalidInput)
 	}
 	exists, err := r.conversationService.Exists(ctx, conversationID)
 	if err != nil {
@@ -100,21 +253,43 @@ func (r *semanticMemoryClient) Retrieve(ctx context.Context, input types.Retriev
 	}
 	if !exists {
 		log.Printf("[ERROR] Retrieve: Conversation does not exist (conversationID: %s)", conversationID)
-		return types.RetrieveSemanticMemoryOutput{}, fmt.Errorf("conversation does not exist")
+		return types.RetrieveSemanticMemoryOutput{}, ErrConversationNotFound
+	}
+	if input.TopK > MaxTopK {
+		log.Printf("[ERROR] Retrieve: Top k exceeds the maximum (topK: %d, max: %d)", input.TopK, MaxTopK)
+		return types.RetrieveSemanticMemoryOutput{}, fmt.Errorf("%w: top k must be at most %d", ErrInvalidInput, MaxTopK)
 	}
-	var topK int
-	if input.TopK == 0 {
+	topK := input.TopK
+	if topK <= 0 {
 		topK = 10
 	}
-	retrievedMemories, err := r.memoryService.Retrieve(ctx, conversationID, r.config.ContextWindowSize)
-	if err != nil {
-		log.Printf("[ERROR] Retrieve: Failed to retrieve memories (conversationID: %s, contextWindowSize: %d) - %v", conversationID, r.config.ContextWindowSize, err)
-		return types.RetrieveSemanticMemoryOutput{}, fmt.Errorf("error retrieving memories")
+	minScore := float32(math.Inf(-1))
+	if input.MinScore != nil {
+		minScore = *input.MinScore
 	}
-	retrievedSimilarMemories, err := r.memoryService.RetrieveSimilar(ctx, conversationID, input.Query, topK)
-	if err != nil {
-		log.Printf("[ERROR] Retrieve: Failed to retrieve similar memories (conversationID: %s, query: %q, topK: %d) - %v", conversationID, input.Query, topK, err)
-		return types.RetrieveSemanticMemoryOutput{}, fmt.Errorf("error retrieving similar memories")
+	// a token budget replaces the context window
+	var retrievedMemories []memory.Memory
+	if input.MaxTokens > 0 {
+		retrievedMemories, err = r.memoryService.RetrieveWithinTokens(ctx, conversationID, input.MaxTokens, input.TruncateOldest, r.tokenizer, filter)
+		if err != nil {
+			log.Printf("[ERROR] Retrieve: Failed to retrieve memories (conversationID: %s, maxTokens: %d) - %v", conversationID, input.MaxTokens, err)
+			return types.RetrieveSemanticMemoryOutput{}, fmt.Errorf("error retrieving memories")
+		}
+	} else {
+		retrievedMemories, err = r.memoryService.Retrieve(ctx, conversationID, r.config.ContextWindowSize, filter)
+		if err != nil {
+			log.Printf("[ERROR] Retrieve: Failed to retrieve memories (conversationID: %s, contextWindowSize: %d) - %v", conversationID, r.config.ContextWindowSize, err)
+			return types.RetrieveSemanticMemoryOutput{}, fmt.Errorf("error retrieving memories")
+		}
+	}
+	// without a query there is nothing to compare against, so only the recent window is returned
+	var retrievedSimilarMemories []memory.Memory
+	if input.Query != "" {
+		retrievedSimilarMemories, err = r.memoryService.RetrieveSimilar(ctx, conversationID, scope, input.Query, topK, minScore, filter)
+		if err != nil {
+			log.Printf("[ERROR] Retrieve: Failed to retrieve similar memories (conversationID: %s, scope: %s, query: %q, topK: %d) - %v", conversationID, scope, input.Query, topK, err)
+			return types.RetrieveSemanticMemoryOutput{}, fmt.Errorf("error retrieving similar memories")
+		}
 	}
 	var memories []types.Memory
 	for _, memory := range retrievedMemories {
@@ -123,15 +298,24 @@ func (r *semanticMemoryClient) Retrieve(ctx context.Context, input types.Retriev
 			Query:     memory.Query,
 			Response:  memory.Response,
 			CreatedAt: memory.CreatedAt,
+			Metadata:  memory.Metadata,
+			Tags:      memory.Tags,
+			Truncated: memory.Truncated,
 		})
 	}
 	var similarMemories []types.SemanticMemory
 	for _, memory := range retrievedSimilarMemories {
 		similarMemories = append(similarMemories, types.SemanticMemory{
-			ID:        memory.ID.String(),
-			Query:     memory.Query,
-			Response:  memory.Response,
-			CreatedAt: memory.CreatedAt,
+			ID:             memory.ID.String(),
+			ConversationID: memory.ConversationID.String(),
+			Query:          memory.Query,
+			Response:       memory.Response,
+			CreatedAt:      memory.CreatedAt,
+			Metadata:       memory.Metadata,
+			Tags:           memory.Tags,
+			Rank:           memory.Rank,
+			Score:          memory.Score,
+			Distance:       memory.Distance,
 		})
 	}
 	return types.RetrieveSemanticMemoryOutput{
@@ -140,10 +324,52 @@ func (r *semanticMemoryClient) Retrieve(ctx context.Context, input types.Retriev
 	}, nil
 }
 
+func (r *semanticMemoryClient) Update(ctx context.Context, input types.UpdateSemanticMemoryInput) (types.UpdateSemanticMemoryOutput, error) {
+	if input.Query == "" || input.Response == "" {
+		log.Printf("[ERROR] Update: Query and response are required but one or both were empty (query: %q, response: %q)", input.Query, input.Response)
+		return types.UpdateSemanticMemoryOutput{}, fmt.Errorf("%w: query and response are required", ErrInvalidInput)
+	}
+	conversationID, memoryID, err := parseMemoryIDs(ctx, r.conversationService, "Update", input.ConversationID, input.MemoryID)
+	if err != nil {
+		return types.UpdateSemanticMemoryOutput{}, err
+	}
+	err = r.memoryService.Update(ctx, conversationID, memoryID, input.Query, input.Response)
+	if errors.Is(err, persistence.ErrNotFound) {
+		log.Printf("[ERROR] Update: Memory does not exist (conversationID: %s, memoryID: %s)", conversationID, memoryID)
+		return types.UpdateSemanticMemoryOutput{}, ErrMemoryNotFound
+	}
+	if err != nil {
+		log.Printf("[ERROR] Update: Failed to update memory (conversationID: %s, memoryID: %s) - %v", conversationID, memoryID, err)
+		return types.UpdateSemanticMemoryOutput{}, fmt.Errorf("error updating memory")
+	}
+	return types.UpdateSemanticMemoryOutput{
+		MemoryID: memoryID.String(),
+	}, nil
+}
+
+func (r *semanticMemoryClient) Delete(ctx context.Context, input types.DeleteSemanticMemoryInput) (types.DeleteSemanticMemoryOutput, error) {
+	conversationID, memoryID, err := parseMemoryIDs(ctx, r.conversationService, "Delete", input.ConversationID, input.MemoryID)
+	if err != nil {
+		return types.DeleteSemanticMemoryOutput{}, err
+	}
+	err = r.memoryService.Delete(ctx, conversationID, memoryID)
+	if errors.Is(err, persistence.ErrNotFound) {
+		log.Printf("[ERROR] Delete: Memory does not exist (conversationID: %s, memoryID: %s)", conversationID, memoryID)
+		return types.DeleteSemanticMemoryOutput{}, ErrMemoryNotFound
+	}
+	if err != nil {
+		log.Printf("[ERROR] Delete: Failed to delete memory (conversationID: %s, memoryID: %s) - %v", conversationID, memoryID, err)
+		return types.DeleteSemanticMemoryOutput{}, fmt.Errorf("error deleting memory")
+	}
+	return types.DeleteSemanticMemoryOutput{
+		MemoryID: memoryID.String(),
+	}, nil
+}
+
 func (r *semanticMemoryClient) RegisterConversation(ctx context.Context, input types.RegisterConversationInput) (types.RegisterConversationOutput, error) {
 	if input.Agent == "" || input.User == "" {
 		log.Printf("[ERROR] RegisterConversation: Agent and user are required but one or both were empty (agent: %q, user: %q)", input.Agent, input.User)
-		return types.RegisterConversationOutput{}, fmt.Errorf("agent and user are required")
+		return types.RegisterConversationOutput{}, fmt.Errorf("%w: agent and user are required", ErrInvalidInput)
 	}
 	id, err := r.conversationService.Create(ctx, input.Agent, input.User)
 	if err != nil {
@@ -154,3 +380,167 @@ func (r *semanticMemoryClient) RegisterConversation(ctx context.Context, input t
 		ConversationID: id.String(),
 	}, nil
 }
+
+func (r *semanticMemoryClient) Reindex(ctx context.Context, input types.ReindexSemanticMemoryInput) (types.ReindexSemanticMemoryOutput, error) {
+	var conversationIDs []uuid.UUID
+	if input.ConversationID != "" {
+		conversationID, err := uuid.Parse(input.ConversationID)
+		if err != nil {
+			log.Printf("[ERROR] Reindex: Invalid conversation ID format - %q, error: %v", input.ConversationID, err)
+			return types.ReindexSemanticMemoryOutput{}, fmt.Errorf("%w: invalid conversation id", ErrInvalidInput)
+		}
+		exists, err := r.conversationService.Exists(ctx, conversationID)
+		if err != nil {
+			log.Printf("[ERROR] Reindex: Failed to check if conversation exists (conversationID: %s) - %v", conversationID, err)
+			return types.ReindexSemanticMemoryOutput{}, fmt.Errorf("error checking if conversation exists")
+		}
+		if !exists {
+			log.Printf("[ERROR] Reindex: Conversation does not exist (conversationID: %s)", conversationID)
+			return types.ReindexSemanticMemoryOutput{}, ErrConversationNotFound
+		}
+		conversationIDs = []uuid.UUID{conversationID}
+	}
+	results, err := r.memoryService.Reindex(ctx, conversationIDs)
+	if err != nil {
+		log.Printf("[ERROR] Reindex: Failed to reindex (conversationID: %q, reindexed: %d) - %v", input.ConversationID, len(results), err)
+		return types.ReindexSemanticMemoryOutput{}, fmt.Errorf("error reindexing memories")
+	}
+	conversations := make([]types.ReindexedConversation, len(results))
+	for i, result := range results {
+		conversations[i] = types.ReindexedConversation{
+			ConversationID: result.ConversationID.String(),
+			Memories:       result.Memories,
+		}
+	}
+	return types.ReindexSemanticMemoryOutput{
+		Conversations: conversations,
+	}, nil
+}
+
+func (r *semanticMemoryClient) EmbeddingCacheStats(ctx context.Context, input types.EmbeddingCacheStatsInput) (types.EmbeddingCacheStatsOutput, error) {
+	if r.embeddingCache == nil {
+		return types.EmbeddingCacheStatsOutput{}, nil
+	}
+	stats := r.embeddingCache.Stats()
+	return types.EmbeddingCacheStatsOutput{
+		Enabled:     true,
+		MemoryHits:  stats.MemoryHits,
+		StoreHits:   stats.StoreHits,
+		Misses:      stats.Misses,
+		StoreErrors: stats.StoreErrors,
+	}, nil
+}
+
+func (r *semanticMemoryClient) EraseUser(ctx context.Context, input types.EraseUserInput) (types.EraseUserOutput, error) {
+	conversations, err := conversationsToErase(ctx, r.conversationService, input)
+	if err != nil {
+		return types.EraseUserOutput{}, err
+	}
+	erased := make([]types.ErasedConversation, 0, len(conversations))
+	for _, conversation := range conversations {
+		result, err := r.memoryService.Erase(ctx, conversation.ID)
+		if err != nil {
+			log.Printf("[ERROR] EraseUser: Failed to erase memories (conversationID: %s, erased: %d) - %v", conversation.ID, len(erased), err)
+			return types.EraseUserOutput{}, fmt.Errorf("error erasing memories")
+		}
+		err = r.conversationService.Delete(ctx, conversation.ID)
+		if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+			log.Printf("[ERROR] EraseUser: Failed to delete conversation (conversationID: %s, erased: %d) - %v", conversation.ID, len(erased), err)
+			return types.EraseUserOutput{}, fmt.Errorf("error deleting conversation")
+		}
+		erased = append(erased, types.ErasedConversation{
+			ConversationID: conversation.ID.String(),
+			Agent:          conversation.Agent,
+			CreatedAt:      conversation.CreatedAt,
+			Memories:       result.Memories,
+			VectorMemories: result.VectorMemories,
+		})
+	}
+	// shared copies went with their conversations, this also drops the ones of conversations deleted earlier
+	_, err = r.memoryService.EraseUser(ctx, input.User)
+	if err != nil {
+		log.Printf("[ERROR] EraseUser: Failed to erase user index (erased: %d) - %v", len(erased), err)
+		return types.EraseUserOutput{}, fmt.Errorf("error erasing user memories")
+	}
+	err = r.purgeEmbeddingCache(ctx)
+	if err != nil {
+		log.Printf("[ERROR] EraseUser: Failed to purge embedding cache (erased: %d) - %v", len(erased), err)
+		return types.EraseUserOutput{}, fmt.Errorf("error purging embedding cache")
+	}
+	return types.EraseUserOutput{
+		User:          input.User,
+		Conversations: erased,
+		ErasedAt:      time.Now(),
+	}, nil
+}
+
+// purgeEmbeddingCache empties both cache tiers. The table is emptied even when
+// the persistent tier is off, an earlier run may have filled it.
+func (r *semanticMemoryClient) purgeEmbeddingCache(ctx context.Context) error {
+	if r.embeddingCache != nil {
+		err := r.embeddingCache.Purge(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := r.embeddingCacheRepo.DeleteAll(ctx)
+	return err
+}
+
+func (r *semanticMemoryClient) ListConversations(ctx context.Context, input types.ListConversationsInput) (types.ListConversationsOutput, error) {
+	return listConversations(ctx, r.conversationService, r.memoryService.Stats, input)
+}
+
+func (r *semanticMemoryClient) GetConversation(ctx context.Context, input types.GetConversationInput) (types.GetConversationOutput, error) {
+	return getConversation(ctx, r.conversationService, r.memoryService.Stats, input)
+}
+
+func (r *semanticMemoryClient) CloseConversation(ctx context.Context, input types.CloseConversationInput) (types.CloseConversationOutput, error) {
+	conversationID, err := setConversationStatus(ctx, r.conversationService, "CloseConversation", input.ConversationID, conversation.StatusClosed)
+	if err != nil {
+		return types.CloseConversationOutput{}, err
+	}
+	return types.CloseConversationOutput{
+		ConversationID: conversationID.String(),
+		Status:         conversation.StatusClosed,
+	}, nil
+}
+
+func (r *semanticMemoryClient) ArchiveConversation(ctx context.Context, input types.ArchiveConversationInput) (types.ArchiveConversationOutput, error) {
+	conversationID, err := setConversationStatus(ctx, r.conversationService, "ArchiveConversation", input.ConversationID, conversation.StatusArchived)
+	if err != nil {
+		return types.ArchiveConversationOutput{}, err
+	}
+	return types.ArchiveConversationOutput{
+		ConversationID: conversationID.String(),
+		Status:         conversation.StatusArchived,
+	}, nil
+}
+
+// DeleteConversation erases the memories before deleting the conversation, so
+// a failed delete can be retried.
+func (r *semanticMemoryClient) DeleteConversation(ctx context.Context, input types.DeleteConversationInput) (types.DeleteConversationOutput, error) {
+	conversationID, err := parseConversationID("DeleteConversation", input.ConversationID)
+	if err != nil {
+		return types.DeleteConversationOutput{}, err
+	}
+	_, err = fetchConversation(ctx, r.conversationService, "DeleteConversation", conversationID)
+	if err != nil {
+		return types.DeleteConversationOutput{}, err
+	}
+	result, err := r.memoryService.Erase(ctx, conversationID)
+	if err != nil {
+		log.Printf("[ERROR] DeleteConversation: Failed to erase memories (conversationID: %s) - %v", conversationID, err)
+		return types.DeleteConversationOutput{}, fmt.Errorf("error erasing memories")
+	}
+	err = r.conversationService.Delete(ctx, conversationID)
+	if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+		log.Printf("[ERROR] DeleteConversation: Failed to delete conversation (conversationID: %s) - %v", conversationID, err)
+		return types.DeleteConversationOutput{}, fmt.Errorf("error deleting conversation")
+	}
+	return types.DeleteConversationOutput{
+		ConversationID: conversationID.String(),
+		Memories:       result.Memories,
+		VectorMemories: result.VectorMemories,
+	}, nil
+}
@@ -2,14 +2,18 @@ package clients
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/haren7/minimal-memory/internal/cache"
 	"github.com/haren7/minimal-memory/internal/conversation"
 	"github.com/haren7/minimal-memory/internal/memory"
+	"github.com/haren7/minimal-memory/internal/persistence"
 	"github.com/haren7/minimal-memory/internal/persistence/rdbms"
 	"github.com/haren7/minimal-memory/internal/summarizer"
+	"github.com/haren7/minimal-memory/internal/tokenizer"
 	"github.com/haren7/minimal-memory/types"
 
 	"github.com/google/uuid"
@@ -18,17 +22,39 @@ import (
 type shortTermMemoryClient struct {
 	memoryService       memory.ServiceInterface
 	conversationService conversation.ConversationServiceInterface
+	tokenizer           tokenizer.Tokenizer
 	config              ShortTermMemoryClientConfig
 }
 
 func NewShortTermMemoryClient(config ShortTermMemoryClientConfig) (ShortTermMemoryClient, error) {
-	duckdbClient, err := rdbms.NewDuckDBClient()
+	err := persistence.Namespace(config.Namespace).Validate()
 	if err != nil {
-		log.Printf("[ERROR] NewShortTermMemoryClient: Failed to connect to DuckDB - %v", err)
+		log.Printf("[ERROR] NewShortTermMemoryClient: Invalid namespace - %v", err)
+		return nil, fmt.Errorf("error invalid namespace")
+	}
+	duckdbClient, err := openDuckDB(config.DuckDBPath, config.Namespace)
+	if err != nil {
+		log.Printf("[ERROR] NewShortTermMemoryClient: Failed to connect to DuckDB (namespace: %q) - %v", config.Namespace, err)
 		return nil, err
 	}
-	memoryRepo := cache.NewInMemMemoryRepo()
-	conversationRepo := rdbms.NewConversationRepo(duckdbClient.GetDB())
+	return newShortTermMemoryClient(config, duckdbClient)
+}
+
+// newShortTermMemoryClient serves the namespace of duckdbClient, config.Namespace
+// and config.DuckDBPath are not read.
+func newShortTermMemoryClient(config ShortTermMemoryClientConfig, duckdbClient *rdbms.DuckDBClient) (ShortTermMemoryClient, error) {
+	config.Namespace = string(duckdbClient.GetNamespace())
+	tokenizer, err := tokenizer.New(config.Tokenizer.tokenizerConfig())
+	if err != nil {
+		log.Printf("[ERROR] NewShortTermMemoryClient: Failed to create tokenizer (model: %q) - %v", config.Tokenizer.Model, err)
+		return nil, fmt.Errorf("error creating tokenizer")
+	}
+	memoryRepo := cache.NewInMemMemoryRepo(cache.Config{
+		MaxWindow:        config.MaxWindow,
+		MaxConversations: config.MaxConversations,
+		IdleTTL:          config.IdleTTL,
+	})
+	conversationRepo := rdbms.NewConversationRepo(duckdbClient)
 	conversationService := conversation.NewConversationService(conversationRepo)
 	summarizerService := summarizer.NewNoOpService()
 	memoryService := memory.NewCachedService(memoryRepo, summarizerService)
@@ -36,33 +62,38 @@ func NewShortTermMemoryClient(config ShortTermMemoryClientConfig) (ShortTermMemo
 		config:              config,
 		memoryService:       memoryService,
 		conversationService: conversationService,
+		tokenizer:           tokenizer,
 	}, nil
 }
 
 func (r *shortTermMemoryClient) Store(ctx context.Context, input types.StoreShortTermMemoryInput) (types.StoreShortTermMemoryOutput, error) {
 	if input.Query == "" || input.Response == "" {
 		log.Printf("[ERROR] Store: Query and response are required but one or both were empty (query: %q, response: %q)", input.Query, input.Response)
-		return types.StoreShortTermMemoryOutput{}, fmt.Errorf("query and response are required")
+		return types.StoreShortTermMemoryOutput{}, fmt.Errorf("%w: query and response are required", ErrInvalidInput)
 	}
 	if input.ConversationID == "" {
 		log.Printf("[ERROR] Store: Conversation ID is required but was empty")
-		return types.StoreShortTermMemoryOutput{}, fmt.Errorf("conversation id is required")
+		return types.StoreShortTermMemoryOutput{}, fmt.Errorf("%w: conversation id is required", ErrInvalidInput)
 	}
 	conversationID, err := uuid.Parse(input.ConversationID)
 	if err != nil {
 		log.Printf("[ERROR] Store: Invalid conversation ID format - %q, error: %v", input.ConversationID, err)
-		return types.StoreShortTermMemoryOutput{}, fmt.Errorf("invalid conversation id")
+		return types.StoreShortTermMemoryOutput{}, fmt.Errorf("%w: invalid conversation id", ErrInvalidInput)
 	}
-	exists, err := r.conversationService.Exists(ctx, conversationID)
+	err = checkMetadata("Store", input.Metadata, input.Tags)
 	if err != nil {
-		log.Printf("[ERROR] Store: Failed to check if conversation exists (conversationID: %s) - %v", conversationID, err)
-		return types.StoreShortTermMemoryOutput{}, fmt.Errorf("error checking if conversation exists")
+		return types.StoreShortTermMemoryOutput{}, err
 	}
-	if !exists {
-		log.Printf("[ERROR] Store: Conversation does not exist (conversationID: %s)", conversationID)
-		return types.StoreShortTermMemoryOutput{}, fmt.Errorf("conversation does not exist")
+	err = writableConversation(ctx, r.conversationService, "Store", conversationID)
+	if err != nil {
+		return types.StoreShortTermMemoryOutput{}, err
 	}
-	id, err := r.memoryService.Store(ctx, conversationID, input.Query, input.Response)
+	id, err := r.memoryService.Store(ctx, conversationID, memory.MemoryInput{
+		Query:    input.Query,
+		Response: input.Response,
+		Metadata: input.Metadata,
+		Tags:     input.Tags,
+	})
 	if err != nil {
 		log.Printf("[ERROR] Store: Failed to store memory (conversationID: %s) - %v", conversationID, err)
 		return types.StoreShortTermMemoryOutput{}, fmt.Errorf("error storing memory")
@@ -75,12 +106,20 @@ func (r *shortTermMemoryClient) Store(ctx context.Context, input types.StoreShor
 func (r *shortTermMemoryClient) Retrieve(ctx context.Context, input types.RetrieveShortTermMemoryInput) (types.RetrieveShortTermMemoryOutput, error) {
 	if input.ConversationID == "" {
 		log.Printf("[ERROR] Retrieve: Conversation ID is required but was empty")
-		return types.RetrieveShortTermMemoryOutput{}, fmt.Errorf("conversation id is required")
+		return types.RetrieveShortTermMemoryOutput{}, fmt.Errorf("%w: conversation id is required", ErrInvalidInput)
 	}
 	conversationID, err := uuid.Parse(input.ConversationID)
 	if err != nil {
 		log.Printf("[ERROR] Retrieve: Invalid conversation ID format - %q, error: %v", input.ConversationID, err)
-		return types.RetrieveShortTermMemoryOutput{}, fmt.Errorf("invalid conversation id")
+		return types.RetrieveShortTermMemoryOutput{}, fmt.Errorf("%w: invalid conversation id", ErrInvalidInput)
+	}
+	filter, err := toFilter("Retrieve", input.Filter)
+	if err != nil {
+		return types.RetrieveShortTermMemoryOutput{}, err
+	}
+	if input.MaxTokens < 0 {
+		log.Printf("[ERROR] Retrieve: Max tokens must not be negative (maxTokens: %d)", input.MaxTokens)
+		return types.RetrieveShortTermMemoryOutput{}, fmt.Errorf("%w: max tokens must not be negative", ErrInvalidInput)
 	}
 	exists, err := r.conversationService.Exists(ctx, conversationID)
 	if err != nil {
@@ -89,17 +128,20 @@ func (r *shortTermMemoryClient) Retrieve(ctx context.Context, input types.Retrie
 	}
 	if !exists {
 		log.Printf("[ERROR] Retrieve: Conversation does not exist (conversationID: %s)", conversationID)
-		return types.RetrieveShortTermMemoryOutput{}, fmt.Errorf("conversation does not exist")
+		return types.RetrieveShortTermMemoryOutput{}, ErrConversationNotFound
 	}
-	var topK int
-	if input.TopK == 0 {
+	topK := input.TopK
+	if topK <= 0 && input.MaxTokens == 0 {
 		topK = 10
 	}
-	retrievedMemories, err := r.memoryService.Retrieve(ctx, conversationID, topK)
+	retrievedMemories, err := r.memoryService.Retrieve(ctx, conversationID, topK, filter)
 	if err != nil {
 		log.Printf("[ERROR] Retrieve: Failed to retrieve memories (conversationID: %s, topK: %d) - %v", conversationID, topK, err)
 		return types.RetrieveShortTermMemoryOutput{}, fmt.Errorf("error retrieving memories")
 	}
+	if input.MaxTokens > 0 {
+		retrievedMemories = memory.WithinTokens(retrievedMemories, input.MaxTokens, input.TruncateOldest, r.tokenizer)
+	}
 	var memories []types.Memory
 	for _, memory := range retrievedMemories {
 		memories = append(memories, types.Memory{
@@ -107,6 +149,9 @@ func (r *shortTermMemoryClient) Retrieve(ctx context.Context, input types.Retrie
 			Query:     memory.Query,
 			Response:  memory.Response,
 			CreatedAt: memory.CreatedAt,
+			Metadata:  memory.Metadata,
+			Tags:      memory.Tags,
+			Truncated: memory.Truncated,
 		})
 	}
 	return types.RetrieveShortTermMemoryOutput{
@@ -114,10 +159,52 @@ func (r *shortTermMemoryClient) Retrieve(ctx context.Context, input types.Retrie
 	}, nil
 }
 
+func (r *shortTermMemoryClient) Update(ctx context.Context, input types.UpdateShortTermMemoryInput) (types.UpdateShortTermMemoryOutput, error) {
+	if input.Query == "" || input.Response == "" {
+		log.Printf("[ERROR] Update: Query and response are required but one or both were empty (query: %q, response: %q)", input.Query, input.Response)
+		return types.UpdateShortTermMemoryOutput{}, fmt.Errorf("%w: query and response are required", ErrInvalidInput)
+	}
+	conversationID, memoryID, err := parseMemoryIDs(ctx, r.conversationService, "Update", input.ConversationID, input.MemoryID)
+	if err != nil {
+		return types.UpdateShortTermMemoryOutput{}, err
+	}
+	err = r.memoryService.Update(ctx, conversationID, memoryID, input.Query, input.Response)
+	if errors.Is(err, cache.ErrNotFound) {
+		log.Printf("[ERROR] Update: Memory does not exist (conversationID: %s, memoryID: %s)", conversationID, memoryID)
+		return types.UpdateShortTermMemoryOutput{}, ErrMemoryNotFound
+	}
+	if err != nil {
+		log.Printf("[ERROR] Update: Failed to update memory (conversationID: %s, memoryID: %s) - %v", conversationID, memoryID, err)
+		return types.UpdateShortTermMemoryOutput{}, fmt.Errorf("error updating memory")
+	}
+	return types.UpdateShortTermMemoryOutput{
+		MemoryID: memoryID.String(),
+	}, nil
+}
+
+func (r *shortTermMemoryClient) Delete(ctx context.Context, input types.DeleteShortTermMemoryInput) (types.DeleteShortTermMemoryOutput, error) {
+	conversationID, memoryID, err := parseMemoryIDs(ctx, r.conversationService, "Delete", input.ConversationID, input.MemoryID)
+	if err != nil {
+		return types.DeleteShortTermMemoryOutput{}, err
+	}
+	err = r.memoryService.Delete(ctx, conversationID, memoryID)
+	if errors.Is(err, cache.ErrNotFound) {
+		log.Printf("[ERROR] Delete: Memory does not exist (conversationID: %s, memoryID: %s)", conversationID, memoryID)
+		return types.DeleteShortTermMemoryOutput{}, ErrMemoryNotFound
+	}
+	if err != nil {
+		log.Printf("[ERROR] Delete: Failed to delete memory (conversationID: %s, memoryID: %s) - %v", conversationID, memoryID, err)
+		return types.DeleteShortTermMemoryOutput{}, fmt.Errorf("error deleting memory")
+	}
+	return types.DeleteShortTermMemoryOutput{
+		MemoryID: memoryID.String(),
+	}, nil
+}
+
 func (r *shortTermMemoryClient) RegisterConversation(ctx context.Context, input types.RegisterConversationInput) (types.RegisterConversationOutput, error) {
 	if input.Agent == "" || input.User == "" {
 		log.Printf("[ERROR] RegisterConversation: Agent and user are required but one or both were empty (agent: %q, user: %q)", input.Agent, input.User)
-		return types.RegisterConversationOutput{}, fmt.Errorf("agent and user are required")
+		return types.RegisterConversationOutput{}, fmt.Errorf("%w: agent and user are required", ErrInvalidInput)
 	}
 	id, err := r.conversationService.Create(ctx, input.Agent, input.User)
 	if err != nil {
@@ -128,3 +215,89 @@ func (r *shortTermMemoryClient) RegisterConversation(ctx context.Context, input
 		ConversationID: id.String(),
 	}, nil
 }
+
+func (r *shortTermMemoryClient) EraseUser(ctx context.Context, input types.EraseUserInput) (types.EraseUserOutput, error) {
+	conversations, err := conversationsToErase(ctx, r.conversationService, input)
+	if err != nil {
+		return types.EraseUserOutput{}, err
+	}
+	erased := make([]types.ErasedConversation, 0, len(conversations))
+	for _, conversation := range conversations {
+		cacheEntries, err := r.memoryService.Erase(ctx, conversation.ID)
+		if err != nil {
+			log.Printf("[ERROR] EraseUser: Failed to erase memories (conversationID: %s, erased: %d) - %v", conversation.ID, len(erased), err)
+			return types.EraseUserOutput{}, fmt.Errorf("error erasing memories")
+		}
+		err = r.conversationService.Delete(ctx, conversation.ID)
+		if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+			log.Printf("[ERROR] EraseUser: Failed to delete conversation (conversationID: %s, erased: %d) - %v", conversation.ID, len(erased), err)
+			return types.EraseUserOutput{}, fmt.Errorf("error deleting conversation")
+		}
+		erased = append(erased, types.ErasedConversation{
+			ConversationID: conversation.ID.String(),
+			Agent:          conversation.Agent,
+			CreatedAt:      conversation.CreatedAt,
+			CacheEntries:   cacheEntries,
+		})
+	}
+	return types.EraseUserOutput{
+		User:          input.User,
+		Conversations: erased,
+		ErasedAt:      time.Now(),
+	}, nil
+}
+
+func (r *shortTermMemoryClient) ListConversations(ctx context.Context, input types.ListConversationsInput) (types.ListConversationsOutput, error) {
+	return listConversations(ctx, r.conversationService, r.memoryService.Stats, input)
+}
+
+func (r *shortTermMemoryClient) GetConversation(ctx context.Context, input types.GetConversationInput) (types.GetConversationOutput, error) {
+	return getConversation(ctx, r.conversationService, r.memoryService.Stats, input)
+}
+
+func (r *shortTermMemoryClient) CloseConversation(ctx context.Context, input types.CloseConversationInput) (types.CloseConversationOutput, error) {
+	conversationID, err := setConversationStatus(ctx, r.conversationService, "CloseConversation", input.ConversationID, conversation.StatusClosed)
+	if err != nil {
+		return types.CloseConversationOutput{}, err
+	}
+	return types.CloseConversationOutput{
+		ConversationID: conversationID.String(),
+		Status:         conversation.StatusClosed,
+	}, nil
+}
+
+func (r *shortTermMemoryClient) ArchiveConversation(ctx context.Context, input types.ArchiveConversationInput) (types.ArchiveConversationOutput, error) {
+	conversationID, err := setConversationStatus(ctx, r.conversationService, "ArchiveConversation", input.ConversationID, conversation.StatusArchived)
+	if err != nil {
+		return types.ArchiveConversationOutput{}, err
+	}
+	return types.ArchiveConversationOutput{
+		ConversationID: conversationID.String(),
+		Status:         conversation.StatusArchived,
+	}, nil
+}
+
+func (r *shortTermMemoryClient) DeleteConversation(ctx context.Context, input types.DeleteConversationInput) (types.DeleteConversationOutput, error) {
+	conversationID, err := parseConversationID("DeleteConversation", input.ConversationID)
+	if err != nil {
+		return types.DeleteConversationOutput{}, err
+	}
+	_, err = fetchConversation(ctx, r.conversationService, "DeleteConversation", conversationID)
+	if err != nil {
+		return types.DeleteConversationOutput{}, err
+	}
+	cacheEntries, err := r.memoryService.Erase(ctx, conversationID)
+	if err != nil {
+		log.Printf("[ERROR] DeleteConversation: Failed to erase memories (conversationID: %s) - %v", conversationID, err)
+		return types.DeleteConversationOutput{}, fmt.Errorf("error erasing memories")
+	}
+	err = r.conversationService.Delete(ctx, conversationID)
+	if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+		log.Printf("[ERROR] DeleteConversation: Failed to delete conversation (conversationID: %s) - %v", conversationID, err)
+		return types.DeleteConversationOutput{}, fmt.Errorf("error deleting conversation")
+	}
+	return types.DeleteConversationOutput{
+		ConversationID: conversationID.String(),
+		CacheEntries:   cacheEntries,
+	}, nil
+}
@@ -0,0 +1,315 @@
+package clients
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+
+	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/persistence"
+	"github.com/haren7/minimal-memory/internal/persistence/rdbms"
+	"github.com/haren7/minimal-memory/types"
+)
+
+const (
+	// NamespaceHeader names the namespace of an HTTP or MCP request.
+	NamespaceHeader = "X-Namespace"
+	// NamespaceMetadataKey names the namespace of a gRPC call.
+	NamespaceMetadataKey = "x-namespace"
+	// AnyNamespace in TenantsConfig.Namespaces serves every valid namespace.
+	AnyNamespace = "*"
+)
+
+type TenantsConfig struct {
+	// DuckDBPath is the database file every namespace is kept in, defaults to memory.db.
+	DuckDBPath string
+	// DefaultNamespace serves the requests that name no namespace.
+	DefaultNamespace string
+	// Namespaces are the other namespaces requests may name, AnyNamespace
+	// allows any valid one. Their schemas are created on first use.
+	Namespaces []string
+	// Semantic and ShortTerm configure the clients of every namespace, their
+	// DuckDBPath and Namespace are not read.
+	Semantic  SemanticMemoryClientConfig
+	ShortTerm ShortTermMemoryClientConfig
+}
+
+// Tenants serves many namespaces from one process. The clients of a namespace
+// are created on first use, all of them share one DuckDB connection and one
+// embedding provider, so its retries and rate limits hold across tenants.
+// Each namespace keeps its own vector indexes and embedding cache.
+type Tenants struct {
+	mu               sync.Mutex
+	config           TenantsConfig
+	duckdbClient     *rdbms.DuckDBClient
+	embeddingService embedding.ServiceInterface
+	duckdbClients    map[persistence.Namespace]*rdbms.DuckDBClient
+	semanticClients  map[persistence.Namespace]SemanticMemoryClient
+	shortTermClients map[persistence.Namespace]ShortTermMemoryClient
+}
+
+func NewTenants(config TenantsConfig) (*Tenants, error) {
+	err := persistence.Namespace(config.DefaultNamespace).Validate()
+	if err != nil {
+		log.Printf("[ERROR] NewTenants: Invalid default namespace - %v", err)
+		return nil, fmt.Errorf("error invalid namespace")
+	}
+	for _, namespace := range config.Namespaces {
+		if namespace == AnyNamespace {
+			continue
+		}
+		err := persistence.Namespace(namespace).Validate()
+		if err != nil {
+			log.Printf("[ERROR] NewTenants: Invalid namespace - %v", err)
+			return nil, fmt.Errorf("error invalid namespace")
+		}
+	}
+	embeddingService, err := newEmbeddingService(config.Semantic)
+	if err != nil {
+		return nil, err
+	}
+	duckdbClient, err := rdbms.NewDuckDBClient(duckDBPathOrDefault(config.DuckDBPath))
+	if err != nil {
+		log.Printf("[ERROR] NewTenants: Failed to connect to DuckDB - %v", err)
+		return nil, fmt.Errorf("error connecting to duckdb")
+	}
+	tenants := &Tenants{
+		config:           config,
+		duckdbClient:     duckdbClient,
+		embeddingService: embeddingService,
+		duckdbClients:    make(map[persistence.Namespace]*rdbms.DuckDBClient),
+		semanticClients:  make(map[persistence.Namespace]SemanticMemoryClient),
+		shortTermClients: make(map[persistence.Namespace]ShortTermMemoryClient),
+	}
+	// the default namespace is created up front so a bad config fails at start
+	_, err = tenants.Semantic(config.DefaultNamespace)
+	if err != nil {
+		duckdbClient.GetDB().Close()
+		return nil, err
+	}
+	_, err = tenants.ShortTerm(config.DefaultNamespace)
+	if err != nil {
+		duckdbClient.GetDB().Close()
+		return nil, err
+	}
+	return tenants, nil
+}
+
+// Semantic returns the semantic client of namespace, the empty namespace is
+// the default one.
+func (r *Tenants) Semantic(namespace string) (SemanticMemoryClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	served, duckdbClient, err := r.namespace(namespace)
+	if err != nil {
+		return nil, err
+	}
+	client, exists := r.semanticClients[served]
+	if exists {
+		return client, nil
+	}
+	client, err = newSemanticMemoryClient(r.config.Semantic, r.embeddingService, duckdbClient)
+	if err != nil {
+		return nil, err
+	}
+	r.semanticClients[served] = client
+	return client, nil
+}
+
+// ShortTerm returns the short term client of namespace, the empty namespace is
+// the default one.
+func (r *Tenants) ShortTerm(namespace string) (ShortTermMemoryClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	served, duckdbClient, err := r.namespace(namespace)
+	if err != nil {
+		return nil, err
+	}
+	client, exists := r.shortTermClients[served]
+	if exists {
+		return client, nil
+	}
+	client, err = newShortTermMemoryClient(r.config.ShortTerm, duckdbClient)
+	if err != nil {
+		return nil, err
+	}
+	r.shortTermClients[served] = client
+	return client, nil
+}
+
+// namespace resolves the namespace a request named and opens its tables, the
+// caller holds the lock.
+func (r *Tenants) namespace(namespace string) (persistence.Namespace, *rdbms.DuckDBClient, error) {
+	served := persistence.Namespace(namespace)
+	if namespace == "" {
+		served = persistence.Namespace(r.config.DefaultNamespace)
+	}
+	err := served.Validate()
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	if string(served) != r.config.DefaultNamespace && !slices.Contains(r.config.Namespaces, AnyNamespace) && !slices.Contains(r.config.Namespaces, string(served)) {
+		log.Printf("[ERROR] Tenants: Namespace is not served (namespace: %q)", served)
+		return "", nil, ErrNamespaceNotServed
+	}
+	duckdbClient, exists := r.duckdbClients[served]
+	if exists {
+		return served, duckdbClient, nil
+	}
+	duckdbClient = r.duckdbClient
+	if served != "" {
+		duckdbClient, err = r.duckdbClient.Namespace(served)
+		if err != nil {
+			log.Printf("[ERROR] Tenants: Failed to create the tables of namespace %q - %v", served, err)
+			return "", nil, fmt.Errorf("error creating namespace")
+		}
+	}
+	r.duckdbClients[served] = duckdbClient
+	return served, duckdbClient, nil
+}
+
+type namespaceKey struct{}
+
+// WithNamespace returns a context whose calls to the clients returned by
+// SemanticByContext and ShortTermByContext are served by namespace.
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, namespace)
+}
+
+// NamespaceFromContext returns the namespace set by WithNamespace, empty for the default one.
+func NamespaceFromContext(ctx context.Context) string {
+	namespace, _ := ctx.Value(namespaceKey{}).(string)
+	return namespace
+}
+
+// SemanticByContext returns a semantic client serving each call with the
+// client of the namespace in its context.
+func (r *Tenants) SemanticByContext() SemanticMemoryClient {
+	return &tenantSemanticMemoryClient{tenants: r}
+}
+
+// ShortTermByContext returns a short term client serving each call with the
+// client of the namespace in its context.
+func (r *Tenants) ShortTermByContext() ShortTermMemoryClient {
+	return &tenantShortTermMemoryClient{tenants: r}
+}
+
+// route calls method on the client of the namespace in ctx.
+func route[C, I, O any](ctx context.Context, client func(namespace string) (C, error), method func(C, context.Context, I) (O, error), input I) (O, error) {
+	namespaced, err := client(NamespaceFromContext(ctx))
+	if err != nil {
+		var output O
+		return output, err
+	}
+	return method(namespaced, ctx, input)
+}
+
+type tenantSemanticMemoryClient struct {
+	tenants *Tenants
+}
+
+func (r *tenantSemanticMemoryClient) Store(ctx context.Context, input types.StoreSemanticMemoryInput) (types.StoreSemanticMemoryOutput, error) {
+	return route(ctx, r.tenants.Semantic, SemanticMemoryClient.Store, input)
+}
+
+func (r *tenantSemanticMemoryClient) StoreMany(ctx context.Context, input types.StoreManySemanticMemoryInput) (types.StoreManySemanticMemoryOutput, error) {
+	return route(ctx, r.tenants.Semantic, SemanticMemoryClient.StoreMany, input)
+}
+
+func (r *tenantSemanticMemoryClient) Retrieve(ctx context.Context, input types.RetrieveSemanticMemoryInput) (types.RetrieveSemanticMemoryOutput, error) {
+	return route(ctx, r.tenants.Semantic, SemanticMemoryClient.Retrieve, input)
+}
+
+func (r *tenantSemanticMemoryClient) Update(ctx context.Context, input types.UpdateSemanticMemoryInput) (types.UpdateSemanticMemoryOutput, error) {
+	return route(ctx, r.tenants.Semantic, SemanticMemoryClient.Update, input)
+}
+
+func (r *tenantSemanticMemoryClient) Delete(ctx context.Context, input types.DeleteSemanticMemoryInput) (types.DeleteSemanticMemoryOutput, error) {
+	return route(ctx, r.tenants.Semantic, SemanticMemoryClient.Delete, input)
+}
+
+func (r *tenantSemanticMemoryClient) RegisterConversation(ctx context.Context, input types.RegisterConversationInput) (types.RegisterConversationOutput, error) {
+	return route(ctx, r.tenants.Semantic, SemanticMemoryClient.RegisterConversation, input)
+}
+
+func (r *tenantSemanticMemoryClient) ListConversations(ctx context.Context, input types.ListConversationsInput) (types.ListConversationsOutput, error) {
+	return route(ctx, r.tenants.Semantic, SemanticMemoryClient.ListConversations, input)
+}
+
+func (r *tenantSemanticMemoryClient) GetConversation(ctx context.Context, input types.GetConversationInput) (types.GetConversationOutput, error) {
+	return route(ctx, r.tenants.Semantic, SemanticMemoryClient.GetConversation, input)
+}
+
+func (r *tenantSemanticMemoryClient) CloseConversation(ctx context.Context, input types.CloseConversationInput) (types.CloseConversationOutput, error) {
+	return route(ctx, r.tenants.Semantic, SemanticMemoryClient.CloseConversation, input)
+}
+
+func (r *tenantSemanticMemoryClient) ArchiveConversation(ctx context.Context, input types.ArchiveConversationInput) (types.ArchiveConversationOutput, error) {
+	return route(ctx, r.tenants.Semantic, SemanticMemoryClient.ArchiveConversation, input)
+}
+
+func (r *tenantSemanticMemoryClient) DeleteConversation(ctx context.Context, input types.DeleteConversationInput) (types.DeleteConversationOutput, error) {
+	return route(ctx, r.tenants.Semantic, SemanticMemoryClient.DeleteConversation, input)
+}
+
+func (r *tenantSemanticMemoryClient) Reindex(ctx context.Context, input types.ReindexSemanticMemoryInput) (types.ReindexSemanticMemoryOutput, error) {
+	return route(ctx, r.tenants.Semantic, SemanticMemoryClient.Reindex, input)
+}
+
+func (r *tenantSemanticMemoryClient) EmbeddingCacheStats(ctx context.Context, input types.EmbeddingCacheStatsInput) (types.EmbeddingCacheStatsOutput, error) {
+	return route(ctx, r.tenants.Semantic, SemanticMemoryClient.EmbeddingCacheStats, input)
+}
+
+func (r *tenantSemanticMemoryClient) EraseUser(ctx context.Context, input types.EraseUserInput) (types.EraseUserOutput, error) {
+	return route(ctx, r.tenants.Semantic, SemanticMemoryClient.EraseUser, input)
+}
+
+type tenantShortTermMemoryClient struct {
+	tenants *Tenants
+}
+
+func (r *tenantShortTermMemoryClient) Store(ctx context.Context, input types.StoreShortTermMemoryInput) (types.StoreShortTermMemoryOutput, error) {
+	return route(ctx, r.tenants.ShortTerm, ShortTermMemoryClient.Store, input)
+}
+
+func (r *tenantShortTermMemoryClient) Retrieve(ctx context.Context, input types.RetrieveShortTermMemoryInput) (types.RetrieveShortTermMemoryOutput, error) {
+	return route(ctx, r.tenants.ShortTerm, ShortTermMemoryClient.Retrieve, input)
+}
+
+func (r *tenantShortTermMemoryClient) Update(ctx context.Context, input types.UpdateShortTermMemoryInput) (types.UpdateShortTermMemoryOutput, error) {
+	return route(ctx, r.tenants.ShortTerm, ShortTermMemoryClient.Update, input)
+}
+
+func (r *tenantShortTermMemoryClient) Delete(ctx context.Context, input types.DeleteShortTermMemoryInput) (types.DeleteShortTermMemoryOutput, error) {
+	return route(ctx, r.tenants.ShortTerm, ShortTermMemoryClient.Delete, input)
+}
+
+func (r *tenantShortTermMemoryClient) RegisterConversation(ctx context.Context, input types.RegisterConversationInput) (types.RegisterConversationOutput, error) {
+	return route(ctx, r.tenants.ShortTerm, ShortTermMemoryClient.RegisterConversation, input)
+}
+
+func (r *tenantShortTermMemoryClient) ListConversations(ctx context.Context, input types.ListConversationsInput) (types.ListConversationsOutput, error) {
+	return route(ctx, r.tenants.ShortTerm, ShortTermMemoryClient.ListConversations, input)
+}
+
+func (r *tenantShortTermMemoryClient) GetConversation(ctx context.Context, input types.GetConversationInput) (types.GetConversationOutput, error) {
+	return route(ctx, r.tenants.ShortTerm, ShortTermMemoryClient.GetConversation, input)
+}
+
+func (r *tenantShortTermMemoryClient) CloseConversation(ctx context.Context, input types.CloseConversationInput) (types.CloseConversationOutput, error) {
+	return route(ctx, r.tenants.ShortTerm, ShortTermMemoryClient.CloseConversation, input)
+}
+
+func (r *tenantShortTermMemoryClient) ArchiveConversation(ctx context.Context, input types.ArchiveConversationInput) (types.ArchiveConversationOutput, error) {
+	return route(ctx, r.tenants.ShortTerm, ShortTermMemoryClient.ArchiveConversation, input)
+}
+
+func (r *tenantShortTermMemoryClient) DeleteConversation(ctx context.Context, input types.DeleteConversationInput) (types.DeleteConversationOutput, error) {
+	return route(ctx, r.tenants.ShortTerm, ShortTermMemoryClient.DeleteConversation, input)
+}
+
+func (r *tenantShortTermMemoryClient) EraseUser(ctx context.Context, input types.EraseUserInput) (types.EraseUserOutput, error) {
+	return route(ctx, r.tenants.ShortTerm, ShortTermMemoryClient.EraseUser, input)
+}
@@ -0,0 +1,99 @@
+package clients
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/haren7/minimal-memory/types"
+)
+
+func newTestTenants(t *testing.T, namespaces ...string) *Tenants {
+	t.Helper()
+	tenants, err := NewTenants(TenantsConfig{
+		DuckDBPath: filepath.Join(t.TempDir(), "memory.db"),
+		Namespaces: namespaces,
+		Semantic: SemanticMemoryClientConfig{
+			EmbeddingProvider: EmbeddingProviderLocal,
+			LocalEmbeddingDim: 8,
+			VectorBackend:     VectorBackendBruteForce,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewTenants: %v", err)
+	}
+	t.Cleanup(func() { tenants.duckdbClient.GetDB().Close() })
+	return tenants
+}
+
+func TestTenantsKeepNamespacesApart(t *testing.T) {
+	tenants := newTestTenants(t, "acme", "globex")
+	semantic := tenants.SemanticByContext()
+	shortTerm := tenants.ShortTermByContext()
+	acme := WithNamespace(context.Background(), "acme")
+	registered, err := semantic.RegisterConversation(acme, types.RegisterConversationInput{Agent: "agent", User: "user"})
+	if err != nil {
+		t.Fatalf("RegisterConversation: %v", err)
+	}
+	_, err = semantic.Store(acme, types.StoreSemanticMemoryInput{ConversationID: registered.ConversationID, Query: "my dog is rex", Response: "noted"})
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		count int
+	}{
+		{name: "same namespace", ctx: acme, count: 1},
+		{name: "other namespace", ctx: WithNamespace(context.Background(), "globex"), count: 0},
+		{name: "default namespace", ctx: context.Background(), count: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			listed, err := semantic.ListConversations(test.ctx, types.ListConversationsInput{})
+			if err != nil {
+				t.Fatalf("ListConversations: %v", err)
+			}
+			if len(listed.Conversations) != test.count {
+				t.Fatalf("semantic client listed %d conversations, want %d", len(listed.Conversations), test.count)
+			}
+			// both clients of a namespace share its conversations table
+			listed, err = shortTerm.ListConversations(test.ctx, types.ListConversationsInput{})
+			if err != nil {
+				t.Fatalf("ListConversations: %v", err)
+			}
+			if len(listed.Conversations) != test.count {
+				t.Fatalf("short term client listed %d conversations, want %d", len(listed.Conversations), test.count)
+			}
+		})
+	}
+	_, err = semantic.Retrieve(context.Background(), types.RetrieveSemanticMemoryInput{ConversationID: registered.ConversationID, Query: "dog"})
+	if !errors.Is(err, ErrConversationNotFound) {
+		t.Fatalf("Retrieve from the default namespace returned %v, want %v", err, ErrConversationNotFound)
+	}
+}
+
+func TestTenantsRejectNamespaces(t *testing.T) {
+	tests := []struct {
+		name       string
+		namespaces []string
+		namespace  string
+		wantErr    error
+	}{
+		{name: "not served", namespaces: []string{"acme"}, namespace: "globex", wantErr: ErrNamespaceNotServed},
+		{name: "invalid", namespaces: []string{AnyNamespace}, namespace: "../acme", wantErr: ErrInvalidInput},
+		{name: "any namespace", namespaces: []string{AnyNamespace}, namespace: "globex"},
+		{name: "default namespace", namespace: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tenants := newTestTenants(t, test.namespaces...)
+			_, err := tenants.SemanticByContext().ListConversations(WithNamespace(context.Background(), test.namespace), types.ListConversationsInput{})
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("ListConversations returned %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
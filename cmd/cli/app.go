@@ -0,0 +1,282 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/haren7/minimal-memory/internal/blobstore"
+	"github.com/haren7/minimal-memory/internal/conversation"
+	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/memory"
+	"github.com/haren7/minimal-memory/internal/persistence"
+	"github.com/haren7/minimal-memory/internal/persistence/rdbms"
+	"github.com/haren7/minimal-memory/internal/persistence/vector"
+	"github.com/haren7/minimal-memory/internal/snapshot"
+	"github.com/haren7/minimal-memory/internal/summarizer"
+
+	"github.com/google/uuid"
+)
+
+type config struct {
+	duckdbPath        string
+	namespace         persistence.Namespace
+	indexDir          string
+	embeddingProvider string
+	localEmbeddingDim int
+	openAI            embedding.OpenAIConfig
+	vectorBackend     string
+	vectorMetric      string
+	faiss             vector.FaissConfig
+	embeddingCache    bool
+	bucket            string
+	output            string
+	// skipIndexLoad leaves the saved indexes to the command, reindex must run
+	// even when one of them is corrupt.
+	skipIndexLoad bool
+}
+
+const (
+	vectorBackendFaiss      = "faiss"
+	vectorBackendBruteForce = "bruteforce"
+	vectorBackendChromem    = "chromem"
+)
+
+// vectorStore is what the admin commands need from a vector backend.
+type vectorStore interface {
+	Mount(dir string, files map[string]io.Reader) error
+	Export(dir string) ([]os.File, error)
+	IndexSizes() map[string]int64
+	// FileNames are the files Export writes for a conversation.
+	FileNames(conversationID string) []string
+}
+
+// app wires the internal services directly, the admin commands need access to
+// the database and vector indexes that the public clients keep to themselves.
+type app struct {
+	config             config
+	printer            printer
+	duckdbClient       *rdbms.DuckDBClient
+	vectorStore        vectorStore
+	conversationRepo   persistence.ConversationRepoInterface
+	memoryRepo         persistence.MemoryRepoInterface
+	faissMemoryRepo    persistence.MemoryRepoInterface
+	embeddingCacheRepo persistence.EmbeddingCacheRepoInterface
+	// embeddingCache is nil without -embedding-cache
+	embeddingCache      embedding.CachedServiceInterface
+	conversationService conversation.ConversationServiceInterface
+	memoryService       memory.SemanticServiceInterface
+}
+
+func newApp(config config, stdout io.Writer) (*app, error) {
+	duckdbClient, err := rdbms.NewDuckDBClient(config.duckdbPath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening duckdb %s: %w", config.duckdbPath, err)
+	}
+	if config.namespace != "" {
+		namespaced, err := duckdbClient.Namespace(config.namespace)
+		if err != nil {
+			duckdbClient.GetDB().Close()
+			return nil, fmt.Errorf("error opening namespace %s: %w", config.namespace, err)
+		}
+		duckdbClient = namespaced
+	}
+	embeddingService, err := newEmbeddingService(config)
+	if err != nil {
+		return nil, err
+	}
+	conversationRepo := rdbms.NewConversationRepo(duckdbClient)
+	memoryRepo := rdbms.NewMemoryRepo(duckdbClient)
+	faissMemoryRepo := rdbms.NewFaissMemoryRepo(duckdbClient)
+	embeddingCacheRepo := rdbms.NewEmbeddingCacheRepo(duckdbClient)
+	embeddingService = embedding.NewResilientService(embeddingService, embedding.ResilienceConfig{})
+	var embeddingCache embedding.CachedServiceInterface
+	if config.embeddingCache {
+		// every invocation is a new process, only the persistent tier pays off
+		embeddingCache = embedding.NewCachedService(embeddingService, 0, embeddingCacheRepo)
+		embeddingService = embeddingCache
+	}
+	vectorStore, vectorMemoryRepo, err := newVectorBackend(config, embeddingService, faissMemoryRepo)
+	if err != nil {
+		return nil, err
+	}
+	if !config.skipIndexLoad {
+		err = loadIndexes(vectorStore, config.indexDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &app{
+		config:              config,
+		printer:             printer{format: config.output, w: stdout},
+		duckdbClient:        duckdbClient,
+		vectorStore:         vectorStore,
+		conversationRepo:    conversationRepo,
+		memoryRepo:          memoryRepo,
+		faissMemoryRepo:     faissMemoryRepo,
+		embeddingCacheRepo:  embeddingCacheRepo,
+		embeddingCache:      embeddingCache,
+		conversationService: conversation.NewConversationService(conversationRepo),
+		memoryService:       memory.NewSemanticService(vectorMemoryRepo, memoryRepo, conversationRepo, summarizer.NewNoOpService()),
+	}, nil
+}
+
+// reportEmbeddingCache writes how the embeddings of the command were served to
+// w, it stays quiet when the cache is off or nothing was embedded.
+func (r *app) reportEmbeddingCache(w io.Writer) {
+	if r.embeddingCache == nil {
+		return
+	}
+	stats := r.embeddingCache.Stats()
+	if stats.MemoryHits+stats.StoreHits+stats.Misses == 0 {
+		return
+	}
+	fmt.Fprintf(w, "embedding cache: %d hits, %d misses, %d store errors\n", stats.MemoryHits+stats.StoreHits, stats.Misses, stats.StoreErrors)
+}
+
+func (r *app) close() {
+	r.duckdbClient.GetDB().Close()
+}
+
+// requireOpenAIApiKey fails early for commands that embed text, the local
+// provider runs offline and needs no key.
+func (r *app) requireOpenAIApiKey() error {
+	if embedding.Provider(r.config.embeddingProvider) == embedding.ProviderOpenAI && r.config.openAI.ApiKey == "" && r.config.openAI.BaseURL == "" {
+		return fmt.Errorf("an openai api key is required, set -openai-api-key or OPENAI_API_KEY")
+	}
+	return nil
+}
+
+func (r *app) snapshotManagers() ([]snapshot.Manager, error) {
+	s3, err := r.snapshotStore()
+	if err != nil {
+		return nil, err
+	}
+	managers := []snapshot.Manager{snapshot.NewDuckdbManager(r.config.bucket, s3, r.duckdbClient)}
+	if manager, ok := newFaissManager(r.config.bucket, s3, r.vectorStore); ok {
+		managers = append(managers, manager)
+	}
+	switch store := r.vectorStore.(type) {
+	case *vector.BruteForceClient:
+		managers = append(managers, snapshot.NewBruteForceManager(r.config.bucket, s3, store))
+	case *vector.ChromemClient:
+		managers = append(managers, snapshot.NewChromemManager(r.config.bucket, s3, store))
+	}
+	return managers, nil
+}
+
+func (r *app) snapshotStore() (blobstore.BlobStoreInterface, error) {
+	if r.config.bucket == "" {
+		return nil, fmt.Errorf("a bucket is required, set -bucket or MINIMAL_MEMORY_BUCKET")
+	}
+	s3Client := blobstore.NewS3Client()
+	if s3Client == nil {
+		return nil, fmt.Errorf("error creating s3 client")
+	}
+	return blobstore.NewS3Store(s3Client), nil
+}
+
+// saveIndexes persists the in-memory vector indexes so the next invocation sees them.
+func (r *app) saveIndexes() error {
+	err := os.MkdirAll(r.config.indexDir, 0755)
+	if err != nil {
+		return fmt.Errorf("error creating index dir %s: %w", r.config.indexDir, err)
+	}
+	files, err := r.vectorStore.Export(r.config.indexDir)
+	if err != nil {
+		return fmt.Errorf("error saving indexes to %s: %w", r.config.indexDir, err)
+	}
+	for i := range files {
+		files[i].Close()
+	}
+	return nil
+}
+
+// removeIndexFiles deletes the saved indexes of conversations whose index was
+// dropped, Export only writes the indexes that still exist.
+func (r *app) removeIndexFiles(conversationIDs []uuid.UUID) error {
+	for _, conversationID := range conversationIDs {
+		for _, name := range r.vectorStore.FileNames(conversationID.String()) {
+			err := os.Remove(filepath.Join(r.config.indexDir, name))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("error removing index file %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// newEmbeddingService defers the openai key check to requireOpenAIApiKey so that
+// commands which never embed text keep working without a key.
+func newEmbeddingService(config config) (embedding.ServiceInterface, error) {
+	if embedding.Provider(config.embeddingProvider) == embedding.ProviderOpenAI {
+		return embedding.NewOpenAIService(config.openAI), nil
+	}
+	embeddingService, err := embedding.NewService(embedding.Config{
+		Provider: embedding.Provider(config.embeddingProvider),
+		LocalDim: config.localEmbeddingDim,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating embedding service: %w", err)
+	}
+	return embeddingService, nil
+}
+
+func newVectorBackend(config config, embeddingService embedding.ServiceInterface, memoryRepo persistence.MemoryRepoInterface) (vectorStore, persistence.VectorMemoryRepoInterface, error) {
+	metric := vector.Metric(config.vectorMetric)
+	switch config.vectorBackend {
+	case vectorBackendFaiss:
+		return newFaissBackend(config, embeddingService, memoryRepo)
+	case vectorBackendBruteForce:
+		bruteForceClient, err := vector.NewBruteForceClient(metric, config.namespace)
+		if err != nil {
+			return nil, nil, err
+		}
+		return bruteForceClient, vector.NewBruteForceMemoryRepo(bruteForceClient, embeddingService, memoryRepo), nil
+	case vectorBackendChromem:
+		chromemClient, err := vector.NewChromem(config.namespace)
+		if err != nil {
+			return nil, nil, err
+		}
+		chromemMemoryRepo, err := vector.NewChromemMemoryRepo(chromemClient, embeddingService, memoryRepo, metric)
+		if err != nil {
+			return nil, nil, err
+		}
+		return chromemClient, chromemMemoryRepo, nil
+	}
+	return nil, nil, fmt.Errorf("%w: unknown vector backend %q", errUsage, config.vectorBackend)
+}
+
+// indexSuffixes are the files the vector backends export.
+var indexSuffixes = []string{".index", ".meta.json", ".vec", ".gob.gz"}
+
+func loadIndexes(store vectorStore, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error reading index dir %s: %w", dir, err)
+	}
+	files := make(map[string]io.Reader)
+	for _, entry := range entries {
+		if entry.IsDir() || !slices.ContainsFunc(indexSuffixes, func(suffix string) bool { return strings.HasSuffix(entry.Name(), suffix) }) {
+			continue
+		}
+		file, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("error opening index %s: %w", entry.Name(), err)
+		}
+		defer file.Close()
+		files[entry.Name()] = file
+	}
+	err = store.Mount(dir, files)
+	if err != nil {
+		return fmt.Errorf("error loading indexes from %s: %w", dir, err)
+	}
+	return nil
+}
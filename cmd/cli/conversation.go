@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/haren7/minimal-memory/internal/conversation"
+
+	"github.com/google/uuid"
+)
+
+type conversationView struct {
+	ID          string    `json:"id"`
+	Agent       string    `json:"agent"`
+	User        string    `json:"user"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	MemoryCount *int      `json:"memory_count,omitempty"`
+}
+
+func (r *app) runConversation(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: conversation requires a subcommand", errUsage)
+	}
+	switch args[0] {
+	case "create":
+		return r.createConversation(ctx, args[1:])
+	case "list":
+		return r.listConversations(ctx, args[1:])
+	case "show":
+		return r.showConversation(ctx, args[1:])
+	case "close":
+		return r.setConversationStatus(ctx, args[1:], conversation.StatusClosed)
+	case "archive":
+		return r.setConversationStatus(ctx, args[1:], conversation.StatusArchived)
+	case "delete":
+		return r.deleteConversation(ctx, args[1:])
+	default:
+		return fmt.Errorf("%w: unknown conversation subcommand %q", errUsage, args[0])
+	}
+}
+
+func (r *app) createConversation(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("conversation create", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	agent := flags.String("agent", "", "agent taking part in the conversation")
+	user := flags.String("user", "", "user taking part in the conversation")
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if *agent == "" || *user == "" {
+		return fmt.Errorf("%w: -agent and -user are required", errUsage)
+	}
+	conversationID, err := r.conversationService.Create(ctx, *agent, *user)
+	if err != nil {
+		return err
+	}
+	return r.printer.print(map[string]string{"conversation_id": conversationID.String()}, []string{"CONVERSATION ID"}, [][]string{{conversationID.String()}})
+}
+
+func (r *app) listConversations(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("conversation list", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	limit := flags.Int("limit", 50, "maximum number of conversations to list, newest first")
+	agent := flags.String("agent", "", "only list conversations with this agent")
+	user := flags.String("user", "", "only list conversations with this user")
+	status := flags.String("status", "", "only list conversations with this status, active, closed or archived, all when empty")
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if *limit <= 0 {
+		return fmt.Errorf("%w: -limit must be positive", errUsage)
+	}
+	filter := conversation.Filter{Agent: *agent, User: *user}
+	switch *status {
+	case "":
+	case conversation.StatusActive, conversation.StatusClosed, conversation.StatusArchived:
+		filter.Statuses = []string{*status}
+	default:
+		return fmt.Errorf("%w: unknown status %q", errUsage, *status)
+	}
+	conversations, _, err := r.conversationService.List(ctx, filter, "", *limit)
+	if err != nil {
+		return err
+	}
+	views := []conversationView{}
+	var rows [][]string
+	for _, conversation := range conversations {
+		views = append(views, conversationView{
+			ID:        conversation.ID.String(),
+			Agent:     conversation.Agent,
+			User:      conversation.User,
+			Status:    conversation.Status,
+			CreatedAt: conversation.CreatedAt,
+		})
+		rows = append(rows, []string{conversation.ID.String(), conversation.Agent, conversation.User, conversation.Status, formatTime(conversation.CreatedAt)})
+	}
+	return r.printer.print(views, []string{"ID", "AGENT", "USER", "STATUS", "CREATED AT"}, rows)
+}
+
+func (r *app) showConversation(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: conversation show requires a conversation id", errUsage)
+	}
+	conversationID, err := uuid.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid conversation id %q: %w", args[0], err)
+	}
+	conversation, err := r.conversationRepo.FetchOne(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+	stats, err := r.memoryRepo.FetchStats(ctx, []uuid.UUID{conversationID})
+	if err != nil {
+		return err
+	}
+	memoryCount := stats[conversationID].Count
+	view := conversationView{
+		ID:          conversation.UUID.String(),
+		Agent:       conversation.Agent,
+		User:        conversation.User,
+		Status:      conversation.Status,
+		CreatedAt:   conversation.CreatedAt,
+		MemoryCount: &memoryCount,
+	}
+	return r.printer.print(view, []string{"ID", "AGENT", "USER", "STATUS", "CREATED AT", "MEMORIES"}, [][]string{
+		{view.ID, view.Agent, view.User, view.Status, formatTime(view.CreatedAt), strconv.Itoa(memoryCount)},
+	})
+}
+
+func (r *app) setConversationStatus(ctx context.Context, args []string, status string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: conversation %s requires a conversation id", errUsage, status)
+	}
+	conversationID, err := uuid.Parse(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid conversation id %q: %w", args[0], err)
+	}
+	err = r.conversationService.SetStatus(ctx, conversationID, status)
+	if err != nil {
+		return err
+	}
+	return r.printer.print(map[string]string{"conversation_id": conversationID.String(), "status": status}, []string{"CONVERSATION ID", "STATUS"}, [][]string{{conversationID.String(), status}})
+}
+
+// deleteConversation erases the memories and index of a conversation before
+// deleting it, so a failed delete can be run again.
+func (r *app) deleteConversation(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: conversation delete requires a conversation id", errUsage)
+	}
+	conversationID, err := r.existingConversation(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	result, err := r.memoryService.Erase(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+	err = r.conversationService.Delete(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+	err = r.removeIndexFiles([]uuid.UUID{conversationID})
+	if err != nil {
+		return err
+	}
+	err = r.saveIndexes()
+	if err != nil {
+		return err
+	}
+	view := erasedConversationView{ConversationID: conversationID.String(), Memories: result.Memories, VectorMemories: result.VectorMemories}
+	return r.printer.print(view, []string{"CONVERSATION ID", "MEMORIES", "VECTOR MEMORIES"}, [][]string{
+		{view.ConversationID, strconv.Itoa(view.Memories), strconv.Itoa(view.VectorMemories)},
+	})
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/haren7/minimal-memory/internal/memory"
+	"github.com/haren7/minimal-memory/internal/snapshot"
+
+	"github.com/google/uuid"
+)
+
+type erasedConversationView struct {
+	ConversationID string    `json:"conversation_id"`
+	Agent          string    `json:"agent"`
+	CreatedAt      time.Time `json:"created_at"`
+	Memories       int       `json:"memories"`
+	VectorMemories int       `json:"vector_memories"`
+}
+
+type eraseUserView struct {
+	User               string                   `json:"user"`
+	Conversations      []erasedConversationView `json:"conversations"`
+	SnapshotsRewritten bool                     `json:"snapshots_rewritten"`
+	ErasedAt           time.Time                `json:"erased_at"`
+}
+
+// runEraseUser deletes every conversation of a user with its memories,
+// vector indexes and user index, empties the embedding cache, then rewrites the
+// snapshots in the bucket when one is set. With a bucket it refuses to run
+// until the local database holds every stored conversation, the vector
+// snapshots are uploaded from the local indexes. Conversations are deleted
+// after their memories, so a failed erase is retried by running it again.
+func (r *app) runEraseUser(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("erase-user", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	user := flags.String("user", "", "user whose conversations and memories are erased")
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if *user == "" {
+		return fmt.Errorf("%w: -user is required", errUsage)
+	}
+	var managers []snapshot.Manager
+	if r.config.bucket != "" {
+		s3, err := r.snapshotStore()
+		if err != nil {
+			return err
+		}
+		unpulled, err := snapshot.UnpulledConversations(ctx, r.config.bucket, s3, r.duckdbClient)
+		if err != nil {
+			return err
+		}
+		if len(unpulled) > 0 {
+			return fmt.Errorf("the bucket holds %d conversations missing locally, run snapshot pull first", len(unpulled))
+		}
+		managers, err = r.snapshotManagers()
+		if err != nil {
+			return err
+		}
+	}
+	conversations, err := r.conversationService.FetchByUser(ctx, *user)
+	if err != nil {
+		return err
+	}
+	view := eraseUserView{User: *user, Conversations: []erasedConversationView{}}
+	var conversationIDs []uuid.UUID
+	var eraseErr error
+	for _, conversation := range conversations {
+		result, err := r.memoryService.Erase(ctx, conversation.ID)
+		if err == nil {
+			err = r.conversationService.Delete(ctx, conversation.ID)
+		}
+		if err != nil {
+			eraseErr = fmt.Errorf("error erasing conversation %s: %w", conversation.ID, err)
+			break
+		}
+		conversationIDs = append(conversationIDs, conversation.ID)
+		view.Conversations = append(view.Conversations, erasedConversationView{
+			ConversationID: conversation.ID.String(),
+			Agent:          conversation.Agent,
+			CreatedAt:      conversation.CreatedAt,
+			Memories:       result.Memories,
+			VectorMemories: result.VectorMemories,
+		})
+	}
+	if eraseErr == nil {
+		_, eraseErr = r.memoryService.EraseUser(ctx, *user)
+		if eraseErr == nil {
+			conversationIDs = append(conversationIDs, memory.UserIndexID(*user))
+		}
+	}
+	if eraseErr == nil {
+		// keys are hashes of the texts, the user's entries cannot be told apart
+		_, eraseErr = r.embeddingCacheRepo.DeleteAll(ctx)
+	}
+	err = r.removeIndexFiles(conversationIDs)
+	if err != nil {
+		return errors.Join(eraseErr, err)
+	}
+	err = r.saveIndexes()
+	if eraseErr != nil || err != nil {
+		return errors.Join(eraseErr, err)
+	}
+	if r.config.bucket != "" {
+		for _, manager := range managers {
+			err := manager.Erase(ctx, conversationIDs)
+			if err != nil {
+				return fmt.Errorf("user erased locally but the bucket may still hold conversations %v: %w", conversationIDs, err)
+			}
+		}
+		view.SnapshotsRewritten = true
+	}
+	view.ErasedAt = time.Now()
+	var rows [][]string
+	for _, conversation := range view.Conversations {
+		rows = append(rows, []string{conversation.ConversationID, conversation.Agent, formatTime(conversation.CreatedAt), strconv.Itoa(conversation.Memories), strconv.Itoa(conversation.VectorMemories)})
+	}
+	return r.printer.print(view, []string{"CONVERSATION ID", "AGENT", "CREATED AT", "MEMORIES", "VECTOR MEMORIES"}, rows)
+}
@@ -0,0 +1,31 @@
+//go:build cgo && !nofaiss
+
+package main
+
+import (
+	"github.com/haren7/minimal-memory/internal/blobstore"
+	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/persistence"
+	"github.com/haren7/minimal-memory/internal/persistence/vector"
+	"github.com/haren7/minimal-memory/internal/snapshot"
+)
+
+func newFaissBackend(config config, embeddingService embedding.ServiceInterface, memoryRepo persistence.MemoryRepoInterface) (vectorStore, persistence.VectorMemoryRepoInterface, error) {
+	faissConfig := config.faiss
+	faissConfig.Metric = vector.Metric(config.vectorMetric)
+	faissConfig.Namespace = config.namespace
+	faissClient, err := vector.NewFaissClient(faissConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	return faissClient, vector.NewFaissMemoryRepo(faissClient, embeddingService, memoryRepo), nil
+}
+
+// newFaissManager returns false for stores that are not FAISS.
+func newFaissManager(bucket string, s3 blobstore.BlobStoreInterface, store vectorStore) (snapshot.Manager, bool) {
+	faissClient, ok := store.(*vector.FaissClient)
+	if !ok {
+		return nil, false
+	}
+	return snapshot.NewFaissManager(bucket, s3, faissClient), true
+}
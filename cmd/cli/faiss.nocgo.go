@@ -0,0 +1,20 @@
+//go:build !cgo || nofaiss
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/haren7/minimal-memory/internal/blobstore"
+	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/persistence"
+	"github.com/haren7/minimal-memory/internal/snapshot"
+)
+
+func newFaissBackend(config config, embeddingService embedding.ServiceInterface, memoryRepo persistence.MemoryRepoInterface) (vectorStore, persistence.VectorMemoryRepoInterface, error) {
+	return nil, nil, fmt.Errorf("%w: this build has no faiss, use -vector-backend bruteforce or chromem", errUsage)
+}
+
+func newFaissManager(bucket string, s3 blobstore.BlobStoreInterface, store vectorStore) (snapshot.Manager, bool) {
+	return nil, false
+}
@@ -2,66 +2,155 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
 
-	"github.com/haren7/minimal-memory/clients"
-	"github.com/haren7/minimal-memory/types"
+	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/persistence/vector"
 )
 
+const usage = `usage: cli [global flags] <command> [flags]
+
+commands:
+  conversation create -agent AGENT -user USER
+  conversation list [-limit N] [-agent AGENT] [-user USER] [-status STATUS]
+  conversation show CONVERSATION_ID
+  conversation close CONVERSATION_ID
+  conversation archive CONVERSATION_ID
+  conversation delete CONVERSATION_ID
+  memory store -conversation CONVERSATION_ID -query QUERY -response RESPONSE [-metadata JSON] [-tags A,B] [-scopes user,agent]
+  memory import -conversation CONVERSATION_ID [-file FILE] [-scopes user,agent]
+  memory recent -conversation CONVERSATION_ID [-limit N] [-metadata JSON] [-tags A,B]
+  memory search -conversation CONVERSATION_ID -query QUERY [-top-k N] [-min-score S] [-metadata JSON] [-tags A,B] [-scope conversation|user|agent]
+  memory update -conversation CONVERSATION_ID -memory MEMORY_ID -query QUERY -response RESPONSE
+  memory delete -conversation CONVERSATION_ID -memory MEMORY_ID
+  reindex [-conversation CONVERSATION_ID]
+  erase-user -user USER
+  snapshot push
+  snapshot pull
+  stats
+
+global flags:
+`
+
+var errUsage = errors.New("invalid usage")
+
 func main() {
-	ctx := context.Background()
-	semanticMemoryClient, err := clients.NewSemanticMemoryClient(clients.SemanticMemoryClientConfig{
-		ContextWindowSize: 10,
-		OpenAIApiKey:      "sk-proj-",
-	})
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
 	if err != nil {
-		fmt.Println("error creating semantic memory client: ", err)
-		return
+		fmt.Fprintln(os.Stderr, "error:", err)
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
+		}
+		os.Exit(1)
 	}
-	registerConversationOutput, err := semanticMemoryClient.RegisterConversation(ctx, types.RegisterConversationInput{
-		Agent: "agent",
-		User:  "user",
-	})
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := flag.NewFlagSet("cli", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+	var config config
+	flags.StringVar(&config.duckdbPath, "db", envOr("MINIMAL_MEMORY_DB", "memory.db"), "path of the duckdb database file")
+	flags.StringVar((*string)(&config.namespace), "namespace", os.Getenv("MINIMAL_MEMORY_NAMESPACE"), "tenant namespace, each has its own duckdb schema, index dir and snapshot prefix")
+	flags.StringVar(&config.indexDir, "index-dir", envOr("MINIMAL_MEMORY_INDEX_DIR", "faiss"), "directory the vector indexes are persisted to between runs")
+	flags.StringVar(&config.openAI.ApiKey, "openai-api-key", os.Getenv("OPENAI_API_KEY"), "openai api key used for embeddings")
+	flags.StringVar(&config.openAI.BaseURL, "openai-base-url", os.Getenv("OPENAI_BASE_URL"), "base url of an openai compatible embeddings endpoint")
+	flags.StringVar(&config.openAI.Model, "embedding-model", envOr("MINIMAL_MEMORY_EMBEDDING_MODEL", embedding.DefaultOpenAIModel), "embedding model requested from the openai compatible endpoint")
+	flags.IntVar(&config.openAI.Dimensions, "embedding-dimensions", envIntOr("MINIMAL_MEMORY_EMBEDDING_DIMENSIONS", 0), "requested embedding dimensions, 0 keeps the model default")
+	flags.StringVar(&config.openAI.Organization, "openai-organization", os.Getenv("OPENAI_ORGANIZATION"), "openai organization id")
+	flags.BoolVar(&config.openAI.Azure, "openai-azure", os.Getenv("MINIMAL_MEMORY_OPENAI_AZURE") == "true", "use azure openai authentication, -openai-base-url is the resource endpoint")
+	flags.StringVar(&config.embeddingProvider, "embedding-provider", envOr("MINIMAL_MEMORY_EMBEDDING_PROVIDER", "openai"), "embedding provider, openai or local")
+	flags.IntVar(&config.localEmbeddingDim, "local-embedding-dim", envIntOr("MINIMAL_MEMORY_LOCAL_EMBEDDING_DIM", 512), "vector size of the local embedding provider")
+	flags.StringVar(&config.faiss.Factory, "faiss-factory", envOr("MINIMAL_MEMORY_FAISS_FACTORY", vector.DefaultFactory), "faiss index factory string, e.g. HNSW32, IVF256,Flat or IVF256,PQ16")
+	flags.StringVar(&config.vectorBackend, "vector-backend", envOr("MINIMAL_MEMORY_VECTOR_BACKEND", vectorBackendFaiss), "vector backend, faiss, bruteforce or chromem")
+	flags.StringVar(&config.vectorMetric, "vector-metric", os.Getenv("MINIMAL_MEMORY_VECTOR_METRIC"), "how memories are compared, l2, ip or cosine, defaults to l2")
+	flags.IntVar(&config.faiss.MigrateAt, "faiss-migrate-at", envIntOr("MINIMAL_MEMORY_FAISS_MIGRATE_AT", 0), "memories after which a conversation moves from flat to -faiss-factory, 0 migrates only factories that need training")
+	flags.IntVar(&config.faiss.NProbe, "faiss-nprobe", envIntOr("MINIMAL_MEMORY_FAISS_NPROBE", 0), "ivf lists searched per query, 0 keeps the faiss default")
+	flags.IntVar(&config.faiss.EfSearch, "faiss-ef-search", envIntOr("MINIMAL_MEMORY_FAISS_EF_SEARCH", 0), "hnsw search depth, 0 keeps the faiss default")
+	flags.BoolVar(&config.embeddingCache, "embedding-cache", os.Getenv("MINIMAL_MEMORY_EMBEDDING_CACHE") == "true", "cache embeddings in the duckdb database across invocations")
+	flags.StringVar(&config.bucket, "bucket", os.Getenv("MINIMAL_MEMORY_BUCKET"), "s3 bucket used by the snapshot commands, erase-user also rewrites its snapshots")
+	flags.StringVar(&config.output, "output", envOr("MINIMAL_MEMORY_OUTPUT", outputTable), "output format, table or json")
+	err := flags.Parse(args)
 	if err != nil {
-		fmt.Println("error registering conversation: ", err)
-		return
+		return errUsage
 	}
-	conversationID := registerConversationOutput.ConversationID
-	storeSemanticMemoryOutput, err := semanticMemoryClient.Store(ctx, types.StoreSemanticMemoryInput{
-		ConversationID: conversationID,
-		Query:          "how can i use the semantic memory client?",
-		Response:       "this is how you can use the semantic memory client",
-	})
+	if config.output != outputTable && config.output != outputJSON {
+		return fmt.Errorf("%w: unknown output format %q", errUsage, config.output)
+	}
+	err = config.namespace.Validate()
 	if err != nil {
-		fmt.Println("error storing semantic memory: ", err)
-		return
+		return fmt.Errorf("%w: %w", errUsage, err)
 	}
-	memoryID := storeSemanticMemoryOutput.MemoryID
-	fmt.Println("memory id: ", memoryID)
-	retrieveSemanticMemoryOutput, err := semanticMemoryClient.Retrieve(ctx, types.RetrieveSemanticMemoryInput{
-		ConversationID: conversationID,
-		Query:          "help me use the semantic memory client",
-	})
+	if config.namespace != "" {
+		// the default namespace keeps its indexes at the top of the dir
+		config.indexDir = filepath.Join(config.indexDir, "tenants", string(config.namespace))
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errUsage
+	}
+
+	commands := map[string]func(*app, context.Context, []string) error{
+		"conversation": (*app).runConversation,
+		"memory":       (*app).runMemory,
+		"reindex":      (*app).runReindex,
+		"erase-user":   (*app).runEraseUser,
+		"snapshot":     (*app).runSnapshot,
+		"stats":        (*app).runStats,
+	}
+	runCommand, ok := commands[flags.Arg(0)]
+	if !ok {
+		flags.Usage()
+		return fmt.Errorf("%w: unknown command %q", errUsage, flags.Arg(0))
+	}
+	config.skipIndexLoad = flags.Arg(0) == "reindex"
+	app, err := newApp(config, stdout)
 	if err != nil {
-		fmt.Println("error retrieving semantic memory: ", err)
-		return
+		return err
 	}
-	memories := retrieveSemanticMemoryOutput.Memories
-	similarMemories := retrieveSemanticMemoryOutput.SimilarMemories
-	for _, memory := range memories {
-		fmt.Println("memories:")
-		fmt.Println("memory id: ", memory.ID)
-		fmt.Println("memory query: ", memory.Query)
-		fmt.Println("memory response: ", memory.Response)
-		fmt.Println("memory created at: ", memory.CreatedAt)
-		fmt.Println("--------------------------------")
+	defer app.close()
+	err = runCommand(app, ctx, flags.Args()[1:])
+	app.reportEmbeddingCache(stderr)
+	if errors.Is(err, errUsage) {
+		flags.Usage()
 	}
-	for _, memory := range similarMemories {
-		fmt.Println("similar memories:")
-		fmt.Println("memory id: ", memory.ID)
-		fmt.Println("memory query: ", memory.Query)
-		fmt.Println("memory response: ", memory.Response)
-		fmt.Println("memory created at: ", memory.CreatedAt)
-		fmt.Println("--------------------------------")
+	return err
+}
+
+func envOr(key string, fallback string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
 	}
+	return value
+}
+
+func envIntOr(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func flagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/haren7/minimal-memory/internal/memory"
+	"github.com/haren7/minimal-memory/types"
+
+	"github.com/google/uuid"
+)
+
+type memoryView struct {
+	ID        string         `json:"id"`
+	Query     string         `json:"query"`
+	Response  string         `json:"response"`
+	CreatedAt time.Time      `json:"created_at"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	Tags      []string       `json:"tags,omitempty"`
+}
+
+type similarMemoryView struct {
+	memoryView
+	ConversationID string  `json:"conversation_id"`
+	Rank           int     `json:"rank"`
+	Score          float32 `json:"score"`
+	Distance       float32 `json:"distance"`
+}
+
+func (r *app) runMemory(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: memory requires a subcommand", errUsage)
+	}
+	switch args[0] {
+	case "store":
+		return r.storeMemory(ctx, args[1:])
+	case "import":
+		return r.importMemories(ctx, args[1:])
+	case "recent":
+		return r.recentMemories(ctx, args[1:])
+	case "search":
+		return r.searchMemories(ctx, args[1:])
+	case "update":
+		return r.updateMemory(ctx, args[1:])
+	case "delete":
+		return r.deleteMemory(ctx, args[1:])
+	default:
+		return fmt.Errorf("%w: unknown memory subcommand %q", errUsage, args[0])
+	}
+}
+
+func (r *app) storeMemory(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("memory store", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	conversation := flags.String("conversation", "", "conversation to store the memory in")
+	query := flags.String("query", "", "user query to remember")
+	response := flags.String("response", "", "agent response to remember")
+	metadata := flags.String("metadata", "", "json object of details to keep with the memory")
+	tags := flags.String("tags", "", "comma separated tags to keep with the memory")
+	scopes := flags.String("scopes", "", "comma separated scopes to also share the memory with, user or agent")
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if *query == "" || *response == "" {
+		return fmt.Errorf("%w: -query and -response are required", errUsage)
+	}
+	filter, err := parseFilter(*metadata, *tags)
+	if err != nil {
+		return err
+	}
+	sharedScopes, err := parseScopes(*scopes)
+	if err != nil {
+		return err
+	}
+	conversationID, err := r.existingConversation(ctx, *conversation)
+	if err != nil {
+		return err
+	}
+	err = r.requireOpenAIApiKey()
+	if err != nil {
+		return err
+	}
+	memoryID, err := r.memoryService.Store(ctx, conversationID, memory.MemoryInput{
+		Query:    *query,
+		Response: *response,
+		Metadata: filter.Metadata,
+		Tags:     filter.Tags,
+		Scopes:   sharedScopes,
+	})
+	if err != nil {
+		return err
+	}
+	err = r.saveIndexes()
+	if err != nil {
+		return err
+	}
+	return r.printer.print(map[string]string{"memory_id": memoryID.String()}, []string{"MEMORY ID"}, [][]string{{memoryID.String()}})
+}
+
+// importBatchSize bounds how many memories are stored per transaction.
+const importBatchSize = 1000
+
+func (r *app) importMemories(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("memory import", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	conversation := flags.String("conversation", "", "conversation to store the memories in")
+	file := flags.String("file", "-", "json lines file of {\"query\", \"response\", \"created_at\", \"metadata\", \"tags\"} objects, - for stdin")
+	scopes := flags.String("scopes", "", "comma separated scopes to also share the memories with, user or agent")
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	sharedScopes, err := parseScopes(*scopes)
+	if err != nil {
+		return err
+	}
+	conversationID, err := r.existingConversation(ctx, *conversation)
+	if err != nil {
+		return err
+	}
+	err = r.requireOpenAIApiKey()
+	if err != nil {
+		return err
+	}
+	var reader io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return fmt.Errorf("error opening %s: %w", *file, err)
+		}
+		defer f.Close()
+		reader = f
+	}
+	var memories []memory.MemoryInput
+	decoder := json.NewDecoder(reader)
+	for line := 1; ; line++ {
+		var entry types.SemanticMemoryEntry
+		err := decoder.Decode(&entry)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error decoding memory %d: %w", line, err)
+		}
+		if entry.Query == "" || entry.Response == "" {
+			return fmt.Errorf("memory %d: query and response are required", line)
+		}
+		memories = append(memories, memory.MemoryInput{
+			Query:     entry.Query,
+			Response:  entry.Response,
+			CreatedAt: entry.CreatedAt,
+			Metadata:  entry.Metadata,
+			Tags:      entry.Tags,
+			Scopes:    sharedScopes,
+		})
+	}
+	imported := 0
+	for start := 0; start < len(memories); start += importBatchSize {
+		end := min(start+importBatchSize, len(memories))
+		_, err := r.memoryService.StoreMany(ctx, conversationID, memories[start:end])
+		if err != nil {
+			// earlier batches are committed, save their vectors before failing
+			saveErr := r.saveIndexes()
+			if saveErr != nil {
+				return errors.Join(err, saveErr)
+			}
+			return fmt.Errorf("error importing memories %d to %d, %d imported before: %w", start+1, end, imported, err)
+		}
+		imported = end
+	}
+	err = r.saveIndexes()
+	if err != nil {
+		return err
+	}
+	return r.printer.print(map[string]int{"imported": imported}, []string{"IMPORTED"}, [][]string{{strconv.Itoa(imported)}})
+}
+
+func (r *app) recentMemories(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("memory recent", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	conversation := flags.String("conversation", "", "conversation to read memories from")
+	limit := flags.Int("limit", 10, "number of most recent memories to show")
+	metadata := flags.String("metadata", "", "json object of metadata values the memories must hold")
+	tags := flags.String("tags", "", "comma separated tags the memories must all carry")
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	filter, err := parseFilter(*metadata, *tags)
+	if err != nil {
+		return err
+	}
+	conversationID, err := r.existingConversation(ctx, *conversation)
+	if err != nil {
+		return err
+	}
+	memories, err := r.memoryService.Retrieve(ctx, conversationID, *limit, filter)
+	if err != nil {
+		return err
+	}
+	return r.printMemories(memories)
+}
+
+func (r *app) searchMemories(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("memory search", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	conversation := flags.String("conversation", "", "conversation to search")
+	query := flags.String("query", "", "text to search similar memories for")
+	topK := flags.Int("top-k", 10, "maximum number of similar memories to show")
+	minScore := flags.Float64("min-score", 0, "minimum similarity score of the memories to show, unset shows every match")
+	metadata := flags.String("metadata", "", "json object of metadata values the memories must hold")
+	tags := flags.String("tags", "", "comma separated tags the memories must all carry")
+	scope := flags.String("scope", string(memory.ScopeConversation), "where to search: conversation, or the memories shared with its user or agent")
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if *query == "" {
+		return fmt.Errorf("%w: -query is required", errUsage)
+	}
+	if !memory.Scope(*scope).IsValid() {
+		return fmt.Errorf("%w: -scope must be conversation, user or agent", errUsage)
+	}
+	filter, err := parseFilter(*metadata, *tags)
+	if err != nil {
+		return err
+	}
+	conversationID, err := r.existingConversation(ctx, *conversation)
+	if err != nil {
+		return err
+	}
+	err = r.requireOpenAIApiKey()
+	if err != nil {
+		return err
+	}
+	// cosine and inner product scores go below zero, an unset flag must not drop them
+	if !flagSet(flags, "min-score") {
+		*minScore = math.Inf(-1)
+	}
+	memories, err := r.memoryService.RetrieveSimilar(ctx, conversationID, memory.Scope(*scope), *query, *topK, float32(*minScore), filter)
+	if err != nil {
+		return err
+	}
+	return r.printSimilarMemories(memories)
+}
+
+func (r *app) updateMemory(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("memory update", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	conversation := flags.String("conversation", "", "conversation the memory belongs to")
+	id := flags.String("memory", "", "memory to update")
+	query := flags.String("query", "", "new user query")
+	response := flags.String("response", "", "new agent response")
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	if *query == "" || *response == "" {
+		return fmt.Errorf("%w: -query and -response are required", errUsage)
+	}
+	conversationID, err := r.existingConversation(ctx, *conversation)
+	if err != nil {
+		return err
+	}
+	memoryID, err := parseMemoryID(*id)
+	if err != nil {
+		return err
+	}
+	err = r.requireOpenAIApiKey()
+	if err != nil {
+		return err
+	}
+	err = r.memoryService.Update(ctx, conversationID, memoryID, *query, *response)
+	if err != nil {
+		return err
+	}
+	err = r.saveIndexes()
+	if err != nil {
+		return err
+	}
+	return r.printer.print(map[string]string{"memory_id": memoryID.String()}, []string{"MEMORY ID"}, [][]string{{memoryID.String()}})
+}
+
+func (r *app) deleteMemory(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("memory delete", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	conversation := flags.String("conversation", "", "conversation the memory belongs to")
+	id := flags.String("memory", "", "memory to delete")
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	conversationID, err := r.existingConversation(ctx, *conversation)
+	if err != nil {
+		return err
+	}
+	memoryID, err := parseMemoryID(*id)
+	if err != nil {
+		return err
+	}
+	err = r.memoryService.Delete(ctx, conversationID, memoryID)
+	if err != nil {
+		return err
+	}
+	err = r.saveIndexes()
+	if err != nil {
+		return err
+	}
+	return r.printer.print(map[string]string{"memory_id": memoryID.String()}, []string{"MEMORY ID"}, [][]string{{memoryID.String()}})
+}
+
+// parseFilter reads the -metadata and -tags flags, memory store keeps them and
+// memory recent and search filter by them.
+func parseFilter(metadata, tags string) (memory.Filter, error) {
+	var filter memory.Filter
+	if metadata != "" {
+		err := json.Unmarshal([]byte(metadata), &filter.Metadata)
+		if err != nil {
+			return memory.Filter{}, fmt.Errorf("%w: -metadata must be a json object, %v", errUsage, err)
+		}
+	}
+	if tags != "" {
+		for _, tag := range strings.Split(tags, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "" {
+				return memory.Filter{}, fmt.Errorf("%w: -tags must not hold empty tags", errUsage)
+			}
+			filter.Tags = append(filter.Tags, tag)
+		}
+	}
+	return filter, nil
+}
+
+// parseScopes reads the -scopes flag of memory store and import.
+func parseScopes(scopes string) ([]memory.Scope, error) {
+	if scopes == "" {
+		return nil, nil
+	}
+	var parsed []memory.Scope
+	for _, scope := range strings.Split(scopes, ",") {
+		scope := memory.Scope(strings.TrimSpace(scope))
+		if !scope.IsValid() {
+			return nil, fmt.Errorf("%w: -scopes must be conversation, user or agent, got %q", errUsage, scope)
+		}
+		parsed = append(parsed, scope)
+	}
+	return parsed, nil
+}
+
+func parseMemoryID(memory string) (uuid.UUID, error) {
+	if memory == "" {
+		return uuid.UUID{}, fmt.Errorf("%w: -memory is required", errUsage)
+	}
+	memoryID, err := uuid.Parse(memory)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("invalid memory id %q: %w", memory, err)
+	}
+	return memoryID, nil
+}
+
+func (r *app) existingConversation(ctx context.Context, conversation string) (uuid.UUID, error) {
+	if conversation == "" {
+		return uuid.UUID{}, fmt.Errorf("%w: -conversation is required", errUsage)
+	}
+	conversationID, err := uuid.Parse(conversation)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("invalid conversation id %q: %w", conversation, err)
+	}
+	exists, err := r.conversationService.Exists(ctx, conversationID)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	if !exists {
+		return uuid.UUID{}, fmt.Errorf("conversation %s does not exist", conversationID)
+	}
+	return conversationID, nil
+}
+
+func (r *app) printMemories(memories []memory.Memory) error {
+	views := []memoryView{}
+	var rows [][]string
+	for _, memory := range memories {
+		views = append(views, memoryView{
+			ID:        memory.ID.String(),
+			Query:     memory.Query,
+			Response:  memory.Response,
+			CreatedAt: memory.CreatedAt,
+			Metadata:  memory.Metadata,
+			Tags:      memory.Tags,
+		})
+		rows = append(rows, []string{memory.ID.String(), truncate(memory.Query, 40), truncate(memory.Response, 60), strings.Join(memory.Tags, ","), formatTime(memory.CreatedAt)})
+	}
+	return r.printer.print(views, []string{"ID", "QUERY", "RESPONSE", "TAGS", "CREATED AT"}, rows)
+}
+
+func (r *app) printSimilarMemories(memories []memory.Memory) error {
+	views := []similarMemoryView{}
+	var rows [][]string
+	for _, memory := range memories {
+		views = append(views, similarMemoryView{
+			memoryView: memoryView{
+				ID:        memory.ID.String(),
+				Query:     memory.Query,
+				Response:  memory.Response,
+				CreatedAt: memory.CreatedAt,
+				Metadata:  memory.Metadata,
+				Tags:      memory.Tags,
+			},
+			ConversationID: memory.ConversationID.String(),
+			Rank:           memory.Rank,
+			Score:          memory.Score,
+			Distance:       memory.Distance,
+		})
+		rows = append(rows, []string{strconv.Itoa(memory.Rank), memory.ID.String(), memory.ConversationID.String(), strconv.FormatFloat(float64(memory.Score), 'f', 4, 32), truncate(memory.Query, 40), truncate(memory.Response, 60), strings.Join(memory.Tags, ","), formatTime(memory.CreatedAt)})
+	}
+	return r.printer.print(views, []string{"RANK", "ID", "CONVERSATION ID", "SCORE", "QUERY", "RESPONSE", "TAGS", "CREATED AT"}, rows)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+type reindexView struct {
+	ConversationID string `json:"conversation_id"`
+	Memories       int    `json:"memories"`
+}
+
+// runReindex rebuilds vector indexes from the memories in DuckDB. The saved
+// indexes are not loaded up front so that a corrupt one cannot block it: a
+// full reindex replaces all of them and a single conversation loads the others
+// before replacing its own.
+func (r *app) runReindex(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("reindex", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	conversation := flags.String("conversation", "", "conversation to reindex, all conversations when empty")
+	err := flags.Parse(args)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	var conversationIDs []uuid.UUID
+	if *conversation != "" {
+		conversationID, err := r.existingConversation(ctx, *conversation)
+		if err != nil {
+			return err
+		}
+		err = loadIndexes(r.vectorStore, r.config.indexDir)
+		if err != nil {
+			return fmt.Errorf("%w, run reindex without -conversation to rebuild every index", err)
+		}
+		conversationIDs = []uuid.UUID{conversationID}
+	}
+	// stored embeddings are reused, only memories without one reach the provider
+	results, err := r.memoryService.Reindex(ctx, conversationIDs)
+	if err != nil && len(results) == 0 {
+		return err
+	}
+	// a partial reindex only ever replaces whole indexes, save the ones rebuilt before the failure
+	saveErr := r.saveIndexes()
+	if err != nil || saveErr != nil {
+		return errors.Join(err, saveErr)
+	}
+	views := []reindexView{}
+	var rows [][]string
+	for _, result := range results {
+		views = append(views, reindexView{ConversationID: result.ConversationID.String(), Memories: result.Memories})
+		rows = append(rows, []string{result.ConversationID.String(), strconv.Itoa(result.Memories)})
+	}
+	return r.printer.print(views, []string{"CONVERSATION ID", "MEMORIES"}, rows)
+}
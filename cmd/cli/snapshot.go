@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+func (r *app) runSnapshot(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: snapshot requires push or pull", errUsage)
+	}
+	switch args[0] {
+	case "push":
+		return r.pushSnapshot(ctx)
+	case "pull":
+		return r.pullSnapshot(ctx)
+	default:
+		return fmt.Errorf("%w: unknown snapshot subcommand %q", errUsage, args[0])
+	}
+}
+
+func (r *app) pushSnapshot(ctx context.Context) error {
+	managers, err := r.snapshotManagers()
+	if err != nil {
+		return err
+	}
+	for _, manager := range managers {
+		err := manager.Store(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return r.printer.print(map[string]string{"status": "pushed", "bucket": r.config.bucket}, []string{"STATUS", "BUCKET"}, [][]string{{"pushed", r.config.bucket}})
+}
+
+func (r *app) pullSnapshot(ctx context.Context) error {
+	managers, err := r.snapshotManagers()
+	if err != nil {
+		return err
+	}
+	for _, manager := range managers {
+		err := manager.Load(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	err = r.saveIndexes()
+	if err != nil {
+		return err
+	}
+	return r.printer.print(map[string]string{"status": "pulled", "bucket": r.config.bucket}, []string{"STATUS", "BUCKET"}, [][]string{{"pulled", r.config.bucket}})
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+type statsView struct {
+	Conversations    int `json:"conversations"`
+	Memories         int `json:"memories"`
+	IndexedRows      int `json:"indexed_rows"`
+	Indexes          int `json:"indexes"`
+	Vectors          int `json:"vectors"`
+	CachedEmbeddings int `json:"cached_embeddings"`
+}
+
+func (r *app) runStats(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("%w: stats takes no arguments", errUsage)
+	}
+	conversations, err := r.conversationRepo.Count(ctx)
+	if err != nil {
+		return err
+	}
+	memories, err := r.memoryRepo.Count(ctx)
+	if err != nil {
+		return err
+	}
+	indexedRows, err := r.faissMemoryRepo.Count(ctx)
+	if err != nil {
+		return err
+	}
+	cachedEmbeddings, err := r.embeddingCacheRepo.Count(ctx)
+	if err != nil {
+		return err
+	}
+	stats := statsView{
+		Conversations:    conversations,
+		Memories:         memories,
+		IndexedRows:      indexedRows,
+		CachedEmbeddings: cachedEmbeddings,
+	}
+	for _, size := range r.vectorStore.IndexSizes() {
+		stats.Indexes++
+		stats.Vectors += int(size)
+	}
+	return r.printer.print(stats, []string{"CONVERSATIONS", "MEMORIES", "INDEXED ROWS", "INDEXES", "VECTORS", "CACHED EMBEDDINGS"}, [][]string{{
+		strconv.Itoa(stats.Conversations),
+		strconv.Itoa(stats.Memories),
+		strconv.Itoa(stats.IndexedRows),
+		strconv.Itoa(stats.Indexes),
+		strconv.Itoa(stats.Vectors),
+		strconv.Itoa(stats.CachedEmbeddings),
+	}})
+}
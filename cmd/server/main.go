@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	memoryv1 "github.com/haren7/minimal-memory/api/memory/v1"
+	"github.com/haren7/minimal-memory/clients"
+	"github.com/haren7/minimal-memory/internal/grpcapi"
+	"github.com/haren7/minimal-memory/internal/httpapi"
+	"github.com/haren7/minimal-memory/internal/mcpapi"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"google.golang.org/grpc"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+func main() {
+	addr := flag.String("addr", envOr("MINIMAL_MEMORY_ADDR", ":8080"), "address for the http server to listen on")
+	grpcAddr := flag.String("grpc-addr", envOr("MINIMAL_MEMORY_GRPC_ADDR", ":9090"), "address for the grpc server to listen on, empty to disable")
+	openAIApiKey := flag.String("openai-api-key", os.Getenv("OPENAI_API_KEY"), "openai api key used for embeddings")
+	openAIBaseURL := flag.String("openai-base-url", os.Getenv("OPENAI_BASE_URL"), "base url of an openai compatible embeddings endpoint")
+	embeddingModel := flag.String("embedding-model", os.Getenv("MINIMAL_MEMORY_EMBEDDING_MODEL"), "embedding model requested from the openai compatible endpoint, defaults to text-embedding-3-small")
+	embeddingDimensions := flag.Int("embedding-dimensions", envIntOr("MINIMAL_MEMORY_EMBEDDING_DIMENSIONS", 0), "requested embedding dimensions, 0 keeps the model default")
+	openAIOrganization := flag.String("openai-organization", os.Getenv("OPENAI_ORGANIZATION"), "openai organization id")
+	openAIAzure := flag.Bool("openai-azure", os.Getenv("MINIMAL_MEMORY_OPENAI_AZURE") == "true", "use azure openai authentication, -openai-base-url is the resource endpoint")
+	embeddingCacheSize := flag.Int("embedding-cache-size", envIntOr("MINIMAL_MEMORY_EMBEDDING_CACHE_SIZE", 1024), "number of embeddings cached in memory, negative to disable")
+	embeddingCachePersistent := flag.Bool("embedding-cache-persistent", os.Getenv("MINIMAL_MEMORY_EMBEDDING_CACHE_PERSISTENT") == "true", "also cache embeddings in the duckdb database")
+	embeddingMaxRetries := flag.Int("embedding-max-retries", envIntOr("MINIMAL_MEMORY_EMBEDDING_MAX_RETRIES", 3), "retries of failed embedding calls, negative to disable")
+	embeddingRPM := flag.Int("embedding-rpm", envIntOr("MINIMAL_MEMORY_EMBEDDING_RPM", 0), "embedding requests per minute, 0 for unlimited")
+	embeddingTPM := flag.Int("embedding-tpm", envIntOr("MINIMAL_MEMORY_EMBEDDING_TPM", 0), "embedding tokens per minute, 0 for unlimited")
+	embeddingProvider := flag.String("embedding-provider", envOr("MINIMAL_MEMORY_EMBEDDING_PROVIDER", "openai"), "embedding provider, openai or local")
+	localEmbeddingDim := flag.Int("local-embedding-dim", envIntOr("MINIMAL_MEMORY_LOCAL_EMBEDDING_DIM", 512), "vector size of the local embedding provider")
+	vectorBackend := flag.String("vector-backend", envOr("MINIMAL_MEMORY_VECTOR_BACKEND", "faiss"), "vector backend, faiss, bruteforce or chromem")
+	vectorMetric := flag.String("vector-metric", os.Getenv("MINIMAL_MEMORY_VECTOR_METRIC"), "how memories are compared, l2, ip or cosine, defaults to l2")
+	faissFactory := flag.String("faiss-factory", os.Getenv("MINIMAL_MEMORY_FAISS_FACTORY"), "faiss index factory string, e.g. HNSW32, IVF256,Flat or IVF256,PQ16, defaults to IDMap,Flat")
+	faissMigrateAt := flag.Int("faiss-migrate-at", envIntOr("MINIMAL_MEMORY_FAISS_MIGRATE_AT", 0), "memories after which a conversation moves from flat to -faiss-factory, 0 migrates only factories that need training")
+	faissNProbe := flag.Int("faiss-nprobe", envIntOr("MINIMAL_MEMORY_FAISS_NPROBE", 0), "ivf lists searched per query, 0 keeps the faiss default")
+	faissEfSearch := flag.Int("faiss-ef-search", envIntOr("MINIMAL_MEMORY_FAISS_EF_SEARCH", 0), "hnsw search depth, 0 keeps the faiss default")
+	duckdbPath := flag.String("db", envOr("MINIMAL_MEMORY_DB", "memory.db"), "path of the duckdb database file")
+	namespace := flag.String("namespace", os.Getenv("MINIMAL_MEMORY_NAMESPACE"), "tenant namespace serving requests that name none, each has its own duckdb schema and vector indexes")
+	tenants := flag.String("tenants", os.Getenv("MINIMAL_MEMORY_TENANTS"), "comma separated namespaces requests may also name with the X-Namespace header, x-namespace grpc metadata or a /tenants/NAMESPACE path prefix, * for any")
+	contextWindowSize := flag.Int("context-window", envIntOr("MINIMAL_MEMORY_CONTEXT_WINDOW", 10), "number of recent memories returned by semantic retrieve")
+	shortTermWindow := flag.Int("short-term-window", envIntOr("MINIMAL_MEMORY_SHORT_TERM_WINDOW", 100), "memories kept per short term conversation, the oldest are dropped")
+	shortTermMaxConversations := flag.Int("short-term-max-conversations", envIntOr("MINIMAL_MEMORY_SHORT_TERM_MAX_CONVERSATIONS", 0), "short term conversations kept in memory, the least recently used are dropped, 0 for unlimited")
+	shortTermIdleTTL := flag.Duration("short-term-idle-ttl", envDurationOr("MINIMAL_MEMORY_SHORT_TERM_IDLE_TTL", 0), "drop short term conversations idle for this long, 0 keeps them")
+	tokenizerModel := flag.String("tokenizer-model", os.Getenv("MINIMAL_MEMORY_TOKENIZER_MODEL"), "model whose encoding counts the tokens of max_tokens, e.g. gpt-4o, defaults to cl100k_base")
+	tokenizerRanksFile := flag.String("tokenizer-ranks-file", os.Getenv("MINIMAL_MEMORY_TOKENIZER_RANKS_FILE"), "tiktoken ranks file of the encoding, e.g. cl100k_base.tiktoken, token counts are estimated without it")
+	mcpStdio := flag.Bool("mcp-stdio", false, "serve the mcp tools over stdin/stdout instead of running the http and grpc servers")
+	flag.Parse()
+
+	if *tokenizerRanksFile == "" {
+		log.Printf("[INFO] main: No tokenizer ranks file, max_tokens budgets count estimated tokens")
+	}
+	var namespaces []string
+	if *tenants != "" {
+		namespaces = strings.Split(*tenants, ",")
+	}
+	tenantClients, err := clients.NewTenants(clients.TenantsConfig{
+		DuckDBPath:       *duckdbPath,
+		DefaultNamespace: *namespace,
+		Namespaces:       namespaces,
+		Semantic: clients.SemanticMemoryClientConfig{
+			ContextWindowSize:         *contextWindowSize,
+			OpenAIApiKey:              *openAIApiKey,
+			EmbeddingProvider:         clients.EmbeddingProvider(*embeddingProvider),
+			LocalEmbeddingDim:         *localEmbeddingDim,
+			OpenAIBaseURL:             *openAIBaseURL,
+			OpenAIEmbeddingModel:      *embeddingModel,
+			OpenAIEmbeddingDimensions: *embeddingDimensions,
+			OpenAIOrganization:        *openAIOrganization,
+			OpenAIAzure:               *openAIAzure,
+			EmbeddingCacheSize:        *embeddingCacheSize,
+			EmbeddingCachePersistent:  *embeddingCachePersistent,
+			EmbeddingResilience: clients.EmbeddingResilienceConfig{
+				MaxRetries:        *embeddingMaxRetries,
+				RequestsPerMinute: *embeddingRPM,
+				TokensPerMinute:   *embeddingTPM,
+			},
+			VectorBackend: clients.VectorBackend(*vectorBackend),
+			VectorMetric:  clients.VectorMetric(*vectorMetric),
+			FaissIndex: clients.FaissIndexConfig{
+				Factory:   *faissFactory,
+				MigrateAt: *faissMigrateAt,
+				NProbe:    *faissNProbe,
+				EfSearch:  *faissEfSearch,
+			},
+			Tokenizer: clients.TokenizerConfig{
+				Model:     *tokenizerModel,
+				RanksFile: *tokenizerRanksFile,
+			},
+		},
+		ShortTerm: clients.ShortTermMemoryClientConfig{
+			MaxWindow:        *shortTermWindow,
+			MaxConversations: *shortTermMaxConversations,
+			IdleTTL:          *shortTermIdleTTL,
+			Tokenizer: clients.TokenizerConfig{
+				Model:     *tokenizerModel,
+				RanksFile: *tokenizerRanksFile,
+			},
+		},
+	})
+	if err != nil {
+		log.Fatalf("[ERROR] main: Failed to create memory clients - %v", err)
+	}
+	semanticMemoryClient := tenantClients.SemanticByContext()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *mcpStdio {
+		defaultClient, err := tenantClients.Semantic("")
+		if err != nil {
+			log.Fatalf("[ERROR] main: Failed to get the default semantic memory client - %v", err)
+		}
+		// stdout carries the protocol, the standard logger already writes to stderr
+		err = mcpapi.NewServer(defaultClient).Run(ctx, &mcp.StdioTransport{})
+		if err != nil && !errors.Is(err, context.Canceled) {
+			log.Fatalf("[ERROR] main: MCP stdio server failed - %v", err)
+		}
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", mcp.NewStreamableHTTPHandler(mcpapi.NewTenantServers(tenantClients), nil))
+	mux.Handle("/", httpapi.NewHandler(semanticMemoryClient, tenantClients.ShortTermByContext()))
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           httpapi.NamespaceHandler(mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(grpcapi.NamespaceInterceptor))
+	memoryv1.RegisterSemanticMemoryServiceServer(grpcServer, grpcapi.NewSemanticMemoryServer(semanticMemoryClient))
+
+	serverErr := make(chan error, 2)
+	go func() {
+		log.Printf("[INFO] main: HTTP server listening on %s", *addr)
+		err := httpServer.ListenAndServe()
+		if !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+	if *grpcAddr != "" {
+		listener, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			log.Fatalf("[ERROR] main: Failed to listen on %s - %v", *grpcAddr, err)
+		}
+		go func() {
+			log.Printf("[INFO] main: gRPC server listening on %s", *grpcAddr)
+			err := grpcServer.Serve(listener)
+			if err != nil {
+				serverErr <- err
+			}
+		}()
+	}
+
+	select {
+	case err := <-serverErr:
+		log.Printf("[ERROR] main: Server failed - %v", err)
+	case <-ctx.Done():
+	}
+
+	log.Printf("[INFO] main: Shutting down servers")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err = httpServer.Shutdown(shutdownCtx)
+	if err != nil {
+		log.Printf("[ERROR] main: Failed to shut down HTTP server gracefully - %v", err)
+	}
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+	}
+}
+
+func envOr(key string, fallback string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func envIntOr(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func envDurationOr(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
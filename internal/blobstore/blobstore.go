@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -14,6 +15,8 @@ import (
 type BlobStoreInterface interface {
 	Store(ctx context.Context, bucket string, path string, files []os.File) error
 	Retrieve(ctx context.Context, bucket string, path string) (map[string]io.Reader, error)
+	// Delete removes the files named names under path, missing files are not an error.
+	Delete(ctx context.Context, bucket string, path string, names []string) error
 }
 
 type s3Store struct {
@@ -26,9 +29,10 @@ func NewS3Store(s3Client *s3.Client) BlobStoreInterface {
 	}
 }
 
-func (r *s3Store) Store(ctx context.Context, bucket string, path string, files []os.File) error {
+func (r *s3Store) Store(ctx context.Context, bucket string, prefix string, files []os.File) error {
 	for _, file := range files {
-		key := filepath.Join(path, file.Name())
+		// file names are local paths, only the base name is kept under the prefix
+		key := path.Join(prefix, filepath.Base(file.Name()))
 		_, err := r.s3Client.PutObject(ctx, &s3.PutObjectInput{
 			Bucket: &bucket,
 			Key:    &key,
@@ -41,27 +45,46 @@ func (r *s3Store) Store(ctx context.Context, bucket string, path string, files [
 	return nil
 }
 
-func (r *s3Store) Retrieve(ctx context.Context, bucket string, path string) (map[string]io.Reader, error) {
-	// get files at path
-	files, err := r.s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+func (r *s3Store) Retrieve(ctx context.Context, bucket string, prefix string) (map[string]io.Reader, error) {
+	// get files under prefix
+	listPrefix := strings.TrimSuffix(prefix, "/") + "/"
+	paginator := s3.NewListObjectsV2Paginator(r.s3Client, &s3.ListObjectsV2Input{
 		Bucket: &bucket,
-		Prefix: &path,
+		Prefix: &listPrefix,
 	})
-	if err != nil {
-		return nil, fmt.Errorf("error listing objects: %w", err)
-	}
 
 	filesMap := make(map[string]io.Reader)
-	for _, object := range files.Contents {
-		file, err := r.s3Client.GetObject(ctx, &s3.GetObjectInput{
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing objects: %w", err)
+		}
+		for _, object := range page.Contents {
+			file, err := r.s3Client.GetObject(ctx, &s3.GetObjectInput{
+				Bucket: &bucket,
+				Key:    object.Key,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error getting object: %w", err)
+			}
+			// map of file name vs reader
+			filesMap[strings.TrimPrefix(*object.Key, listPrefix)] = file.Body
+		}
+	}
+	return filesMap, nil
+}
+
+func (r *s3Store) Delete(ctx context.Context, bucket string, prefix string, names []string) error {
+	for _, name := range names {
+		key := path.Join(prefix, name)
+		// deleting a missing key succeeds
+		_, err := r.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
 			Bucket: &bucket,
-			Key:    object.Key,
+			Key:    &key,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("error getting object: %w", err)
+			return fmt.Errorf("error deleting object %s: %w", key, err)
 		}
-		// map of file name vs reader
-		filesMap[strings.TrimPrefix(*object.Key, path)] = file.Body
 	}
-	return filesMap, nil
+	return nil
 }
@@ -1,61 +1,238 @@
 package cache
 
 import (
+	"container/list"
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var ErrNotFound = errors.New("memory not found")
+
+// DefaultMaxWindow is the number of memories kept per conversation when
+// Config.MaxWindow is not set.
+const DefaultMaxWindow = 100
+
 type MemoryRepoInterface interface {
-	SetOne(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID, query string, response string, createdAt time.Time) error
+	SetOne(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID, query string, response string, createdAt time.Time, metadata map[string]any, tags []string) error
 	DeleteLastN(ctx context.Context, conversationID uuid.UUID, lastN int) error
+	// DeleteOne and UpdateOne return ErrNotFound for memories the conversation does not hold.
+	DeleteOne(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID) error
+	UpdateOne(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID, query string, response string) error
+	// DeleteConversation removes every memory of a conversation and returns how many were removed.
+	DeleteConversation(ctx context.Context, conversationID uuid.UUID) (int, error)
+	// Get returns the last lastK memories of a conversation, oldest first, or
+	// all of them when lastK is not positive. Unknown conversations have none.
 	Get(ctx context.Context, conversationID uuid.UUID, lastK int) ([]Memory, error)
 	Len(ctx context.Context, convesationID uuid.UUID) (int, error)
+	Stats(ctx context.Context, conversationID uuid.UUID) (Stats, error)
+}
+
+type Config struct {
+	// MaxWindow is the number of memories kept per conversation, storing more
+	// drops the oldest. Defaults to DefaultMaxWindow.
+	MaxWindow int
+	// MaxConversations bounds the conversations held, the least recently used
+	// one is evicted to make room. Zero keeps every conversation.
+	MaxConversations int
+	// IdleTTL evicts conversations that were not stored to or read for that
+	// long. Zero keeps them until they are evicted or deleted.
+	IdleTTL time.Duration
 }
 
+// InMemMemoryRepo keeps a ring buffer of recent memories per conversation.
+// Conversations are kept in least recently used order, so the idle ones are
+// the first to be evicted.
 type InMemMemoryRepo struct {
-	memories map[uuid.UUID][]Memory
+	mu               sync.Mutex
+	maxWindow        int
+	maxConversations int
+	idleTTL          time.Duration
+	now              func() time.Time
+	lru              *list.List
+	conversations    map[uuid.UUID]*list.Element
+}
+
+type window struct {
+	id         uuid.UUID
+	memories   *ring
+	lastAccess time.Time
 }
 
-func NewInMemMemoryRepo() MemoryRepoInterface {
+func NewInMemMemoryRepo(config Config) MemoryRepoInterface {
+	maxWindow := config.MaxWindow
+	if maxWindow <= 0 {
+		maxWindow = DefaultMaxWindow
+	}
 	return &InMemMemoryRepo{
-		memories: make(map[uuid.UUID][]Memory),
+		maxWindow:        maxWindow,
+		maxConversations: max(config.MaxConversations, 0),
+		idleTTL:          max(config.IdleTTL, 0),
+		now:              time.Now,
+		lru:              list.New(),
+		conversations:    make(map[uuid.UUID]*list.Element),
 	}
 }
 
-func (r *InMemMemoryRepo) SetOne(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID, query string, response string, createdAt time.Time) error {
-	values, exists := r.memories[conversationID]
-	if exists {
-		values = append(values, Memory{
-			ID:        memoryID,
-			Query:     query,
-			Response:  response,
-			CreatedAt: createdAt,
-		})
-	} else {
-		r.memories[conversationID] = []Memory{Memory{ID: memoryID, Query: query, Response: response}}
+func (r *InMemMemoryRepo) SetOne(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID, query string, response string, createdAt time.Time, metadata map[string]any, tags []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.evictIdle()
+	conversation := r.touch(conversationID)
+	if conversation == nil {
+		conversation = r.add(conversationID)
 	}
+	conversation.memories.push(Memory{
+		ID:        memoryID,
+		Query:     query,
+		Response:  response,
+		CreatedAt: createdAt,
+		Metadata:  metadata,
+		Tags:      tags,
+	})
 	return nil
 }
 
 func (r *InMemMemoryRepo) DeleteLastN(ctx context.Context, conversationID uuid.UUID, lastN int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	conversation := r.touch(conversationID)
+	if conversation == nil {
+		return nil
+	}
+	conversation.memories.dropLast(lastN)
 	return nil
 }
 
+func (r *InMemMemoryRepo) DeleteOne(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	conversation := r.touch(conversationID)
+	if conversation == nil || !conversation.memories.remove(memoryID) {
+		return fmt.Errorf("cache: memory %s not found for conversation id %s, %w", memoryID, conversationID, ErrNotFound)
+	}
+	return nil
+}
+
+func (r *InMemMemoryRepo) UpdateOne(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID, query string, response string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	conversation := r.touch(conversationID)
+	if conversation == nil {
+		return fmt.Errorf("cache: memory %s not found for conversation id %s, %w", memoryID, conversationID, ErrNotFound)
+	}
+	memory := conversation.memories.find(memoryID)
+	if memory == nil {
+		return fmt.Errorf("cache: memory %s not found for conversation id %s, %w", memoryID, conversationID, ErrNotFound)
+	}
+	memory.Query = query
+	memory.Response = response
+	return nil
+}
+
+func (r *InMemMemoryRepo) DeleteConversation(ctx context.Context, conversationID uuid.UUID) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	conversation := r.lookup(conversationID)
+	if conversation == nil {
+		return 0, nil
+	}
+	deleted := conversation.memories.len()
+	r.evict(r.conversations[conversationID])
+	return deleted, nil
+}
+
 func (r *InMemMemoryRepo) Get(ctx context.Context, conversationID uuid.UUID, lastK int) ([]Memory, error) {
-	memories, exists := r.memories[conversationID]
-	if !exists {
-		return nil, fmt.Errorf("cache: error no memories")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	conversation := r.touch(conversationID)
+	if conversation == nil {
+		return nil, nil
 	}
-	return memories, nil
+	return conversation.memories.last(lastK), nil
 }
 
 func (r *InMemMemoryRepo) Len(ctx context.Context, convesationID uuid.UUID) (int, error) {
-	memories, exists := r.memories[convesationID]
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	conversation := r.lookup(convesationID)
+	if conversation == nil {
+		return 0, nil
+	}
+	return conversation.memories.len(), nil
+}
+
+// Stats does not count as using the conversation, listing conversations must
+// not keep them from being evicted.
+func (r *InMemMemoryRepo) Stats(ctx context.Context, conversationID uuid.UUID) (Stats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	conversation := r.lookup(conversationID)
+	if conversation == nil {
+		return Stats{}, nil
+	}
+	stats := Stats{Memories: conversation.memories.len()}
+	for _, memory := range conversation.memories.last(0) {
+		if memory.CreatedAt.After(stats.LastCreatedAt) {
+			stats.LastCreatedAt = memory.CreatedAt
+		}
+	}
+	return stats, nil
+}
+
+// lookup returns the conversation unless it does not exist or has expired, in
+// which case it is evicted.
+func (r *InMemMemoryRepo) lookup(conversationID uuid.UUID) *window {
+	element, exists := r.conversations[conversationID]
 	if !exists {
-		return 0, fmt.Errorf("cache: error no memories")
+		return nil
+	}
+	conversation := element.Value.(*window)
+	if r.expired(conversation) {
+		r.evict(element)
+		return nil
+	}
+	return conversation
+}
+
+// touch is lookup that also marks the conversation as most recently used.
+func (r *InMemMemoryRepo) touch(conversationID uuid.UUID) *window {
+	conversation := r.lookup(conversationID)
+	if conversation == nil {
+		return nil
 	}
-	return len(memories), nil
+	conversation.lastAccess = r.now()
+	r.lru.MoveToFront(r.conversations[conversationID])
+	return conversation
+}
+
+func (r *InMemMemoryRepo) add(conversationID uuid.UUID) *window {
+	if r.maxConversations > 0 {
+		for r.lru.Len() >= r.maxConversations {
+			r.evict(r.lru.Back())
+		}
+	}
+	conversation := &window{id: conversationID, memories: newRing(r.maxWindow), lastAccess: r.now()}
+	r.conversations[conversationID] = r.lru.PushFront(conversation)
+	return conversation
+}
+
+// evictIdle drops expired conversations, they are all at the back of the list.
+func (r *InMemMemoryRepo) evictIdle() {
+	for element := r.lru.Back(); element != nil && r.expired(element.Value.(*window)); element = r.lru.Back() {
+		r.evict(element)
+	}
+}
+
+func (r *InMemMemoryRepo) expired(conversation *window) bool {
+	return r.idleTTL > 0 && r.now().Sub(conversation.lastAccess) >= r.idleTTL
+}
+
+func (r *InMemMemoryRepo) evict(element *list.Element) {
+	r.lru.Remove(element)
+	delete(r.conversations, element.Value.(*window).id)
 }
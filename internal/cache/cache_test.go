@@ -0,0 +1,266 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// store sets one memory per query on conversationID.
+func store(t *testing.T, repo MemoryRepoInterface, conversationID uuid.UUID, queries ...string) []uuid.UUID {
+	t.Helper()
+	var ids []uuid.UUID
+	for _, query := range queries {
+		id := uuid.New()
+		err := repo.SetOne(context.Background(), conversationID, id, query, "response to "+query, time.Now(), nil, nil)
+		if err != nil {
+			t.Fatalf("SetOne: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func queries(t *testing.T, repo MemoryRepoInterface, conversationID uuid.UUID, lastK int) []string {
+	t.Helper()
+	memories, err := repo.Get(context.Background(), conversationID, lastK)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got := []string{}
+	for _, memory := range memories {
+		got = append(got, memory.Query)
+	}
+	return got
+}
+
+func TestInMemMemoryRepoWindow(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		stored []string
+		apply  func(t *testing.T, repo MemoryRepoInterface, conversationID uuid.UUID, ids []uuid.UUID)
+		lastK  int
+		expect []string
+	}{
+		{name: "last k", stored: []string{"a", "b", "c"}, lastK: 2, expect: []string{"b", "c"}},
+		{name: "all when k is zero", stored: []string{"a", "b", "c"}, lastK: 0, expect: []string{"a", "b", "c"}},
+		{name: "all when k is larger", stored: []string{"a", "b"}, lastK: 5, expect: []string{"a", "b"}},
+		{name: "window drops the oldest", stored: []string{"a", "b", "c", "d", "e"}, lastK: 0, expect: []string{"c", "d", "e"}},
+		{name: "last k of a wrapped window", stored: []string{"a", "b", "c", "d", "e"}, lastK: 2, expect: []string{"d", "e"}},
+		{
+			name:   "delete last n",
+			stored: []string{"a", "b", "c", "d"},
+			apply: func(t *testing.T, repo MemoryRepoInterface, conversationID uuid.UUID, ids []uuid.UUID) {
+				err := repo.DeleteLastN(ctx, conversationID, 2)
+				if err != nil {
+					t.Fatalf("DeleteLastN: %v", err)
+				}
+				store(t, repo, conversationID, "e")
+			},
+			expect: []string{"b", "e"},
+		},
+		{
+			name:   "delete one of a wrapped window",
+			stored: []string{"a", "b", "c", "d"},
+			apply: func(t *testing.T, repo MemoryRepoInterface, conversationID uuid.UUID, ids []uuid.UUID) {
+				err := repo.DeleteOne(ctx, conversationID, ids[2])
+				if err != nil {
+					t.Fatalf("DeleteOne: %v", err)
+				}
+				store(t, repo, conversationID, "e")
+			},
+			expect: []string{"b", "d", "e"},
+		},
+		{
+			name:   "update one",
+			stored: []string{"a", "b"},
+			apply: func(t *testing.T, repo MemoryRepoInterface, conversationID uuid.UUID, ids []uuid.UUID) {
+				err := repo.UpdateOne(ctx, conversationID, ids[0], "z", "response to z")
+				if err != nil {
+					t.Fatalf("UpdateOne: %v", err)
+				}
+			},
+			expect: []string{"z", "b"},
+		},
+		{
+			name:   "unknown memories are not found",
+			stored: []string{"a"},
+			apply: func(t *testing.T, repo MemoryRepoInterface, conversationID uuid.UUID, ids []uuid.UUID) {
+				if err := repo.DeleteOne(ctx, conversationID, uuid.New()); !errors.Is(err, ErrNotFound) {
+					t.Fatalf("DeleteOne = %v, want ErrNotFound", err)
+				}
+				if err := repo.UpdateOne(ctx, uuid.New(), ids[0], "z", "z"); !errors.Is(err, ErrNotFound) {
+					t.Fatalf("UpdateOne = %v, want ErrNotFound", err)
+				}
+			},
+			expect: []string{"a"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := NewInMemMemoryRepo(Config{MaxWindow: 3})
+			conversationID := uuid.New()
+			ids := store(t, repo, conversationID, test.stored...)
+			if test.apply != nil {
+				test.apply(t, repo, conversationID, ids)
+			}
+			got := queries(t, repo, conversationID, test.lastK)
+			if !slices.Equal(got, test.expect) {
+				t.Fatalf("Get(%d) = %v, want %v", test.lastK, got, test.expect)
+			}
+		})
+	}
+}
+
+func TestInMemMemoryRepoKeepsCreatedAt(t *testing.T) {
+	repo := NewInMemMemoryRepo(Config{})
+	conversationID := uuid.New()
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := repo.SetOne(context.Background(), conversationID, uuid.New(), "q", "r", createdAt, nil, nil)
+	if err != nil {
+		t.Fatalf("SetOne: %v", err)
+	}
+	stats, err := repo.Stats(context.Background(), conversationID)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.Memories != 1 || !stats.LastCreatedAt.Equal(createdAt) {
+		t.Fatalf("Stats = %+v, want 1 memory created at %v", stats, createdAt)
+	}
+}
+
+func TestInMemMemoryRepoEviction(t *testing.T) {
+	ctx := context.Background()
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	tests := []struct {
+		name   string
+		config Config
+		run    func(t *testing.T, repo MemoryRepoInterface, advance func(time.Duration))
+		expect map[uuid.UUID]int
+	}{
+		{
+			name:   "least recently used is evicted",
+			config: Config{MaxConversations: 2},
+			run: func(t *testing.T, repo MemoryRepoInterface, advance func(time.Duration)) {
+				store(t, repo, a, "a")
+				store(t, repo, b, "b")
+				queries(t, repo, a, 0)
+				store(t, repo, c, "c")
+			},
+			expect: map[uuid.UUID]int{a: 1, b: 0, c: 1},
+		},
+		{
+			name:   "stats do not count as use",
+			config: Config{MaxConversations: 2},
+			run: func(t *testing.T, repo MemoryRepoInterface, advance func(time.Duration)) {
+				store(t, repo, a, "a")
+				store(t, repo, b, "b")
+				repo.Stats(ctx, a)
+				store(t, repo, c, "c")
+			},
+			expect: map[uuid.UUID]int{a: 0, b: 1, c: 1},
+		},
+		{
+			name:   "idle conversations expire",
+			config: Config{IdleTTL: time.Minute},
+			run: func(t *testing.T, repo MemoryRepoInterface, advance func(time.Duration)) {
+				store(t, repo, a, "a")
+				store(t, repo, b, "b")
+				advance(40 * time.Second)
+				queries(t, repo, b, 0)
+				advance(40 * time.Second)
+			},
+			expect: map[uuid.UUID]int{a: 0, b: 1},
+		},
+		{
+			name:   "storing keeps a conversation alive",
+			config: Config{IdleTTL: time.Minute},
+			run: func(t *testing.T, repo MemoryRepoInterface, advance func(time.Duration)) {
+				for range 3 {
+					store(t, repo, a, "a")
+					advance(50 * time.Second)
+				}
+			},
+			expect: map[uuid.UUID]int{a: 3},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := NewInMemMemoryRepo(test.config)
+			now := time.Now()
+			repo.(*InMemMemoryRepo).now = func() time.Time { return now }
+			test.run(t, repo, func(d time.Duration) { now = now.Add(d) })
+			for conversationID, want := range test.expect {
+				got, err := repo.Len(ctx, conversationID)
+				if err != nil {
+					t.Fatalf("Len: %v", err)
+				}
+				if got != want {
+					t.Fatalf("Len(%s) = %d, want %d", conversationID, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestInMemMemoryRepoConcurrent is meant for go test -race.
+func TestInMemMemoryRepoConcurrent(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemMemoryRepo(Config{MaxWindow: 50, MaxConversations: 4, IdleTTL: time.Hour})
+	conversationIDs := []uuid.UUID{uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()}
+	var wg sync.WaitGroup
+	for worker := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 200 {
+				conversationID := conversationIDs[(worker+i)%len(conversationIDs)]
+				memoryID := uuid.New()
+				err := repo.SetOne(ctx, conversationID, memoryID, fmt.Sprintf("q%d-%d", worker, i), "r", time.Now(), nil, nil)
+				if err != nil {
+					t.Errorf("SetOne: %v", err)
+					return
+				}
+				memories, err := repo.Get(ctx, conversationID, 5)
+				if err != nil || len(memories) > 5 {
+					t.Errorf("Get = %d memories, %v", len(memories), err)
+					return
+				}
+				switch i % 4 {
+				case 0:
+					repo.UpdateOne(ctx, conversationID, memoryID, "updated", "r")
+				case 1:
+					repo.DeleteOne(ctx, conversationID, memoryID)
+				case 2:
+					repo.Stats(ctx, conversationID)
+				case 3:
+					repo.DeleteLastN(ctx, conversationID, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	held := 0
+	for _, conversationID := range conversationIDs {
+		n, err := repo.Len(ctx, conversationID)
+		if err != nil {
+			t.Fatalf("Len: %v", err)
+		}
+		if n > 50 {
+			t.Fatalf("Len(%s) = %d, above the window of 50", conversationID, n)
+		}
+		if n > 0 {
+			held++
+		}
+	}
+	if held > 4 {
+		t.Fatalf("%d conversations held, want at most 4", held)
+	}
+}
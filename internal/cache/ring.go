@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"github.com/google/uuid"
+)
+
+// ring holds the newest memories of a conversation up to its capacity, pushing
+// onto a full ring overwrites the oldest memory.
+type ring struct {
+	memories []Memory
+	start    int
+	count    int
+}
+
+func newRing(capacity int) *ring {
+	return &ring{memories: make([]Memory, capacity)}
+}
+
+func (r *ring) len() int {
+	return r.count
+}
+
+// at returns the i-th oldest memory.
+func (r *ring) at(i int) *Memory {
+	return &r.memories[(r.start+i)%len(r.memories)]
+}
+
+func (r *ring) push(memory Memory) {
+	if r.count < len(r.memories) {
+		*r.at(r.count) = memory
+		r.count++
+		return
+	}
+	r.memories[r.start] = memory
+	r.start = (r.start + 1) % len(r.memories)
+}
+
+// last copies the newest k memories, oldest first, or all of them when k is
+// not positive.
+func (r *ring) last(k int) []Memory {
+	if k <= 0 || k > r.count {
+		k = r.count
+	}
+	memories := make([]Memory, 0, k)
+	for i := r.count - k; i < r.count; i++ {
+		memories = append(memories, *r.at(i))
+	}
+	return memories
+}
+
+func (r *ring) dropLast(n int) {
+	n = min(max(n, 0), r.count)
+	for i := r.count - n; i < r.count; i++ {
+		*r.at(i) = Memory{}
+	}
+	r.count -= n
+}
+
+func (r *ring) find(memoryID uuid.UUID) *Memory {
+	for i := range r.count {
+		if r.at(i).ID == memoryID {
+			return r.at(i)
+		}
+	}
+	return nil
+}
+
+// remove shifts the newer memories back over the removed one.
+func (r *ring) remove(memoryID uuid.UUID) bool {
+	for i := range r.count {
+		if r.at(i).ID != memoryID {
+			continue
+		}
+		for j := i; j < r.count-1; j++ {
+			*r.at(j) = *r.at(j + 1)
+		}
+		r.dropLast(1)
+		return true
+	}
+	return false
+}
@@ -11,4 +11,13 @@ type Memory struct {
 	Query     string
 	Response  string
 	CreatedAt time.Time
+	Metadata  map[string]any
+	Tags      []string
+}
+
+// Stats summarizes the memories of a conversation, LastCreatedAt is zero when
+// it has none.
+type Stats struct {
+	Memories      int
+	LastCreatedAt time.Time
 }
@@ -2,7 +2,10 @@ package conversation
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/haren7/minimal-memory/internal/persistence"
@@ -10,9 +13,29 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	StatusActive   = persistence.ConversationStatusActive
+	StatusClosed   = persistence.ConversationStatusClosed
+	StatusArchived = persistence.ConversationStatusArchived
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
 type ConversationServiceInterface interface {
 	Create(ctx context.Context, agent, user string) (uuid.UUID, error)
 	Exists(ctx context.Context, conversationID uuid.UUID) (bool, error)
+	// Get returns persistence.ErrNotFound for unknown conversations.
+	Get(ctx context.Context, conversationID uuid.UUID) (Conversation, error)
+	// List returns up to limit conversations matching filter, newest first,
+	// starting after cursor. The returned cursor fetches the next page and is
+	// empty on the last one.
+	List(ctx context.Context, filter Filter, cursor string, limit int) ([]Conversation, string, error)
+	// SetStatus returns persistence.ErrNotFound for unknown conversations.
+	SetStatus(ctx context.Context, conversationID uuid.UUID, status string) error
+	// FetchByUser returns every conversation of a user, oldest first.
+	FetchByUser(ctx context.Context, user string) ([]Conversation, error)
+	// Delete removes a conversation, it returns persistence.ErrNotFound for unknown ones.
+	Delete(ctx context.Context, conversationID uuid.UUID) error
 }
 
 type ConversationService struct {
@@ -40,8 +63,86 @@ func (r *ConversationService) Create(ctx context.Context, agent, user string) (u
 
 func (r *ConversationService) Exists(ctx context.Context, conversationID uuid.UUID) (bool, error) {
 	_, err := r.conversationRepo.FetchOne(ctx, conversationID)
+	if errors.Is(err, persistence.ErrNotFound) {
+		return false, nil
+	}
 	if err != nil {
 		return false, fmt.Errorf("conversation: error checking if conversation exists, %w", err)
 	}
 	return true, nil
 }
+
+func (r *ConversationService) Get(ctx context.Context, conversationID uuid.UUID) (Conversation, error) {
+	row, err := r.conversationRepo.FetchOne(ctx, conversationID)
+	if err != nil {
+		return Conversation{}, fmt.Errorf("conversation: error fetching conversation, %w", err)
+	}
+	return toConversation(row), nil
+}
+
+// List pages by row id, which grows with every conversation created, so pages
+// stay stable while conversations are added or deleted.
+func (r *ConversationService) List(ctx context.Context, filter Filter, cursor string, limit int) ([]Conversation, string, error) {
+	beforeID := 0
+	if cursor != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			return nil, "", fmt.Errorf("conversation: error decoding cursor, %w", ErrInvalidCursor)
+		}
+		beforeID, err = strconv.Atoi(string(decoded))
+		if err != nil || beforeID <= 0 {
+			return nil, "", fmt.Errorf("conversation: error decoding cursor, %w", ErrInvalidCursor)
+		}
+	}
+	// one extra row tells whether there is a next page
+	rows, err := r.conversationRepo.FetchPage(ctx, persistence.ConversationFilter{
+		Agent:    filter.Agent,
+		User:     filter.User,
+		Statuses: filter.Statuses,
+	}, beforeID, limit+1)
+	if err != nil {
+		return nil, "", fmt.Errorf("conversation: error listing conversations, %w", err)
+	}
+	next := ""
+	if len(rows) > limit {
+		rows = rows[:limit]
+		next = base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(rows[limit-1].ID)))
+	}
+	conversations := make([]Conversation, len(rows))
+	for i, row := range rows {
+		conversations[i] = toConversation(row)
+	}
+	return conversations, next, nil
+}
+
+func (r *ConversationService) SetStatus(ctx context.Context, conversationID uuid.UUID, status string) error {
+	err := r.conversationRepo.UpdateStatus(ctx, conversationID, status)
+	if err != nil {
+		return fmt.Errorf("conversation: error setting status, %w", err)
+	}
+	return nil
+}
+
+func (r *ConversationService) FetchByUser(ctx context.Context, user string) ([]Conversation, error) {
+	rows, err := r.conversationRepo.FetchManyByUser(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("conversation: error fetching conversations, %w", err)
+	}
+	conversations := make([]Conversation, len(rows))
+	for i, row := range rows {
+		conversations[i] = toConversation(row)
+	}
+	return conversations, nil
+}
+
+func (r *ConversationService) Delete(ctx context.Context, conversationID uuid.UUID) error {
+	err := r.conversationRepo.DeleteOne(ctx, conversationID)
+	if err != nil {
+		return fmt.Errorf("conversation: error deleting conversation, %w", err)
+	}
+	return nil
+}
+
+func toConversation(row persistence.Conversation) Conversation {
+	return Conversation{ID: row.UUID, Agent: row.Agent, User: row.User, Status: row.Status, CreatedAt: row.CreatedAt}
+}
@@ -0,0 +1,69 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/haren7/minimal-memory/internal/persistence/rdbms"
+	"github.com/haren7/minimal-memory/internal/persistence/rdbms/rdbmstest"
+
+	"github.com/google/uuid"
+)
+
+func TestConversationServiceList(t *testing.T) {
+	ctx := context.Background()
+	duckdbClient := rdbmstest.NewDuckDBClient(t)
+	service := NewConversationService(rdbms.NewConversationRepo(duckdbClient))
+	var created []uuid.UUID
+	for _, participants := range [][2]string{{"support", "alice"}, {"support", "bob"}, {"sales", "alice"}, {"support", "alice"}, {"sales", "bob"}} {
+		conversationID, err := service.Create(ctx, participants[0], participants[1])
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		created = append(created, conversationID)
+	}
+	err := service.SetStatus(ctx, created[3], StatusArchived)
+	if err != nil {
+		t.Fatalf("SetStatus: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		expect []uuid.UUID
+	}{
+		{name: "every conversation newest first", expect: []uuid.UUID{created[4], created[3], created[2], created[1], created[0]}},
+		{name: "by agent and user", filter: Filter{Agent: "support", User: "alice"}, expect: []uuid.UUID{created[3], created[0]}},
+		{name: "by status", filter: Filter{User: "alice", Statuses: []string{StatusActive, StatusClosed}}, expect: []uuid.UUID{created[2], created[0]}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// pages of two so that every case crosses a page boundary or ends on one
+			var got []uuid.UUID
+			cursor := ""
+			for range len(created) {
+				conversations, next, err := service.List(ctx, test.filter, cursor, 2)
+				if err != nil {
+					t.Fatalf("List: %v", err)
+				}
+				for _, conversation := range conversations {
+					got = append(got, conversation.ID)
+				}
+				if next == "" {
+					break
+				}
+				cursor = next
+			}
+			if !slices.Equal(got, test.expect) {
+				t.Fatalf("List returned %v, want %v", got, test.expect)
+			}
+		})
+	}
+
+	_, _, err = service.List(ctx, Filter{}, "not a cursor", 2)
+	if !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("List with a bad cursor returned %v, want %v", err, ErrInvalidCursor)
+	}
+}
@@ -0,0 +1,22 @@
+package conversation
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Conversation struct {
+	ID        uuid.UUID
+	Agent     string
+	User      string
+	Status    string
+	CreatedAt time.Time
+}
+
+// Filter selects conversations, empty fields match every conversation.
+type Filter struct {
+	Agent    string
+	User     string
+	Statuses []string
+}
@@ -0,0 +1,246 @@
+package embedding
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/haren7/minimal-memory/internal/persistence"
+)
+
+const DefaultCacheSize = 1024
+
+type CacheStats struct {
+	// MemoryHits are texts served from the in-memory LRU.
+	MemoryHits int64
+	// StoreHits are texts served from the persistent tier after missing the LRU.
+	StoreHits int64
+	// Misses are texts that had to be embedded by the wrapped service.
+	Misses int64
+	// StoreErrors counts failed persistent tier reads and writes, a failing
+	// tier degrades to calling the wrapped service instead of failing the embed.
+	StoreErrors int64
+}
+
+type CachedServiceInterface interface {
+	ServiceInterface
+	Stats() CacheStats
+	// Purge drops every cached embedding from both tiers.
+	Purge(ctx context.Context) error
+}
+
+// CachedService puts an LRU tier and an optional persistent tier in front of
+// another embedding service. Entries are keyed by the model and the normalized
+// text, so switching models never serves stale vectors.
+type CachedService struct {
+	next        ServiceInterface
+	lru         *lruCache
+	store       persistence.EmbeddingCacheRepoInterface
+	memoryHits  atomic.Int64
+	storeHits   atomic.Int64
+	misses      atomic.Int64
+	storeErrors atomic.Int64
+}
+
+// NewCachedService wraps next with an LRU holding up to size embeddings, a
+// non-positive size uses DefaultCacheSize. store may be nil to keep the cache
+// in memory only.
+func NewCachedService(next ServiceInterface, size int, store persistence.EmbeddingCacheRepoInterface) CachedServiceInterface {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &CachedService{
+		next:  next,
+		lru:   newLRUCache(size),
+		store: store,
+	}
+}
+
+func (r *CachedService) Model() string {
+	return r.next.Model()
+}
+
+func (r *CachedService) Stats() CacheStats {
+	return CacheStats{
+		MemoryHits:  r.memoryHits.Load(),
+		StoreHits:   r.storeHits.Load(),
+		Misses:      r.misses.Load(),
+		StoreErrors: r.storeErrors.Load(),
+	}
+}
+
+// Purge empties the cache when a user is erased, keys are hashes of the texts
+// and retrieval queries are cached as well, so the entries derived from one
+// user cannot be picked out.
+func (r *CachedService) Purge(ctx context.Context) error {
+	r.lru.clear()
+	if r.store == nil {
+		return nil
+	}
+	_, err := r.store.DeleteAll(ctx)
+	if err != nil {
+		return fmt.Errorf("embedding: error purging cache, %w", err)
+	}
+	return nil
+}
+
+func (r *CachedService) EmbedOne(ctx context.Context, text string) (Embedding, error) {
+	embeddings, err := r.EmbedMany(ctx, []string{text})
+	if err != nil {
+		return Embedding{}, err
+	}
+	return embeddings[0], nil
+}
+
+func (r *CachedService) EmbedMany(ctx context.Context, texts []string) ([]Embedding, error) {
+	model := r.next.Model()
+	embeddings := make([]Embedding, len(texts))
+	keys := make([]string, len(texts))
+	// positions of every text still missing, grouped by key so duplicates are embedded once
+	missing := make(map[string][]int)
+	var missingKeys []string
+	for i, text := range texts {
+		keys[i] = cacheKey(model, text)
+		embedding, ok := r.lru.get(keys[i])
+		if ok {
+			r.memoryHits.Add(1)
+			embeddings[i] = embedding
+			continue
+		}
+		if _, seen := missing[keys[i]]; !seen {
+			missingKeys = append(missingKeys, keys[i])
+		}
+		missing[keys[i]] = append(missing[keys[i]], i)
+	}
+	if len(missingKeys) == 0 {
+		return embeddings, nil
+	}
+
+	if r.store != nil {
+		entries, err := r.store.FetchMany(ctx, missingKeys)
+		if err != nil {
+			r.storeErrors.Add(1)
+		}
+		var stillMissing []string
+		for _, key := range missingKeys {
+			entry, ok := entries[key]
+			if !ok {
+				stillMissing = append(stillMissing, key)
+				continue
+			}
+			embedding := Embedding{Model: entry.Model, Dim: len(entry.Vector), Vector: entry.Vector}
+			r.lru.put(key, embedding)
+			for _, i := range missing[key] {
+				r.storeHits.Add(1)
+				embeddings[i] = embedding
+			}
+		}
+		missingKeys = stillMissing
+		if len(missingKeys) == 0 {
+			return embeddings, nil
+		}
+	}
+
+	missingTexts := make([]string, len(missingKeys))
+	for i, key := range missingKeys {
+		missingTexts[i] = texts[missing[key][0]]
+	}
+	fresh, err := r.next.EmbedMany(ctx, missingTexts)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]persistence.EmbeddingCacheEntry, 0, len(missingKeys))
+	now := time.Now()
+	for i, key := range missingKeys {
+		r.lru.put(key, fresh[i])
+		for _, j := range missing[key] {
+			r.misses.Add(1)
+			embeddings[j] = fresh[i]
+		}
+		entries = append(entries, persistence.EmbeddingCacheEntry{
+			Key:       key,
+			Model:     fresh[i].Model,
+			Vector:    fresh[i].Vector,
+			CreatedAt: now,
+		})
+	}
+	if r.store != nil {
+		err := r.store.InsertMany(ctx, entries)
+		if err != nil {
+			r.storeErrors.Add(1)
+		}
+	}
+	return embeddings, nil
+}
+
+// normalizeText folds case and whitespace so that queries differing only in
+// formatting share a cache entry.
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), " ")
+}
+
+func cacheKey(model string, text string) string {
+	sum := sha256.Sum256([]byte(model + "\x00" + normalizeText(text)))
+	return hex.EncodeToString(sum[:])
+}
+
+type lruEntry struct {
+	key       string
+	embedding Embedding
+}
+
+type lruCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+func newLRUCache(capacity int) *lruCache {
+	return &lruCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element, capacity),
+	}
+}
+
+func (r *lruCache) get(key string) (Embedding, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	element, ok := r.items[key]
+	if !ok {
+		return Embedding{}, false
+	}
+	r.order.MoveToFront(element)
+	return element.Value.(*lruEntry).embedding, true
+}
+
+func (r *lruCache) put(key string, embedding Embedding) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	element, ok := r.items[key]
+	if ok {
+		element.Value.(*lruEntry).embedding = embedding
+		r.order.MoveToFront(element)
+		return
+	}
+	r.items[key] = r.order.PushFront(&lruEntry{key: key, embedding: embedding})
+	if r.order.Len() > r.capacity {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (r *lruCache) clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.order.Init()
+	r.items = make(map[string]*list.Element, r.capacity)
+}
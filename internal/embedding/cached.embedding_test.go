@@ -0,0 +1,135 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/haren7/minimal-memory/internal/persistence"
+)
+
+type memoryCacheStore struct {
+	entries map[string]persistence.EmbeddingCacheEntry
+}
+
+func (r *memoryCacheStore) FetchMany(ctx context.Context, keys []string) (map[string]persistence.EmbeddingCacheEntry, error) {
+	entries := make(map[string]persistence.EmbeddingCacheEntry)
+	for _, key := range keys {
+		if entry, ok := r.entries[key]; ok {
+			entries[key] = entry
+		}
+	}
+	return entries, nil
+}
+
+func (r *memoryCacheStore) InsertMany(ctx context.Context, entries []persistence.EmbeddingCacheEntry) error {
+	for _, entry := range entries {
+		r.entries[entry.Key] = entry
+	}
+	return nil
+}
+
+func (r *memoryCacheStore) Count(ctx context.Context) (int, error) {
+	return len(r.entries), nil
+}
+
+func (r *memoryCacheStore) DeleteAll(ctx context.Context) (int, error) {
+	deleted := len(r.entries)
+	r.entries = make(map[string]persistence.EmbeddingCacheEntry)
+	return deleted, nil
+}
+
+type failingCacheStore struct{}
+
+func (r failingCacheStore) FetchMany(ctx context.Context, keys []string) (map[string]persistence.EmbeddingCacheEntry, error) {
+	return nil, errors.New("store down")
+}
+
+func (r failingCacheStore) InsertMany(ctx context.Context, entries []persistence.EmbeddingCacheEntry) error {
+	return errors.New("store down")
+}
+
+func (r failingCacheStore) Count(ctx context.Context) (int, error) {
+	return 0, errors.New("store down")
+}
+
+func (r failingCacheStore) DeleteAll(ctx context.Context) (int, error) {
+	return 0, errors.New("store down")
+}
+
+// countingService counts the texts it is asked to embed.
+type countingService struct {
+	ServiceInterface
+	embedded int
+}
+
+func (r *countingService) EmbedMany(ctx context.Context, texts []string) ([]Embedding, error) {
+	r.embedded += len(texts)
+	return r.ServiceInterface.EmbedMany(ctx, texts)
+}
+
+func TestCachedServiceStats(t *testing.T) {
+	tests := []struct {
+		name         string
+		size         int
+		store        persistence.EmbeddingCacheRepoInterface
+		calls        [][]string
+		wantStats    CacheStats
+		wantEmbedded int
+	}{
+		{name: "repeated text hits the lru", calls: [][]string{{"a"}, {"a"}}, wantStats: CacheStats{MemoryHits: 1, Misses: 1}, wantEmbedded: 1},
+		{name: "formatting is normalized", calls: [][]string{{"Hello  World"}, {"hello world"}}, wantStats: CacheStats{MemoryHits: 1, Misses: 1}, wantEmbedded: 1},
+		{name: "duplicates in a batch are embedded once", calls: [][]string{{"a", "a", "b"}}, wantStats: CacheStats{Misses: 3}, wantEmbedded: 2},
+		{name: "store serves what the lru evicted", size: 1, store: &memoryCacheStore{entries: make(map[string]persistence.EmbeddingCacheEntry)},
+			calls: [][]string{{"a"}, {"b"}, {"a"}}, wantStats: CacheStats{StoreHits: 1, Misses: 2}, wantEmbedded: 2},
+		{name: "failing store falls back to the provider", store: failingCacheStore{},
+			calls: [][]string{{"a"}}, wantStats: CacheStats{Misses: 1, StoreErrors: 2}, wantEmbedded: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			next := &countingService{ServiceInterface: NewHashedService(8)}
+			cached := NewCachedService(next, test.size, test.store)
+			for _, texts := range test.calls {
+				embeddings, err := cached.EmbedMany(ctx, texts)
+				if err != nil {
+					t.Fatalf("EmbedMany: %v", err)
+				}
+				if len(embeddings) != len(texts) {
+					t.Fatalf("EmbedMany returned %d embeddings for %d texts", len(embeddings), len(texts))
+				}
+			}
+			if stats := cached.Stats(); stats != test.wantStats {
+				t.Fatalf("Stats() = %+v, want %+v", stats, test.wantStats)
+			}
+			if next.embedded != test.wantEmbedded {
+				t.Fatalf("provider embedded %d texts, want %d", next.embedded, test.wantEmbedded)
+			}
+		})
+	}
+}
+
+func TestCachedServicePurge(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryCacheStore{entries: make(map[string]persistence.EmbeddingCacheEntry)}
+	cached := NewCachedService(NewHashedService(8), 0, store)
+	_, err := cached.EmbedOne(ctx, "erased text")
+	if err != nil {
+		t.Fatalf("EmbedOne: %v", err)
+	}
+	err = cached.Purge(ctx)
+	if err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if len(store.entries) != 0 {
+		t.Fatalf("store holds %d entries after Purge, want 0", len(store.entries))
+	}
+	_, err = cached.EmbedOne(ctx, "erased text")
+	if err != nil {
+		t.Fatalf("EmbedOne: %v", err)
+	}
+	stats := cached.Stats()
+	if stats.Misses != 2 || stats.MemoryHits != 0 || stats.StoreHits != 0 {
+		t.Fatalf("Stats() = %+v, want 2 misses and no hits", stats)
+	}
+}
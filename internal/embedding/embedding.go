@@ -1,8 +1,43 @@
 package embedding
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 type ServiceInterface interface {
 	EmbedOne(ctx context.Context, text string) (Embedding, error)
 	EmbedMany(ctx context.Context, texts []string) ([]Embedding, error)
+	Model() string
+}
+
+type Provider string
+
+const (
+	ProviderOpenAI Provider = "openai"
+	ProviderLocal  Provider = "local"
+)
+
+type Config struct {
+	Provider Provider
+	OpenAI   OpenAIConfig
+	// LocalDim is the vector size of the local hashed embedder, defaults to DefaultHashedDim.
+	LocalDim int
+}
+
+// NewService builds the embedding service selected by config.Provider, an
+// empty provider selects openai.
+func NewService(config Config) (ServiceInterface, error) {
+	switch config.Provider {
+	case ProviderOpenAI, "":
+		// self hosted compatible endpoints often run without authentication
+		if config.OpenAI.ApiKey == "" && config.OpenAI.BaseURL == "" {
+			return nil, fmt.Errorf("embedding: openai api key is required")
+		}
+		return NewOpenAIService(config.OpenAI), nil
+	case ProviderLocal:
+		return NewHashedService(config.LocalDim), nil
+	default:
+		return nil, fmt.Errorf("embedding: unknown provider %q", config.Provider)
+	}
 }
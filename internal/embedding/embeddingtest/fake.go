@@ -0,0 +1,58 @@
+// Package embeddingtest provides a deterministic embedding service for tests.
+package embeddingtest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/haren7/minimal-memory/internal/embedding"
+)
+
+const FakeModel = "fake"
+
+// FakeService returns the vectors it was built with, so tests control exactly
+// which memories are near a query. Unknown texts are an error.
+type FakeService struct {
+	dim     int
+	vectors map[string][]float32
+}
+
+func NewFakeService(vectors map[string][]float32) *FakeService {
+	dim := 0
+	for _, vector := range vectors {
+		dim = len(vector)
+		break
+	}
+	return &FakeService{
+		dim:     dim,
+		vectors: vectors,
+	}
+}
+
+func (r *FakeService) Model() string {
+	return FakeModel
+}
+
+func (r *FakeService) EmbedOne(ctx context.Context, text string) (embedding.Embedding, error) {
+	vector, ok := r.vectors[text]
+	if !ok {
+		return embedding.Embedding{}, fmt.Errorf("embeddingtest: no vector for %q", text)
+	}
+	return embedding.Embedding{
+		Model:  FakeModel,
+		Dim:    r.dim,
+		Vector: append([]float32(nil), vector...),
+	}, nil
+}
+
+func (r *FakeService) EmbedMany(ctx context.Context, texts []string) ([]embedding.Embedding, error) {
+	embeddings := make([]embedding.Embedding, 0, len(texts))
+	for _, text := range texts {
+		embedding, err := r.EmbedOne(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, embedding)
+	}
+	return embeddings, nil
+}
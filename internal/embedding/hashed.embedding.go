@@ -0,0 +1,104 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const DefaultHashedDim = 512
+
+const (
+	wordWeight    = 1.0
+	bigramWeight  = 0.7
+	trigramWeight = 0.3
+)
+
+// HashedService embeds text fully in-process with the hashing trick: word
+// unigrams, word bigrams and character trigrams are hashed into a fixed number
+// of signed buckets and the result is L2 normalized. It needs no model files
+// or network access, which makes it suitable for tests and air-gapped setups,
+// at the cost of only capturing lexical rather than semantic similarity.
+type HashedService struct {
+	dim int
+}
+
+func NewHashedService(dim int) ServiceInterface {
+	if dim <= 0 {
+		dim = DefaultHashedDim
+	}
+	return &HashedService{
+		dim: dim,
+	}
+}
+
+func (r *HashedService) Model() string {
+	return fmt.Sprintf("local-hashed@%d", r.dim)
+}
+
+func (r *HashedService) EmbedOne(ctx context.Context, text string) (Embedding, error) {
+	return Embedding{
+		Model:  r.Model(),
+		Dim:    r.dim,
+		Vector: r.embed(text),
+	}, nil
+}
+
+func (r *HashedService) EmbedMany(ctx context.Context, texts []string) ([]Embedding, error) {
+	embeddings := make([]Embedding, 0, len(texts))
+	for _, text := range texts {
+		embeddings = append(embeddings, Embedding{
+			Model:  r.Model(),
+			Dim:    r.dim,
+			Vector: r.embed(text),
+		})
+	}
+	return embeddings, nil
+}
+
+func (r *HashedService) embed(text string) []float32 {
+	vector := make([]float32, r.dim)
+	words := strings.FieldsFunc(strings.ToLower(text), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsNumber(c)
+	})
+	for i, word := range words {
+		r.add(vector, "w:"+word, wordWeight)
+		if i > 0 {
+			r.add(vector, "b:"+words[i-1]+" "+word, bigramWeight)
+		}
+		padded := []rune("#" + word + "#")
+		for j := 0; j+3 <= len(padded); j++ {
+			r.add(vector, "c:"+string(padded[j:j+3]), trigramWeight)
+		}
+	}
+	normalize(vector)
+	return vector
+}
+
+func (r *HashedService) add(vector []float32, feature string, weight float32) {
+	hash := fnv.New64a()
+	hash.Write([]byte(feature))
+	sum := hash.Sum64()
+	// the top bit picks the sign so that colliding features tend to cancel out
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	vector[sum%uint64(r.dim)] += weight
+}
+
+func normalize(vector []float32) {
+	var sum float64
+	for _, value := range vector {
+		sum += float64(value) * float64(value)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+}
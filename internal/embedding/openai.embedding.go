@@ -3,32 +3,80 @@ package embedding
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/sashabaranov/go-openai"
 )
 
+const DefaultOpenAIModel = string(openai.SmallEmbedding3)
+
+// OpenAIConfig points the service at any OpenAI compatible embeddings endpoint,
+// such as Azure OpenAI, vLLM, Ollama or LocalAI.
+type OpenAIConfig struct {
+	ApiKey string
+	// BaseURL overrides the default OpenAI endpoint, e.g. http://localhost:11434/v1.
+	BaseURL string
+	// Model defaults to DefaultOpenAIModel.
+	Model string
+	// Dimensions asks the endpoint to shorten embeddings, zero keeps the model default.
+	Dimensions   int
+	Organization string
+	// Azure switches to Azure OpenAI authentication, BaseURL must then be the resource endpoint.
+	Azure bool
+}
+
 type OpenAIService struct {
 	openAiClient *openai.Client
+	model        string
+	dimensions   int
 }
 
-func NewOpenAIService(apiKey string) ServiceInterface {
-	openAiClient := openai.NewClient(apiKey)
+func NewOpenAIService(config OpenAIConfig) ServiceInterface {
+	var clientConfig openai.ClientConfig
+	if config.Azure {
+		clientConfig = openai.DefaultAzureConfig(config.ApiKey, config.BaseURL)
+	} else {
+		clientConfig = openai.DefaultConfig(config.ApiKey)
+		if config.BaseURL != "" {
+			clientConfig.BaseURL = config.BaseURL
+		}
+	}
+	clientConfig.OrgID = config.Organization
+	clientConfig.HTTPClient = &http.Client{Transport: &retryAfterTransport{next: http.DefaultTransport}}
+	model := config.Model
+	if model == "" {
+		model = DefaultOpenAIModel
+	}
 	return &OpenAIService{
-		openAiClient: openAiClient,
+		openAiClient: openai.NewClientWithConfig(clientConfig),
+		model:        model,
+		dimensions:   config.Dimensions,
 	}
 }
 
+func (r *OpenAIService) Model() string {
+	if r.dimensions > 0 {
+		return fmt.Sprintf("%s@%d", r.model, r.dimensions)
+	}
+	return r.model
+}
+
 func (r *OpenAIService) EmbedOne(ctx context.Context, text string) (Embedding, error) {
 	input := openai.EmbeddingRequest{
-		Input: text,
-		Model: openai.SmallEmbedding3,
+		Input:      text,
+		Model:      openai.EmbeddingModel(r.model),
+		Dimensions: r.dimensions,
 	}
 	response, err := r.openAiClient.CreateEmbeddings(ctx, input)
 	if err != nil {
 		return Embedding{}, fmt.Errorf("openai: error embedding one, %w", err)
 	}
+	if len(response.Data) == 0 {
+		return Embedding{}, fmt.Errorf("openai: error embedding one, empty response")
+	}
 	embedding := response.Data[0].Embedding
 	return Embedding{
+		Model:  r.Model(),
 		Dim:    len(embedding),
 		Vector: embedding,
 	}, nil
@@ -36,19 +84,27 @@ func (r *OpenAIService) EmbedOne(ctx context.Context, text string) (Embedding, e
 
 func (r *OpenAIService) EmbedMany(ctx context.Context, texts []string) ([]Embedding, error) {
 	input := openai.EmbeddingRequest{
-		Input: texts,
-		Model: openai.SmallEmbedding3,
+		Input:      texts,
+		Model:      openai.EmbeddingModel(r.model),
+		Dimensions: r.dimensions,
 	}
 	resp, err := r.openAiClient.CreateEmbeddings(ctx, input)
 	if err != nil {
 		return []Embedding{}, fmt.Errorf("openai: error embedding many, %w", err)
 	}
-	var embeddings []Embedding
+	if len(resp.Data) != len(texts) {
+		return []Embedding{}, fmt.Errorf("openai: error embedding many, got %d embeddings for %d texts", len(resp.Data), len(texts))
+	}
+	embeddings := make([]Embedding, len(texts))
 	for _, emb := range resp.Data {
-		embeddings = append(embeddings, Embedding{
+		if emb.Index < 0 || emb.Index >= len(texts) {
+			return []Embedding{}, fmt.Errorf("openai: error embedding many, index %d out of range", emb.Index)
+		}
+		embeddings[emb.Index] = Embedding{
+			Model:  r.Model(),
 			Dim:    len(emb.Embedding),
 			Vector: emb.Embedding,
-		})
+		}
 	}
 	return embeddings, nil
 }
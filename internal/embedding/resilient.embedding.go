@@ -0,0 +1,409 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+var ErrCircuitOpen = errors.New("embedding: circuit breaker is open")
+
+const (
+	DefaultMaxRetries       = 3
+	DefaultInitialBackoff   = 500 * time.Millisecond
+	DefaultMaxBackoff       = 30 * time.Second
+	DefaultBreakerThreshold = 5
+	DefaultBreakerCooldown  = 30 * time.Second
+)
+
+// ResilienceConfig is the retry, rate limit and circuit breaker policy of a
+// ResilientService. Zero values select the defaults, rate limits are off
+// unless set.
+type ResilienceConfig struct {
+	// MaxRetries is the number of retries after the first attempt, negative disables retries.
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// RequestsPerMinute and TokensPerMinute throttle calls before they are sent.
+	RequestsPerMinute int
+	TokensPerMinute   int
+	// BreakerThreshold is the number of consecutive failed calls that opens the
+	// breaker, negative disables it.
+	BreakerThreshold int
+	// BreakerCooldown is how long an open breaker fails fast before letting a trial call through.
+	BreakerCooldown time.Duration
+}
+
+// ResilientService retries transient failures of another embedding service
+// with exponential backoff and full jitter, honours a Retry-After up to
+// MaxBackoff and gives up on a longer one, throttles
+// requests and tokens per minute and stops calling a failing provider for a
+// while once the circuit breaker opens.
+type ResilientService struct {
+	next           ServiceInterface
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	requests       *tokenBucket
+	tokens         *tokenBucket
+	breaker        *circuitBreaker
+}
+
+func NewResilientService(next ServiceInterface, config ResilienceConfig) ServiceInterface {
+	maxRetries := config.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	initialBackoff := config.InitialBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = DefaultInitialBackoff
+	}
+	maxBackoff := config.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+	threshold := config.BreakerThreshold
+	if threshold == 0 {
+		threshold = DefaultBreakerThreshold
+	}
+	cooldown := config.BreakerCooldown
+	if cooldown <= 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+	var breaker *circuitBreaker
+	if threshold > 0 {
+		breaker = &circuitBreaker{threshold: threshold, cooldown: cooldown}
+	}
+	return &ResilientService{
+		next:           next,
+		maxRetries:     maxRetries,
+		initialBackoff: initialBackoff,
+		maxBackoff:     maxBackoff,
+		requests:       newTokenBucket(config.RequestsPerMinute),
+		tokens:         newTokenBucket(config.TokensPerMinute),
+		breaker:        breaker,
+	}
+}
+
+func (r *ResilientService) Model() string {
+	return r.next.Model()
+}
+
+func (r *ResilientService) EmbedOne(ctx context.Context, text string) (Embedding, error) {
+	var embedding Embedding
+	err := r.call(ctx, estimateTokens(text), func(ctx context.Context) error {
+		var err error
+		embedding, err = r.next.EmbedOne(ctx, text)
+		return err
+	})
+	return embedding, err
+}
+
+func (r *ResilientService) EmbedMany(ctx context.Context, texts []string) ([]Embedding, error) {
+	tokens := 0
+	for _, text := range texts {
+		tokens += estimateTokens(text)
+	}
+	var embeddings []Embedding
+	err := r.call(ctx, tokens, func(ctx context.Context) error {
+		var err error
+		embeddings, err = r.next.EmbedMany(ctx, texts)
+		return err
+	})
+	return embeddings, err
+}
+
+func (r *ResilientService) call(ctx context.Context, tokens int, attempt func(ctx context.Context) error) error {
+	if !r.breaker.allow() {
+		return ErrCircuitOpen
+	}
+	var err error
+	for i := 0; ; i++ {
+		err = r.requests.wait(ctx, 1)
+		if err == nil {
+			err = r.tokens.wait(ctx, tokens)
+		}
+		if err != nil {
+			r.breaker.release()
+			return fmt.Errorf("embedding: error waiting for rate limit, %w", err)
+		}
+		attemptCtx, retryAfter := withRetryAfter(ctx)
+		err = attempt(attemptCtx)
+		if err == nil {
+			r.breaker.success()
+			return nil
+		}
+		if i >= r.maxRetries || !isRetryable(ctx, err) {
+			break
+		}
+		delay := r.backoff(i)
+		if after := retryAfter.get(); after > 0 {
+			if after > r.maxBackoff {
+				// waiting that long would block callers without a deadline, leave the decision to them
+				err = fmt.Errorf("embedding: provider asked to retry after %s, longer than the max backoff of %s, %w", after, r.maxBackoff, err)
+				break
+			}
+			delay = after
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			// the caller gave up, which says nothing about the provider
+			timer.Stop()
+			r.breaker.release()
+			return fmt.Errorf("embedding: gave up retrying, %w", errors.Join(err, ctx.Err()))
+		case <-timer.C:
+		}
+	}
+	switch {
+	case ctx.Err() != nil:
+		r.breaker.release()
+	case isRetryable(ctx, err):
+		// only provider side failures count towards opening the breaker
+		r.breaker.failure()
+	default:
+		r.breaker.success()
+	}
+	return err
+}
+
+// backoff returns a full jitter delay for the given retry, uniformly drawn from
+// [0, min(maxBackoff, initialBackoff*2^retry)).
+func (r *ResilientService) backoff(retry int) time.Duration {
+	ceiling := r.initialBackoff << min(retry, 30)
+	if ceiling <= 0 || ceiling > r.maxBackoff {
+		ceiling = r.maxBackoff
+	}
+	return time.Duration(rand.Int64N(int64(ceiling)) + 1)
+}
+
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return isRetryableStatus(apiErr.HTTPStatusCode)
+	}
+	var requestErr *openai.RequestError
+	if errors.As(err, &requestErr) {
+		return isRetryableStatus(requestErr.HTTPStatusCode)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusRequestTimeout || status >= http.StatusInternalServerError
+}
+
+// estimateTokens approximates the tokenizer with the usual four characters per token.
+func estimateTokens(text string) int {
+	return len(text)/4 + 1
+}
+
+type retryAfterKey struct{}
+
+// retryAfterHolder carries the Retry-After header of a failed response from the
+// http transport back up to the retry loop, the openai client drops headers
+// when it turns a response into an error.
+type retryAfterHolder struct {
+	mu    sync.Mutex
+	delay time.Duration
+}
+
+func withRetryAfter(ctx context.Context) (context.Context, *retryAfterHolder) {
+	holder := &retryAfterHolder{}
+	return context.WithValue(ctx, retryAfterKey{}, holder), holder
+}
+
+func (r *retryAfterHolder) get() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.delay
+}
+
+func (r *retryAfterHolder) set(delay time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delay = delay
+}
+
+// retryAfterTransport records the Retry-After header of throttled responses in
+// the retryAfterHolder of the request context, if there is one.
+type retryAfterTransport struct {
+	next http.RoundTripper
+}
+
+func (r *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	holder, ok := req.Context().Value(retryAfterKey{}).(*retryAfterHolder)
+	if !ok || resp.StatusCode < http.StatusBadRequest {
+		return resp, nil
+	}
+	delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if ok {
+		holder.set(delay)
+	}
+	return resp, nil
+}
+
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(value)
+	if err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	at, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	delay := at.Sub(now)
+	if delay < 0 {
+		delay = 0
+	}
+	return delay, true
+}
+
+// tokenBucket refills perMinute tokens every minute, a nil bucket never waits.
+type tokenBucket struct {
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	rate     float64 // tokens per second
+	last     time.Time
+}
+
+func newTokenBucket(perMinute int) *tokenBucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &tokenBucket{
+		capacity: float64(perMinute),
+		tokens:   float64(perMinute),
+		rate:     float64(perMinute) / 60,
+		last:     time.Now(),
+	}
+}
+
+func (r *tokenBucket) wait(ctx context.Context, n int) error {
+	if r == nil {
+		return nil
+	}
+	for {
+		delay := r.reserve(float64(n))
+		if delay == 0 {
+			return nil
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes n tokens and returns zero, or returns how long to wait until
+// they are available. Requests larger than the bucket only wait for a full bucket.
+func (r *tokenBucket) reserve(n float64) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.tokens = min(r.capacity, r.tokens+now.Sub(r.last).Seconds()*r.rate)
+	r.last = now
+	n = min(n, r.capacity)
+	if r.tokens >= n {
+		r.tokens -= n
+		return 0
+	}
+	return time.Duration((n - r.tokens) / r.rate * float64(time.Second))
+}
+
+// circuitBreaker opens after threshold consecutive failures and lets a single
+// trial call through once cooldown has passed, a nil breaker is always closed.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+func (r *circuitBreaker) allow() bool {
+	if r == nil {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures < r.threshold {
+		return true
+	}
+	if time.Now().Before(r.openUntil) || r.trial {
+		return false
+	}
+	r.trial = true
+	return true
+}
+
+func (r *circuitBreaker) success() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures = 0
+	r.trial = false
+}
+
+// release ends a trial call that neither failed nor succeeded, e.g. because the caller gave up.
+func (r *circuitBreaker) release() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trial = false
+}
+
+func (r *circuitBreaker) failure() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures++
+	r.trial = false
+	if r.failures >= r.threshold {
+		r.openUntil = time.Now().Add(r.cooldown)
+	}
+}
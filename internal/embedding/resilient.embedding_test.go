@@ -0,0 +1,271 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+var (
+	errUnavailable = &openai.APIError{HTTPStatusCode: http.StatusServiceUnavailable}
+	errBadRequest  = &openai.APIError{HTTPStatusCode: http.StatusBadRequest}
+)
+
+// scriptedService fails with the next error of errs on each call, a nil error
+// or running out of errs embeds the text.
+type scriptedService struct {
+	mu    sync.Mutex
+	errs  []error
+	calls int
+}
+
+func (r *scriptedService) Model() string {
+	return "scripted"
+}
+
+func (r *scriptedService) EmbedOne(ctx context.Context, text string) (Embedding, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	if len(r.errs) > 0 {
+		err := r.errs[0]
+		r.errs = r.errs[1:]
+		if err != nil {
+			return Embedding{}, err
+		}
+	}
+	return Embedding{Model: "scripted", Dim: 1, Vector: []float32{1}}, nil
+}
+
+func (r *scriptedService) EmbedMany(ctx context.Context, texts []string) ([]Embedding, error) {
+	embeddings := make([]Embedding, len(texts))
+	for i, text := range texts {
+		embedding, err := r.EmbedOne(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		embeddings[i] = embedding
+	}
+	return embeddings, nil
+}
+
+func (r *scriptedService) script(errs ...error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = errs
+	r.calls = 0
+}
+
+func TestResilientServiceRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		errs       []error
+		wantCalls  int
+		wantErr    error
+	}{
+		{name: "transient failures are retried", maxRetries: 3, errs: []error{errUnavailable, errUnavailable}, wantCalls: 3},
+		{name: "gives up after max retries", maxRetries: 2, errs: []error{errUnavailable, errUnavailable, errUnavailable, errUnavailable}, wantCalls: 3, wantErr: errUnavailable},
+		{name: "client errors are not retried", maxRetries: 3, errs: []error{errBadRequest}, wantCalls: 1, wantErr: errBadRequest},
+		{name: "retries disabled", maxRetries: -1, errs: []error{errUnavailable}, wantCalls: 1, wantErr: errUnavailable},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			next := &scriptedService{errs: test.errs}
+			service := NewResilientService(next, ResilienceConfig{
+				MaxRetries:       test.maxRetries,
+				InitialBackoff:   time.Millisecond,
+				MaxBackoff:       time.Millisecond,
+				BreakerThreshold: -1,
+			})
+			_, err := service.EmbedOne(context.Background(), "text")
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("EmbedOne returned %v, want %v", err, test.wantErr)
+			}
+			if next.calls != test.wantCalls {
+				t.Fatalf("provider called %d times, want %d", next.calls, test.wantCalls)
+			}
+		})
+	}
+}
+
+// retryAfterService fails its first call as a throttled response carrying a
+// Retry-After header would.
+type retryAfterService struct {
+	scriptedService
+	retryAfter time.Duration
+}
+
+func (r *retryAfterService) EmbedOne(ctx context.Context, text string) (Embedding, error) {
+	if r.calls == 0 {
+		ctx.Value(retryAfterKey{}).(*retryAfterHolder).set(r.retryAfter)
+	}
+	return r.scriptedService.EmbedOne(ctx, text)
+}
+
+func TestResilientServiceRetryAfter(t *testing.T) {
+	const maxBackoff = 50 * time.Millisecond
+	tests := []struct {
+		name       string
+		retryAfter time.Duration
+		wantCalls  int
+		wantErr    error
+		wantWait   time.Duration
+	}{
+		{name: "waits the retry after", retryAfter: 20 * time.Millisecond, wantCalls: 2, wantWait: 20 * time.Millisecond},
+		{name: "gives up on a retry after past the max backoff", retryAfter: time.Hour, wantCalls: 1, wantErr: errUnavailable},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			next := &retryAfterService{scriptedService: scriptedService{errs: []error{errUnavailable}}, retryAfter: test.retryAfter}
+			service := NewResilientService(next, ResilienceConfig{
+				MaxRetries:       3,
+				InitialBackoff:   time.Millisecond,
+				MaxBackoff:       maxBackoff,
+				BreakerThreshold: -1,
+			})
+			start := time.Now()
+			_, err := service.EmbedOne(context.Background(), "text")
+			waited := time.Since(start)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("EmbedOne returned %v, want %v", err, test.wantErr)
+			}
+			if next.calls != test.wantCalls {
+				t.Fatalf("provider called %d times, want %d", next.calls, test.wantCalls)
+			}
+			if waited < test.wantWait || waited > test.wantWait+maxBackoff {
+				t.Fatalf("EmbedOne took %s, want about %s", waited, test.wantWait)
+			}
+		})
+	}
+}
+
+func TestResilientServiceCircuitBreaker(t *testing.T) {
+	const cooldown = 20 * time.Millisecond
+	type step struct {
+		errs      []error
+		wait      time.Duration
+		wantCalls int
+		wantErr   error
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{name: "opens after threshold failures", steps: []step{
+			{errs: []error{errUnavailable}, wantCalls: 1, wantErr: errUnavailable},
+			{errs: []error{errUnavailable}, wantCalls: 1, wantErr: errUnavailable},
+			{wantCalls: 0, wantErr: ErrCircuitOpen},
+		}},
+		{name: "client errors keep it closed", steps: []step{
+			{errs: []error{errBadRequest}, wantCalls: 1, wantErr: errBadRequest},
+			{errs: []error{errBadRequest}, wantCalls: 1, wantErr: errBadRequest},
+			{wantCalls: 1},
+		}},
+		{name: "successful trial closes it", steps: []step{
+			{errs: []error{errUnavailable}, wantCalls: 1, wantErr: errUnavailable},
+			{errs: []error{errUnavailable}, wantCalls: 1, wantErr: errUnavailable},
+			{wait: cooldown, wantCalls: 1},
+			{errs: []error{errUnavailable}, wantCalls: 1, wantErr: errUnavailable},
+			{wantCalls: 1},
+		}},
+		{name: "failed trial opens it again", steps: []step{
+			{errs: []error{errUnavailable}, wantCalls: 1, wantErr: errUnavailable},
+			{errs: []error{errUnavailable}, wantCalls: 1, wantErr: errUnavailable},
+			{wait: cooldown, errs: []error{errUnavailable}, wantCalls: 1, wantErr: errUnavailable},
+			{wantCalls: 0, wantErr: ErrCircuitOpen},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			next := &scriptedService{}
+			service := NewResilientService(next, ResilienceConfig{
+				MaxRetries:       -1,
+				BreakerThreshold: 2,
+				BreakerCooldown:  cooldown,
+			})
+			for i, step := range test.steps {
+				time.Sleep(step.wait)
+				next.script(step.errs...)
+				_, err := service.EmbedOne(context.Background(), "text")
+				if !errors.Is(err, step.wantErr) {
+					t.Fatalf("step %d: EmbedOne returned %v, want %v", i, err, step.wantErr)
+				}
+				if next.calls != step.wantCalls {
+					t.Fatalf("step %d: provider called %d times, want %d", i, next.calls, step.wantCalls)
+				}
+			}
+		})
+	}
+}
+
+func TestResilientServiceHalfOpenAllowsOneTrial(t *testing.T) {
+	breaker := &circuitBreaker{threshold: 1, cooldown: time.Millisecond}
+	breaker.failure()
+	if breaker.allow() {
+		t.Fatalf("open breaker allowed a call")
+	}
+	time.Sleep(2 * time.Millisecond)
+	if !breaker.allow() {
+		t.Fatalf("breaker did not allow a trial after its cooldown")
+	}
+	if breaker.allow() {
+		t.Fatalf("breaker allowed a second call during its trial")
+	}
+	breaker.release()
+	if !breaker.allow() {
+		t.Fatalf("breaker did not allow a new trial once the first was released")
+	}
+}
+
+func TestResilientServiceCancelledDuringBackoff(t *testing.T) {
+	tests := []struct {
+		name string
+		// open fails the breaker open and waits out its cooldown first, so the
+		// cancelled call is the half-open trial
+		open bool
+	}{
+		{name: "closed"},
+		{name: "half-open", open: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			const cooldown = 10 * time.Millisecond
+			next := &scriptedService{}
+			service := NewResilientService(next, ResilienceConfig{
+				MaxRetries:       1,
+				InitialBackoff:   time.Hour,
+				MaxBackoff:       time.Hour,
+				BreakerThreshold: 1,
+				BreakerCooldown:  cooldown,
+			})
+			if test.open {
+				service.(*ResilientService).breaker.failure()
+				time.Sleep(2 * cooldown)
+			}
+
+			next.script(errUnavailable)
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+			_, err := service.EmbedOne(ctx, "text")
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("EmbedOne returned %v, want the deadline", err)
+			}
+
+			// giving up is not a provider failure, the breaker lets the next call through
+			next.script()
+			_, err = service.EmbedOne(context.Background(), "text")
+			if err != nil {
+				t.Fatalf("EmbedOne after the cancelled call returned %v", err)
+			}
+			if next.calls != 1 {
+				t.Fatalf("provider called %d times, want 1", next.calls)
+			}
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package embedding
 
 type Embedding struct {
+	// Model identifies the service that produced the vector, vectors are only
+	// comparable when their models match.
+	Model  string
 	Dim    int
 	Vector []float32
 }
@@ -0,0 +1,360 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	memoryv1 "github.com/haren7/minimal-memory/api/memory/v1"
+	"github.com/haren7/minimal-memory/clients"
+	"github.com/haren7/minimal-memory/types"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type SemanticMemoryServer struct {
+	memoryv1.UnimplementedSemanticMemoryServiceServer
+	semanticClient clients.SemanticMemoryClient
+}
+
+func NewSemanticMemoryServer(semanticClient clients.SemanticMemoryClient) memoryv1.SemanticMemoryServiceServer {
+	return &SemanticMemoryServer{
+		semanticClient: semanticClient,
+	}
+}
+
+// NamespaceInterceptor puts the namespace named by the x-namespace metadata of
+// a call in its context, calls naming none go to the default namespace.
+func NamespaceInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	values := metadata.ValueFromIncomingContext(ctx, clients.NamespaceMetadataKey)
+	if len(values) > 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: %s names more than one namespace", clients.ErrInvalidInput, clients.NamespaceMetadataKey)
+	}
+	if len(values) == 1 {
+		ctx = clients.WithNamespace(ctx, values[0])
+	}
+	return handler(ctx, req)
+}
+
+func (r *SemanticMemoryServer) RegisterConversation(ctx context.Context, req *memoryv1.RegisterConversationRequest) (*memoryv1.RegisterConversationResponse, error) {
+	output, err := r.semanticClient.RegisterConversation(ctx, types.RegisterConversationInput{
+		Agent: req.GetAgent(),
+		User:  req.GetUser(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &memoryv1.RegisterConversationResponse{
+		ConversationId: output.ConversationID,
+	}, nil
+}
+
+func (r *SemanticMemoryServer) Store(ctx context.Context, req *memoryv1.StoreSemanticMemoryRequest) (*memoryv1.StoreSemanticMemoryResponse, error) {
+	output, err := r.semanticClient.Store(ctx, types.StoreSemanticMemoryInput{
+		ConversationID: req.GetConversationId(),
+		Query:          req.GetQuery(),
+		Response:       req.GetResponse(),
+		Metadata:       fromStruct(req.GetMetadata()),
+		Tags:           req.GetTags(),
+		Scopes:         req.GetScopes(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &memoryv1.StoreSemanticMemoryResponse{
+		MemoryId: output.MemoryID,
+	}, nil
+}
+
+func (r *SemanticMemoryServer) StoreMany(ctx context.Context, req *memoryv1.StoreManySemanticMemoryRequest) (*memoryv1.StoreManySemanticMemoryResponse, error) {
+	memories := make([]types.SemanticMemoryEntry, len(req.GetMemories()))
+	for i, entry := range req.GetMemories() {
+		memories[i] = types.SemanticMemoryEntry{
+			Query:    entry.GetQuery(),
+			Response: entry.GetResponse(),
+			Metadata: fromStruct(entry.GetMetadata()),
+			Tags:     entry.GetTags(),
+		}
+		if entry.GetCreatedAt() != nil {
+			memories[i].CreatedAt = entry.GetCreatedAt().AsTime()
+		}
+	}
+	output, err := r.semanticClient.StoreMany(ctx, types.StoreManySemanticMemoryInput{
+		ConversationID: req.GetConversationId(),
+		Memories:       memories,
+		Scopes:         req.GetScopes(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &memoryv1.StoreManySemanticMemoryResponse{
+		MemoryIds: output.MemoryIDs,
+	}, nil
+}
+
+func (r *SemanticMemoryServer) Retrieve(ctx context.Context, req *memoryv1.RetrieveSemanticMemoryRequest) (*memoryv1.RetrieveSemanticMemoryResponse, error) {
+	output, err := r.semanticClient.Retrieve(ctx, types.RetrieveSemanticMemoryInput{
+		ConversationID: req.GetConversationId(),
+		Query:          req.GetQuery(),
+		TopK:           int(req.GetTopK()),
+		MinScore:       req.MinScore,
+		Filter: types.MemoryFilter{
+			Tags:     req.GetFilter().GetTags(),
+			Metadata: fromStruct(req.GetFilter().GetMetadata()),
+		},
+		Scope:          req.GetScope(),
+		MaxTokens:      int(req.GetMaxTokens()),
+		TruncateOldest: req.GetTruncateOldest(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	var memories []*memoryv1.Memory
+	for _, memory := range output.Memories {
+		metadata, err := toStruct(memory.Metadata)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error encoding metadata of memory %s", memory.ID)
+		}
+		memories = append(memories, &memoryv1.Memory{
+			Id:        memory.ID,
+			Query:     memory.Query,
+			Response:  memory.Response,
+			CreatedAt: timestamppb.New(memory.CreatedAt),
+			Metadata:  metadata,
+			Tags:      memory.Tags,
+			Truncated: memory.Truncated,
+		})
+	}
+	var similarMemories []*memoryv1.SemanticMemory
+	for _, memory := range output.SimilarMemories {
+		metadata, err := toStruct(memory.Metadata)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "error encoding metadata of memory %s", memory.ID)
+		}
+		similarMemories = append(similarMemories, &memoryv1.SemanticMemory{
+			Id:             memory.ID,
+			ConversationId: memory.ConversationID,
+			Query:          memory.Query,
+			Response:       memory.Response,
+			CreatedAt:      timestamppb.New(memory.CreatedAt),
+			Metadata:       metadata,
+			Tags:           memory.Tags,
+			Rank:           int32(memory.Rank),
+			Score:          memory.Score,
+			Distance:       memory.Distance,
+		})
+	}
+	return &memoryv1.RetrieveSemanticMemoryResponse{
+		Memories:        memories,
+		SimilarMemories: similarMemories,
+	}, nil
+}
+
+func (r *SemanticMemoryServer) Update(ctx context.Context, req *memoryv1.UpdateSemanticMemoryRequest) (*memoryv1.UpdateSemanticMemoryResponse, error) {
+	output, err := r.semanticClient.Update(ctx, types.UpdateSemanticMemoryInput{
+		ConversationID: req.GetConversationId(),
+		MemoryID:       req.GetMemoryId(),
+		Query:          req.GetQuery(),
+		Response:       req.GetResponse(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &memoryv1.UpdateSemanticMemoryResponse{
+		MemoryId: output.MemoryID,
+	}, nil
+}
+
+func (r *SemanticMemoryServer) Delete(ctx context.Context, req *memoryv1.DeleteSemanticMemoryRequest) (*memoryv1.DeleteSemanticMemoryResponse, error) {
+	output, err := r.semanticClient.Delete(ctx, types.DeleteSemanticMemoryInput{
+		ConversationID: req.GetConversationId(),
+		MemoryID:       req.GetMemoryId(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &memoryv1.DeleteSemanticMemoryResponse{
+		MemoryId: output.MemoryID,
+	}, nil
+}
+
+func (r *SemanticMemoryServer) Reindex(ctx context.Context, req *memoryv1.ReindexSemanticMemoryRequest) (*memoryv1.ReindexSemanticMemoryResponse, error) {
+	output, err := r.semanticClient.Reindex(ctx, types.ReindexSemanticMemoryInput{
+		ConversationID: req.GetConversationId(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	conversations := make([]*memoryv1.ReindexedConversation, len(output.Conversations))
+	for i, conversation := range output.Conversations {
+		conversations[i] = &memoryv1.ReindexedConversation{
+			ConversationId: conversation.ConversationID,
+			Memories:       int32(conversation.Memories),
+		}
+	}
+	return &memoryv1.ReindexSemanticMemoryResponse{
+		Conversations: conversations,
+	}, nil
+}
+
+func (r *SemanticMemoryServer) EmbeddingCacheStats(ctx context.Context, req *memoryv1.EmbeddingCacheStatsRequest) (*memoryv1.EmbeddingCacheStatsResponse, error) {
+	output, err := r.semanticClient.EmbeddingCacheStats(ctx, types.EmbeddingCacheStatsInput{})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &memoryv1.EmbeddingCacheStatsResponse{
+		Enabled:     output.Enabled,
+		MemoryHits:  output.MemoryHits,
+		StoreHits:   output.StoreHits,
+		Misses:      output.Misses,
+		StoreErrors: output.StoreErrors,
+	}, nil
+}
+
+func (r *SemanticMemoryServer) EraseUser(ctx context.Context, req *memoryv1.EraseUserRequest) (*memoryv1.EraseUserResponse, error) {
+	output, err := r.semanticClient.EraseUser(ctx, types.EraseUserInput{
+		User:            req.GetUser(),
+		ConversationIDs: req.GetConversationIds(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	conversations := make([]*memoryv1.ErasedConversation, len(output.Conversations))
+	for i, conversation := range output.Conversations {
+		conversations[i] = &memoryv1.ErasedConversation{
+			ConversationId: conversation.ConversationID,
+			Agent:          conversation.Agent,
+			Memories:       int32(conversation.Memories),
+			VectorMemories: int32(conversation.VectorMemories),
+			CacheEntries:   int32(conversation.CacheEntries),
+		}
+		if !conversation.CreatedAt.IsZero() {
+			conversations[i].CreatedAt = timestamppb.New(conversation.CreatedAt)
+		}
+	}
+	return &memoryv1.EraseUserResponse{
+		User:          output.User,
+		Conversations: conversations,
+		ErasedAt:      timestamppb.New(output.ErasedAt),
+	}, nil
+}
+
+func (r *SemanticMemoryServer) ListConversations(ctx context.Context, req *memoryv1.ListConversationsRequest) (*memoryv1.ListConversationsResponse, error) {
+	output, err := r.semanticClient.ListConversations(ctx, types.ListConversationsInput{
+		Agent:  req.GetAgent(),
+		User:   req.GetUser(),
+		Status: req.GetStatus(),
+		Limit:  int(req.GetLimit()),
+		Cursor: req.GetCursor(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	conversations := make([]*memoryv1.Conversation, len(output.Conversations))
+	for i, conversation := range output.Conversations {
+		conversations[i] = toConversationMessage(conversation)
+	}
+	return &memoryv1.ListConversationsResponse{
+		Conversations: conversations,
+		NextCursor:    output.NextCursor,
+	}, nil
+}
+
+func (r *SemanticMemoryServer) GetConversation(ctx context.Context, req *memoryv1.GetConversationRequest) (*memoryv1.GetConversationResponse, error) {
+	output, err := r.semanticClient.GetConversation(ctx, types.GetConversationInput{
+		ConversationID: req.GetConversationId(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &memoryv1.GetConversationResponse{
+		Conversation: toConversationMessage(output.Conversation),
+	}, nil
+}
+
+func (r *SemanticMemoryServer) CloseConversation(ctx context.Context, req *memoryv1.CloseConversationRequest) (*memoryv1.CloseConversationResponse, error) {
+	output, err := r.semanticClient.CloseConversation(ctx, types.CloseConversationInput{
+		ConversationID: req.GetConversationId(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &memoryv1.CloseConversationResponse{
+		ConversationId: output.ConversationID,
+		Status:         output.Status,
+	}, nil
+}
+
+func (r *SemanticMemoryServer) ArchiveConversation(ctx context.Context, req *memoryv1.ArchiveConversationRequest) (*memoryv1.ArchiveConversationResponse, error) {
+	output, err := r.semanticClient.ArchiveConversation(ctx, types.ArchiveConversationInput{
+		ConversationID: req.GetConversationId(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &memoryv1.ArchiveConversationResponse{
+		ConversationId: output.ConversationID,
+		Status:         output.Status,
+	}, nil
+}
+
+func (r *SemanticMemoryServer) DeleteConversation(ctx context.Context, req *memoryv1.DeleteConversationRequest) (*memoryv1.DeleteConversationResponse, error) {
+	output, err := r.semanticClient.DeleteConversation(ctx, types.DeleteConversationInput{
+		ConversationID: req.GetConversationId(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &memoryv1.DeleteConversationResponse{
+		ConversationId: output.ConversationID,
+		Memories:       int32(output.Memories),
+		VectorMemories: int32(output.VectorMemories),
+		CacheEntries:   int32(output.CacheEntries),
+	}, nil
+}
+
+func toConversationMessage(conversation types.Conversation) *memoryv1.Conversation {
+	return &memoryv1.Conversation{
+		Id:             conversation.ID,
+		Agent:          conversation.Agent,
+		User:           conversation.User,
+		Status:         conversation.Status,
+		CreatedAt:      timestamppb.New(conversation.CreatedAt),
+		MemoryCount:    int32(conversation.MemoryCount),
+		LastActivityAt: timestamppb.New(conversation.LastActivityAt),
+	}
+}
+
+// fromStruct returns nil for an unset struct so that it matches an omitted field.
+func fromStruct(metadata *structpb.Struct) map[string]any {
+	if metadata == nil {
+		return nil
+	}
+	return metadata.AsMap()
+}
+
+func toStruct(metadata map[string]any) (*structpb.Struct, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	return structpb.NewStruct(metadata)
+}
+
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, clients.ErrInvalidInput):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, clients.ErrConversationNotFound), errors.Is(err, clients.ErrMemoryNotFound), errors.Is(err, clients.ErrNamespaceNotServed):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, clients.ErrConversationClosed):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, clients.ErrUnsupported):
+		return status.Error(codes.Unimplemented, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
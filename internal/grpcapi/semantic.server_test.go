@@ -0,0 +1,229 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+
+	memoryv1 "github.com/haren7/minimal-memory/api/memory/v1"
+	"github.com/haren7/minimal-memory/clients"
+	"github.com/haren7/minimal-memory/types"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves a fresh database over an in-memory listener and returns
+// the gRPC client of it. Only the acme namespace is served besides the default.
+func newTestClient(t *testing.T) clients.SemanticMemoryClient {
+	t.Helper()
+	tenants, err := clients.NewTenants(clients.TenantsConfig{
+		DuckDBPath: filepath.Join(t.TempDir(), "memory.db"),
+		Namespaces: []string{"acme"},
+		Semantic: clients.SemanticMemoryClientConfig{
+			ContextWindowSize: 10,
+			EmbeddingProvider: clients.EmbeddingProviderLocal,
+			LocalEmbeddingDim: 8,
+			VectorBackend:     clients.VectorBackendBruteForce,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewTenants: %v", err)
+	}
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.UnaryInterceptor(NamespaceInterceptor))
+	memoryv1.RegisterSemanticMemoryServiceServer(server, NewSemanticMemoryServer(tenants.SemanticByContext()))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return clients.NewSemanticMemoryGRPCClient(conn)
+}
+
+func TestSemanticMemoryServerRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	registered, err := client.RegisterConversation(ctx, types.RegisterConversationInput{Agent: "agent", User: "user"})
+	if err != nil {
+		t.Fatalf("RegisterConversation: %v", err)
+	}
+	stored, err := client.Store(ctx, types.StoreSemanticMemoryInput{
+		ConversationID: registered.ConversationID,
+		Query:          "my dog is called rex",
+		Response:       "noted",
+		Metadata:       map[string]any{"role": "user", "turn": float64(1)},
+		Tags:           []string{"pets"},
+	})
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	batch, err := client.StoreMany(ctx, types.StoreManySemanticMemoryInput{
+		ConversationID: registered.ConversationID,
+		Memories: []types.SemanticMemoryEntry{
+			{Query: "rex likes long walks", Response: "noted", Tags: []string{"pets"}},
+			{Query: "i prefer tea to coffee", Response: "noted"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("StoreMany: %v", err)
+	}
+	if len(batch.MemoryIDs) != 2 {
+		t.Fatalf("StoreMany returned %d ids, want 2", len(batch.MemoryIDs))
+	}
+
+	retrieved, err := client.Retrieve(ctx, types.RetrieveSemanticMemoryInput{
+		ConversationID: registered.ConversationID,
+		Query:          "dog",
+		TopK:           2,
+		Filter:         types.MemoryFilter{Metadata: map[string]any{"role": "user"}},
+	})
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if len(retrieved.Memories) != 1 || len(retrieved.SimilarMemories) != 1 {
+		t.Fatalf("Retrieve returned %d recent and %d similar memories, want 1 and 1", len(retrieved.Memories), len(retrieved.SimilarMemories))
+	}
+	similar := retrieved.SimilarMemories[0]
+	if similar.ID != stored.MemoryID || similar.ConversationID != registered.ConversationID || similar.Rank != 1 || similar.Metadata["turn"] != float64(1) || len(similar.Tags) != 1 {
+		t.Fatalf("Retrieve returned %+v, want the stored memory with its metadata and tags", similar)
+	}
+	retrieved, err = client.Retrieve(ctx, types.RetrieveSemanticMemoryInput{ConversationID: registered.ConversationID, Query: "dog", TopK: 2})
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if len(retrieved.Memories) != 3 || len(retrieved.SimilarMemories) != 2 {
+		t.Fatalf("Retrieve returned %d recent and %d similar memories, want 3 and 2", len(retrieved.Memories), len(retrieved.SimilarMemories))
+	}
+
+	_, err = client.Update(ctx, types.UpdateSemanticMemoryInput{ConversationID: registered.ConversationID, MemoryID: stored.MemoryID, Query: "my dog is called max", Response: "noted"})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	_, err = client.Delete(ctx, types.DeleteSemanticMemoryInput{ConversationID: registered.ConversationID, MemoryID: batch.MemoryIDs[1]})
+	if err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	reindexed, err := client.Reindex(ctx, types.ReindexSemanticMemoryInput{ConversationID: registered.ConversationID})
+	if err != nil {
+		t.Fatalf("Reindex: %v", err)
+	}
+	if len(reindexed.Conversations) != 1 || reindexed.Conversations[0].Memories != 2 {
+		t.Fatalf("Reindex returned %+v, want one conversation of 2 memories", reindexed.Conversations)
+	}
+	conversation, err := client.GetConversation(ctx, types.GetConversationInput{ConversationID: registered.ConversationID})
+	if err != nil {
+		t.Fatalf("GetConversation: %v", err)
+	}
+	if conversation.Conversation.MemoryCount != 2 || conversation.Conversation.User != "user" {
+		t.Fatalf("GetConversation returned %+v, want user's conversation with 2 memories", conversation.Conversation)
+	}
+	listed, err := client.ListConversations(ctx, types.ListConversationsInput{User: "user"})
+	if err != nil {
+		t.Fatalf("ListConversations: %v", err)
+	}
+	if len(listed.Conversations) != 1 {
+		t.Fatalf("ListConversations returned %d conversations, want 1", len(listed.Conversations))
+	}
+	cacheStats, err := client.EmbeddingCacheStats(ctx, types.EmbeddingCacheStatsInput{})
+	if err != nil {
+		t.Fatalf("EmbeddingCacheStats: %v", err)
+	}
+	if !cacheStats.Enabled {
+		t.Fatalf("EmbeddingCacheStats returned %+v, want an enabled cache", cacheStats)
+	}
+	_, err = client.CloseConversation(ctx, types.CloseConversationInput{ConversationID: registered.ConversationID})
+	if err != nil {
+		t.Fatalf("CloseConversation: %v", err)
+	}
+	erased, err := client.EraseUser(ctx, types.EraseUserInput{User: "user"})
+	if err != nil {
+		t.Fatalf("EraseUser: %v", err)
+	}
+	if len(erased.Conversations) != 1 || erased.Conversations[0].Memories != 2 {
+		t.Fatalf("EraseUser returned %+v, want one conversation of 2 memories", erased.Conversations)
+	}
+}
+
+func TestSemanticMemoryServerErrors(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t)
+	registered, err := client.RegisterConversation(ctx, types.RegisterConversationInput{Agent: "agent", User: "user"})
+	if err != nil {
+		t.Fatalf("RegisterConversation: %v", err)
+	}
+	closed, err := client.RegisterConversation(ctx, types.RegisterConversationInput{Agent: "agent", User: "user"})
+	if err != nil {
+		t.Fatalf("RegisterConversation: %v", err)
+	}
+	_, err = client.CloseConversation(ctx, types.CloseConversationInput{ConversationID: closed.ConversationID})
+	if err != nil {
+		t.Fatalf("CloseConversation: %v", err)
+	}
+	unknown := "00000000-0000-0000-0000-000000000000"
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		call func(ctx context.Context) error
+		want error
+	}{
+		{name: "missing agent", ctx: ctx, call: func(ctx context.Context) error {
+			_, err := client.RegisterConversation(ctx, types.RegisterConversationInput{User: "user"})
+			return err
+		}, want: clients.ErrInvalidInput},
+		{name: "top k above the maximum", ctx: ctx, call: func(ctx context.Context) error {
+			_, err := client.Retrieve(ctx, types.RetrieveSemanticMemoryInput{ConversationID: registered.ConversationID, Query: "q", TopK: 1 << 32})
+			return err
+		}, want: clients.ErrInvalidInput},
+		{name: "max tokens above int32", ctx: ctx, call: func(ctx context.Context) error {
+			_, err := client.Retrieve(ctx, types.RetrieveSemanticMemoryInput{ConversationID: registered.ConversationID, Query: "q", MaxTokens: 1 << 32})
+			return err
+		}, want: clients.ErrInvalidInput},
+		{name: "limit above int32", ctx: ctx, call: func(ctx context.Context) error {
+			_, err := client.ListConversations(ctx, types.ListConversationsInput{User: "user", Limit: 1 << 32})
+			return err
+		}, want: clients.ErrInvalidInput},
+		{name: "unknown conversation", ctx: ctx, call: func(ctx context.Context) error {
+			_, err := client.Retrieve(ctx, types.RetrieveSemanticMemoryInput{ConversationID: unknown})
+			return err
+		}, want: clients.ErrConversationNotFound},
+		{name: "unknown memory", ctx: ctx, call: func(ctx context.Context) error {
+			_, err := client.Delete(ctx, types.DeleteSemanticMemoryInput{ConversationID: registered.ConversationID, MemoryID: unknown})
+			return err
+		}, want: clients.ErrMemoryNotFound},
+		{name: "closed conversation", ctx: ctx, call: func(ctx context.Context) error {
+			_, err := client.Store(ctx, types.StoreSemanticMemoryInput{ConversationID: closed.ConversationID, Query: "q", Response: "r"})
+			return err
+		}, want: clients.ErrConversationClosed},
+		{name: "namespace not served", ctx: metadata.AppendToOutgoingContext(ctx, clients.NamespaceMetadataKey, "globex"), call: func(ctx context.Context) error {
+			_, err := client.ListConversations(ctx, types.ListConversationsInput{})
+			return err
+		}, want: clients.ErrNamespaceNotServed},
+		{name: "conversation of another namespace", ctx: metadata.AppendToOutgoingContext(ctx, clients.NamespaceMetadataKey, "acme"), call: func(ctx context.Context) error {
+			_, err := client.GetConversation(ctx, types.GetConversationInput{ConversationID: registered.ConversationID})
+			return err
+		}, want: clients.ErrConversationNotFound},
+		{name: "two namespaces", ctx: metadata.AppendToOutgoingContext(ctx, clients.NamespaceMetadataKey, "acme", clients.NamespaceMetadataKey, "globex"), call: func(ctx context.Context) error {
+			_, err := client.ListConversations(ctx, types.ListConversationsInput{})
+			return err
+		}, want: clients.ErrInvalidInput},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.call(test.ctx)
+			if !errors.Is(err, test.want) {
+				t.Fatalf("got error %v, want %v", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/haren7/minimal-memory/types"
+)
+
+// conversationClient is the part of the semantic and short-term clients that
+// manages conversations, both are served by the same handlers.
+type conversationClient interface {
+	ListConversations(ctx context.Context, input types.ListConversationsInput) (types.ListConversationsOutput, error)
+	GetConversation(ctx context.Context, input types.GetConversationInput) (types.GetConversationOutput, error)
+	CloseConversation(ctx context.Context, input types.CloseConversationInput) (types.CloseConversationOutput, error)
+	ArchiveConversation(ctx context.Context, input types.ArchiveConversationInput) (types.ArchiveConversationOutput, error)
+	DeleteConversation(ctx context.Context, input types.DeleteConversationInput) (types.DeleteConversationOutput, error)
+}
+
+func listConversations(client conversationClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		limit, err := queryInt(req, "limit")
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		query := req.URL.Query()
+		output, err := client.ListConversations(req.Context(), types.ListConversationsInput{
+			Agent:  query.Get("agent"),
+			User:   query.Get("user"),
+			Status: query.Get("status"),
+			Limit:  limit,
+			Cursor: query.Get("cursor"),
+		})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, output)
+	}
+}
+
+func getConversation(client conversationClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		output, err := client.GetConversation(req.Context(), types.GetConversationInput{ConversationID: req.PathValue("conversationID")})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, output)
+	}
+}
+
+func closeConversation(client conversationClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		output, err := client.CloseConversation(req.Context(), types.CloseConversationInput{ConversationID: req.PathValue("conversationID")})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, output)
+	}
+}
+
+func archiveConversation(client conversationClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		output, err := client.ArchiveConversation(req.Context(), types.ArchiveConversationInput{ConversationID: req.PathValue("conversationID")})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, output)
+	}
+}
+
+func deleteConversation(client conversationClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		output, err := client.DeleteConversation(req.Context(), types.DeleteConversationInput{ConversationID: req.PathValue("conversationID")})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, output)
+	}
+}
@@ -0,0 +1,203 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/haren7/minimal-memory/clients"
+	"github.com/haren7/minimal-memory/types"
+)
+
+const maxRequestBodyBytes = 1 << 20
+
+// maxBatchRequestBodyBytes is higher for batch endpoints used to backfill history.
+const maxBatchRequestBodyBytes = 32 << 20
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type Server struct {
+	semanticClient  clients.SemanticMemoryClient
+	shortTermClient clients.ShortTermMemoryClient
+}
+
+func NewHandler(semanticClient clients.SemanticMemoryClient, shortTermClient clients.ShortTermMemoryClient) http.Handler {
+	server := &Server{
+		semanticClient:  semanticClient,
+		shortTermClient: shortTermClient,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", server.health)
+	mux.HandleFunc("POST /v1/semantic/conversations", server.registerSemanticConversation)
+	mux.HandleFunc("POST /v1/semantic/conversations/{conversationID}/memories", server.storeSemanticMemory)
+	mux.HandleFunc("GET /v1/semantic/conversations/{conversationID}/memories", server.retrieveSemanticMemory)
+	mux.HandleFunc("POST /v1/semantic/conversations/{conversationID}/memories/batch", server.storeManySemanticMemories)
+	mux.HandleFunc("PUT /v1/semantic/conversations/{conversationID}/memories/{memoryID}", server.updateSemanticMemory)
+	mux.HandleFunc("DELETE /v1/semantic/conversations/{conversationID}/memories/{memoryID}", server.deleteSemanticMemory)
+	mux.HandleFunc("POST /v1/semantic/reindex", server.reindexSemanticMemory)
+	mux.HandleFunc("POST /v1/semantic/conversations/{conversationID}/reindex", server.reindexSemanticMemory)
+	mux.HandleFunc("GET /v1/semantic/embedding-cache", server.embeddingCacheStats)
+	mux.HandleFunc("POST /v1/short-term/conversations", server.registerShortTermConversation)
+	mux.HandleFunc("POST /v1/short-term/conversations/{conversationID}/memories", server.storeShortTermMemory)
+	mux.HandleFunc("GET /v1/short-term/conversations/{conversationID}/memories", server.retrieveShortTermMemory)
+	mux.HandleFunc("PUT /v1/short-term/conversations/{conversationID}/memories/{memoryID}", server.updateShortTermMemory)
+	mux.HandleFunc("DELETE /v1/short-term/conversations/{conversationID}/memories/{memoryID}", server.deleteShortTermMemory)
+	mux.HandleFunc("DELETE /v1/users/{user}", server.eraseUser)
+	for prefix, client := range map[string]conversationClient{"semantic": semanticClient, "short-term": shortTermClient} {
+		mux.HandleFunc("GET /v1/"+prefix+"/conversations", listConversations(client))
+		mux.HandleFunc("GET /v1/"+prefix+"/conversations/{conversationID}", getConversation(client))
+		mux.HandleFunc("POST /v1/"+prefix+"/conversations/{conversationID}/close", closeConversation(client))
+		mux.HandleFunc("POST /v1/"+prefix+"/conversations/{conversationID}/archive", archiveConversation(client))
+		mux.HandleFunc("DELETE /v1/"+prefix+"/conversations/{conversationID}", deleteConversation(client))
+	}
+	return mux
+}
+
+// NamespaceHandler serves next with the namespace of each request in its
+// context, named by the X-Namespace header or a /tenants/{namespace} prefix of
+// the path, which is stripped. Requests naming none go to the default namespace.
+func NamespaceHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		namespace := req.Header.Get(clients.NamespaceHeader)
+		rest, prefixed := strings.CutPrefix(req.URL.Path, "/tenants/")
+		if prefixed {
+			pathNamespace, path, _ := strings.Cut(rest, "/")
+			if pathNamespace == "" || (namespace != "" && namespace != pathNamespace) {
+				writeError(w, fmt.Errorf("%w: the path and the %s header name different namespaces", clients.ErrInvalidInput, clients.NamespaceHeader))
+				return
+			}
+			namespace = pathNamespace
+			url := *req.URL
+			url.Path = "/" + path
+			url.RawPath = ""
+			req = req.Clone(req.Context())
+			req.URL = &url
+		}
+		if namespace != "" {
+			req = req.WithContext(clients.WithNamespace(req.Context(), namespace))
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (r *Server) health(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func decodeJSON(w http.ResponseWriter, req *http.Request, dst any) error {
+	return decodeJSONWithLimit(w, req, dst, maxRequestBodyBytes)
+}
+
+func decodeJSONWithLimit(w http.ResponseWriter, req *http.Request, dst any, limit int64) error {
+	req.Body = http.MaxBytesReader(w, req.Body, limit)
+	decoder := json.NewDecoder(req.Body)
+	err := decoder.Decode(dst)
+	if err != nil {
+		return fmt.Errorf("%w: malformed json body, %v", clients.ErrInvalidInput, err)
+	}
+	return nil
+}
+
+func queryInt(req *http.Request, key string) (int, error) {
+	value := req.URL.Query().Get(key)
+	if value == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("%w: %s must be a non-negative integer", clients.ErrInvalidInput, key)
+	}
+	return parsed, nil
+}
+
+// queryFloat returns nil when key is not set.
+func queryFloat(req *http.Request, key string) (*float32, error) {
+	value := req.URL.Query().Get(key)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 32)
+	if err != nil || math.IsNaN(parsed) {
+		return nil, fmt.Errorf("%w: %s must be a number", clients.ErrInvalidInput, key)
+	}
+	score := float32(parsed)
+	return &score, nil
+}
+
+func queryBool(req *http.Request, key string) (bool, error) {
+	value := req.URL.Query().Get(key)
+	if value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s must be a boolean", clients.ErrInvalidInput, key)
+	}
+	return parsed, nil
+}
+
+// queryFilter reads repeated tag parameters and metadata.KEY=VALUE pairs.
+// Values that parse as JSON keep their type, so metadata.turn=3 matches the
+// number 3 and metadata.role=user the string "user", quote a value to match
+// it as a string.
+func queryFilter(req *http.Request) (types.MemoryFilter, error) {
+	var filter types.MemoryFilter
+	for key, values := range req.URL.Query() {
+		if key == "tag" {
+			filter.Tags = append(filter.Tags, values...)
+			continue
+		}
+		name, ok := strings.CutPrefix(key, "metadata.")
+		if !ok {
+			continue
+		}
+		if name == "" || len(values) > 1 {
+			return types.MemoryFilter{}, fmt.Errorf("%w: %s must name one metadata key once", clients.ErrInvalidInput, key)
+		}
+		if filter.Metadata == nil {
+			filter.Metadata = make(map[string]any)
+		}
+		var value any
+		err := json.Unmarshal([]byte(values[0]), &value)
+		if err != nil {
+			value = values[0]
+		}
+		filter.Metadata[name] = value
+	}
+	return filter, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		log.Printf("[ERROR] writeJSON: Failed to encode response body - %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	writeJSON(w, statusFromError(err), ErrorResponse{Error: err.Error()})
+}
+
+func statusFromError(err error) int {
+	switch {
+	case errors.Is(err, clients.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, clients.ErrConversationNotFound), errors.Is(err, clients.ErrMemoryNotFound), errors.Is(err, clients.ErrNamespaceNotServed):
+		return http.StatusNotFound
+	case errors.Is(err, clients.ErrConversationClosed):
+		return http.StatusConflict
+	case errors.Is(err, clients.ErrUnsupported):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,113 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/haren7/minimal-memory/clients"
+)
+
+// newTestHandler serves both clients of the default namespace of a fresh
+// database, embedding locally into brute-force indexes.
+func newTestHandler(t *testing.T) http.Handler {
+	t.Helper()
+	tenants, err := clients.NewTenants(clients.TenantsConfig{
+		DuckDBPath: filepath.Join(t.TempDir(), "memory.db"),
+		Semantic: clients.SemanticMemoryClientConfig{
+			ContextWindowSize: 10,
+			EmbeddingProvider: clients.EmbeddingProviderLocal,
+			LocalEmbeddingDim: 8,
+			VectorBackend:     clients.VectorBackendBruteForce,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewTenants: %v", err)
+	}
+	return NewHandler(tenants.SemanticByContext(), tenants.ShortTermByContext())
+}
+
+// serve sends body as json and decodes the response into out unless it is nil.
+func serve(t *testing.T, handler http.Handler, method, path string, body, out any) int {
+	t.Helper()
+	var encoded bytes.Buffer
+	if body != nil {
+		err := json.NewEncoder(&encoded).Encode(body)
+		if err != nil {
+			t.Fatalf("encoding body: %v", err)
+		}
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, path, &encoded))
+	if out != nil && recorder.Code < http.StatusBadRequest {
+		err := json.NewDecoder(recorder.Body).Decode(out)
+		if err != nil {
+			t.Fatalf("decoding %s %s response: %v", method, path, err)
+		}
+	}
+	return recorder.Code
+}
+
+func TestStatusFromError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{err: fmt.Errorf("%w: top k must be at most 1000", clients.ErrInvalidInput), want: http.StatusBadRequest},
+		{err: clients.ErrConversationNotFound, want: http.StatusNotFound},
+		{err: clients.ErrMemoryNotFound, want: http.StatusNotFound},
+		{err: clients.ErrNamespaceNotServed, want: http.StatusNotFound},
+		{err: clients.ErrConversationClosed, want: http.StatusConflict},
+		{err: clients.ErrUnsupported, want: http.StatusNotImplemented},
+		{err: errors.New("error retrieving memories"), want: http.StatusInternalServerError},
+	}
+	for _, test := range tests {
+		if got := statusFromError(test.err); got != test.want {
+			t.Fatalf("statusFromError(%q) = %d, want %d", test.err, got, test.want)
+		}
+	}
+}
+
+func TestNamespaceHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		header        string
+		wantStatus    int
+		wantNamespace string
+		wantPath      string
+	}{
+		{name: "default namespace", path: "/v1/semantic/conversations", wantStatus: http.StatusOK, wantPath: "/v1/semantic/conversations"},
+		{name: "header", path: "/v1/semantic/conversations", header: "acme", wantStatus: http.StatusOK, wantNamespace: "acme", wantPath: "/v1/semantic/conversations"},
+		{name: "path prefix is stripped", path: "/tenants/acme/v1/semantic/conversations", wantStatus: http.StatusOK, wantNamespace: "acme", wantPath: "/v1/semantic/conversations"},
+		{name: "header and path agree", path: "/tenants/acme/mcp", header: "acme", wantStatus: http.StatusOK, wantNamespace: "acme", wantPath: "/mcp"},
+		{name: "header and path disagree", path: "/tenants/acme/v1/semantic/conversations", header: "globex", wantStatus: http.StatusBadRequest},
+		{name: "empty path namespace", path: "/tenants//v1/semantic/conversations", wantStatus: http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var namespace, path string
+			handler := NamespaceHandler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				namespace = clients.NamespaceFromContext(req.Context())
+				path = req.URL.Path
+			}))
+			req := httptest.NewRequest(http.MethodGet, test.path, nil)
+			if test.header != "" {
+				req.Header.Set(clients.NamespaceHeader, test.header)
+			}
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			if recorder.Code != test.wantStatus {
+				t.Fatalf("status %d, want %d", recorder.Code, test.wantStatus)
+			}
+			if namespace != test.wantNamespace || path != test.wantPath {
+				t.Fatalf("served namespace %q at %q, want %q at %q", namespace, path, test.wantNamespace, test.wantPath)
+			}
+		})
+	}
+}
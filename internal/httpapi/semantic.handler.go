@@ -0,0 +1,151 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/haren7/minimal-memory/types"
+)
+
+func (r *Server) registerSemanticConversation(w http.ResponseWriter, req *http.Request) {
+	var input types.RegisterConversationInput
+	err := decodeJSON(w, req, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	output, err := r.semanticClient.RegisterConversation(req.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, output)
+}
+
+func (r *Server) storeSemanticMemory(w http.ResponseWriter, req *http.Request) {
+	var input types.StoreSemanticMemoryInput
+	err := decodeJSON(w, req, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	input.ConversationID = req.PathValue("conversationID")
+	output, err := r.semanticClient.Store(req.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, output)
+}
+
+func (r *Server) storeManySemanticMemories(w http.ResponseWriter, req *http.Request) {
+	var input types.StoreManySemanticMemoryInput
+	err := decodeJSONWithLimit(w, req, &input, maxBatchRequestBodyBytes)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	input.ConversationID = req.PathValue("conversationID")
+	output, err := r.semanticClient.StoreMany(req.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, output)
+}
+
+func (r *Server) retrieveSemanticMemory(w http.ResponseWriter, req *http.Request) {
+	topK, err := queryInt(req, "top_k")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	minScore, err := queryFloat(req, "min_score")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	filter, err := queryFilter(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	maxTokens, err := queryInt(req, "max_tokens")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	truncateOldest, err := queryBool(req, "truncate_oldest")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	input := types.RetrieveSemanticMemoryInput{
+		ConversationID: req.PathValue("conversationID"),
+		Query:          req.URL.Query().Get("query"),
+		TopK:           topK,
+		MinScore:       minScore,
+		Filter:         filter,
+		Scope:          req.URL.Query().Get("scope"),
+		MaxTokens:      maxTokens,
+		TruncateOldest: truncateOldest,
+	}
+	output, err := r.semanticClient.Retrieve(req.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, output)
+}
+
+// reindexSemanticMemory rebuilds the index of the conversation in the path, or
+// of every conversation when there is none.
+func (r *Server) reindexSemanticMemory(w http.ResponseWriter, req *http.Request) {
+	input := types.ReindexSemanticMemoryInput{
+		ConversationID: req.PathValue("conversationID"),
+	}
+	output, err := r.semanticClient.Reindex(req.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, output)
+}
+
+func (r *Server) embeddingCacheStats(w http.ResponseWriter, req *http.Request) {
+	output, err := r.semanticClient.EmbeddingCacheStats(req.Context(), types.EmbeddingCacheStatsInput{})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, output)
+}
+
+func (r *Server) updateSemanticMemory(w http.ResponseWriter, req *http.Request) {
+	var input types.UpdateSemanticMemoryInput
+	err := decodeJSON(w, req, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	input.ConversationID = req.PathValue("conversationID")
+	input.MemoryID = req.PathValue("memoryID")
+	output, err := r.semanticClient.Update(req.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, output)
+}
+
+func (r *Server) deleteSemanticMemory(w http.ResponseWriter, req *http.Request) {
+	input := types.DeleteSemanticMemoryInput{
+		ConversationID: req.PathValue("conversationID"),
+		MemoryID:       req.PathValue("memoryID"),
+	}
+	output, err := r.semanticClient.Delete(req.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, output)
+}
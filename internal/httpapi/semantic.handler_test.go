@@ -0,0 +1,100 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/haren7/minimal-memory/types"
+)
+
+func TestSemanticHandlers(t *testing.T) {
+	handler := newTestHandler(t)
+	var registered types.RegisterConversationOutput
+	status := serve(t, handler, http.MethodPost, "/v1/semantic/conversations", types.RegisterConversationInput{Agent: "agent", User: "user"}, &registered)
+	if status != http.StatusCreated {
+		t.Fatalf("register returned %d, want %d", status, http.StatusCreated)
+	}
+	memories := "/v1/semantic/conversations/" + registered.ConversationID + "/memories"
+	var stored types.StoreSemanticMemoryOutput
+	status = serve(t, handler, http.MethodPost, memories, types.StoreSemanticMemoryInput{Query: "my dog is called rex", Response: "noted", Tags: []string{"pets"}}, &stored)
+	if status != http.StatusCreated {
+		t.Fatalf("store returned %d, want %d", status, http.StatusCreated)
+	}
+	var batch types.StoreManySemanticMemoryOutput
+	status = serve(t, handler, http.MethodPost, memories+"/batch", types.StoreManySemanticMemoryInput{Memories: []types.SemanticMemoryEntry{
+		{Query: "rex likes long walks", Response: "noted", Tags: []string{"pets"}},
+		{Query: "i prefer tea to coffee", Response: "noted"},
+	}}, &batch)
+	if status != http.StatusCreated || len(batch.MemoryIDs) != 2 {
+		t.Fatalf("batch store returned %d with %d ids, want %d with 2", status, len(batch.MemoryIDs), http.StatusCreated)
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		wantStatus  int
+		wantRecent  int
+		wantSimilar int
+	}{
+		{name: "recent only", path: memories, wantStatus: http.StatusOK, wantRecent: 3},
+		{name: "default top k", path: memories + "?query=dog", wantStatus: http.StatusOK, wantRecent: 3, wantSimilar: 3},
+		{name: "top k is passed through", path: memories + "?query=dog&top_k=2", wantStatus: http.StatusOK, wantRecent: 3, wantSimilar: 2},
+		{name: "tag filter", path: memories + "?query=dog&tag=pets", wantStatus: http.StatusOK, wantRecent: 2, wantSimilar: 2},
+		{name: "min score above every memory", path: memories + "?query=dog&min_score=2", wantStatus: http.StatusOK, wantRecent: 3},
+		{name: "top k above the maximum", path: memories + "?query=dog&top_k=1001", wantStatus: http.StatusBadRequest},
+		{name: "malformed min score", path: memories + "?query=dog&min_score=high", wantStatus: http.StatusBadRequest},
+		{name: "malformed truncate oldest", path: memories + "?max_tokens=10&truncate_oldest=maybe", wantStatus: http.StatusBadRequest},
+		{name: "unknown scope", path: memories + "?query=dog&scope=team", wantStatus: http.StatusBadRequest},
+		{name: "unknown conversation", path: "/v1/semantic/conversations/00000000-0000-0000-0000-000000000000/memories", wantStatus: http.StatusNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var output types.RetrieveSemanticMemoryOutput
+			status := serve(t, handler, http.MethodGet, test.path, nil, &output)
+			if status != test.wantStatus {
+				t.Fatalf("retrieve returned %d, want %d", status, test.wantStatus)
+			}
+			if len(output.Memories) != test.wantRecent || len(output.SimilarMemories) != test.wantSimilar {
+				t.Fatalf("retrieve returned %d recent and %d similar memories, want %d and %d", len(output.Memories), len(output.SimilarMemories), test.wantRecent, test.wantSimilar)
+			}
+		})
+	}
+
+	status = serve(t, handler, http.MethodPut, memories+"/"+stored.MemoryID, types.UpdateSemanticMemoryInput{Query: "my dog is called max", Response: "noted"}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("update returned %d, want %d", status, http.StatusOK)
+	}
+	status = serve(t, handler, http.MethodDelete, memories+"/"+batch.MemoryIDs[1], nil, nil)
+	if status != http.StatusOK {
+		t.Fatalf("delete returned %d, want %d", status, http.StatusOK)
+	}
+	status = serve(t, handler, http.MethodDelete, memories+"/"+batch.MemoryIDs[1], nil, nil)
+	if status != http.StatusNotFound {
+		t.Fatalf("deleting a deleted memory returned %d, want %d", status, http.StatusNotFound)
+	}
+	var reindexed types.ReindexSemanticMemoryOutput
+	status = serve(t, handler, http.MethodPost, "/v1/semantic/conversations/"+registered.ConversationID+"/reindex", nil, &reindexed)
+	if status != http.StatusOK || len(reindexed.Conversations) != 1 || reindexed.Conversations[0].Memories != 2 {
+		t.Fatalf("reindex returned %d with %+v, want one conversation of 2 memories", status, reindexed.Conversations)
+	}
+	var conversation types.GetConversationOutput
+	status = serve(t, handler, http.MethodGet, "/v1/semantic/conversations/"+registered.ConversationID, nil, &conversation)
+	if status != http.StatusOK || conversation.Conversation.MemoryCount != 2 {
+		t.Fatalf("get conversation returned %d with %+v, want 2 memories", status, conversation.Conversation)
+	}
+	var cacheStats types.EmbeddingCacheStatsOutput
+	status = serve(t, handler, http.MethodGet, "/v1/semantic/embedding-cache", nil, &cacheStats)
+	if status != http.StatusOK || !cacheStats.Enabled {
+		t.Fatalf("embedding cache stats returned %d with %+v, want an enabled cache", status, cacheStats)
+	}
+
+	var erased types.EraseUserOutput
+	status = serve(t, handler, http.MethodDelete, "/v1/users/user", nil, &erased)
+	if status != http.StatusOK || len(erased.Conversations) != 1 {
+		t.Fatalf("erase user returned %d with %+v, want one conversation", status, erased.Conversations)
+	}
+	status = serve(t, handler, http.MethodGet, memories, nil, nil)
+	if status != http.StatusNotFound {
+		t.Fatalf("retrieve from an erased conversation returned %d, want %d", status, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,104 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/haren7/minimal-memory/types"
+)
+
+func (r *Server) registerShortTermConversation(w http.ResponseWriter, req *http.Request) {
+	var input types.RegisterConversationInput
+	err := decodeJSON(w, req, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	output, err := r.shortTermClient.RegisterConversation(req.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, output)
+}
+
+func (r *Server) storeShortTermMemory(w http.ResponseWriter, req *http.Request) {
+	var input types.StoreShortTermMemoryInput
+	err := decodeJSON(w, req, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	input.ConversationID = req.PathValue("conversationID")
+	output, err := r.shortTermClient.Store(req.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, output)
+}
+
+func (r *Server) retrieveShortTermMemory(w http.ResponseWriter, req *http.Request) {
+	topK, err := queryInt(req, "top_k")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	filter, err := queryFilter(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	maxTokens, err := queryInt(req, "max_tokens")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	truncateOldest, err := queryBool(req, "truncate_oldest")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	input := types.RetrieveShortTermMemoryInput{
+		ConversationID: req.PathValue("conversationID"),
+		TopK:           topK,
+		Filter:         filter,
+		MaxTokens:      maxTokens,
+		TruncateOldest: truncateOldest,
+	}
+	output, err := r.shortTermClient.Retrieve(req.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, output)
+}
+
+func (r *Server) updateShortTermMemory(w http.ResponseWriter, req *http.Request) {
+	var input types.UpdateShortTermMemoryInput
+	err := decodeJSON(w, req, &input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	input.ConversationID = req.PathValue("conversationID")
+	input.MemoryID = req.PathValue("memoryID")
+	output, err := r.shortTermClient.Update(req.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, output)
+}
+
+func (r *Server) deleteShortTermMemory(w http.ResponseWriter, req *http.Request) {
+	input := types.DeleteShortTermMemoryInput{
+		ConversationID: req.PathValue("conversationID"),
+		MemoryID:       req.PathValue("memoryID"),
+	}
+	output, err := r.shortTermClient.Delete(req.Context(), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, output)
+}
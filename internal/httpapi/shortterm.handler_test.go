@@ -0,0 +1,94 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/haren7/minimal-memory/types"
+)
+
+func TestShortTermHandlers(t *testing.T) {
+	handler := newTestHandler(t)
+	var registered types.RegisterConversationOutput
+	status := serve(t, handler, http.MethodPost, "/v1/short-term/conversations", types.RegisterConversationInput{Agent: "agent", User: "user"}, &registered)
+	if status != http.StatusCreated {
+		t.Fatalf("register returned %d, want %d", status, http.StatusCreated)
+	}
+	memories := "/v1/short-term/conversations/" + registered.ConversationID + "/memories"
+	var stored []string
+	for _, query := range []string{"first", "second", "third"} {
+		var output types.StoreShortTermMemoryOutput
+		status := serve(t, handler, http.MethodPost, memories, types.StoreShortTermMemoryInput{Query: query, Response: "noted"}, &output)
+		if status != http.StatusCreated {
+			t.Fatalf("store returned %d, want %d", status, http.StatusCreated)
+		}
+		stored = append(stored, output.MemoryID)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantQuery  []string
+	}{
+		{name: "default top k", path: memories, wantStatus: http.StatusOK, wantQuery: []string{"first", "second", "third"}},
+		{name: "top k is passed through", path: memories + "?top_k=2", wantStatus: http.StatusOK, wantQuery: []string{"second", "third"}},
+		{name: "negative top k", path: memories + "?top_k=-1", wantStatus: http.StatusBadRequest},
+		{name: "malformed top k", path: memories + "?top_k=two", wantStatus: http.StatusBadRequest},
+		{name: "malformed max tokens", path: memories + "?max_tokens=many", wantStatus: http.StatusBadRequest},
+		{name: "invalid conversation id", path: "/v1/short-term/conversations/nope/memories", wantStatus: http.StatusBadRequest},
+		{name: "unknown conversation", path: "/v1/short-term/conversations/00000000-0000-0000-0000-000000000000/memories", wantStatus: http.StatusNotFound},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var output types.RetrieveShortTermMemoryOutput
+			status := serve(t, handler, http.MethodGet, test.path, nil, &output)
+			if status != test.wantStatus {
+				t.Fatalf("retrieve returned %d, want %d", status, test.wantStatus)
+			}
+			var queries []string
+			for _, memory := range output.Memories {
+				queries = append(queries, memory.Query)
+			}
+			if len(queries) != len(test.wantQuery) {
+				t.Fatalf("retrieve returned %q, want %q", queries, test.wantQuery)
+			}
+			for i := range queries {
+				if queries[i] != test.wantQuery[i] {
+					t.Fatalf("retrieve returned %q, want %q", queries, test.wantQuery)
+				}
+			}
+		})
+	}
+
+	status = serve(t, handler, http.MethodPut, memories+"/"+stored[0], types.UpdateShortTermMemoryInput{Query: "updated", Response: "noted"}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("update returned %d, want %d", status, http.StatusOK)
+	}
+	status = serve(t, handler, http.MethodDelete, memories+"/"+stored[1], nil, nil)
+	if status != http.StatusOK {
+		t.Fatalf("delete returned %d, want %d", status, http.StatusOK)
+	}
+	status = serve(t, handler, http.MethodDelete, memories+"/"+stored[1], nil, nil)
+	if status != http.StatusNotFound {
+		t.Fatalf("deleting a deleted memory returned %d, want %d", status, http.StatusNotFound)
+	}
+	var output types.RetrieveShortTermMemoryOutput
+	serve(t, handler, http.MethodGet, memories, nil, &output)
+	if len(output.Memories) != 2 || output.Memories[0].Query != "updated" {
+		t.Fatalf("retrieve after update and delete returned %+v", output.Memories)
+	}
+
+	status = serve(t, handler, http.MethodPost, memories, types.StoreShortTermMemoryInput{Query: "q"}, nil)
+	if status != http.StatusBadRequest {
+		t.Fatalf("store without a response returned %d, want %d", status, http.StatusBadRequest)
+	}
+	status = serve(t, handler, http.MethodPost, "/v1/short-term/conversations/"+registered.ConversationID+"/close", nil, nil)
+	if status != http.StatusOK {
+		t.Fatalf("close returned %d, want %d", status, http.StatusOK)
+	}
+	status = serve(t, handler, http.MethodPost, memories, types.StoreShortTermMemoryInput{Query: "q", Response: "r"}, nil)
+	if status != http.StatusConflict {
+		t.Fatalf("store into a closed conversation returned %d, want %d", status, http.StatusConflict)
+	}
+}
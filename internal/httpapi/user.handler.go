@@ -0,0 +1,47 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/haren7/minimal-memory/types"
+)
+
+// eraseUser erases the user through the semantic client first and then the
+// short-term client, which is handed the conversations already deleted so it
+// drops their cached memories too. Both reports are merged per conversation.
+func (r *Server) eraseUser(w http.ResponseWriter, req *http.Request) {
+	user := req.PathValue("user")
+	semantic, err := r.semanticClient.EraseUser(req.Context(), types.EraseUserInput{User: user})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	conversationIDs := make([]string, len(semantic.Conversations))
+	for i, conversation := range semantic.Conversations {
+		conversationIDs[i] = conversation.ConversationID
+	}
+	shortTerm, err := r.shortTermClient.EraseUser(req.Context(), types.EraseUserInput{User: user, ConversationIDs: conversationIDs})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	conversations := semantic.Conversations
+	positions := make(map[string]int, len(conversations))
+	for i, conversation := range conversations {
+		positions[conversation.ConversationID] = i
+	}
+	for _, conversation := range shortTerm.Conversations {
+		i, ok := positions[conversation.ConversationID]
+		if !ok {
+			// registered after the semantic erase listed the user's conversations
+			conversations = append(conversations, conversation)
+			continue
+		}
+		conversations[i].CacheEntries = conversation.CacheEntries
+	}
+	writeJSON(w, http.StatusOK, types.EraseUserOutput{
+		User:          user,
+		Conversations: conversations,
+		ErasedAt:      shortTerm.ErasedAt,
+	})
+}
@@ -0,0 +1,130 @@
+package mcpapi
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/haren7/minimal-memory/clients"
+	"github.com/haren7/minimal-memory/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	serverName    = "minimal-memory"
+	serverVersion = "v0.1.0"
+)
+
+type RetrieveRecentInput struct {
+	ConversationID string             `json:"conversation_id" jsonschema:"id returned by register_conversation"`
+	Filter         types.MemoryFilter `json:"filter,omitzero" jsonschema:"only return memories with these tags and metadata"`
+	MaxTokens      int                `json:"max_tokens,omitempty" jsonschema:"return the most recent memories whose queries and responses fit in this many tokens instead of a fixed number"`
+	TruncateOldest bool               `json:"truncate_oldest,omitempty" jsonschema:"cut the oldest memory to fit max_tokens instead of leaving it out"`
+}
+
+type RetrieveRecentOutput struct {
+	Memories []types.Memory `json:"memories"`
+}
+
+type SearchMemoriesOutput struct {
+	SimilarMemories []types.SemanticMemory `json:"similar_memories"`
+}
+
+type tools struct {
+	semanticClient clients.SemanticMemoryClient
+}
+
+// NewServer returns an MCP server exposing the semantic memory client as tools.
+// The same server can be run over stdio or mounted behind the streamable HTTP handler.
+func NewServer(semanticClient clients.SemanticMemoryClient) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: serverName, Version: serverVersion}, nil)
+	tools := &tools{semanticClient: semanticClient}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "register_conversation",
+		Description: "Start a new conversation between an agent and a user and return its id. Call this once before storing or retrieving memories.",
+	}, tools.registerConversation)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "store_memory",
+		Description: "Remember one exchange of a conversation: the user's query and the agent's response, optionally with metadata and tags to filter by later. Scopes user or agent also remember it for later conversations of the same user or agent.",
+	}, tools.storeMemory)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "retrieve_recent",
+		Description: "Return the most recent memories of a conversation, oldest first.",
+	}, tools.retrieveRecent)
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "search_memories",
+		Description: "Return the memories of a conversation that are most similar to the given query, or with scope user or agent the most similar ones shared from any conversation of its user or agent, each with the conversation it came from.",
+	}, tools.searchMemories)
+	return server
+}
+
+// NewTenantServers returns the getServer function of the streamable HTTP
+// handler. A session is served by the server of the namespace its first request
+// names, see httpapi.NamespaceHandler, servers are created on first use.
+func NewTenantServers(tenants *clients.Tenants) func(*http.Request) *mcp.Server {
+	var mu sync.Mutex
+	servers := make(map[string]*mcp.Server)
+	return func(req *http.Request) *mcp.Server {
+		namespace := clients.NamespaceFromContext(req.Context())
+		mu.Lock()
+		defer mu.Unlock()
+		server, exists := servers[namespace]
+		if exists {
+			return server
+		}
+		semanticClient, err := tenants.Semantic(namespace)
+		if err != nil {
+			// the handler answers requests without a server with 400
+			log.Printf("[ERROR] NewTenantServers: Failed to get the client of namespace %q - %v", namespace, err)
+			return nil
+		}
+		server = NewServer(semanticClient)
+		servers[namespace] = server
+		return server
+	}
+}
+
+func (r *tools) registerConversation(ctx context.Context, req *mcp.CallToolRequest, input types.RegisterConversationInput) (*mcp.CallToolResult, types.RegisterConversationOutput, error) {
+	output, err := r.semanticClient.RegisterConversation(ctx, input)
+	return nil, output, err
+}
+
+func (r *tools) storeMemory(ctx context.Context, req *mcp.CallToolRequest, input types.StoreSemanticMemoryInput) (*mcp.CallToolResult, types.StoreSemanticMemoryOutput, error) {
+	output, err := r.semanticClient.Store(ctx, input)
+	return nil, output, err
+}
+
+func (r *tools) retrieveRecent(ctx context.Context, req *mcp.CallToolRequest, input RetrieveRecentInput) (*mcp.CallToolResult, RetrieveRecentOutput, error) {
+	output, err := r.semanticClient.Retrieve(ctx, types.RetrieveSemanticMemoryInput{
+		ConversationID: input.ConversationID,
+		Filter:         input.Filter,
+		MaxTokens:      input.MaxTokens,
+		TruncateOldest: input.TruncateOldest,
+	})
+	if err != nil {
+		return nil, RetrieveRecentOutput{}, err
+	}
+	memories := output.Memories
+	if memories == nil {
+		memories = []types.Memory{}
+	}
+	return nil, RetrieveRecentOutput{Memories: memories}, nil
+}
+
+func (r *tools) searchMemories(ctx context.Context, req *mcp.CallToolRequest, input types.RetrieveSemanticMemoryInput) (*mcp.CallToolResult, SearchMemoriesOutput, error) {
+	if input.Query == "" {
+		return nil, SearchMemoriesOutput{}, fmt.Errorf("%w: query is required", clients.ErrInvalidInput)
+	}
+	output, err := r.semanticClient.Retrieve(ctx, input)
+	if err != nil {
+		return nil, SearchMemoriesOutput{}, err
+	}
+	similarMemories := output.SimilarMemories
+	if similarMemories == nil {
+		similarMemories = []types.SemanticMemory{}
+	}
+	return nil, SearchMemoriesOutput{SimilarMemories: similarMemories}, nil
+}
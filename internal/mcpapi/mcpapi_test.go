@@ -0,0 +1,167 @@
+package mcpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/haren7/minimal-memory/clients"
+	"github.com/haren7/minimal-memory/internal/httpapi"
+	"github.com/haren7/minimal-memory/types"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func newTestTenants(t *testing.T) *clients.Tenants {
+	t.Helper()
+	tenants, err := clients.NewTenants(clients.TenantsConfig{
+		DuckDBPath: filepath.Join(t.TempDir(), "memory.db"),
+		Namespaces: []string{"acme"},
+		Semantic: clients.SemanticMemoryClientConfig{
+			ContextWindowSize: 10,
+			EmbeddingProvider: clients.EmbeddingProviderLocal,
+			LocalEmbeddingDim: 8,
+			VectorBackend:     clients.VectorBackendBruteForce,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewTenants: %v", err)
+	}
+	return tenants
+}
+
+func connect(t *testing.T, transport mcp.Transport) *mcp.ClientSession {
+	t.Helper()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(context.Background(), transport, nil)
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+// callTool calls a tool and decodes its structured output into out, it
+// returns whether the tool reported an error.
+func callTool(t *testing.T, session *mcp.ClientSession, name string, arguments, out any) bool {
+	t.Helper()
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: arguments})
+	if err != nil {
+		t.Fatalf("CallTool(%s): %v", name, err)
+	}
+	if result.IsError || out == nil {
+		return result.IsError
+	}
+	encoded, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		t.Fatalf("encoding %s output: %v", name, err)
+	}
+	err = json.Unmarshal(encoded, out)
+	if err != nil {
+		t.Fatalf("decoding %s output: %v", name, err)
+	}
+	return false
+}
+
+func TestServerTools(t *testing.T) {
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := NewServer(newTestTenants(t).SemanticByContext()).Connect(context.Background(), serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+	session := connect(t, clientTransport)
+
+	listed, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools: %v", err)
+	}
+	var names []string
+	for _, tool := range listed.Tools {
+		names = append(names, tool.Name)
+	}
+	if len(names) != 4 {
+		t.Fatalf("ListTools returned %q, want the four memory tools", names)
+	}
+
+	var registered types.RegisterConversationOutput
+	if callTool(t, session, "register_conversation", map[string]any{"agent": "agent", "user": "user"}, &registered) {
+		t.Fatalf("register_conversation reported an error")
+	}
+	for _, query := range []string{"my dog is called rex", "rex likes long walks", "i prefer tea to coffee"} {
+		arguments := map[string]any{"conversation_id": registered.ConversationID, "query": query, "response": "noted", "tags": []string{"pets"}}
+		if callTool(t, session, "store_memory", arguments, nil) {
+			t.Fatalf("store_memory reported an error")
+		}
+	}
+
+	var recent RetrieveRecentOutput
+	if callTool(t, session, "retrieve_recent", map[string]any{"conversation_id": registered.ConversationID}, &recent) {
+		t.Fatalf("retrieve_recent reported an error")
+	}
+	if len(recent.Memories) != 3 || recent.Memories[0].Query != "my dog is called rex" {
+		t.Fatalf("retrieve_recent returned %+v, want the three memories oldest first", recent.Memories)
+	}
+	var searched SearchMemoriesOutput
+	if callTool(t, session, "search_memories", map[string]any{"conversation_id": registered.ConversationID, "query": "dog", "top_k": 2}, &searched) {
+		t.Fatalf("search_memories reported an error")
+	}
+	if len(searched.SimilarMemories) != 2 || searched.SimilarMemories[0].ConversationID != registered.ConversationID {
+		t.Fatalf("search_memories returned %+v, want 2 memories of the conversation", searched.SimilarMemories)
+	}
+
+	tests := []struct {
+		name      string
+		tool      string
+		arguments map[string]any
+	}{
+		{name: "search with an empty query", tool: "search_memories", arguments: map[string]any{"conversation_id": registered.ConversationID, "query": ""}},
+		{name: "unknown conversation", tool: "retrieve_recent", arguments: map[string]any{"conversation_id": "00000000-0000-0000-0000-000000000000"}},
+		{name: "store with an empty response", tool: "store_memory", arguments: map[string]any{"conversation_id": registered.ConversationID, "query": "q", "response": ""}},
+		{name: "unknown scope", tool: "search_memories", arguments: map[string]any{"conversation_id": registered.ConversationID, "query": "dog", "scope": "team"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !callTool(t, session, test.tool, test.arguments, nil) {
+				t.Fatalf("%s succeeded, want a tool error", test.tool)
+			}
+		})
+	}
+}
+
+func TestTenantServers(t *testing.T) {
+	handler := httpapi.NamespaceHandler(mcp.NewStreamableHTTPHandler(NewTenantServers(newTestTenants(t)), nil))
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	acme := connect(t, &mcp.StreamableClientTransport{Endpoint: server.URL + "/tenants/acme/mcp", MaxRetries: -1})
+	var registered types.RegisterConversationOutput
+	if callTool(t, acme, "register_conversation", map[string]any{"agent": "agent", "user": "user"}, &registered) {
+		t.Fatalf("register_conversation reported an error")
+	}
+	if callTool(t, acme, "retrieve_recent", map[string]any{"conversation_id": registered.ConversationID}, nil) {
+		t.Fatalf("retrieve_recent in the same namespace reported an error")
+	}
+	defaultNamespace := connect(t, &mcp.StreamableClientTransport{Endpoint: server.URL + "/mcp", MaxRetries: -1})
+	if !callTool(t, defaultNamespace, "retrieve_recent", map[string]any{"conversation_id": registered.ConversationID}, nil) {
+		t.Fatalf("retrieve_recent found a conversation of another namespace")
+	}
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{Endpoint: server.URL + "/tenants/globex/mcp", MaxRetries: -1}, nil)
+	if err == nil {
+		session.Close()
+		t.Fatalf("Connect to a namespace that is not served succeeded")
+	}
+	resp, err := http.Get(server.URL + "/tenants/globex/mcp")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < http.StatusBadRequest {
+		t.Fatalf("a namespace that is not served answered %d", resp.StatusCode)
+	}
+}
@@ -23,33 +23,87 @@ func NewCachedService(memoryRepo cache.MemoryRepoInterface, summarizerService su
 	}
 }
 
-func (r *CachedService) Store(ctx context.Context, conversationID uuid.UUID, query, response string) (uuid.UUID, error) {
+func (r *CachedService) Store(ctx context.Context, conversationID uuid.UUID, memory MemoryInput) (uuid.UUID, error) {
 	memoryId, err := uuid.NewUUID()
 	if err != nil {
 		return uuid.UUID{}, fmt.Errorf("cached: error creating memory id, %w", err)
 	}
-	createdAt := time.Now()
-	summarizedResponse, err := r.summarizerService.Summarize(ctx, response)
+	createdAt := memory.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	summarizedResponse, err := r.summarizerService.Summarize(ctx, memory.Response)
 	if err != nil {
 		return uuid.UUID{}, fmt.Errorf("cached: error summarizing response, %w", err)
 	}
-	r.memoryRepo.SetOne(ctx, conversationID, memoryId, query, summarizedResponse, createdAt)
+	err = r.memoryRepo.SetOne(ctx, conversationID, memoryId, memory.Query, summarizedResponse, createdAt, memory.Metadata, memory.Tags)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("cached: error storing memory, %w", err)
+	}
 	return memoryId, nil
 }
 
-func (r *CachedService) Retrieve(ctx context.Context, conversationID uuid.UUID, lastK int) ([]Memory, error) {
-	cacheMemories, err := r.memoryRepo.Get(ctx, conversationID, lastK)
+func (r *CachedService) Retrieve(ctx context.Context, conversationID uuid.UUID, lastK int, filter Filter) ([]Memory, error) {
+	// a filter can skip any number of recent memories, so it reads them all
+	limit := lastK
+	if !filter.IsZero() {
+		limit = 0
+	}
+	cacheMemories, err := r.memoryRepo.Get(ctx, conversationID, limit)
 	if err != nil {
 		return nil, fmt.Errorf("cached: error retrieving memories, %w", err)
 	}
 	var memories []Memory
 	for _, memory := range cacheMemories {
 		memories = append(memories, Memory{
-			ID:        memory.ID,
-			Query:     memory.Query,
-			Response:  memory.Response,
-			CreatedAt: memory.CreatedAt,
+			ID:             memory.ID,
+			ConversationID: conversationID,
+			Query:          memory.Query,
+			Response:       memory.Response,
+			CreatedAt:      memory.CreatedAt,
+			Metadata:       memory.Metadata,
+			Tags:           memory.Tags,
 		})
 	}
-	return memories, nil
+	return latest(memories, lastK, filter), nil
+}
+
+func (r *CachedService) Delete(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID) error {
+	err := r.memoryRepo.DeleteOne(ctx, conversationID, memoryID)
+	if err != nil {
+		return fmt.Errorf("cached: error deleting memory, %w", err)
+	}
+	return nil
+}
+
+func (r *CachedService) Update(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID, query, response string) error {
+	summarizedResponse, err := r.summarizerService.Summarize(ctx, response)
+	if err != nil {
+		return fmt.Errorf("cached: error summarizing response, %w", err)
+	}
+	err = r.memoryRepo.UpdateOne(ctx, conversationID, memoryID, query, summarizedResponse)
+	if err != nil {
+		return fmt.Errorf("cached: error updating memory, %w", err)
+	}
+	return nil
+}
+
+func (r *CachedService) Erase(ctx context.Context, conversationID uuid.UUID) (int, error) {
+	erased, err := r.memoryRepo.DeleteConversation(ctx, conversationID)
+	if err != nil {
+		return 0, fmt.Errorf("cached: error erasing memories, %w", err)
+	}
+	return erased, nil
+}
+
+func (r *CachedService) Stats(ctx context.Context, conversationIDs []uuid.UUID) (map[uuid.UUID]Stats, error) {
+	stats := make(map[uuid.UUID]Stats, len(conversationIDs))
+	for _, conversationID := range conversationIDs {
+		cacheStats, err := r.memoryRepo.Stats(ctx, conversationID)
+		if err != nil {
+			return nil, fmt.Errorf("cached: error fetching stats, %w", err)
+		}
+		stats[conversationID] = Stats{Memories: cacheStats.Memories, LastCreatedAt: cacheStats.LastCreatedAt}
+	}
+	return stats, nil
 }
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+)
+
+// Filter selects memories by their tags and metadata, the zero Filter matches
+// every memory.
+type Filter struct {
+	// Tags must all be carried by a memory.
+	Tags []string
+	// Metadata holds the value a memory must have under each key. Values are
+	// compared by their JSON encoding, so 1 matches 1.0 and nested objects
+	// match regardless of key order.
+	Metadata map[string]any
+}
+
+func (r Filter) IsZero() bool {
+	return len(r.Tags) == 0 && len(r.Metadata) == 0
+}
+
+func (r Filter) Matches(metadata map[string]any, tags []string) bool {
+	for _, tag := range r.Tags {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	for key, want := range r.Metadata {
+		got, exists := metadata[key]
+		if !exists || !jsonEqual(got, want) {
+			return false
+		}
+	}
+	return true
+}
+
+func jsonEqual(a, b any) bool {
+	encodedA, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	encodedB, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(encodedA, encodedB)
+}
+
+// latest keeps the memories matching filter and returns the last lastK of
+// them, all of them for a non-positive lastK. memories are oldest first.
+func latest(memories []Memory, lastK int, filter Filter) []Memory {
+	var matching []Memory
+	for _, memory := range memories {
+		if filter.Matches(memory.Metadata, memory.Tags) {
+			matching = append(matching, memory)
+		}
+	}
+	if lastK > 0 && len(matching) > lastK {
+		matching = matching[len(matching)-lastK:]
+	}
+	return matching
+}
@@ -3,16 +3,49 @@ package memory
 import (
 	"context"
 
+	"github.com/haren7/minimal-memory/internal/tokenizer"
+
 	"github.com/google/uuid"
 )
 
 type ServiceInterface interface {
-	Store(ctx context.Context, conversationID uuid.UUID, query, response string) (uuid.UUID, error)
-	Retrieve(ctx context.Context, conversationID uuid.UUID, lastK int) ([]Memory, error)
+	Store(ctx context.Context, conversationID uuid.UUID, memory MemoryInput) (uuid.UUID, error)
+	// Retrieve returns the last lastK memories matching filter, oldest first.
+	Retrieve(ctx context.Context, conversationID uuid.UUID, lastK int, filter Filter) ([]Memory, error)
+	Delete(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID) error
+	Update(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID, query, response string) error
+	// Erase removes every memory of a conversation and returns how many were removed.
+	Erase(ctx context.Context, conversationID uuid.UUID) (int, error)
+	Stats(ctx context.Context, conversationIDs []uuid.UUID) (map[uuid.UUID]Stats, error)
 }
 
 type SemanticServiceInterface interface {
-	Store(ctx context.Context, convesationID uuid.UUID, query, response string) (uuid.UUID, error)
-	Retrieve(ctx context.Context, conversationID uuid.UUID, lastK int) ([]Memory, error)
-	RetrieveSimilar(ctx context.Context, conversationID uuid.UUID, query string, topK int) ([]Memory, error)
+	Store(ctx context.Context, convesationID uuid.UUID, memory MemoryInput) (uuid.UUID, error)
+	StoreMany(ctx context.Context, conversationID uuid.UUID, memories []MemoryInput) ([]uuid.UUID, error)
+	// Retrieve returns the last lastK memories matching filter, oldest first.
+	Retrieve(ctx context.Context, conversationID uuid.UUID, lastK int, filter Filter) ([]Memory, error)
+	// RetrieveWithinTokens returns the latest memories matching filter that fit
+	// in maxTokens, oldest first, as WithinTokens keeps them.
+	RetrieveWithinTokens(ctx context.Context, conversationID uuid.UUID, maxTokens int, truncate bool, tokenizer tokenizer.Tokenizer, filter Filter) ([]Memory, error)
+	// RetrieveSimilar returns up to topK memories matching filter whose score is
+	// at least minScore, most similar first, a minScore of -Inf keeps every
+	// match. scope searches the conversation or the memories shared with its
+	// user or agent.
+	RetrieveSimilar(ctx context.Context, conversationID uuid.UUID, scope Scope, query string, topK int, minScore float32, filter Filter) ([]Memory, error)
+	// Delete removes a memory from the database and the vector index.
+	Delete(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID) error
+	// Update replaces the query and response of a memory and re-embeds it.
+	Update(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID, query, response string) error
+	// Erase removes every memory of a conversation from the database and the
+	// vector store, including the ones shared with its user and agent, and
+	// drops its index. It does not check the conversation exists.
+	Erase(ctx context.Context, conversationID uuid.UUID) (EraseResult, error)
+	// EraseUser drops the index shared by the conversations of user and returns
+	// how many memories it held.
+	EraseUser(ctx context.Context, user string) (int, error)
+	// Stats returns an entry for each of conversationIDs, including the ones without memories.
+	Stats(ctx context.Context, conversationIDs []uuid.UUID) (map[uuid.UUID]Stats, error)
+	// Reindex rebuilds the vector indexes of conversationIDs from the database,
+	// nil rebuilds every conversation that has memories.
+	Reindex(ctx context.Context, conversationIDs []uuid.UUID) ([]ReindexResult, error)
 }
@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/haren7/minimal-memory/internal/persistence"
+
+	"github.com/google/uuid"
+)
+
+// Scope is how far a memory reaches. Every memory belongs to its conversation
+// and can also be shared with the user or the agent of that conversation, whose
+// indexes span all of their conversations.
+type Scope string
+
+const (
+	ScopeConversation Scope = "conversation"
+	ScopeUser         Scope = "user"
+	ScopeAgent        Scope = "agent"
+)
+
+// scopeNamespace derives the ids of user and agent indexes, which the vector
+// store keeps next to the conversation indexes.
+var scopeNamespace = uuid.MustParse("8118d2ea-82e5-4b17-9a7d-c615a9c747cb")
+
+// UserIndexID is the id of the index shared by every conversation of user.
+func UserIndexID(user string) uuid.UUID {
+	return uuid.NewSHA1(scopeNamespace, []byte("user:"+user))
+}
+
+// AgentIndexID is the id of the index shared by every conversation of agent.
+func AgentIndexID(agent string) uuid.UUID {
+	return uuid.NewSHA1(scopeNamespace, []byte("agent:"+agent))
+}
+
+func (r Scope) IsValid() bool {
+	return r == ScopeConversation || r == ScopeUser || r == ScopeAgent
+}
+
+// indexID returns the index scope searches from conversation, the empty scope
+// is the conversation.
+func indexID(scope Scope, conversation persistence.Conversation) (uuid.UUID, error) {
+	switch scope {
+	case "", ScopeConversation:
+		return conversation.UUID, nil
+	case ScopeUser:
+		return UserIndexID(conversation.User), nil
+	case ScopeAgent:
+		return AgentIndexID(conversation.Agent), nil
+	}
+	return uuid.UUID{}, fmt.Errorf("unknown scope %q", scope)
+}
+
+// shareWith returns the user and agent indexes scopes share a memory of
+// conversation with, the conversation scope adds none.
+func shareWith(scopes []Scope, conversation persistence.Conversation) ([]uuid.UUID, error) {
+	var indexIDs []uuid.UUID
+	for _, scope := range scopes {
+		id, err := indexID(scope, conversation)
+		if err != nil {
+			return nil, err
+		}
+		if id != conversation.UUID && !slices.Contains(indexIDs, id) {
+			indexIDs = append(indexIDs, id)
+		}
+	}
+	return indexIDs, nil
+}
+
+// sharedIndexIDs returns the user and agent indexes memories of conversation
+// can be shared with.
+func sharedIndexIDs(conversation persistence.Conversation) []uuid.UUID {
+	return []uuid.UUID{UserIndexID(conversation.User), AgentIndexID(conversation.Agent)}
+}
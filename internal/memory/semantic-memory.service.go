@@ -2,15 +2,24 @@ package memory
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/haren7/minimal-memory/internal/persistence"
 	"github.com/haren7/minimal-memory/internal/summarizer"
+	"github.com/haren7/minimal-memory/internal/tokenizer"
 
 	"github.com/google/uuid"
 )
 
+// similarOverFetch is how many times more candidates than it needs a filtered
+// RetrieveSimilar asks the vector store for.
+const similarOverFetch = 4
+
+// tokenPageSize is how many memories RetrieveWithinTokens reads at a time.
+const tokenPageSize = 32
+
 type SemanticService struct {
 	vectorMemoryRepo  persistence.VectorMemoryRepoInterface
 	rdbmsMemoryRepo   persistence.MemoryRepoInterface
@@ -32,71 +41,417 @@ func NewSemanticService(
 	}
 }
 
-func (r *SemanticService) Store(ctx context.Context, conversationID uuid.UUID, query, response string) (uuid.UUID, error) {
-	_, err := r.converstionRepo.FetchOne(ctx, conversationID)
+// Store indexes the memory in its conversation and then in the user and agent
+// indexes of memory.Scopes, which share its ID.
+func (r *SemanticService) Store(ctx context.Context, conversationID uuid.UUID, memory MemoryInput) (uuid.UUID, error) {
+	conversation, err := r.converstionRepo.FetchOne(ctx, conversationID)
 	if err != nil {
 		return uuid.UUID{}, fmt.Errorf("semantic: error conversation does not exist, %w", err)
 	}
+	sharedIDs, err := shareWith(memory.Scopes, conversation)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("semantic: error resolving scopes, %w", err)
+	}
 	memoryUUID, err := uuid.NewUUID()
-	createdAt := time.Now()
 	if err != nil {
 		return uuid.UUID{}, fmt.Errorf("semantic: error creating memory id, %w", err)
 	}
-	summarizedResponse, err := r.summarizerService.Summarize(ctx, response)
+	createdAt := memory.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	summarizedResponse, err := r.summarizerService.Summarize(ctx, memory.Response)
 	if err != nil {
 		return uuid.UUID{}, fmt.Errorf("semantic: error summarizing response, %w", err)
 	}
 
-	_, err = r.rdbmsMemoryRepo.InsertOne(ctx, conversationID, memoryUUID, query, summarizedResponse, createdAt)
-	if err != nil {
-		return uuid.UUID{}, fmt.Errorf("semantic: error persisting memory, %w", err)
+	vectorMemory := persistence.VectorMemory{
+		ID:                   memoryUUID,
+		Query:                memory.Query,
+		Response:             summarizedResponse,
+		CreatedAt:            createdAt,
+		Metadata:             memory.Metadata,
+		Tags:                 memory.Tags,
+		SourceConversationID: conversationID,
 	}
-	_, err = r.vectorMemoryRepo.Index(ctx, conversationID, memoryUUID, query, summarizedResponse, createdAt)
+	// index first, it calls the embedding provider and is the step most likely to
+	// fail. The shared indexes reuse the embedding of the conversation's copy, on
+	// any failure the memory is removed from every index tried.
+	indexed, err := r.vectorMemoryRepo.Index(ctx, conversationID, vectorMemory)
 	if err != nil {
 		return uuid.UUID{}, fmt.Errorf("semantic: error indexing memory, %w", err)
 	}
+	indexedIDs := []uuid.UUID{conversationID}
+	for _, indexID := range sharedIDs {
+		indexedIDs = append(indexedIDs, indexID)
+		_, err = r.vectorMemoryRepo.Index(ctx, indexID, indexed)
+		if err != nil {
+			err = fmt.Errorf("semantic: error indexing memory, %w", err)
+			return uuid.UUID{}, errors.Join(err, r.unindex(ctx, indexedIDs, []uuid.UUID{memoryUUID}))
+		}
+	}
+	_, err = r.rdbmsMemoryRepo.InsertOne(ctx, persistence.Memory{
+		UUID:           memoryUUID,
+		ConversationID: conversationID,
+		Query:          memory.Query,
+		Response:       summarizedResponse,
+		CreatedAt:      createdAt,
+		Metadata:       memory.Metadata,
+		Tags:           memory.Tags,
+	})
+	if err != nil {
+		err = fmt.Errorf("semantic: error persisting memory, %w", err)
+		return uuid.UUID{}, errors.Join(err, r.unindex(ctx, indexedIDs, []uuid.UUID{memoryUUID}))
+	}
 	return memoryUUID, nil
 }
 
-func (r *SemanticService) Retrieve(ctx context.Context, conversationID uuid.UUID, lastK int) ([]Memory, error) {
+func (r *SemanticService) StoreMany(ctx context.Context, conversationID uuid.UUID, memories []MemoryInput) ([]uuid.UUID, error) {
+	conversation, err := r.converstionRepo.FetchOne(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("semantic: error conversation does not exist, %w", err)
+	}
+	now := time.Now()
+	memoryUUIDs := make([]uuid.UUID, len(memories))
+	vectorMemories := make([]persistence.VectorMemory, len(memories))
+	rdbmsMemories := make([]persistence.Memory, len(memories))
+	// byIndex holds the positions of the memories each shared index takes, in input order
+	byIndex := map[uuid.UUID][]int{}
+	var sharedIDs []uuid.UUID
+	for i, memory := range memories {
+		indexIDs, err := shareWith(memory.Scopes, conversation)
+		if err != nil {
+			return nil, fmt.Errorf("semantic: error resolving scopes of memory %d, %w", i, err)
+		}
+		memoryUUID, err := uuid.NewUUID()
+		if err != nil {
+			return nil, fmt.Errorf("semantic: error creating memory id, %w", err)
+		}
+		summarizedResponse, err := r.summarizerService.Summarize(ctx, memory.Response)
+		if err != nil {
+			return nil, fmt.Errorf("semantic: error summarizing response, %w", err)
+		}
+		createdAt := memory.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = now
+		}
+		memoryUUIDs[i] = memoryUUID
+		vectorMemories[i] = persistence.VectorMemory{
+			ID:                   memoryUUID,
+			ConversationID:       conversationID,
+			Query:                memory.Query,
+			Response:             summarizedResponse,
+			CreatedAt:            createdAt,
+			Metadata:             memory.Metadata,
+			Tags:                 memory.Tags,
+			SourceConversationID: conversationID,
+		}
+		for _, indexID := range indexIDs {
+			if _, exists := byIndex[indexID]; !exists {
+				sharedIDs = append(sharedIDs, indexID)
+			}
+			byIndex[indexID] = append(byIndex[indexID], i)
+		}
+		rdbmsMemories[i] = persistence.Memory{
+			UUID:           memoryUUID,
+			ConversationID: conversationID,
+			Query:          memory.Query,
+			Response:       summarizedResponse,
+			CreatedAt:      createdAt,
+			Metadata:       memory.Metadata,
+			Tags:           memory.Tags,
+		}
+	}
+	// memories_meta is written by the vector repo along with its index, on any
+	// failure the memories are removed from every index tried so none is half
+	// stored. The shared indexes reuse the embeddings of the conversation's copies.
+	indexedIDs := []uuid.UUID{conversationID}
+	indexed, err := r.vectorMemoryRepo.IndexMany(ctx, conversationID, vectorMemories)
+	if err != nil {
+		err = fmt.Errorf("semantic: error indexing memories, %w", err)
+		return nil, errors.Join(err, r.unindex(ctx, indexedIDs, memoryUUIDs))
+	}
+	for _, indexID := range sharedIDs {
+		indexedIDs = append(indexedIDs, indexID)
+		shared := make([]persistence.VectorMemory, len(byIndex[indexID]))
+		for j, i := range byIndex[indexID] {
+			shared[j] = indexed[i]
+		}
+		_, err = r.vectorMemoryRepo.IndexMany(ctx, indexID, shared)
+		if err != nil {
+			err = fmt.Errorf("semantic: error indexing memories, %w", err)
+			return nil, errors.Join(err, r.unindex(ctx, indexedIDs, memoryUUIDs))
+		}
+	}
+	_, err = r.rdbmsMemoryRepo.InsertMany(ctx, rdbmsMemories)
+	if err != nil {
+		err = fmt.Errorf("semantic: error persisting memories, %w", err)
+		return nil, errors.Join(err, r.unindex(ctx, indexedIDs, memoryUUIDs))
+	}
+	return memoryUUIDs, nil
+}
+
+// unindex deletes memoryIDs from the indexes of indexIDs after a failed store,
+// memories an index does not hold are skipped. It runs even when ctx was
+// cancelled, the failure may have been the cancellation.
+func (r *SemanticService) unindex(ctx context.Context, indexIDs []uuid.UUID, memoryIDs []uuid.UUID) error {
+	ctx = context.WithoutCancel(ctx)
+	var errs []error
+	for _, indexID := range indexIDs {
+		for _, memoryID := range memoryIDs {
+			err := r.vectorMemoryRepo.Delete(ctx, indexID, memoryID)
+			if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("semantic: error removing indexed memories, %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (r *SemanticService) Retrieve(ctx context.Context, conversationID uuid.UUID, lastK int, filter Filter) ([]Memory, error) {
 	_, err := r.converstionRepo.FetchOne(ctx, conversationID)
 	if err != nil {
 		return nil, fmt.Errorf("semantic: error conversation does not exist, %w", err)
 	}
-	rdbmsMemories, err := r.rdbmsMemoryRepo.FetchManyByConversationID(ctx, conversationID, lastK)
+	rdbmsMemories, err := r.rdbmsMemoryRepo.FetchManyByConversationID(ctx, conversationID, lastK, persistence.MemoryFilter{Tags: filter.Tags, Metadata: filter.Metadata})
 	if err != nil {
 		return nil, fmt.Errorf("semantic: error fetching memories, %w", err)
 	}
 	var memories []Memory
 	for _, memory := range rdbmsMemories {
-		memories = append(memories, Memory{
-			ID:        memory.UUID,
-			Query:     memory.Query,
-			Response:  memory.Response,
-			CreatedAt: memory.CreatedAt,
-		})
+		memories = append(memories, fromRecord(memory))
 	}
-
 	return memories, nil
 }
 
-func (r *SemanticService) RetrieveSimilar(ctx context.Context, conversationID uuid.UUID, query string, topK int) ([]Memory, error) {
+// RetrieveWithinTokens reads memories newest first a page at a time and stops
+// at the first one that does not fit in maxTokens, so a long conversation is
+// not read whole for a small budget.
+func (r *SemanticService) RetrieveWithinTokens(ctx context.Context, conversationID uuid.UUID, maxTokens int, truncate bool, tokenizer tokenizer.Tokenizer, filter Filter) ([]Memory, error) {
 	_, err := r.converstionRepo.FetchOne(ctx, conversationID)
 	if err != nil {
 		return nil, fmt.Errorf("semantic: error conversation does not exist, %w", err)
 	}
-	vectorMemories, err := r.vectorMemoryRepo.Search(ctx, conversationID, query, topK)
+	budget := &tokenBudget{remaining: maxTokens, truncate: truncate, tokenizer: tokenizer}
+	var before persistence.Memory
+	for {
+		page, err := r.rdbmsMemoryRepo.FetchPageByConversationID(ctx, conversationID, persistence.MemoryFilter{Tags: filter.Tags, Metadata: filter.Metadata}, before, tokenPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("semantic: error fetching memories, %w", err)
+		}
+		for _, memory := range page {
+			if !budget.add(fromRecord(memory)) {
+				return budget.memories(), nil
+			}
+		}
+		if len(page) < tokenPageSize {
+			return budget.memories(), nil
+		}
+		before = page[len(page)-1]
+	}
+}
+
+func fromRecord(memory persistence.Memory) Memory {
+	return Memory{
+		ID:             memory.UUID,
+		ConversationID: memory.ConversationID,
+		Query:          memory.Query,
+		Response:       memory.Response,
+		CreatedAt:      memory.CreatedAt,
+		Metadata:       memory.Metadata,
+		Tags:           memory.Tags,
+	}
+}
+
+// RetrieveSimilar over-fetches candidates when filtering, asking the vector
+// store for similarOverFetch times more each round until topK of them match or
+// the whole index was searched.
+func (r *SemanticService) RetrieveSimilar(ctx context.Context, conversationID uuid.UUID, scope Scope, query string, topK int, minScore float32, filter Filter) ([]Memory, error) {
+	conversation, err := r.converstionRepo.FetchOne(ctx, conversationID)
 	if err != nil {
-		return nil, fmt.Errorf("semantic: error searching for memories, %w", err)
+		return nil, fmt.Errorf("semantic: error conversation does not exist, %w", err)
 	}
-	var memories []Memory
-	for _, memory := range vectorMemories {
-		memories = append(memories, Memory{
-			ID:        memory.ID,
-			Query:     memory.Query,
-			Response:  memory.Response,
-			CreatedAt: memory.CreatedAt,
-		})
+	searchID, err := indexID(scope, conversation)
+	if err != nil {
+		return nil, fmt.Errorf("semantic: error resolving scope, %w", err)
 	}
-	return memories, nil
+	if topK <= 0 {
+		return nil, nil
+	}
+	fetch := topK
+	if !filter.IsZero() {
+		fetch = topK * similarOverFetch
+	}
+	size, err := r.vectorMemoryRepo.Size(ctx, searchID)
+	if err != nil {
+		return nil, fmt.Errorf("semantic: error sizing index, %w", err)
+	}
+	fetch = min(fetch, size)
+	if fetch == 0 {
+		return nil, nil
+	}
+	for {
+		vectorMemories, err := r.vectorMemoryRepo.Search(ctx, searchID, query, fetch)
+		if err != nil {
+			return nil, fmt.Errorf("semantic: error searching for memories, %w", err)
+		}
+		var memories []Memory
+		for _, memory := range vectorMemories {
+			if len(memories) == topK {
+				break
+			}
+			if memory.Score < minScore || !filter.Matches(memory.Metadata, memory.Tags) {
+				continue
+			}
+			memories = append(memories, Memory{
+				ID:             memory.ID,
+				ConversationID: memory.SourceConversationID,
+				Query:          memory.Query,
+				Response:       memory.Response,
+				CreatedAt:      memory.CreatedAt,
+				Metadata:       memory.Metadata,
+				Tags:           memory.Tags,
+				Rank:           len(memories) + 1,
+				Distance:       memory.Distance,
+				Score:          memory.Score,
+			})
+		}
+		// results are nearest first, once one scores below minScore every further
+		// candidate does too. Fewer results than fetch does not mean the index ran
+		// out, memories deleted since they were indexed are skipped.
+		exhausted := fetch == size || (len(vectorMemories) > 0 && vectorMemories[len(vectorMemories)-1].Score < minScore)
+		if len(memories) == topK || exhausted {
+			return memories, nil
+		}
+		fetch = min(fetch*similarOverFetch, size)
+	}
+}
+
+// Delete removes the memory from the vector store first, which reports unknown
+// memories, then from the user and agent indexes it was shared with and last
+// from the memories table. The steps share no transaction: when a later one
+// fails the memory is already gone from its conversation, a retry reports it
+// unknown, and its copies in the user and agent indexes and its row in the
+// memories table stay until the conversation is erased.
+func (r *SemanticService) Delete(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID) error {
+	conversation, err := r.converstionRepo.FetchOne(ctx, conversationID)
+	if err != nil {
+		return fmt.Errorf("semantic: error conversation does not exist, %w", err)
+	}
+	err = r.vectorMemoryRepo.Delete(ctx, conversationID, memoryID)
+	if err != nil {
+		return fmt.Errorf("semantic: error deleting memory from vector store, %w", err)
+	}
+	for _, indexID := range sharedIndexIDs(conversation) {
+		err = r.vectorMemoryRepo.Delete(ctx, indexID, memoryID)
+		if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+			return fmt.Errorf("semantic: error deleting shared memory from vector store, %w", err)
+		}
+	}
+	// a Store that failed after indexing left no row here
+	_, err = r.rdbmsMemoryRepo.DeleteOne(ctx, conversationID, memoryID)
+	if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+		return fmt.Errorf("semantic: error deleting memory, %w", err)
+	}
+	return nil
+}
+
+// Update rewrites the memory in the vector store, the user and agent indexes
+// it was shared with and the memories table, in that order. The steps share no
+// transaction but each one overwrites, so retrying a failed Update converges.
+func (r *SemanticService) Update(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID, query, response string) error {
+	conversation, err := r.converstionRepo.FetchOne(ctx, conversationID)
+	if err != nil {
+		return fmt.Errorf("semantic: error conversation does not exist, %w", err)
+	}
+	summarizedResponse, err := r.summarizerService.Summarize(ctx, response)
+	if err != nil {
+		return fmt.Errorf("semantic: error summarizing response, %w", err)
+	}
+	// update the vector store first, it calls the embedding provider and is the step most likely to fail
+	err = r.vectorMemoryRepo.Update(ctx, conversationID, memoryID, query, summarizedResponse)
+	if err != nil {
+		return fmt.Errorf("semantic: error updating memory in vector store, %w", err)
+	}
+	for _, indexID := range sharedIndexIDs(conversation) {
+		err = r.vectorMemoryRepo.Update(ctx, indexID, memoryID, query, summarizedResponse)
+		if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+			return fmt.Errorf("semantic: error updating shared memory in vector store, %w", err)
+		}
+	}
+	_, err = r.rdbmsMemoryRepo.UpdateOne(ctx, persistence.Memory{
+		UUID:           memoryID,
+		ConversationID: conversationID,
+		Query:          query,
+		Response:       summarizedResponse,
+	})
+	if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+		return fmt.Errorf("semantic: error updating memory, %w", err)
+	}
+	return nil
+}
+
+func (r *SemanticService) Erase(ctx context.Context, conversationID uuid.UUID) (EraseResult, error) {
+	vectorMemories, err := r.vectorMemoryRepo.Erase(ctx, conversationID)
+	if err != nil {
+		return EraseResult{}, fmt.Errorf("semantic: error erasing memories from vector store, %w", err)
+	}
+	// a conversation that is already gone leaves no user or agent to look its shared memories up by
+	conversation, err := r.converstionRepo.FetchOne(ctx, conversationID)
+	if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+		return EraseResult{}, fmt.Errorf("semantic: error fetching conversation, %w", err)
+	}
+	if err == nil {
+		for _, indexID := range sharedIndexIDs(conversation) {
+			shared, err := r.vectorMemoryRepo.EraseShared(ctx, indexID, conversationID)
+			if err != nil {
+				return EraseResult{}, fmt.Errorf("semantic: error erasing shared memories from vector store, %w", err)
+			}
+			vectorMemories += shared
+		}
+	}
+	memories, err := r.rdbmsMemoryRepo.DeleteManyByConversationID(ctx, conversationID)
+	if err != nil {
+		return EraseResult{}, fmt.Errorf("semantic: error erasing memories, %w", err)
+	}
+	return EraseResult{Memories: memories, VectorMemories: vectorMemories}, nil
+}
+
+func (r *SemanticService) EraseUser(ctx context.Context, user string) (int, error) {
+	erased, err := r.vectorMemoryRepo.Erase(ctx, UserIndexID(user))
+	if err != nil {
+		return 0, fmt.Errorf("semantic: error erasing user index, %w", err)
+	}
+	return erased, nil
+}
+
+func (r *SemanticService) Stats(ctx context.Context, conversationIDs []uuid.UUID) (map[uuid.UUID]Stats, error) {
+	rows, err := r.rdbmsMemoryRepo.FetchStats(ctx, conversationIDs)
+	if err != nil {
+		return nil, fmt.Errorf("semantic: error fetching stats, %w", err)
+	}
+	stats := make(map[uuid.UUID]Stats, len(conversationIDs))
+	for _, conversationID := range conversationIDs {
+		row := rows[conversationID]
+		stats[conversationID] = Stats{Memories: row.Count, LastCreatedAt: row.LastCreatedAt}
+	}
+	return stats, nil
+}
+
+func (r *SemanticService) Reindex(ctx context.Context, conversationIDs []uuid.UUID) ([]ReindexResult, error) {
+	results, err := r.vectorMemoryRepo.Reindex(ctx, conversationIDs)
+	reindexed := make([]ReindexResult, len(results))
+	for i, result := range results {
+		reindexed[i] = ReindexResult{
+			ConversationID: result.ConversationID,
+			Memories:       result.Memories,
+		}
+	}
+	if err != nil {
+		return reindexed, fmt.Errorf("semantic: error reindexing, %w", err)
+	}
+	return reindexed, nil
 }
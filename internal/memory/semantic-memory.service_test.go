@@ -0,0 +1,577 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/embedding/embeddingtest"
+	"github.com/haren7/minimal-memory/internal/persistence"
+	"github.com/haren7/minimal-memory/internal/persistence/rdbms"
+	"github.com/haren7/minimal-memory/internal/persistence/rdbms/rdbmstest"
+	"github.com/haren7/minimal-memory/internal/persistence/vector"
+	"github.com/haren7/minimal-memory/internal/summarizer"
+	"github.com/haren7/minimal-memory/internal/tokenizer"
+
+	"github.com/google/uuid"
+)
+
+func TestSemanticServiceRetrieveSimilarOrdering(t *testing.T) {
+	ctx := context.Background()
+	duckdbClient := rdbmstest.NewDuckDBClient(t)
+	embeddingService := embeddingtest.NewFakeService(map[string][]float32{
+		"what is my dog called":  {1, 0, 0},
+		"my dog is called rex":   {0.95, 0.05, 0},
+		"rex likes long walks":   {0.7, 0.7, 0},
+		"i prefer tea to coffee": {0, 0.1, 1},
+		"remind me to buy milk":  {0.2, 1, 0},
+	})
+	conversationRepo := rdbms.NewConversationRepo(duckdbClient)
+	bruteForceClient, err := vector.NewBruteForceClient(vector.MetricL2, "")
+	if err != nil {
+		t.Fatalf("NewBruteForceClient: %v", err)
+	}
+	vectorMemoryRepo := vector.NewBruteForceMemoryRepo(bruteForceClient, embeddingService, rdbms.NewFaissMemoryRepo(duckdbClient))
+	service := NewSemanticService(vectorMemoryRepo, rdbms.NewMemoryRepo(duckdbClient), conversationRepo, summarizer.NewNoOpService())
+
+	conversationID := uuid.New()
+	_, err = conversationRepo.InsertOne(ctx, "agent", "user", conversationID, time.Now())
+	if err != nil {
+		t.Fatalf("InsertOne: %v", err)
+	}
+	// stored so that neither insertion order nor its reverse matches the ranking
+	_, err = service.Store(ctx, conversationID, MemoryInput{Query: "i prefer tea to coffee", Response: "noted"})
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	_, err = service.StoreMany(ctx, conversationID, []MemoryInput{
+		{Query: "rex likes long walks", Response: "noted"},
+		{Query: "remind me to buy milk", Response: "noted"},
+	})
+	if err != nil {
+		t.Fatalf("StoreMany: %v", err)
+	}
+	_, err = service.Store(ctx, conversationID, MemoryInput{Query: "my dog is called rex", Response: "noted"})
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		topK     int
+		minScore float32
+		expect   []string
+	}{
+		{name: "all memories nearest first", topK: 10, expect: []string{"my dog is called rex", "rex likes long walks", "remind me to buy milk", "i prefer tea to coffee"}},
+		{name: "top k", topK: 2, expect: []string{"my dog is called rex", "rex likes long walks"}},
+		{name: "min score drops weak matches", topK: 10, minScore: 0.5, expect: []string{"my dog is called rex", "rex likes long walks"}},
+		{name: "min score above every match", topK: 10, minScore: 1.01, expect: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			memories, err := service.RetrieveSimilar(ctx, conversationID, ScopeConversation, "what is my dog called", test.topK, test.minScore, Filter{})
+			if err != nil {
+				t.Fatalf("RetrieveSimilar: %v", err)
+			}
+			var got []string
+			for i, memory := range memories {
+				got = append(got, memory.Query)
+				if memory.Rank != i+1 {
+					t.Errorf("memory %q has rank %d, want %d", memory.Query, memory.Rank, i+1)
+				}
+				if memory.Score < test.minScore {
+					t.Errorf("memory %q scores %v, below the minimum %v", memory.Query, memory.Score, test.minScore)
+				}
+			}
+			if !slices.Equal(got, test.expect) {
+				t.Fatalf("RetrieveSimilar returned %q, want %q", got, test.expect)
+			}
+		})
+	}
+}
+
+func TestSemanticServiceRetrieveSimilarNegativeScores(t *testing.T) {
+	ctx := context.Background()
+	duckdbClient := rdbmstest.NewDuckDBClient(t)
+	embeddingService := embeddingtest.NewFakeService(map[string][]float32{
+		"what is my dog called": {1, 0},
+		"my dog is called rex":  {1, 0.2},
+		"i have no pets":        {-1, 0.2},
+	})
+	conversationRepo := rdbms.NewConversationRepo(duckdbClient)
+	bruteForceClient, err := vector.NewBruteForceClient(vector.MetricCosine, "")
+	if err != nil {
+		t.Fatalf("NewBruteForceClient: %v", err)
+	}
+	vectorMemoryRepo := vector.NewBruteForceMemoryRepo(bruteForceClient, embeddingService, rdbms.NewFaissMemoryRepo(duckdbClient))
+	service := NewSemanticService(vectorMemoryRepo, rdbms.NewMemoryRepo(duckdbClient), conversationRepo, summarizer.NewNoOpService())
+	conversationID := uuid.New()
+	_, err = conversationRepo.InsertOne(ctx, "agent", "user", conversationID, time.Now())
+	if err != nil {
+		t.Fatalf("InsertOne: %v", err)
+	}
+	_, err = service.StoreMany(ctx, conversationID, []MemoryInput{
+		{Query: "my dog is called rex", Response: "noted"},
+		{Query: "i have no pets", Response: "noted"},
+	})
+	if err != nil {
+		t.Fatalf("StoreMany: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		minScore float32
+		expect   []string
+	}{
+		{name: "no threshold keeps negative cosine", minScore: float32(math.Inf(-1)), expect: []string{"my dog is called rex", "i have no pets"}},
+		{name: "zero drops negative cosine", minScore: 0, expect: []string{"my dog is called rex"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			memories, err := service.RetrieveSimilar(ctx, conversationID, ScopeConversation, "what is my dog called", 10, test.minScore, Filter{})
+			if err != nil {
+				t.Fatalf("RetrieveSimilar: %v", err)
+			}
+			var got []string
+			for _, memory := range memories {
+				got = append(got, memory.Query)
+			}
+			if !slices.Equal(got, test.expect) {
+				t.Fatalf("RetrieveSimilar returned %q, want %q", got, test.expect)
+			}
+		})
+	}
+}
+
+func TestSemanticServiceRetrieveFiltered(t *testing.T) {
+	ctx := context.Background()
+	duckdbClient := rdbmstest.NewDuckDBClient(t)
+	vectors := map[string][]float32{
+		"what is my dog called": {1, 0, 0},
+		"my dog is called rex":  {0.3, 0, 1},
+		"rex likes long walks":  {0, 1, 0.2},
+	}
+	// more nearer memories than the first over-fetch round asks for
+	var memories []MemoryInput
+	for i := range 3 * similarOverFetch {
+		query := "noise " + strconv.Itoa(i)
+		vectors[query] = []float32{1, float32(i+1) / 100, 0}
+		memories = append(memories, MemoryInput{Query: query, Response: "noted", Tags: []string{"noise"}})
+	}
+	memories = append(memories,
+		MemoryInput{Query: "my dog is called rex", Response: "noted", Tags: []string{"pets"}, Metadata: map[string]any{"role": "user", "turn": 3}},
+		MemoryInput{Query: "rex likes long walks", Response: "noted", Tags: []string{"pets", "walks"}, Metadata: map[string]any{"role": "tool", "turn": 4}},
+	)
+	embeddingService := embeddingtest.NewFakeService(vectors)
+	conversationRepo := rdbms.NewConversationRepo(duckdbClient)
+	bruteForceClient, err := vector.NewBruteForceClient(vector.MetricL2, "")
+	if err != nil {
+		t.Fatalf("NewBruteForceClient: %v", err)
+	}
+	vectorMemoryRepo := vector.NewBruteForceMemoryRepo(bruteForceClient, embeddingService, rdbms.NewFaissMemoryRepo(duckdbClient))
+	service := NewSemanticService(vectorMemoryRepo, rdbms.NewMemoryRepo(duckdbClient), conversationRepo, summarizer.NewNoOpService())
+	conversationID := uuid.New()
+	_, err = conversationRepo.InsertOne(ctx, "agent", "user", conversationID, time.Now())
+	if err != nil {
+		t.Fatalf("InsertOne: %v", err)
+	}
+	memoryIDs, err := service.StoreMany(ctx, conversationID, memories)
+	if err != nil {
+		t.Fatalf("StoreMany: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		topK   int
+		filter Filter
+		expect []string
+	}{
+		{name: "tag beyond the first round", topK: 1, filter: Filter{Tags: []string{"pets"}}, expect: []string{"my dog is called rex"}},
+		{name: "topk honored", topK: 2, filter: Filter{Tags: []string{"pets"}}, expect: []string{"my dog is called rex", "rex likes long walks"}},
+		{name: "every tag required", topK: 10, filter: Filter{Tags: []string{"pets", "walks"}}, expect: []string{"rex likes long walks"}},
+		{name: "metadata", topK: 10, filter: Filter{Metadata: map[string]any{"role": "user"}}, expect: []string{"my dog is called rex"}},
+		{name: "metadata numbers compare as json", topK: 10, filter: Filter{Metadata: map[string]any{"turn": 3.0}}, expect: []string{"my dog is called rex"}},
+		{name: "no match", topK: 10, filter: Filter{Metadata: map[string]any{"role": "agent"}}, expect: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			memories, err := service.RetrieveSimilar(ctx, conversationID, ScopeConversation, "what is my dog called", test.topK, 0, test.filter)
+			if err != nil {
+				t.Fatalf("RetrieveSimilar: %v", err)
+			}
+			var got []string
+			for i, memory := range memories {
+				got = append(got, memory.Query)
+				if memory.Rank != i+1 {
+					t.Errorf("memory %q has rank %d, want %d", memory.Query, memory.Rank, i+1)
+				}
+			}
+			if !slices.Equal(got, test.expect) {
+				t.Fatalf("RetrieveSimilar returned %q, want %q", got, test.expect)
+			}
+		})
+	}
+
+	// rows deleted behind the index's back are skipped, the search goes on past them
+	for _, memoryID := range memoryIDs[:similarOverFetch] {
+		_, err := rdbms.NewFaissMemoryRepo(duckdbClient).DeleteOne(ctx, conversationID, memoryID)
+		if err != nil {
+			t.Fatalf("DeleteOne: %v", err)
+		}
+	}
+	similar, err := service.RetrieveSimilar(ctx, conversationID, ScopeConversation, "what is my dog called", 1, 0, Filter{Tags: []string{"pets"}})
+	if err != nil {
+		t.Fatalf("RetrieveSimilar: %v", err)
+	}
+	if len(similar) != 1 || similar[0].Query != "my dog is called rex" {
+		t.Fatalf("RetrieveSimilar past deleted rows returned %+v, want the rex memory", similar)
+	}
+
+	recent, err := service.Retrieve(ctx, conversationID, 1, Filter{Tags: []string{"pets"}})
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if len(recent) != 1 || recent[0].Query != "rex likes long walks" || recent[0].Metadata["role"] != "tool" {
+		t.Fatalf("Retrieve returned %+v, want the latest pets memory with its metadata", recent)
+	}
+}
+
+// pagingMemoryRepo counts the pages read by FetchPageByConversationID.
+type pagingMemoryRepo struct {
+	persistence.MemoryRepoInterface
+	pages int
+}
+
+func (r *pagingMemoryRepo) FetchPageByConversationID(ctx context.Context, conversationID uuid.UUID, filter persistence.MemoryFilter, before persistence.Memory, limit int) ([]persistence.Memory, error) {
+	r.pages++
+	return r.MemoryRepoInterface.FetchPageByConversationID(ctx, conversationID, filter, before, limit)
+}
+
+func TestSemanticServiceRetrieveWithinTokens(t *testing.T) {
+	ctx := context.Background()
+	duckdbClient := rdbmstest.NewDuckDBClient(t)
+	estimated, err := tokenizer.NewEstimated(tokenizer.EncodingCl100k)
+	if err != nil {
+		t.Fatalf("NewEstimated: %v", err)
+	}
+	conversationRepo := rdbms.NewConversationRepo(duckdbClient)
+	memoryRepo := rdbms.NewMemoryRepo(duckdbClient)
+	conversationID := uuid.New()
+	_, err = conversationRepo.InsertOne(ctx, "agent", "user", conversationID, time.Now())
+	if err != nil {
+		t.Fatalf("InsertOne: %v", err)
+	}
+	// each memory estimates to one token of query and one of response
+	var records []persistence.Memory
+	var memories []Memory
+	createdAt := time.Now()
+	for i := range 2*tokenPageSize + 5 {
+		record := persistence.Memory{UUID: uuid.New(), ConversationID: conversationID, Query: "q", Response: "r", CreatedAt: createdAt.Add(time.Duration(i) * time.Second)}
+		records = append(records, record)
+		memories = append(memories, fromRecord(record))
+	}
+	_, err = memoryRepo.InsertMany(ctx, records)
+	if err != nil {
+		t.Fatalf("InsertMany: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		maxTokens int
+		truncate  bool
+		pages     int
+	}{
+		{name: "budget within the first page", maxTokens: 10, pages: 1},
+		{name: "truncated memory on the second page", maxTokens: 2*tokenPageSize + 3, truncate: true, pages: 2},
+		{name: "everything fits", maxTokens: 1000, pages: 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			paging := &pagingMemoryRepo{MemoryRepoInterface: memoryRepo}
+			service := NewSemanticService(nil, paging, conversationRepo, summarizer.NewNoOpService())
+			got, err := service.RetrieveWithinTokens(ctx, conversationID, test.maxTokens, test.truncate, estimated, Filter{})
+			if err != nil {
+				t.Fatalf("RetrieveWithinTokens: %v", err)
+			}
+			expect := WithinTokens(memories, test.maxTokens, test.truncate, estimated)
+			if !slices.EqualFunc(got, expect, func(a, b Memory) bool {
+				return a.ID == b.ID && a.Query == b.Query && a.Response == b.Response && a.Truncated == b.Truncated
+			}) {
+				t.Fatalf("RetrieveWithinTokens returned %d memories, want the %d WithinTokens keeps", len(got), len(expect))
+			}
+			if paging.pages != test.pages {
+				t.Fatalf("RetrieveWithinTokens read %d pages, want %d", paging.pages, test.pages)
+			}
+		})
+	}
+}
+
+func TestSemanticServiceScopes(t *testing.T) {
+	ctx := context.Background()
+	duckdbClient := rdbmstest.NewDuckDBClient(t)
+	embeddingService := embeddingtest.NewFakeService(map[string][]float32{
+		"what is my dog called":  {1, 0, 0},
+		"my dog is called rex":   {0.95, 0.05, 0},
+		"my dog is called max":   {0.9, 0.1, 0},
+		"rex likes long walks":   {0.7, 0.7, 0},
+		"i prefer tea to coffee": {0, 0.1, 1},
+	})
+	conversationRepo := rdbms.NewConversationRepo(duckdbClient)
+	bruteForceClient, err := vector.NewBruteForceClient(vector.MetricL2, "")
+	if err != nil {
+		t.Fatalf("NewBruteForceClient: %v", err)
+	}
+	vectorMemoryRepo := vector.NewBruteForceMemoryRepo(bruteForceClient, embeddingService, rdbms.NewFaissMemoryRepo(duckdbClient))
+	service := NewSemanticService(vectorMemoryRepo, rdbms.NewMemoryRepo(duckdbClient), conversationRepo, summarizer.NewNoOpService())
+	newConversation := func(agent, user string) uuid.UUID {
+		conversationID := uuid.New()
+		_, err := conversationRepo.InsertOne(ctx, agent, user, conversationID, time.Now())
+		if err != nil {
+			t.Fatalf("InsertOne: %v", err)
+		}
+		return conversationID
+	}
+	first := newConversation("assistant", "alice")
+	second := newConversation("planner", "alice")
+	dogID, err := service.Store(ctx, first, MemoryInput{Query: "my dog is called rex", Response: "noted", Scopes: []Scope{ScopeUser}})
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	_, err = service.Store(ctx, first, MemoryInput{Query: "i prefer tea to coffee", Response: "noted"})
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	walkIDs, err := service.StoreMany(ctx, second, []MemoryInput{{Query: "rex likes long walks", Response: "noted", Scopes: []Scope{ScopeUser, ScopeAgent}}})
+	if err != nil {
+		t.Fatalf("StoreMany: %v", err)
+	}
+
+	// similar returns the queries found from a conversation in a scope with the conversations they were stored in
+	similar := func(conversationID uuid.UUID, scope Scope) []string {
+		t.Helper()
+		memories, err := service.RetrieveSimilar(ctx, conversationID, scope, "what is my dog called", 10, 0, Filter{})
+		if err != nil {
+			t.Fatalf("RetrieveSimilar: %v", err)
+		}
+		var got []string
+		for _, memory := range memories {
+			got = append(got, memory.Query+"@"+memory.ConversationID.String())
+		}
+		return got
+	}
+	fresh := newConversation("assistant", "alice")
+	tests := []struct {
+		name           string
+		conversationID uuid.UUID
+		scope          Scope
+		expect         []string
+	}{
+		{name: "new conversation", conversationID: fresh, scope: ScopeConversation, expect: nil},
+		{name: "user", conversationID: fresh, scope: ScopeUser, expect: []string{"my dog is called rex@" + first.String(), "rex likes long walks@" + second.String()}},
+		{name: "agent", conversationID: newConversation("planner", "bob"), scope: ScopeAgent, expect: []string{"rex likes long walks@" + second.String()}},
+		{name: "other user", conversationID: newConversation("planner", "bob"), scope: ScopeUser, expect: nil},
+		{name: "other agent", conversationID: fresh, scope: ScopeAgent, expect: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := similar(test.conversationID, test.scope)
+			if !slices.Equal(got, test.expect) {
+				t.Fatalf("RetrieveSimilar returned %q, want %q", got, test.expect)
+			}
+		})
+	}
+
+	err = service.Update(ctx, first, dogID, "my dog is called max", "noted")
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	err = service.Delete(ctx, second, walkIDs[0])
+	if err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	got := similar(fresh, ScopeUser)
+	if want := []string{"my dog is called max@" + first.String()}; !slices.Equal(got, want) {
+		t.Fatalf("user scope after update and delete returned %q, want %q", got, want)
+	}
+
+	result, err := service.Erase(ctx, first)
+	if err != nil {
+		t.Fatalf("Erase: %v", err)
+	}
+	if result != (EraseResult{Memories: 2, VectorMemories: 3}) {
+		t.Fatalf("Erase returned %+v, want the shared copy counted", result)
+	}
+	if got := similar(fresh, ScopeUser); got != nil {
+		t.Fatalf("user scope after erase returned %q", got)
+	}
+}
+
+type failingMemoryRepo struct {
+	persistence.MemoryRepoInterface
+}
+
+func (r failingMemoryRepo) InsertMany(ctx context.Context, memories []persistence.Memory) ([]int, error) {
+	return nil, errors.New("insert failed")
+}
+
+func (r failingMemoryRepo) InsertOne(ctx context.Context, memory persistence.Memory) (int, error) {
+	return 0, errors.New("insert failed")
+}
+
+// failingVectorMemoryRepo fails to index into failOn only.
+type failingVectorMemoryRepo struct {
+	persistence.VectorMemoryRepoInterface
+	failOn uuid.UUID
+}
+
+func (r failingVectorMemoryRepo) IndexMany(ctx context.Context, conversationID uuid.UUID, memories []persistence.VectorMemory) ([]persistence.VectorMemory, error) {
+	if conversationID == r.failOn {
+		return nil, errors.New("index failed")
+	}
+	return r.VectorMemoryRepoInterface.IndexMany(ctx, conversationID, memories)
+}
+
+func (r failingVectorMemoryRepo) Index(ctx context.Context, conversationID uuid.UUID, memory persistence.VectorMemory) (persistence.VectorMemory, error) {
+	if conversationID == r.failOn {
+		return persistence.VectorMemory{}, errors.New("index failed")
+	}
+	return r.VectorMemoryRepoInterface.Index(ctx, conversationID, memory)
+}
+
+func TestSemanticServiceStoreLeavesNothingOnFailure(t *testing.T) {
+	embeddingService := embeddingtest.NewFakeService(map[string][]float32{
+		"what is my dog called": {1, 0},
+		"my dog is called rex":  {0.95, 0.05},
+		"rex likes long walks":  {0.7, 0.7},
+	})
+	tests := []struct {
+		name       string
+		one        bool
+		failInsert bool
+		failOn     uuid.UUID
+	}{
+		{name: "memories insert fails", failInsert: true},
+		{name: "user index fails", failOn: UserIndexID("user")},
+		{name: "store one memories insert fails", one: true, failInsert: true},
+		{name: "store one user index fails", one: true, failOn: UserIndexID("user")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			duckdbClient := rdbmstest.NewDuckDBClient(t)
+			conversationRepo := rdbms.NewConversationRepo(duckdbClient)
+			bruteForceClient, err := vector.NewBruteForceClient(vector.MetricL2, "")
+			if err != nil {
+				t.Fatalf("NewBruteForceClient: %v", err)
+			}
+			metaRepo := rdbms.NewFaissMemoryRepo(duckdbClient)
+			var vectorMemoryRepo persistence.VectorMemoryRepoInterface = failingVectorMemoryRepo{vector.NewBruteForceMemoryRepo(bruteForceClient, embeddingService, metaRepo), test.failOn}
+			memoryRepo := rdbms.NewMemoryRepo(duckdbClient)
+			serviceMemoryRepo := memoryRepo
+			if test.failInsert {
+				serviceMemoryRepo = failingMemoryRepo{memoryRepo}
+			}
+			service := NewSemanticService(vectorMemoryRepo, serviceMemoryRepo, conversationRepo, summarizer.NewNoOpService())
+			conversationID := uuid.New()
+			_, err = conversationRepo.InsertOne(ctx, "agent", "user", conversationID, time.Now())
+			if err != nil {
+				t.Fatalf("InsertOne: %v", err)
+			}
+
+			if test.one {
+				_, err = service.Store(ctx, conversationID, MemoryInput{Query: "my dog is called rex", Response: "noted", Scopes: []Scope{ScopeUser, ScopeAgent}})
+			} else {
+				_, err = service.StoreMany(ctx, conversationID, []MemoryInput{
+					{Query: "my dog is called rex", Response: "noted", Scopes: []Scope{ScopeUser}},
+					{Query: "rex likes long walks", Response: "noted"},
+				})
+			}
+			if err == nil {
+				t.Fatalf("store succeeded, want an error")
+			}
+			for _, repo := range []persistence.MemoryRepoInterface{memoryRepo, metaRepo} {
+				count, err := repo.Count(ctx)
+				if err != nil {
+					t.Fatalf("Count: %v", err)
+				}
+				if count != 0 {
+					t.Fatalf("%d rows left after a failed store, want 0", count)
+				}
+			}
+			for _, size := range bruteForceClient.IndexSizes() {
+				if size != 0 {
+					t.Fatalf("indexes hold %v vectors after a failed store, want none", bruteForceClient.IndexSizes())
+				}
+			}
+		})
+	}
+}
+
+// countingEmbeddingService counts the texts it embeds.
+type countingEmbeddingService struct {
+	*embeddingtest.FakeService
+	embedded int
+}
+
+func (r *countingEmbeddingService) EmbedOne(ctx context.Context, text string) (embedding.Embedding, error) {
+	r.embedded++
+	return r.FakeService.EmbedOne(ctx, text)
+}
+
+func (r *countingEmbeddingService) EmbedMany(ctx context.Context, texts []string) ([]embedding.Embedding, error) {
+	r.embedded += len(texts)
+	return r.FakeService.EmbedMany(ctx, texts)
+}
+
+func TestSemanticServiceStoreEmbedsOnce(t *testing.T) {
+	ctx := context.Background()
+	duckdbClient := rdbmstest.NewDuckDBClient(t)
+	embeddingService := &countingEmbeddingService{FakeService: embeddingtest.NewFakeService(map[string][]float32{
+		"my dog is called rex": {0.95, 0.05},
+		"rex likes long walks": {0.7, 0.7},
+	})}
+	conversationRepo := rdbms.NewConversationRepo(duckdbClient)
+	bruteForceClient, err := vector.NewBruteForceClient(vector.MetricL2, "")
+	if err != nil {
+		t.Fatalf("NewBruteForceClient: %v", err)
+	}
+	vectorMemoryRepo := vector.NewBruteForceMemoryRepo(bruteForceClient, embeddingService, rdbms.NewFaissMemoryRepo(duckdbClient))
+	service := NewSemanticService(vectorMemoryRepo, rdbms.NewMemoryRepo(duckdbClient), conversationRepo, summarizer.NewNoOpService())
+	conversationID := uuid.New()
+	_, err = conversationRepo.InsertOne(ctx, "agent", "user", conversationID, time.Now())
+	if err != nil {
+		t.Fatalf("InsertOne: %v", err)
+	}
+
+	shared := []Scope{ScopeUser, ScopeAgent}
+	_, err = service.Store(ctx, conversationID, MemoryInput{Query: "my dog is called rex", Response: "noted", Scopes: shared})
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	if embeddingService.embedded != 1 {
+		t.Fatalf("Store embedded %d texts, want 1", embeddingService.embedded)
+	}
+	embeddingService.embedded = 0
+	_, err = service.StoreMany(ctx, conversationID, []MemoryInput{
+		{Query: "my dog is called rex", Response: "noted", Scopes: shared},
+		{Query: "rex likes long walks", Response: "noted", Scopes: []Scope{ScopeUser}},
+	})
+	if err != nil {
+		t.Fatalf("StoreMany: %v", err)
+	}
+	if embeddingService.embedded != 2 {
+		t.Fatalf("StoreMany embedded %d texts, want 2", embeddingService.embedded)
+	}
+	sizes := bruteForceClient.IndexSizes()
+	want := map[uuid.UUID]int64{conversationID: 3, UserIndexID("user"): 3, AgentIndexID("agent"): 2}
+	for indexID, size := range want {
+		if sizes[indexID.String()] != size {
+			t.Fatalf("index sizes are %v, want %v", sizes, want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"slices"
+
+	"github.com/haren7/minimal-memory/internal/tokenizer"
+)
+
+// WithinTokens keeps the most recent of memories, oldest first, whose queries
+// and responses fit in maxTokens together. With truncate, the newest memory
+// that does not fit is cut to the rest of the budget, its query first, and
+// kept as the oldest one.
+func WithinTokens(memories []Memory, maxTokens int, truncate bool, tokenizer tokenizer.Tokenizer) []Memory {
+	budget := &tokenBudget{remaining: maxTokens, truncate: truncate, tokenizer: tokenizer}
+	for i := len(memories) - 1; i >= 0; i-- {
+		if !budget.add(memories[i]) {
+			break
+		}
+	}
+	return budget.memories()
+}
+
+// tokenBudget takes memories newest first for as long as they fit.
+type tokenBudget struct {
+	remaining int
+	truncate  bool
+	tokenizer tokenizer.Tokenizer
+	kept      []Memory
+}
+
+// add keeps memory if it fits and reports whether an older memory may still
+// be added.
+func (r *tokenBudget) add(memory Memory) bool {
+	tokens := r.tokenizer.Count(memory.Query) + r.tokenizer.Count(memory.Response)
+	if tokens <= r.remaining {
+		r.remaining -= tokens
+		r.kept = append(r.kept, memory)
+		return true
+	}
+	if r.truncate && r.remaining > 0 {
+		var queryTokens int
+		memory.Query, queryTokens = r.fit(memory.Query, r.remaining)
+		memory.Response, _ = r.fit(memory.Response, r.remaining-queryTokens)
+		memory.Truncated = true
+		// nothing may fit in what is left, an empty memory is not worth keeping
+		if memory.Query != "" || memory.Response != "" {
+			r.kept = append(r.kept, memory)
+		}
+	}
+	return false
+}
+
+// fit truncates text to what Count measures as at most maxTokens tokens and
+// returns that count. Encoding a truncated prefix again can take more tokens
+// than it was cut to, so it is cut shorter until it fits.
+func (r *tokenBudget) fit(text string, maxTokens int) (string, int) {
+	for limit := maxTokens; limit > 0; limit-- {
+		truncated := r.tokenizer.Truncate(text, limit)
+		tokens := r.tokenizer.Count(truncated)
+		if tokens <= maxTokens {
+			return truncated, tokens
+		}
+	}
+	return "", 0
+}
+
+// memories returns the kept memories oldest first.
+func (r *tokenBudget) memories() []Memory {
+	kept := slices.Clone(r.kept)
+	slices.Reverse(kept)
+	return kept
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/haren7/minimal-memory/internal/tokenizer"
+)
+
+func TestWithinTokens(t *testing.T) {
+	estimated, err := tokenizer.NewEstimated(tokenizer.EncodingCl100k)
+	if err != nil {
+		t.Fatalf("NewEstimated: %v", err)
+	}
+	// each memory estimates to one token of query and one of response
+	memories := []Memory{
+		{Query: "qa", Response: "ra"},
+		{Query: "qb", Response: "rb"},
+		{Query: "qc", Response: "rc"},
+	}
+	tests := []struct {
+		name      string
+		maxTokens int
+		truncate  bool
+		expect    []Memory
+	}{
+		{name: "everything fits", maxTokens: 10, expect: memories},
+		{name: "newest first", maxTokens: 4, expect: memories[1:]},
+		{name: "partial memory left out", maxTokens: 5, expect: memories[1:]},
+		{name: "partial memory truncated", maxTokens: 5, truncate: true, expect: []Memory{
+			{Query: "qa", Truncated: true}, memories[1], memories[2],
+		}},
+		{name: "nothing to truncate into", maxTokens: 4, truncate: true, expect: memories[1:]},
+		{name: "nothing fits", maxTokens: 1, expect: []Memory{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := WithinTokens(memories, test.maxTokens, test.truncate, estimated)
+			if len(got) != len(test.expect) {
+				t.Fatalf("WithinTokens(%d) = %v, want %v", test.maxTokens, got, test.expect)
+			}
+			for i := range got {
+				if got[i].Query != test.expect[i].Query || got[i].Response != test.expect[i].Response || got[i].Truncated != test.expect[i].Truncated {
+					t.Fatalf("WithinTokens(%d)[%d] = %+v, want %+v", test.maxTokens, i, got[i], test.expect[i])
+				}
+			}
+		})
+	}
+}
+
+// overrunningTokenizer counts a token per byte but truncates to one byte more
+// than asked, like a prefix that encodes to more tokens than it was cut to.
+type overrunningTokenizer struct{}
+
+func (overrunningTokenizer) Count(text string) int {
+	return len(text)
+}
+
+func (overrunningTokenizer) Truncate(text string, maxTokens int) string {
+	return text[:min(maxTokens+1, len(text))]
+}
+
+func TestWithinTokensNeverExceedsBudget(t *testing.T) {
+	memories := []Memory{
+		{Query: "aaaa", Response: "bbbb"},
+		{Query: "cc", Response: "dd"},
+	}
+	tests := []struct {
+		name      string
+		maxTokens int
+		expect    []Memory
+	}{
+		{name: "query cut short", maxTokens: 7, expect: []Memory{{Query: "aaa", Truncated: true}, memories[1]}},
+		{name: "response cut short", maxTokens: 10, expect: []Memory{{Query: "aaaa", Response: "bb", Truncated: true}, memories[1]}},
+		{name: "no prefix fits", maxTokens: 5, expect: memories[1:]},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := WithinTokens(memories, test.maxTokens, true, overrunningTokenizer{})
+			tokens := 0
+			for _, memory := range got {
+				tokens += len(memory.Query) + len(memory.Response)
+			}
+			if tokens > test.maxTokens {
+				t.Fatalf("WithinTokens(%d) kept %d tokens", test.maxTokens, tokens)
+			}
+			if len(got) != len(test.expect) {
+				t.Fatalf("WithinTokens(%d) = %v, want %v", test.maxTokens, got, test.expect)
+			}
+			for i := range got {
+				if got[i].Query != test.expect[i].Query || got[i].Response != test.expect[i].Response || got[i].Truncated != test.expect[i].Truncated {
+					t.Fatalf("WithinTokens(%d)[%d] = %+v, want %+v", test.maxTokens, i, got[i], test.expect[i])
+				}
+			}
+		})
+	}
+}
@@ -25,8 +25,54 @@ type RerankerOpts struct {
 }
 
 type Memory struct {
-	ID        uuid.UUID
+	ID uuid.UUID
+	// ConversationID is the conversation the memory was stored in, memories
+	// found in a user or agent index come from any of its conversations.
+	ConversationID uuid.UUID
+	Query          string
+	Response       string
+	CreatedAt      time.Time
+	Metadata       map[string]any
+	Tags           []string
+	// Rank, Distance and Score are only set by similarity retrieval, Rank is
+	// 1-based with the most similar memory first.
+	Rank     int
+	Distance float32
+	Score    float32
+	// Truncated is set on the oldest memory of a token budget when its query
+	// or response was cut to fit.
+	Truncated bool
+}
+
+// ReindexResult is the number of memories a conversation index holds after a reindex.
+type ReindexResult struct {
+	ConversationID uuid.UUID
+	Memories       int
+}
+
+// EraseResult is what erasing a conversation removed, Memories from the
+// memories table and VectorMemories from the vector store, shared copies
+// included.
+type EraseResult struct {
+	Memories       int
+	VectorMemories int
+}
+
+// Stats summarizes the memories of a conversation, LastCreatedAt is zero when
+// it has none.
+type Stats struct {
+	Memories      int
+	LastCreatedAt time.Time
+}
+
+// MemoryInput is one exchange to store, a zero CreatedAt means now.
+type MemoryInput struct {
 	Query     string
 	Response  string
 	CreatedAt time.Time
+	Metadata  map[string]any
+	Tags      []string
+	// Scopes are shared with on top of the conversation, ScopeUser and
+	// ScopeAgent also index the memory where other conversations find it.
+	Scopes []Scope
 }
@@ -0,0 +1,40 @@
+package persistence
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+)
+
+// Namespace keeps the data of one tenant apart from every other tenant, it has
+// its own DuckDB schema, vector indexes and snapshot prefix. The empty
+// Namespace is the default one and keeps the layout from before tenants.
+type Namespace string
+
+var namespacePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// Validate accepts lowercase letters, digits, '_' and '-', the name ends up in
+// schema names, directories and object keys.
+func (r Namespace) Validate() error {
+	if r == "" || namespacePattern.MatchString(string(r)) {
+		return nil
+	}
+	return fmt.Errorf("invalid namespace %q, use up to 63 lowercase letters, digits, '_' or '-'", string(r))
+}
+
+// Schema is the DuckDB schema holding the tables of the namespace.
+func (r Namespace) Schema() string {
+	if r == "" {
+		return "main"
+	}
+	return "tenant_" + string(r)
+}
+
+// Dir nests name under the namespace, the default namespace keeps "/name" and
+// every other one uses "/tenants/<namespace>/name" so no prefix contains another.
+func (r Namespace) Dir(name string) string {
+	if r == "" {
+		return path.Join("/", name)
+	}
+	return path.Join("/tenants", string(r), name)
+}
@@ -0,0 +1,26 @@
+package persistence
+
+import "testing"
+
+func TestNamespace(t *testing.T) {
+	tests := []struct {
+		namespace Namespace
+		valid     bool
+		dir       string
+	}{
+		{namespace: "", valid: true, dir: "/faiss"},
+		{namespace: "acme-1_eu", valid: true, dir: "/tenants/acme-1_eu/faiss"},
+		{namespace: "Acme", valid: false},
+		{namespace: "../acme", valid: false},
+		{namespace: `acme"; DROP TABLE memories; --`, valid: false},
+	}
+	for _, test := range tests {
+		err := test.namespace.Validate()
+		if (err == nil) != test.valid {
+			t.Fatalf("Validate(%q) = %v, want valid %v", test.namespace, err, test.valid)
+		}
+		if test.valid && test.namespace.Dir("faiss") != test.dir {
+			t.Fatalf("Dir(%q) = %q, want %q", test.namespace, test.namespace.Dir("faiss"), test.dir)
+		}
+	}
+}
@@ -2,24 +2,93 @@ package persistence
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var ErrNotFound = errors.New("not found")
+
 type ConversationRepoInterface interface {
 	FetchOne(ctx context.Context, conversationID uuid.UUID) (Conversation, error)
+	FetchMany(ctx context.Context, limit int) ([]Conversation, error)
+	// FetchManyByUser returns every conversation of a user, oldest first.
+	FetchManyByUser(ctx context.Context, user string) ([]Conversation, error)
+	// FetchPage returns up to limit conversations matching filter, newest first,
+	// whose row id is below beforeID. A zero beforeID starts from the newest.
+	FetchPage(ctx context.Context, filter ConversationFilter, beforeID int, limit int) ([]Conversation, error)
 	InsertOne(ctx context.Context, agent, user string, conversationID uuid.UUID, createdAt time.Time) (int, error)
+	UpdateStatus(ctx context.Context, conversationID uuid.UUID, status string) error
+	DeleteOne(ctx context.Context, conversationID uuid.UUID) error
+	Count(ctx context.Context) (int, error)
 }
 
 type MemoryRepoInterface interface {
 	FetchOne(ctx context.Context, conversationID uuid.UUID) (Memory, error)
+	// FetchMany returns memories in the order of memoryIds, skipping unknown ids.
 	FetchMany(ctx context.Context, memoryIds []int) ([]Memory, error)
-	FetchManyByConversationID(ctx context.Context, conversationID uuid.UUID, limit int) ([]Memory, error)
-	InsertOne(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID, query, response string, createdAt time.Time) (int, error)
+	// FetchManyByConversationID returns the latest limit memories of a
+	// conversation matching filter, oldest first.
+	FetchManyByConversationID(ctx context.Context, conversationID uuid.UUID, limit int, filter MemoryFilter) ([]Memory, error)
+	// FetchPageByConversationID returns up to limit memories of a conversation
+	// matching filter, newest first, that come before the memory before. A zero
+	// before starts from the newest.
+	FetchPageByConversationID(ctx context.Context, conversationID uuid.UUID, filter MemoryFilter, before Memory, limit int) ([]Memory, error)
+	InsertOne(ctx context.Context, memory Memory) (int, error)
+	InsertMany(ctx context.Context, memories []Memory) ([]int, error)
+	// FetchEmbeddings returns every memory of a conversation in insertion order
+	// along with its stored embedding. Only the repos of the vector backends keep
+	// embeddings, the others fail.
+	FetchEmbeddings(ctx context.Context, conversationID uuid.UUID) ([]Memory, error)
+	// UpdateEmbeddings stores the Embedding and EmbeddingModel of memories by their ID.
+	UpdateEmbeddings(ctx context.Context, memories []Memory) error
+	// DeleteManyByConversationID removes every memory of a conversation and
+	// returns how many were removed.
+	DeleteManyByConversationID(ctx context.Context, conversationID uuid.UUID) (int, error)
+	// DeleteManyBySource removes the memories sourceConversationID shared with
+	// the index of conversationID and returns their row ids.
+	DeleteManyBySource(ctx context.Context, conversationID uuid.UUID, sourceConversationID uuid.UUID) ([]int, error)
+	// DeleteOne removes a memory of a conversation and returns its row id.
+	DeleteOne(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID) (int, error)
+	// UpdateOne sets the Query, Response and embedding of the memory matching the
+	// UUID and ConversationID of memory and returns its row id.
+	UpdateOne(ctx context.Context, memory Memory) (int, error)
+	// FetchStats returns the memory count and latest creation time of each of
+	// conversationIDs that has memories.
+	FetchStats(ctx context.Context, conversationIDs []uuid.UUID) (map[uuid.UUID]MemoryStats, error)
+	// FetchConversationIDs returns every conversation that has memories.
+	FetchConversationIDs(ctx context.Context) ([]uuid.UUID, error)
+	Count(ctx context.Context) (int, error)
 }
 
 type VectorMemoryRepoInterface interface {
-	Index(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID, query, response string, createdAt time.Time) (VectorMemory, error)
+	// Index embeds and indexes memory under its ID in the index of conversationID,
+	// it returns the memory with its Embedding set. A memory that already
+	// carries an Embedding is not embedded again.
+	Index(ctx context.Context, conversationID uuid.UUID, memory VectorMemory) (VectorMemory, error)
+	IndexMany(ctx context.Context, conversationID uuid.UUID, memories []VectorMemory) ([]VectorMemory, error)
 	Search(ctx context.Context, conversationID uuid.UUID, query string, topK int) ([]VectorMemory, error)
+	// Size returns how many memories the index of conversationID holds.
+	Size(ctx context.Context, conversationID uuid.UUID) (int, error)
+	// Delete removes a memory from the database and the index of its conversation.
+	Delete(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID) error
+	// Update replaces the query and response of a memory and re-embeds its query.
+	Update(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID, query, response string) error
+	// Erase removes every memory of a conversation and drops its index, it
+	// returns how many memories were removed.
+	Erase(ctx context.Context, conversationID uuid.UUID) (int, error)
+	// EraseShared removes the memories sourceConversationID shared with the
+	// user or agent index indexID and returns how many were removed.
+	EraseShared(ctx context.Context, indexID uuid.UUID, sourceConversationID uuid.UUID) (int, error)
+	// Reindex rebuilds the indexes of conversationIDs from the memories stored in
+	// the database, nil rebuilds every conversation that has memories.
+	Reindex(ctx context.Context, conversationIDs []uuid.UUID) ([]ReindexResult, error)
+}
+
+type EmbeddingCacheRepoInterface interface {
+	FetchMany(ctx context.Context, keys []string) (map[string]EmbeddingCacheEntry, error)
+	InsertMany(ctx context.Context, entries []EmbeddingCacheEntry) error
+	Count(ctx context.Context) (int, error)
+	DeleteAll(ctx context.Context) (int, error)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/haren7/minimal-memory/internal/persistence"
@@ -11,33 +12,147 @@ import (
 	"github.com/google/uuid"
 )
 
+const conversationColumns = "id, uuid, agent, user, status, created_at"
+
 type ConversationRepo struct {
-	db *sql.DB
+	db        *sql.DB
+	tableName string
 }
 
-func NewConversationRepo(db *sql.DB) persistence.ConversationRepoInterface {
-	return &ConversationRepo{db}
+func NewConversationRepo(client *DuckDBClient) persistence.ConversationRepoInterface {
+	return &ConversationRepo{db: client.GetDB(), tableName: client.table("conversations")}
 }
 
 func (r *ConversationRepo) FetchOne(ctx context.Context, conversationID uuid.UUID) (persistence.Conversation, error) {
-	query := "SELECT id, uuid, agent, user, created_at FROM conversations WHERE uuid = $1"
+	query := "SELECT " + conversationColumns + " FROM " + r.tableName + " WHERE uuid = $1"
 	row := r.db.QueryRowContext(ctx, query, conversationID)
 	var conversation persistence.Conversation
-	err := row.Scan(&conversation.ID, &conversation.UUID, &conversation.Agent, &conversation.User, &conversation.CreatedAt)
+	err := row.Scan(&conversation.ID, &conversation.UUID, &conversation.Agent, &conversation.User, &conversation.Status, &conversation.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return persistence.Conversation{}, fmt.Errorf("repo: conversation not found for id %s, %w", conversationID, err)
+			return persistence.Conversation{}, fmt.Errorf("repo: conversation not found for id %s, %w", conversationID, persistence.ErrNotFound)
 		}
 		return persistence.Conversation{}, fmt.Errorf("repo: error fetching conversation for id %s, %w", conversationID, err)
 	}
 	return conversation, nil
 }
 
+func (r *ConversationRepo) FetchMany(ctx context.Context, limit int) ([]persistence.Conversation, error) {
+	query := "SELECT " + conversationColumns + " FROM " + r.tableName + " ORDER BY created_at DESC LIMIT $1"
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("repo: error fetching conversations, %w", err)
+	}
+	return scanConversations(rows)
+}
+
+func (r *ConversationRepo) FetchManyByUser(ctx context.Context, user string) ([]persistence.Conversation, error) {
+	query := "SELECT " + conversationColumns + " FROM " + r.tableName + " WHERE user = $1 ORDER BY created_at, id"
+	rows, err := r.db.QueryContext(ctx, query, user)
+	if err != nil {
+		return nil, fmt.Errorf("repo: error fetching conversations by user, %w", err)
+	}
+	return scanConversations(rows)
+}
+
+func (r *ConversationRepo) FetchPage(ctx context.Context, filter persistence.ConversationFilter, beforeID int, limit int) ([]persistence.Conversation, error) {
+	var conditions []string
+	var args []any
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.Agent != "" {
+		where("agent = $%d", filter.Agent)
+	}
+	if filter.User != "" {
+		where("user = $%d", filter.User)
+	}
+	if beforeID > 0 {
+		where("id < $%d", beforeID)
+	}
+	if len(filter.Statuses) > 0 {
+		placeholders := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			args = append(args, status)
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+		conditions = append(conditions, fmt.Sprintf("status IN (%s)", strings.Join(placeholders, ", ")))
+	}
+	query := "SELECT " + conversationColumns + " FROM " + r.tableName
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args))
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("repo: error fetching conversations, %w", err)
+	}
+	return scanConversations(rows)
+}
+
 func (r *ConversationRepo) InsertOne(ctx context.Context, agent string, user string, conversationID uuid.UUID, createdAt time.Time) (int, error) {
 	var insertedID int
-	err := r.db.QueryRowContext(ctx, "INSERT INTO conversations (uuid, agent, user, created_at) VALUES ($1, $2, $3, $4) RETURNING id", conversationID, agent, user, createdAt).Scan(&insertedID)
+	err := r.db.QueryRowContext(ctx, "INSERT INTO "+r.tableName+" (uuid, agent, user, status, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING id", conversationID, agent, user, persistence.ConversationStatusActive, createdAt).Scan(&insertedID)
 	if err != nil {
 		return 0, fmt.Errorf("repo: error inserting conversation, %w", err)
 	}
 	return insertedID, nil
 }
+
+func (r *ConversationRepo) UpdateStatus(ctx context.Context, conversationID uuid.UUID, status string) error {
+	result, err := r.db.ExecContext(ctx, "UPDATE "+r.tableName+" SET status = $1 WHERE uuid = $2", status, conversationID)
+	if err != nil {
+		return fmt.Errorf("repo: error updating status of conversation %s, %w", conversationID, err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("repo: error updating status of conversation %s, %w", conversationID, err)
+	}
+	if updated == 0 {
+		return fmt.Errorf("repo: conversation not found for id %s, %w", conversationID, persistence.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *ConversationRepo) DeleteOne(ctx context.Context, conversationID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM "+r.tableName+" WHERE uuid = $1", conversationID)
+	if err != nil {
+		return fmt.Errorf("repo: error deleting conversation %s, %w", conversationID, err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("repo: error deleting conversation %s, %w", conversationID, err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("repo: conversation not found for id %s, %w", conversationID, persistence.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *ConversationRepo) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT count(*) FROM "+r.tableName).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("repo: error counting conversations, %w", err)
+	}
+	return count, nil
+}
+
+func scanConversations(rows *sql.Rows) ([]persistence.Conversation, error) {
+	defer rows.Close()
+	var conversations []persistence.Conversation
+	for rows.Next() {
+		var conversation persistence.Conversation
+		err := rows.Scan(&conversation.ID, &conversation.UUID, &conversation.Agent, &conversation.User, &conversation.Status, &conversation.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("repo: error scanning conversation, %w", err)
+		}
+		conversations = append(conversations, conversation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: error iterating conversations, %w", err)
+	}
+	return conversations, nil
+}
@@ -1,49 +1,119 @@
 package rdbms
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
+
+	"github.com/haren7/minimal-memory/internal/persistence"
 
 	_ "github.com/duckdb/duckdb-go/v2"
+	"github.com/google/uuid"
 )
 
+// DuckDBClient keeps the tables of one namespace, in the main schema for the
+// default namespace and in a schema of its own for every other one.
 type DuckDBClient struct {
-	db *sql.DB
+	db        *sql.DB
+	namespace persistence.Namespace
 }
 
-func NewDuckDBClient() (*DuckDBClient, error) {
-	db, err := sql.Open("duckdb", "memory.db")
+func NewDuckDBClient(path string) (*DuckDBClient, error) {
+	db, err := sql.Open("duckdb", path)
 	if err != nil {
 		return nil, err
 	}
-	err = createSequences(db)
+	client := &DuckDBClient{db: db}
+	err = client.createTables()
 	if err != nil {
 		return nil, err
 	}
-	err = createMemoryTable(db)
+	return client, nil
+
+}
+
+// Namespace returns a client for the tables of namespace, creating them if
+// needed. It shares the connection, a database file can only be opened once.
+func (r *DuckDBClient) Namespace(namespace persistence.Namespace) (*DuckDBClient, error) {
+	err := namespace.Validate()
 	if err != nil {
 		return nil, err
 	}
-	err = createConversationTable(db)
+	client := &DuckDBClient{db: r.db, namespace: namespace}
+	_, err = r.db.Exec(fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", client.schema()))
 	if err != nil {
 		return nil, err
 	}
-	err = createMemoryMetaTable(db)
+	err = client.createTables()
 	if err != nil {
 		return nil, err
 	}
-	return &DuckDBClient{db: db}, nil
-
+	return client, nil
 }
 
 func (r *DuckDBClient) GetDB() *sql.DB {
 	return r.db
 }
 
+func (r *DuckDBClient) GetNamespace() persistence.Namespace {
+	return r.namespace
+}
+
+func (r *DuckDBClient) schema() string {
+	return fmt.Sprintf(`"%s"`, r.namespace.Schema())
+}
+
+// table qualifies name with the schema of the namespace.
+func (r *DuckDBClient) table(name string) string {
+	return r.schema() + "." + name
+}
+
+func (r *DuckDBClient) createTables() error {
+	err := r.createSequences()
+	if err != nil {
+		return err
+	}
+	err = r.createMemoryTable()
+	if err != nil {
+		return err
+	}
+	err = r.createConversationTable()
+	if err != nil {
+		return err
+	}
+	err = r.createMemoryMetaTable()
+	if err != nil {
+		return err
+	}
+	return r.createEmbeddingCacheTable()
+}
+
+// mountedTables are the tables Mount loads each snapshot file into.
+var mountedTables = map[string]string{
+	"memory.parquet":        "memories",
+	"conversations.parquet": "conversations",
+	"memories_meta.parquet": "memories_meta",
+}
+
 func (r *DuckDBClient) Mount(dir string, files map[string]io.Reader) error {
+	// the files are appended, refuse tables that already hold rows so a repeated pull does not duplicate them
+	for fileName, table := range mountedTables {
+		if _, exists := files[fileName]; !exists {
+			continue
+		}
+		var rows int
+		err := r.db.QueryRow(fmt.Sprintf("SELECT count(*) FROM %s", r.table(table))).Scan(&rows)
+		if err != nil {
+			return fmt.Errorf("error counting rows of %s: %w", table, err)
+		}
+		if rows > 0 {
+			return fmt.Errorf("error mounting file %s: table %s already holds %d rows, mount into an empty database", fileName, table, rows)
+		}
+	}
 	for fileName, reader := range files {
 		if fileName == "memory.parquet" {
 			// write the reader to the file memory.parquet inside dir
@@ -55,8 +125,9 @@ func (r *DuckDBClient) Mount(dir string, files map[string]io.Reader) error {
 			if err != nil {
 				return fmt.Errorf("error writing file %s: %w", fileName, err)
 			}
-			// now use the sql command of duckdb to copy to the table memories
-			_, err = r.db.Exec(fmt.Sprintf("COPY memories FROM '%s' (FORMAT PARQUET)", filepath.Join(dir, "memory.parquet")))
+			// insert by name so snapshots taken before the metadata columns existed still load, and
+			// leave out the embedding columns snapshots taken while memories had them hold
+			_, err = r.db.Exec(fmt.Sprintf("INSERT INTO %s BY NAME SELECT COLUMNS(c -> c NOT IN ('embedding', 'embedding_model', 'embedding_dim')) FROM read_parquet('%s')", r.table("memories"), filepath.Join(dir, "memory.parquet")))
 			if err != nil {
 				return fmt.Errorf("error copying file %s: %w", fileName, err)
 			}
@@ -70,8 +141,8 @@ func (r *DuckDBClient) Mount(dir string, files map[string]io.Reader) error {
 			if err != nil {
 				return fmt.Errorf("error writing file %s: %w", fileName, err)
 			}
-			// now use the sql command of duckdb to copy to the table conversations
-			_, err = r.db.Exec(fmt.Sprintf("COPY conversations FROM '%s' (FORMAT PARQUET)", filepath.Join(dir, "conversations.parquet")))
+			// insert by name so snapshots taken before conversations had a status still load
+			_, err = r.db.Exec(fmt.Sprintf("INSERT INTO %s BY NAME SELECT * FROM read_parquet('%s')", r.table("conversations"), filepath.Join(dir, "conversations.parquet")))
 			if err != nil {
 				return fmt.Errorf("error copying file %s: %w", fileName, err)
 			}
@@ -85,13 +156,38 @@ func (r *DuckDBClient) Mount(dir string, files map[string]io.Reader) error {
 			if err != nil {
 				return fmt.Errorf("error writing file %s: %w", fileName, err)
 			}
-			// now use the sql command of duckdb to copy to the table memories_meta
-			_, err = r.db.Exec(fmt.Sprintf("COPY memories_meta FROM '%s' (FORMAT PARQUET)", filepath.Join(dir, "memories_meta.parquet")))
+			// insert by name so snapshots taken before the embedding and metadata columns existed still load
+			_, err = r.db.Exec(fmt.Sprintf("INSERT INTO %s BY NAME SELECT * FROM read_parquet('%s')", r.table("memories_meta"), filepath.Join(dir, "memories_meta.parquet")))
 			if err != nil {
 				return fmt.Errorf("error copying file %s: %w", fileName, err)
 			}
 		}
 	}
+	return r.advanceSequences()
+}
+
+// sequenceTables are the tables whose ids the sequences of createSequences hand out.
+var sequenceTables = map[string]string{
+	"memories":      "memories_id_seq",
+	"memories_meta": "memories_meta_id_seq",
+	"conversations": "conversations_id_seq",
+}
+
+// advanceSequences moves every sequence past the largest id of its table,
+// mounted rows keep their ids and the next insert would otherwise reuse them.
+func (r *DuckDBClient) advanceSequences() error {
+	for table, sequence := range sequenceTables {
+		var maxID int64
+		err := r.db.QueryRow(fmt.Sprintf("SELECT coalesce(max(id), 0) FROM %s", r.table(table))).Scan(&maxID)
+		if err != nil {
+			return fmt.Errorf("error reading the largest id of %s: %w", table, err)
+		}
+		// a sequence cannot be restarted while a table default uses it, draw from it instead
+		_, err = r.db.Exec(fmt.Sprintf("SELECT max(nextval('%s')) FROM range($1)", r.table(sequence)), maxID)
+		if err != nil {
+			return fmt.Errorf("error advancing sequence %s: %w", sequence, err)
+		}
+	}
 	return nil
 }
 
@@ -100,7 +196,7 @@ func (r *DuckDBClient) Export(dir string) ([]os.File, error) {
 	memoryPath := filepath.Join(dir, "memory.parquet")
 	conversationsPath := filepath.Join(dir, "conversations.parquet")
 	memoriesMetaPath := filepath.Join(dir, "memories_meta.parquet")
-	_, err := r.db.Exec(fmt.Sprintf("COPY (SELECT * FROM memories) TO '%s' (FORMAT PARQUET)", memoryPath))
+	_, err := r.db.Exec(fmt.Sprintf("COPY (SELECT * FROM %s) TO '%s' (FORMAT PARQUET)", r.table("memories"), memoryPath))
 	if err != nil {
 		return nil, err
 	}
@@ -109,7 +205,7 @@ func (r *DuckDBClient) Export(dir string) ([]os.File, error) {
 		return nil, err
 	}
 	files = append(files, *memoryFile)
-	_, err = r.db.Exec(fmt.Sprintf("COPY (SELECT * FROM conversations) TO '%s' (FORMAT PARQUET)", conversationsPath))
+	_, err = r.db.Exec(fmt.Sprintf("COPY (SELECT * FROM %s) TO '%s' (FORMAT PARQUET)", r.table("conversations"), conversationsPath))
 	if err != nil {
 		return nil, err
 	}
@@ -118,7 +214,7 @@ func (r *DuckDBClient) Export(dir string) ([]os.File, error) {
 		return nil, err
 	}
 	files = append(files, *conversationsFile)
-	_, err = r.db.Exec(fmt.Sprintf("COPY (SELECT * FROM memories_meta) TO '%s' (FORMAT PARQUET)", memoriesMetaPath))
+	_, err = r.db.Exec(fmt.Sprintf("COPY (SELECT * FROM %s) TO '%s' (FORMAT PARQUET)", r.table("memories_meta"), memoriesMetaPath))
 	if err != nil {
 		return nil, err
 	}
@@ -130,14 +226,56 @@ func (r *DuckDBClient) Export(dir string) ([]os.File, error) {
 	return files, nil
 }
 
-func createSequences(db *sql.DB) error {
+// ConversationIDs lists every conversation of the namespace.
+func (r *DuckDBClient) ConversationIDs(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf("SELECT uuid FROM %s", r.table("conversations")))
+	if err != nil {
+		return nil, fmt.Errorf("error listing conversations: %w", err)
+	}
+	defer rows.Close()
+	var conversationIDs []uuid.UUID
+	for rows.Next() {
+		var conversationID uuid.UUID
+		err := rows.Scan(&conversationID)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning conversation: %w", err)
+		}
+		conversationIDs = append(conversationIDs, conversationID)
+	}
+	return conversationIDs, rows.Err()
+}
+
+// EraseConversations deletes the rows of conversationIDs from every exported
+// table in one transaction, along with the memories shared from them.
+func (r *DuckDBClient) EraseConversations(ctx context.Context, conversationIDs []uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
 	queries := []string{
-		"CREATE SEQUENCE IF NOT EXISTS memories_id_seq START 1",
-		"CREATE SEQUENCE IF NOT EXISTS memories_meta_id_seq START 1",
-		"CREATE SEQUENCE IF NOT EXISTS conversations_id_seq START 1",
+		fmt.Sprintf("DELETE FROM %s WHERE conversation_id = $1 OR source_conversation_id = $1", r.table("memories")),
+		fmt.Sprintf("DELETE FROM %s WHERE conversation_id = $1 OR source_conversation_id = $1", r.table("memories_meta")),
+		fmt.Sprintf("DELETE FROM %s WHERE uuid = $1", r.table("conversations")),
+	}
+	for _, conversationID := range conversationIDs {
+		for _, query := range queries {
+			_, err := tx.ExecContext(ctx, query, conversationID)
+			if err != nil {
+				return fmt.Errorf("error erasing conversation %s: %w", conversationID, err)
+			}
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error committing erase: %w", err)
 	}
-	for _, query := range queries {
-		_, err := db.Exec(query)
+	return nil
+}
+
+func (r *DuckDBClient) createSequences() error {
+	for _, sequence := range []string{"memories_id_seq", "memories_meta_id_seq", "conversations_id_seq"} {
+		_, err := r.db.Exec(fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS %s START 1", r.table(sequence)))
 		if err != nil {
 			return err
 		}
@@ -145,53 +283,117 @@ func createSequences(db *sql.DB) error {
 	return nil
 }
 
-func createMemoryTable(db *sql.DB) error {
-	query := `
-		CREATE TABLE IF NOT EXISTS memories (
-			id INTEGER PRIMARY KEY DEFAULT nextval('memories_id_seq'),
+func (r *DuckDBClient) createMemoryTable() error {
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id INTEGER PRIMARY KEY DEFAULT nextval('%s'),
 			uuid UUID NOT NULL,
 			conversation_id UUID NOT NULL,
 			query TEXT NOT NULL,
 			response TEXT NOT NULL,
-			created_at TIMESTAMP NOT NULL
+			created_at TIMESTAMP NOT NULL,
+			metadata JSON,
+			tags TEXT[],
+			source_conversation_id UUID
 		)
-	`
-	_, err := db.Exec(query)
+	`, r.table("memories"), r.table("memories_id_seq"))
+	_, err := r.db.Exec(query)
 	if err != nil {
 		return err
 	}
+	err = r.addMemoryColumns(r.table("memories"), addedMemoryColumns)
+	if err != nil {
+		return err
+	}
+	// memories never kept embeddings, only memories_meta does, tables created
+	// while it had the columns drop them
+	for _, column := range []string{"embedding", "embedding_model", "embedding_dim"} {
+		_, err = r.db.Exec(fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s", r.table("memories"), column))
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func createMemoryMetaTable(db *sql.DB) error {
-	query := `
-		CREATE TABLE IF NOT EXISTS memories_meta (
-			id INTEGER PRIMARY KEY DEFAULT nextval('memories_meta_id_seq'),
+func (r *DuckDBClient) createMemoryMetaTable() error {
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id INTEGER PRIMARY KEY DEFAULT nextval('%s'),
 			uuid UUID NOT NULL,
 			conversation_id UUID NOT NULL,
 			query TEXT NOT NULL,
 			response TEXT NOT NULL,
-			created_at TIMESTAMP NOT NULL
+			created_at TIMESTAMP NOT NULL,
+			embedding FLOAT[],
+			embedding_model TEXT,
+			embedding_dim INTEGER,
+			metadata JSON,
+			tags TEXT[],
+			source_conversation_id UUID
 		)
-	`
-	_, err := db.Exec(query)
+	`, r.table("memories_meta"), r.table("memories_meta_id_seq"))
+	_, err := r.db.Exec(query)
 	if err != nil {
 		return err
 	}
+	return r.addMemoryColumns(r.table("memories_meta"), slices.Concat(addedEmbeddingColumns, addedMemoryColumns))
+}
+
+var (
+	addedMemoryColumns    = []string{"metadata JSON", "tags TEXT[]", "source_conversation_id UUID"}
+	addedEmbeddingColumns = []string{"embedding FLOAT[]", "embedding_model TEXT", "embedding_dim INTEGER"}
+)
+
+// addMemoryColumns upgrades tables created before embeddings, metadata, tags
+// and source conversations were stored. Existing rows keep a NULL embedding
+// until they are reindexed. Their metadata and tags stay NULL, only an empty
+// filter matches them, and a NULL source conversation is the row's own
+// conversation.
+func (r *DuckDBClient) addMemoryColumns(table string, columns []string) error {
+	for _, column := range columns {
+		_, err := r.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s", table, column))
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func createConversationTable(db *sql.DB) error {
-	query := `
-		CREATE TABLE IF NOT EXISTS conversations (
-			id INTEGER PRIMARY KEY DEFAULT nextval('conversations_id_seq'),
+func (r *DuckDBClient) createConversationTable() error {
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			id INTEGER PRIMARY KEY DEFAULT nextval('%s'),
 			uuid UUID NOT NULL,
 			agent TEXT NOT NULL,
 			user TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'active',
+			created_at TIMESTAMP NOT NULL
+		)
+	`, r.table("conversations"), r.table("conversations_id_seq"))
+	_, err := r.db.Exec(query)
+	if err != nil {
+		return err
+	}
+	// tables created before conversations could be closed hold only active ones
+	_, err = r.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS status TEXT DEFAULT 'active'", r.table("conversations")))
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// embedding_cache is deliberately left out of Export, it can always be rebuilt.
+func (r *DuckDBClient) createEmbeddingCacheTable() error {
+	query := fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %s (
+			key TEXT PRIMARY KEY,
+			model TEXT NOT NULL,
+			vector FLOAT[] NOT NULL,
 			created_at TIMESTAMP NOT NULL
 		)
-	`
-	_, err := db.Exec(query)
+	`, r.table("embedding_cache"))
+	_, err := r.db.Exec(query)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,243 @@
+package rdbms
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/haren7/minimal-memory/internal/persistence"
+
+	"github.com/google/uuid"
+)
+
+// newTestDuckDBClient is rdbmstest.NewDuckDBClient for the tests of this
+// package, which that package imports.
+func newTestDuckDBClient(t *testing.T) *DuckDBClient {
+	t.Helper()
+	duckdbClient, err := NewDuckDBClient("")
+	if err != nil {
+		t.Fatalf("NewDuckDBClient: %v", err)
+	}
+	t.Cleanup(func() { duckdbClient.GetDB().Close() })
+	return duckdbClient
+}
+
+func TestDuckDBClientNamespacesAreIsolated(t *testing.T) {
+	ctx := context.Background()
+	duckdbClient := newTestDuckDBClient(t)
+	clients := map[persistence.Namespace]*DuckDBClient{"": duckdbClient}
+	for _, namespace := range []persistence.Namespace{"acme", "globex"} {
+		namespaced, err := duckdbClient.Namespace(namespace)
+		if err != nil {
+			t.Fatalf("Namespace(%q): %v", namespace, err)
+		}
+		clients[namespace] = namespaced
+	}
+	conversationID := uuid.New()
+	_, err := NewConversationRepo(clients["acme"]).InsertOne(ctx, "agent", "user", conversationID, time.Now())
+	if err != nil {
+		t.Fatalf("InsertOne: %v", err)
+	}
+	_, err = NewMemoryRepo(clients["acme"]).InsertMany(ctx, []persistence.Memory{{UUID: uuid.New(), ConversationID: conversationID, Query: "q", Response: "r", CreatedAt: time.Now()}})
+	if err != nil {
+		t.Fatalf("InsertMany: %v", err)
+	}
+
+	tests := []struct {
+		namespace     persistence.Namespace
+		conversations int
+		memories      int
+	}{
+		{namespace: "", conversations: 0, memories: 0},
+		{namespace: "acme", conversations: 1, memories: 1},
+		{namespace: "globex", conversations: 0, memories: 0},
+	}
+	for _, test := range tests {
+		conversations, err := NewConversationRepo(clients[test.namespace]).Count(ctx)
+		if err != nil {
+			t.Fatalf("Count(%q): %v", test.namespace, err)
+		}
+		memories, err := NewMemoryRepo(clients[test.namespace]).Count(ctx)
+		if err != nil {
+			t.Fatalf("Count(%q): %v", test.namespace, err)
+		}
+		if conversations != test.conversations || memories != test.memories {
+			t.Fatalf("namespace %q has %d conversations and %d memories, want %d and %d", test.namespace, conversations, memories, test.conversations, test.memories)
+		}
+	}
+
+	// an export of acme mounted into globex lands in globex only
+	dir := t.TempDir()
+	files, err := clients["acme"].Export(dir)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	readers := make(map[string]io.Reader)
+	for i := range files {
+		files[i].Close()
+		file, err := os.Open(files[i].Name())
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		defer file.Close()
+		readers[filepath.Base(file.Name())] = file
+	}
+	err = clients["globex"].Mount(t.TempDir(), readers)
+	if err != nil {
+		t.Fatalf("Mount: %v", err)
+	}
+	for namespace, want := range map[persistence.Namespace]int{"": 0, "globex": 1} {
+		count, err := NewConversationRepo(clients[namespace]).Count(ctx)
+		if err != nil {
+			t.Fatalf("Count(%q): %v", namespace, err)
+		}
+		if count != want {
+			t.Fatalf("namespace %q has %d conversations after mount, want %d", namespace, count, want)
+		}
+	}
+	err = clients["globex"].Mount(t.TempDir(), readers)
+	if err == nil {
+		t.Fatalf("Mount into a namespace that holds rows succeeded, want an error")
+	}
+}
+
+func TestDuckDBClientMountAdvancesSequences(t *testing.T) {
+	ctx := context.Background()
+	source := newTestDuckDBClient(t)
+	conversationID := uuid.New()
+	for range 3 {
+		_, err := NewConversationRepo(source).InsertOne(ctx, "agent", "user", uuid.New(), time.Now())
+		if err != nil {
+			t.Fatalf("InsertOne: %v", err)
+		}
+	}
+	memories := []persistence.Memory{
+		{UUID: uuid.New(), ConversationID: conversationID, Query: "q", Response: "r", CreatedAt: time.Now()},
+		{UUID: uuid.New(), ConversationID: conversationID, Query: "q", Response: "r", CreatedAt: time.Now()},
+	}
+	for _, memoryRepo := range []persistence.MemoryRepoInterface{NewMemoryRepo(source), NewFaissMemoryRepo(source)} {
+		_, err := memoryRepo.InsertMany(ctx, memories)
+		if err != nil {
+			t.Fatalf("InsertMany: %v", err)
+		}
+	}
+	dir := t.TempDir()
+	files, err := source.Export(dir)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	for i := range files {
+		files[i].Close()
+	}
+
+	mounted := newTestDuckDBClient(t)
+	namespaced, err := mounted.Namespace("acme")
+	if err != nil {
+		t.Fatalf("Namespace: %v", err)
+	}
+	tests := []struct {
+		name   string
+		client *DuckDBClient
+	}{
+		{name: "default namespace", client: mounted},
+		{name: "namespace", client: namespaced},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			readers := make(map[string]io.Reader)
+			for i := range files {
+				file, err := os.Open(files[i].Name())
+				if err != nil {
+					t.Fatalf("Open: %v", err)
+				}
+				defer file.Close()
+				readers[filepath.Base(file.Name())] = file
+			}
+			err := test.client.Mount(t.TempDir(), readers)
+			if err != nil {
+				t.Fatalf("Mount: %v", err)
+			}
+			id, err := NewConversationRepo(test.client).InsertOne(ctx, "agent", "user", uuid.New(), time.Now())
+			if err != nil {
+				t.Fatalf("InsertOne after mount: %v", err)
+			}
+			if id != 4 {
+				t.Fatalf("InsertOne after mount returned id %d, want 4", id)
+			}
+			for _, memoryRepo := range []persistence.MemoryRepoInterface{NewMemoryRepo(test.client), NewFaissMemoryRepo(test.client)} {
+				ids, err := memoryRepo.InsertMany(ctx, memories[:1])
+				if err != nil {
+					t.Fatalf("InsertMany after mount: %v", err)
+				}
+				if ids[0] != 3 {
+					t.Fatalf("InsertMany after mount returned id %d, want 3", ids[0])
+				}
+			}
+		})
+	}
+}
+
+func TestDuckDBClientDropsMemoryEmbeddingColumns(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "memory.db")
+	old, err := NewDuckDBClient(path)
+	if err != nil {
+		t.Fatalf("NewDuckDBClient: %v", err)
+	}
+	// memories as it was created while it had the embedding columns
+	for _, column := range addedEmbeddingColumns {
+		_, err = old.GetDB().Exec("ALTER TABLE memories ADD COLUMN " + column)
+		if err != nil {
+			t.Fatalf("ADD COLUMN: %v", err)
+		}
+	}
+	_, err = NewMemoryRepo(old).InsertMany(ctx, []persistence.Memory{{UUID: uuid.New(), ConversationID: uuid.New(), Query: "q", Response: "r", CreatedAt: time.Now()}})
+	if err != nil {
+		t.Fatalf("InsertMany: %v", err)
+	}
+	dir := t.TempDir()
+	files, err := old.Export(dir)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	for i := range files {
+		files[i].Close()
+	}
+	old.GetDB().Close()
+
+	reopened, err := NewDuckDBClient(path)
+	if err != nil {
+		t.Fatalf("NewDuckDBClient: %v", err)
+	}
+	t.Cleanup(func() { reopened.GetDB().Close() })
+	var columns int
+	err = reopened.GetDB().QueryRow("SELECT count(*) FROM information_schema.columns WHERE table_name = 'memories' AND column_name LIKE 'embedding%'").Scan(&columns)
+	if err != nil {
+		t.Fatalf("counting columns: %v", err)
+	}
+	if columns != 0 {
+		t.Fatalf("memories has %d embedding columns after reopening, want 0", columns)
+	}
+
+	// the snapshot still holds them and loads
+	snapshot, err := os.Open(filepath.Join(dir, "memory.parquet"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer snapshot.Close()
+	mounted := newTestDuckDBClient(t)
+	err = mounted.Mount(t.TempDir(), map[string]io.Reader{"memory.parquet": snapshot})
+	if err != nil {
+		t.Fatalf("Mount: %v", err)
+	}
+	count, err := NewMemoryRepo(mounted).Count(ctx)
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("mounted %d memories, want 1", count)
+	}
+}
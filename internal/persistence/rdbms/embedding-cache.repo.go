@@ -0,0 +1,117 @@
+package rdbms
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/haren7/minimal-memory/internal/persistence"
+)
+
+type EmbeddingCacheRepo struct {
+	db        *sql.DB
+	tableName string
+}
+
+func NewEmbeddingCacheRepo(client *DuckDBClient) persistence.EmbeddingCacheRepoInterface {
+	return &EmbeddingCacheRepo{db: client.GetDB(), tableName: client.table("embedding_cache")}
+}
+
+func (r *EmbeddingCacheRepo) FetchMany(ctx context.Context, keys []string) (map[string]persistence.EmbeddingCacheEntry, error) {
+	entries := make(map[string]persistence.EmbeddingCacheEntry, len(keys))
+	if len(keys) == 0 {
+		return entries, nil
+	}
+	placeholders := make([]string, len(keys))
+	args := make([]interface{}, len(keys))
+	for i, key := range keys {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = key
+	}
+	query := fmt.Sprintf(`SELECT key, model, vector, created_at FROM %s WHERE key IN (%s)`, r.tableName, strings.Join(placeholders, ", "))
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("repo: error fetching cached embeddings, %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var entry persistence.EmbeddingCacheEntry
+		var vector []interface{}
+		err := rows.Scan(&entry.Key, &entry.Model, &vector, &entry.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("repo: error scanning cached embedding, %w", err)
+		}
+		entry.Vector, err = toFloat32s(vector)
+		if err != nil {
+			return nil, fmt.Errorf("repo: error decoding cached embedding %s, %w", entry.Key, err)
+		}
+		entries[entry.Key] = entry
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: error iterating cached embeddings, %w", err)
+	}
+	return entries, nil
+}
+
+// InsertMany keeps the first vector stored for a key, concurrent writers of the
+// same text produce the same embedding anyway.
+func (r *EmbeddingCacheRepo) InsertMany(ctx context.Context, entries []persistence.EmbeddingCacheEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: error starting transaction, %w", err)
+	}
+	defer tx.Rollback()
+	for _, entry := range entries {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT OR IGNORE INTO %s (key, model, vector, created_at) VALUES ($1, $2, $3, $4)`, r.tableName), entry.Key, entry.Model, entry.Vector, entry.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("repo: error inserting cached embedding %s, %w", entry.Key, err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("repo: error committing cached embeddings, %w", err)
+	}
+	return nil
+}
+
+func (r *EmbeddingCacheRepo) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s`, r.tableName)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("repo: error counting cached embeddings, %w", err)
+	}
+	return count, nil
+}
+
+// DeleteAll empties the cache, a key is a hash of the text so the entries of
+// one user cannot be told apart from the others.
+func (r *EmbeddingCacheRepo) DeleteAll(ctx context.Context) (int, error) {
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s`, r.tableName))
+	if err != nil {
+		return 0, fmt.Errorf("repo: error deleting cached embeddings, %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("repo: error counting deleted cached embeddings, %w", err)
+	}
+	return int(deleted), nil
+}
+
+func toFloat32s(values []interface{}) ([]float32, error) {
+	vector := make([]float32, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case float32:
+			vector[i] = v
+		case float64:
+			vector[i] = float32(v)
+		default:
+			return nil, fmt.Errorf("unexpected element type %T", value)
+		}
+	}
+	return vector, nil
+}
@@ -3,42 +3,49 @@ package rdbms
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/haren7/minimal-memory/internal/persistence"
 
 	"github.com/google/uuid"
 )
 
+// memoryColumns are the columns scanMemory reads, in order.
+const memoryColumns = "id, uuid, conversation_id, query, response, created_at, metadata, tags, source_conversation_id"
+
 type MemoryRepo struct {
 	db        *sql.DB
 	tableName string
+	// embeddings is set on memories_meta, the only table keeping the vectors
+	// memories were indexed with.
+	embeddings bool
 }
 
-func NewMemoryRepo(db *sql.DB) persistence.MemoryRepoInterface {
-	return &MemoryRepo{db: db, tableName: "memories"}
+func NewMemoryRepo(client *DuckDBClient) persistence.MemoryRepoInterface {
+	return &MemoryRepo{db: client.GetDB(), tableName: client.table("memories")}
 }
 
-func NewFaissMemoryRepo(db *sql.DB) persistence.MemoryRepoInterface {
-	return &MemoryRepo{db: db, tableName: "memories_meta"}
+func NewFaissMemoryRepo(client *DuckDBClient) persistence.MemoryRepoInterface {
+	return &MemoryRepo{db: client.GetDB(), tableName: client.table("memories_meta"), embeddings: true}
 }
 
 func (r *MemoryRepo) FetchOne(ctx context.Context, conversationID uuid.UUID) (persistence.Memory, error) {
-	query := fmt.Sprintf(`SELECT id, uuid, conversation_id, query, response, created_at FROM %s WHERE conversation_id = $1`, r.tableName)
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE conversation_id = $1`, memoryColumns, r.tableName)
 	row := r.db.QueryRowContext(ctx, query, conversationID)
-	var memory persistence.Memory
-	err := row.Scan(&memory.ID, &memory.UUID, &memory.ConversationID, &memory.Query, &memory.Response, &memory.CreatedAt)
+	memory, err := scanMemory(row)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return persistence.Memory{}, fmt.Errorf("repo: memory not found for conversation id %s, %w", conversationID, err)
+			return persistence.Memory{}, fmt.Errorf("repo: memory not found for conversation id %s, %w", conversationID, persistence.ErrNotFound)
 		}
 		return persistence.Memory{}, fmt.Errorf("repo: error fetching memory for conversation id %s, %w", conversationID, err)
 	}
 	return memory, nil
 }
 
+// FetchMany returns the memories in the order of memoryIds, ids that do not
+// exist are skipped. Callers rely on this to keep vector search ranking.
 func (r *MemoryRepo) FetchMany(ctx context.Context, memoryIds []int) ([]persistence.Memory, error) {
 	if len(memoryIds) == 0 {
 		return []persistence.Memory{}, nil
@@ -52,47 +59,464 @@ func (r *MemoryRepo) FetchMany(ctx context.Context, memoryIds []int) ([]persiste
 		args[i] = id
 	}
 
-	query := fmt.Sprintf(`SELECT id, uuid, conversation_id, query, response, created_at FROM %s WHERE id IN (%s)`, r.tableName, strings.Join(placeholders, ", "))
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id IN (%s)`, memoryColumns, r.tableName, strings.Join(placeholders, ", "))
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("repo: error fetching memories, %w", err)
 	}
 	defer rows.Close()
+	idVsMemory := make(map[int]persistence.Memory, len(memoryIds))
+	for rows.Next() {
+		memory, err := scanMemory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repo: error scanning memory, %w", err)
+		}
+		idVsMemory[memory.ID] = memory
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: error iterating memories, %w", err)
+	}
+	memories := make([]persistence.Memory, 0, len(idVsMemory))
+	for _, id := range memoryIds {
+		memory, exists := idVsMemory[id]
+		if exists {
+			memories = append(memories, memory)
+		}
+	}
+	return memories, nil
+}
+
+// FetchManyByConversationID returns the latest limit memories of a conversation
+// matching filter in chronological order, a non-positive limit returns all of
+// them. Metadata values are compared once both sides are normalized by DuckDB,
+// the stored and the wanted JSON are both encoded by encoding/json so key order
+// and number formatting agree.
+func (r *MemoryRepo) FetchManyByConversationID(ctx context.Context, conversationID uuid.UUID, limit int, filter persistence.MemoryFilter) ([]persistence.Memory, error) {
+	conditions, args, err := memoryConditions(conversationID, filter)
+	if err != nil {
+		return nil, err
+	}
+	var limitArg any
+	if limit > 0 {
+		limitArg = limit
+	}
+	args = append(args, limitArg)
+	query := fmt.Sprintf(`SELECT %[1]s FROM (
+		SELECT %[1]s FROM %[2]s WHERE %[3]s ORDER BY created_at DESC, id DESC LIMIT $%[4]d
+	) ORDER BY created_at ASC, id ASC`, memoryColumns, r.tableName, strings.Join(conditions, " AND "), len(args))
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("repo: error fetching memories by conversation id %s, %w", conversationID, err)
+	}
+	defer rows.Close()
 	var memories []persistence.Memory
 	for rows.Next() {
-		var memory persistence.Memory
-		err := rows.Scan(&memory.ID, &memory.UUID, &memory.ConversationID, &memory.Query, &memory.Response, &memory.CreatedAt)
+		memory, err := scanMemory(rows)
 		if err != nil {
 			return nil, fmt.Errorf("repo: error scanning memory, %w", err)
 		}
 		memories = append(memories, memory)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: error iterating memories, %w", err)
+	}
 	return memories, nil
 }
 
-func (r *MemoryRepo) FetchManyByConversationID(ctx context.Context, conversationID uuid.UUID, limit int) ([]persistence.Memory, error) {
-	query := fmt.Sprintf(`SELECT id, uuid, conversation_id, query, response, created_at FROM %s WHERE conversation_id = $1`, r.tableName)
+// FetchPageByConversationID returns up to limit memories of a conversation
+// matching filter, newest first, that come before the memory before in that
+// order. A zero before starts from the newest.
+func (r *MemoryRepo) FetchPageByConversationID(ctx context.Context, conversationID uuid.UUID, filter persistence.MemoryFilter, before persistence.Memory, limit int) ([]persistence.Memory, error) {
+	conditions, args, err := memoryConditions(conversationID, filter)
+	if err != nil {
+		return nil, err
+	}
+	if before.ID != 0 {
+		args = append(args, before.CreatedAt, before.ID)
+		conditions = append(conditions, fmt.Sprintf("(created_at < $%[1]d OR (created_at = $%[1]d AND id < $%[2]d))", len(args)-1, len(args)))
+	}
+	args = append(args, limit)
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE %s ORDER BY created_at DESC, id DESC LIMIT $%d`,
+		memoryColumns, r.tableName, strings.Join(conditions, " AND "), len(args))
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("repo: error fetching memory page by conversation id %s, %w", conversationID, err)
+	}
+	defer rows.Close()
+	var memories []persistence.Memory
+	for rows.Next() {
+		memory, err := scanMemory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("repo: error scanning memory, %w", err)
+		}
+		memories = append(memories, memory)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: error iterating memories, %w", err)
+	}
+	return memories, nil
+}
+
+// memoryConditions returns the WHERE conditions and their arguments selecting
+// the memories of a conversation matching filter.
+func memoryConditions(conversationID uuid.UUID, filter persistence.MemoryFilter) ([]string, []any, error) {
+	conditions := []string{"conversation_id = $1"}
+	args := []any{conversationID}
+	for _, tag := range filter.Tags {
+		args = append(args, tag)
+		conditions = append(conditions, fmt.Sprintf("list_contains(tags, $%d)", len(args)))
+	}
+	for key, value := range filter.Metadata {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("repo: error encoding metadata filter %q, %w", key, err)
+		}
+		args = append(args, metadataPointer(key), string(encoded))
+		conditions = append(conditions, fmt.Sprintf("json_extract(metadata, $%d) = json($%d)", len(args)-1, len(args)))
+	}
+	return conditions, args, nil
+}
+
+func (r *MemoryRepo) InsertOne(ctx context.Context, memory persistence.Memory) (int, error) {
+	insertedIDs, err := r.InsertMany(ctx, []persistence.Memory{memory})
+	if err != nil {
+		return 0, err
+	}
+	return insertedIDs[0], nil
+}
+
+// InsertMany inserts all memories in one transaction and returns their row ids
+// in input order, either every memory is stored or none is.
+func (r *MemoryRepo) InsertMany(ctx context.Context, memories []persistence.Memory) ([]int, error) {
+	if len(memories) == 0 {
+		return []int{}, nil
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("repo: error starting transaction, %w", err)
+	}
+	defer tx.Rollback()
+	insert := fmt.Sprintf(`INSERT INTO %s (conversation_id, uuid, query, response, created_at, metadata, tags, source_conversation_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`, r.tableName)
+	if r.embeddings {
+		insert = fmt.Sprintf(`INSERT INTO %s (conversation_id, uuid, query, response, created_at, metadata, tags, source_conversation_id, embedding, embedding_model, embedding_dim) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`, r.tableName)
+	}
+	stmt, err := tx.PrepareContext(ctx, insert)
+	if err != nil {
+		return nil, fmt.Errorf("repo: error preparing insert, %w", err)
+	}
+	defer stmt.Close()
+	insertedIDs := make([]int, len(memories))
+	for i, memory := range memories {
+		metadata, tags, err := metadataArgs(memory)
+		if err != nil {
+			return nil, fmt.Errorf("repo: error encoding metadata of memory %s, %w", memory.UUID, err)
+		}
+		// rows of their own conversation leave the source NULL
+		var source any
+		if memory.SourceConversationID != uuid.Nil && memory.SourceConversationID != memory.ConversationID {
+			source = memory.SourceConversationID
+		}
+		args := []any{memory.ConversationID, memory.UUID, memory.Query, memory.Response, memory.CreatedAt, metadata, tags, source}
+		if r.embeddings {
+			vector, model, dim := embeddingArgs(memory)
+			args = append(args, vector, model, dim)
+		}
+		err = stmt.QueryRowContext(ctx, args...).Scan(&insertedIDs[i])
+		if err != nil {
+			return nil, fmt.Errorf("repo: error inserting memory %s, %w", memory.UUID, err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("repo: error committing memories, %w", err)
+	}
+	return insertedIDs, nil
+}
+
+// FetchEmbeddings returns every memory of a conversation ordered by id, which
+// is the order they were indexed in.
+func (r *MemoryRepo) FetchEmbeddings(ctx context.Context, conversationID uuid.UUID) ([]persistence.Memory, error) {
+	if !r.embeddings {
+		return nil, fmt.Errorf("repo: %s keeps no embeddings", r.tableName)
+	}
+	query := fmt.Sprintf(`SELECT %s, embedding, embedding_model FROM %s WHERE conversation_id = $1 ORDER BY id`, memoryColumns, r.tableName)
 	rows, err := r.db.QueryContext(ctx, query, conversationID)
 	if err != nil {
-		return nil, fmt.Errorf("repo: error fetching memories by conversation id %s, %w", conversationID, err)
+		return nil, fmt.Errorf("repo: error fetching embeddings by conversation id %s, %w", conversationID, err)
 	}
+	defer rows.Close()
 	var memories []persistence.Memory
 	for rows.Next() {
-		var memory persistence.Memory
-		err = rows.Scan(&memory.ID, &memory.UUID, &memory.ConversationID, &memory.Query, &memory.Response, &memory.CreatedAt)
+		// embedding is NULL on memories stored before it was kept
+		var vector any
+		var model sql.NullString
+		memory, err := scanMemory(rows, &vector, &model)
 		if err != nil {
 			return nil, fmt.Errorf("repo: error scanning memory, %w", err)
 		}
+		if values, ok := vector.([]interface{}); ok {
+			memory.Embedding, err = toFloat32s(values)
+			if err != nil {
+				return nil, fmt.Errorf("repo: error decoding embedding of memory %s, %w", memory.UUID, err)
+			}
+			memory.EmbeddingModel = model.String
+		}
 		memories = append(memories, memory)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: error iterating memories, %w", err)
+	}
 	return memories, nil
 }
 
-func (r *MemoryRepo) InsertOne(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID, query string, response string, createdAt time.Time) (int, error) {
-	var insertedID int
-	err := r.db.QueryRowContext(ctx, fmt.Sprintf(`INSERT INTO %s (conversation_id, uuid, query, response, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING id`, r.tableName), conversationID, memoryID, query, response, createdAt).Scan(&insertedID)
+// UpdateEmbeddings sets the embeddings of memories in one transaction.
+func (r *MemoryRepo) UpdateEmbeddings(ctx context.Context, memories []persistence.Memory) error {
+	if !r.embeddings {
+		return fmt.Errorf("repo: %s keeps no embeddings", r.tableName)
+	}
+	if len(memories) == 0 {
+		return nil
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repo: error starting transaction, %w", err)
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(`UPDATE %s SET embedding = $1, embedding_model = $2, embedding_dim = $3 WHERE id = $4`, r.tableName))
 	if err != nil {
-		return 0, fmt.Errorf("repo: error inserting memory, %w", err)
+		return fmt.Errorf("repo: error preparing update, %w", err)
+	}
+	defer stmt.Close()
+	for _, memory := range memories {
+		vector, model, dim := embeddingArgs(memory)
+		_, err := stmt.ExecContext(ctx, vector, model, dim, memory.ID)
+		if err != nil {
+			return fmt.Errorf("repo: error updating embedding of memory %d, %w", memory.ID, err)
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("repo: error committing embeddings, %w", err)
+	}
+	return nil
+}
+
+func (r *MemoryRepo) DeleteManyByConversationID(ctx context.Context, conversationID uuid.UUID) (int, error) {
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE conversation_id = $1`, r.tableName), conversationID)
+	if err != nil {
+		return 0, fmt.Errorf("repo: error deleting memories of conversation id %s, %w", conversationID, err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("repo: error deleting memories of conversation id %s, %w", conversationID, err)
+	}
+	return int(deleted), nil
+}
+
+func (r *MemoryRepo) DeleteManyBySource(ctx context.Context, conversationID uuid.UUID, sourceConversationID uuid.UUID) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE conversation_id = $1 AND source_conversation_id = $2 RETURNING id`, r.tableName), conversationID, sourceConversationID)
+	if err != nil {
+		return nil, fmt.Errorf("repo: error deleting memories of conversation id %s shared with %s, %w", sourceConversationID, conversationID, err)
+	}
+	defer rows.Close()
+	var deletedIDs []int
+	for rows.Next() {
+		var id int
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, fmt.Errorf("repo: error scanning deleted memory id, %w", err)
+		}
+		deletedIDs = append(deletedIDs, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: error iterating deleted memory ids, %w", err)
+	}
+	return deletedIDs, nil
+}
+
+func (r *MemoryRepo) DeleteOne(ctx context.Context, conversationID uuid.UUID, memoryID uuid.UUID) (int, error) {
+	var deletedID int
+	err := r.db.QueryRowContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE uuid = $1 AND conversation_id = $2 RETURNING id`, r.tableName), memoryID, conversationID).Scan(&deletedID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("repo: memory %s not found for conversation id %s, %w", memoryID, conversationID, persistence.ErrNotFound)
+		}
+		return 0, fmt.Errorf("repo: error deleting memory %s, %w", memoryID, err)
+	}
+	return deletedID, nil
+}
+
+// UpdateOne keeps the row id and created_at of the memory, an empty Embedding
+// clears the stored one.
+func (r *MemoryRepo) UpdateOne(ctx context.Context, memory persistence.Memory) (int, error) {
+	update := fmt.Sprintf(`UPDATE %s SET query = $3, response = $4 WHERE uuid = $1 AND conversation_id = $2 RETURNING id`, r.tableName)
+	args := []any{memory.UUID, memory.ConversationID, memory.Query, memory.Response}
+	if r.embeddings {
+		update = fmt.Sprintf(`UPDATE %s SET query = $3, response = $4, embedding = $5, embedding_model = $6, embedding_dim = $7 WHERE uuid = $1 AND conversation_id = $2 RETURNING id`, r.tableName)
+		vector, model, dim := embeddingArgs(memory)
+		args = append(args, vector, model, dim)
+	}
+	var updatedID int
+	err := r.db.QueryRowContext(ctx, update, args...).Scan(&updatedID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("repo: memory %s not found for conversation id %s, %w", memory.UUID, memory.ConversationID, persistence.ErrNotFound)
+		}
+		return 0, fmt.Errorf("repo: error updating memory %s, %w", memory.UUID, err)
+	}
+	return updatedID, nil
+}
+
+func (r *MemoryRepo) FetchStats(ctx context.Context, conversationIDs []uuid.UUID) (map[uuid.UUID]persistence.MemoryStats, error) {
+	stats := make(map[uuid.UUID]persistence.MemoryStats, len(conversationIDs))
+	if len(conversationIDs) == 0 {
+		return stats, nil
+	}
+	placeholders := make([]string, len(conversationIDs))
+	args := make([]any, len(conversationIDs))
+	for i, conversationID := range conversationIDs {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = conversationID
+	}
+	query := fmt.Sprintf(`SELECT conversation_id, count(*), max(created_at) FROM %s WHERE conversation_id IN (%s) GROUP BY conversation_id`, r.tableName, strings.Join(placeholders, ", "))
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("repo: error fetching memory stats, %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var conversationID uuid.UUID
+		var stat persistence.MemoryStats
+		err = rows.Scan(&conversationID, &stat.Count, &stat.LastCreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("repo: error scanning memory stats, %w", err)
+		}
+		stats[conversationID] = stat
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: error iterating memory stats, %w", err)
+	}
+	return stats, nil
+}
+
+func (r *MemoryRepo) FetchConversationIDs(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`SELECT DISTINCT conversation_id FROM %s ORDER BY conversation_id`, r.tableName))
+	if err != nil {
+		return nil, fmt.Errorf("repo: error fetching conversation ids, %w", err)
+	}
+	defer rows.Close()
+	var conversationIDs []uuid.UUID
+	for rows.Next() {
+		var conversationID uuid.UUID
+		err = rows.Scan(&conversationID)
+		if err != nil {
+			return nil, fmt.Errorf("repo: error scanning conversation id, %w", err)
+		}
+		conversationIDs = append(conversationIDs, conversationID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("repo: error iterating conversation ids, %w", err)
+	}
+	return conversationIDs, nil
+}
+
+func (r *MemoryRepo) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT count(*) FROM %s`, r.tableName)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("repo: error counting memories, %w", err)
+	}
+	return count, nil
+}
+
+// embeddingArgs returns the embedding columns of a memory, all NULL when it has none.
+func embeddingArgs(memory persistence.Memory) (any, any, any) {
+	if len(memory.Embedding) == 0 {
+		return nil, nil, nil
+	}
+	return memory.Embedding, memory.EmbeddingModel, len(memory.Embedding)
+}
+
+// metadataPointer returns the JSON pointer to key at the top of a metadata object.
+func metadataPointer(key string) string {
+	return "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+// metadataArgs returns the metadata and tags columns of a memory, NULL when it has none.
+func metadataArgs(memory persistence.Memory) (any, any, error) {
+	var metadata, tags any
+	if len(memory.Metadata) > 0 {
+		encoded, err := json.Marshal(memory.Metadata)
+		if err != nil {
+			return nil, nil, err
+		}
+		metadata = string(encoded)
+	}
+	if len(memory.Tags) > 0 {
+		tags = memory.Tags
+	}
+	return metadata, tags, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanMemory reads the memoryColumns of a row followed by extra columns into extra.
+func scanMemory(row rowScanner, extra ...any) (persistence.Memory, error) {
+	var memory persistence.Memory
+	var metadata, tags any
+	var source uuid.NullUUID
+	dest := append([]any{&memory.ID, &memory.UUID, &memory.ConversationID, &memory.Query, &memory.Response, &memory.CreatedAt, &metadata, &tags, &source}, extra...)
+	err := row.Scan(dest...)
+	if err != nil {
+		return persistence.Memory{}, err
+	}
+	memory.SourceConversationID = memory.ConversationID
+	if source.Valid {
+		memory.SourceConversationID = source.UUID
+	}
+	memory.Metadata, err = toMetadata(metadata)
+	if err != nil {
+		return persistence.Memory{}, fmt.Errorf("error decoding metadata of memory %s, %w", memory.UUID, err)
+	}
+	memory.Tags, err = toStrings(tags)
+	if err != nil {
+		return persistence.Memory{}, fmt.Errorf("error decoding tags of memory %s, %w", memory.UUID, err)
+	}
+	return memory, nil
+}
+
+// toMetadata decodes a JSON column, the driver hands objects over decoded.
+func toMetadata(value any) (map[string]any, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		return v, nil
+	case string:
+		var metadata map[string]any
+		err := json.Unmarshal([]byte(v), &metadata)
+		return metadata, err
+	}
+	return nil, fmt.Errorf("unexpected metadata type %T", value)
+}
+
+func toStrings(value any) ([]string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	values, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected list type %T", value)
+	}
+	strs := make([]string, len(values))
+	for i, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected element type %T", value)
+		}
+		strs[i] = str
 	}
-	return insertedID, nil
+	return strs, nil
 }
@@ -0,0 +1,222 @@
+package rdbms
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/haren7/minimal-memory/internal/persistence"
+
+	"github.com/google/uuid"
+)
+
+func newTestMemoryRepo(t *testing.T) persistence.MemoryRepoInterface {
+	t.Helper()
+	return NewMemoryRepo(newTestDuckDBClient(t))
+}
+
+func TestMemoryRepoFetchManyPreservesOrder(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestMemoryRepo(t)
+	conversationID := uuid.New()
+	createdAt := time.Now()
+	var memories []persistence.Memory
+	for _, query := range []string{"first", "second", "third", "fourth"} {
+		memories = append(memories, persistence.Memory{
+			UUID:           uuid.New(),
+			ConversationID: conversationID,
+			Query:          query,
+			Response:       "response to " + query,
+			CreatedAt:      createdAt,
+		})
+	}
+	ids, err := repo.InsertMany(ctx, memories)
+	if err != nil {
+		t.Fatalf("InsertMany: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		ids    []int
+		expect []string
+	}{
+		{name: "reversed", ids: []int{ids[3], ids[2], ids[1], ids[0]}, expect: []string{"fourth", "third", "second", "first"}},
+		{name: "interleaved", ids: []int{ids[2], ids[0], ids[3], ids[1]}, expect: []string{"third", "first", "fourth", "second"}},
+		{name: "unknown ids are skipped", ids: []int{ids[1], 999999, ids[0]}, expect: []string{"second", "first"}},
+		{name: "empty", ids: nil, expect: []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fetched, err := repo.FetchMany(ctx, test.ids)
+			if err != nil {
+				t.Fatalf("FetchMany: %v", err)
+			}
+			got := make([]string, 0, len(fetched))
+			for _, memory := range fetched {
+				got = append(got, memory.Query)
+			}
+			if !slices.Equal(got, test.expect) {
+				t.Fatalf("FetchMany(%v) = %v, want %v", test.ids, got, test.expect)
+			}
+		})
+	}
+}
+
+func TestMemoryRepoStoresEmbeddings(t *testing.T) {
+	ctx := context.Background()
+	duckdbClient := newTestDuckDBClient(t)
+	// only memories_meta keeps embeddings
+	_, err := NewMemoryRepo(duckdbClient).FetchEmbeddings(ctx, uuid.New())
+	if err == nil {
+		t.Fatalf("FetchEmbeddings of memories succeeded, want an error")
+	}
+	repo := NewFaissMemoryRepo(duckdbClient)
+	conversationID := uuid.New()
+	ids, err := repo.InsertMany(ctx, []persistence.Memory{
+		{UUID: uuid.New(), ConversationID: conversationID, Query: "embedded", Response: "ok", CreatedAt: time.Now(), Embedding: []float32{0.5, -1, 2}, EmbeddingModel: "model"},
+		{UUID: uuid.New(), ConversationID: conversationID, Query: "not embedded", Response: "ok", CreatedAt: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("InsertMany: %v", err)
+	}
+	memories, err := repo.FetchEmbeddings(ctx, conversationID)
+	if err != nil {
+		t.Fatalf("FetchEmbeddings: %v", err)
+	}
+	if len(memories) != 2 || !slices.Equal(memories[0].Embedding, []float32{0.5, -1, 2}) || memories[0].EmbeddingModel != "model" {
+		t.Fatalf("FetchEmbeddings returned %+v", memories)
+	}
+	if memories[1].Embedding != nil || memories[1].EmbeddingModel != "" {
+		t.Fatalf("memory stored without an embedding has %v from %q", memories[1].Embedding, memories[1].EmbeddingModel)
+	}
+
+	err = repo.UpdateEmbeddings(ctx, []persistence.Memory{{ID: ids[1], Embedding: []float32{3, 4, 5}, EmbeddingModel: "other"}})
+	if err != nil {
+		t.Fatalf("UpdateEmbeddings: %v", err)
+	}
+	memories, err = repo.FetchEmbeddings(ctx, conversationID)
+	if err != nil {
+		t.Fatalf("FetchEmbeddings: %v", err)
+	}
+	if !slices.Equal(memories[1].Embedding, []float32{3, 4, 5}) || memories[1].EmbeddingModel != "other" {
+		t.Fatalf("updated memory has %v from %q", memories[1].Embedding, memories[1].EmbeddingModel)
+	}
+	if !slices.Equal(memories[0].Embedding, []float32{0.5, -1, 2}) {
+		t.Fatalf("UpdateEmbeddings changed another memory to %v", memories[0].Embedding)
+	}
+}
+
+func TestMemoryRepoFetchManyByConversationIDFilters(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestMemoryRepo(t)
+	conversationID := uuid.New()
+	createdAt := time.Now()
+	memories := []persistence.Memory{
+		{Query: "first", Tags: []string{"pets"}, Metadata: map[string]any{"role": "user", "turn": 1, "a/b": true}},
+		{Query: "second", Tags: []string{"pets", "walks"}, Metadata: map[string]any{"role": "tool", "place": map[string]any{"city": "oslo", "zip": "0150"}}},
+		{Query: "third", Metadata: map[string]any{"role": "user", "turn": 3}},
+		{Query: "fourth", Tags: []string{"pets"}},
+	}
+	for i := range memories {
+		memories[i].UUID = uuid.New()
+		memories[i].ConversationID = conversationID
+		memories[i].Response = "noted"
+		memories[i].CreatedAt = createdAt.Add(time.Duration(i) * time.Second)
+	}
+	_, err := repo.InsertMany(ctx, memories)
+	if err != nil {
+		t.Fatalf("InsertMany: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		limit  int
+		filter persistence.MemoryFilter
+		expect []string
+	}{
+		{name: "no filter", expect: []string{"first", "second", "third", "fourth"}},
+		{name: "tag", filter: persistence.MemoryFilter{Tags: []string{"pets"}}, expect: []string{"first", "second", "fourth"}},
+		{name: "every tag required", filter: persistence.MemoryFilter{Tags: []string{"pets", "walks"}}, expect: []string{"second"}},
+		{name: "limit applies after the filter", limit: 1, filter: persistence.MemoryFilter{Metadata: map[string]any{"role": "user"}}, expect: []string{"third"}},
+		{name: "numbers compare as json", filter: persistence.MemoryFilter{Metadata: map[string]any{"turn": 1.0}}, expect: []string{"first"}},
+		{name: "nested object in any key order", filter: persistence.MemoryFilter{Metadata: map[string]any{"place": map[string]any{"zip": "0150", "city": "oslo"}}}, expect: []string{"second"}},
+		{name: "key with a slash", filter: persistence.MemoryFilter{Metadata: map[string]any{"a/b": true}}, expect: []string{"first"}},
+		{name: "tag and metadata", filter: persistence.MemoryFilter{Tags: []string{"pets"}, Metadata: map[string]any{"role": "user"}}, expect: []string{"first"}},
+		{name: "no match", filter: persistence.MemoryFilter{Metadata: map[string]any{"role": "agent"}}, expect: []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fetched, err := repo.FetchManyByConversationID(ctx, conversationID, test.limit, test.filter)
+			if err != nil {
+				t.Fatalf("FetchManyByConversationID: %v", err)
+			}
+			got := make([]string, 0, len(fetched))
+			for _, memory := range fetched {
+				got = append(got, memory.Query)
+			}
+			if !slices.Equal(got, test.expect) {
+				t.Fatalf("FetchManyByConversationID returned %q, want %q", got, test.expect)
+			}
+		})
+	}
+}
+
+func TestMemoryRepoFetchPageByConversationID(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestMemoryRepo(t)
+	conversationID := uuid.New()
+	createdAt := time.Now()
+	var memories []persistence.Memory
+	for i := range 7 {
+		memories = append(memories, persistence.Memory{
+			UUID:           uuid.New(),
+			ConversationID: conversationID,
+			Query:          strconv.Itoa(i),
+			Response:       "noted",
+			Tags:           []string{[]string{"even", "odd"}[i%2]},
+			// pairs of memories share a creation time, the row id orders them
+			CreatedAt: createdAt.Add(time.Duration(i/2) * time.Second),
+		})
+	}
+	_, err := repo.InsertMany(ctx, memories)
+	if err != nil {
+		t.Fatalf("InsertMany: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		limit  int
+		filter persistence.MemoryFilter
+		expect [][]string
+	}{
+		{name: "pages", limit: 3, expect: [][]string{{"6", "5", "4"}, {"3", "2", "1"}, {"0"}}},
+		{name: "one page", limit: 10, expect: [][]string{{"6", "5", "4", "3", "2", "1", "0"}}},
+		{name: "filtered", limit: 2, filter: persistence.MemoryFilter{Tags: []string{"odd"}}, expect: [][]string{{"5", "3"}, {"1"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got [][]string
+			var before persistence.Memory
+			for {
+				page, err := repo.FetchPageByConversationID(ctx, conversationID, test.filter, before, test.limit)
+				if err != nil {
+					t.Fatalf("FetchPageByConversationID: %v", err)
+				}
+				if len(page) == 0 {
+					break
+				}
+				var queries []string
+				for _, memory := range page {
+					queries = append(queries, memory.Query)
+				}
+				got = append(got, queries)
+				before = page[len(page)-1]
+			}
+			if !slices.EqualFunc(got, test.expect, slices.Equal) {
+				t.Fatalf("FetchPageByConversationID paged %q, want %q", got, test.expect)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+// Package rdbmstest provides in-memory DuckDB databases for tests.
+package rdbmstest
+
+import (
+	"testing"
+
+	"github.com/haren7/minimal-memory/internal/persistence/rdbms"
+)
+
+// NewDuckDBClient opens an in-memory database with every table created, it is
+// closed when the test ends.
+func NewDuckDBClient(t testing.TB) *rdbms.DuckDBClient {
+	t.Helper()
+	duckdbClient, err := rdbms.NewDuckDBClient("")
+	if err != nil {
+		t.Fatalf("NewDuckDBClient: %v", err)
+	}
+	t.Cleanup(func() { duckdbClient.GetDB().Close() })
+	return duckdbClient
+}
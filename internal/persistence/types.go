@@ -11,9 +11,37 @@ type Conversation struct {
 	UUID      uuid.UUID `db:"uuid"`
 	Agent     string    `db:"agent"`
 	User      string    `db:"user"`
+	Status    string    `db:"status"`
 	CreatedAt time.Time `db:"created_at"`
 }
 
+// Conversation statuses, closed and archived conversations take no new memories.
+const (
+	ConversationStatusActive   = "active"
+	ConversationStatusClosed   = "closed"
+	ConversationStatusArchived = "archived"
+)
+
+// ConversationFilter selects conversations, empty fields match every conversation.
+type ConversationFilter struct {
+	Agent    string
+	User     string
+	Statuses []string
+}
+
+// MemoryFilter selects memories carrying all of Tags and the JSON encoding of
+// each value of Metadata under its key, empty fields match every memory.
+type MemoryFilter struct {
+	Tags     []string
+	Metadata map[string]any
+}
+
+// MemoryStats summarizes the memories of a conversation.
+type MemoryStats struct {
+	Count         int
+	LastCreatedAt time.Time
+}
+
 type Memory struct {
 	ID             int       `db:"id"`
 	UUID           uuid.UUID `db:"uuid"`
@@ -21,6 +49,18 @@ type Memory struct {
 	Query          string    `db:"query"`
 	Response       string    `db:"response"`
 	CreatedAt      time.Time `db:"created_at"`
+	// Metadata and Tags are stored as given and let retrieval filter memories.
+	Metadata map[string]any `db:"metadata"`
+	Tags     []string       `db:"tags"`
+	// SourceConversationID is the conversation a memory was stored in, it only
+	// differs from ConversationID in the user and agent indexes memories are
+	// shared with. It is ConversationID when fetched and zero means the same.
+	SourceConversationID uuid.UUID `db:"source_conversation_id"`
+	// Embedding is the raw vector Query was indexed with, so indexes can be
+	// rebuilt without calling the embedding provider. It is only loaded by
+	// FetchEmbeddings and is nil for memories stored before it was kept.
+	Embedding      []float32 `db:"embedding"`
+	EmbeddingModel string    `db:"embedding_model"`
 }
 
 type VectorMemory struct {
@@ -29,4 +69,33 @@ type VectorMemory struct {
 	Query          string
 	Response       string
 	CreatedAt      time.Time
+	Metadata       map[string]any
+	Tags           []string
+	// SourceConversationID is the conversation the memory was stored in, zero
+	// means ConversationID.
+	SourceConversationID uuid.UUID
+	// Embedding is the embedding of Query made by EmbeddingModel, Index embeds
+	// memories that carry none.
+	Embedding      []float32
+	EmbeddingModel string
+	// Rank is the 1-based position of a search result, nearest first.
+	Rank int
+	// Distance is the raw distance reported by the vector store, lower is closer.
+	Distance float32
+	// Score is the similarity, higher is closer. L2 distances map onto (0, 1]
+	// and cosine similarities are reported as is.
+	Score float32
+}
+
+// ReindexResult is the number of memories a conversation index holds after a reindex.
+type ReindexResult struct {
+	ConversationID uuid.UUID
+	Memories       int
+}
+
+type EmbeddingCacheEntry struct {
+	Key       string    `db:"key"`
+	Model     string    `db:"model"`
+	Vector    []float32 `db:"vector"`
+	CreatedAt time.Time `db:"created_at"`
 }
@@ -0,0 +1,216 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/embedding/embeddingtest"
+	"github.com/haren7/minimal-memory/internal/persistence"
+
+	"github.com/google/uuid"
+)
+
+// vectors places the memories at known distances from the query, they are
+// stored middle, farthest, nearest so that id order differs from rank order.
+var vectors = map[string][]float32{
+	"query":    {1, 0, 0, 0},
+	"nearest":  {0.9, 0.1, 0, 0},
+	"middle":   {0.6, 0.8, 0, 0},
+	"farthest": {0, 0, 0, 1},
+}
+
+var storeOrder = []string{"middle", "farthest", "nearest"}
+
+// testBackend builds a memory repo over empty indexes along with the store
+// holding them.
+type testBackend struct {
+	name    string
+	newRepo func(t *testing.T) (persistence.VectorMemoryRepoInterface, vectorStore)
+}
+
+func chromemTestBackend(embeddingService embedding.ServiceInterface, metric Metric) testBackend {
+	return testBackend{name: "chromem", newRepo: func(t *testing.T) (persistence.VectorMemoryRepoInterface, vectorStore) {
+		return newTestChromemMemoryRepo(t, metric, embeddingService)
+	}}
+}
+
+func indexOneByOne(ctx context.Context, repo persistence.VectorMemoryRepoInterface, conversationID uuid.UUID) error {
+	for _, query := range storeOrder {
+		_, err := repo.Index(ctx, conversationID, persistence.VectorMemory{
+			ID:        uuid.New(),
+			Query:     query,
+			Response:  "response to " + query,
+			CreatedAt: time.Now(),
+			Metadata:  map[string]any{"query": query},
+			Tags:      []string{"stored"},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func indexMany(ctx context.Context, repo persistence.VectorMemoryRepoInterface, conversationID uuid.UUID) error {
+	var memories []persistence.VectorMemory
+	for _, query := range storeOrder {
+		memories = append(memories, persistence.VectorMemory{
+			ID:        uuid.New(),
+			Query:     query,
+			Response:  "response to " + query,
+			CreatedAt: time.Now(),
+		})
+	}
+	_, err := repo.IndexMany(ctx, conversationID, memories)
+	return err
+}
+
+func TestMemoryRepoDeleteAndUpdate(t *testing.T) {
+	embeddingService := embeddingtest.NewFakeService(vectors)
+	tests := append(faissTestBackends(embeddingService, MetricL2),
+		testBackend{name: "bruteforce", newRepo: func(t *testing.T) (persistence.VectorMemoryRepoInterface, vectorStore) {
+			return newTestBruteForceMemoryRepo(t, MetricL2, embeddingService)
+		}},
+		chromemTestBackend(embeddingService, ""),
+	)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo, store := test.newRepo(t)
+			conversationID := uuid.New()
+			err := indexMany(ctx, repo, conversationID)
+			if err != nil {
+				t.Fatalf("IndexMany: %v", err)
+			}
+			stored, err := repo.Search(ctx, conversationID, "query", 10)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			queryVsID := make(map[string]uuid.UUID)
+			for _, memory := range stored {
+				queryVsID[memory.Query] = memory.ID
+			}
+
+			err = repo.Delete(ctx, conversationID, queryVsID["nearest"])
+			if err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			// farthest now sits where nearest was and keeps its id and creation time
+			err = repo.Update(ctx, conversationID, queryVsID["farthest"], "nearest", "updated")
+			if err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+			got, err := repo.Search(ctx, conversationID, "query", 10)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if !slices.Equal(queriesOf(got), []string{"nearest", "middle"}) {
+				t.Fatalf("Search ranked %v after delete and update", queriesOf(got))
+			}
+			if got[0].ID != queryVsID["farthest"] || got[0].Response != "updated" {
+				t.Fatalf("updated memory is %+v, want id %s and the new response", got[0], queryVsID["farthest"])
+			}
+			for _, size := range store.IndexSizes() {
+				if size != 2 {
+					t.Fatalf("index holds %d vectors after a delete, want 2", size)
+				}
+			}
+
+			err = repo.Delete(ctx, conversationID, queryVsID["nearest"])
+			if !errors.Is(err, persistence.ErrNotFound) {
+				t.Fatalf("Delete of a deleted memory returned %v, want %v", err, persistence.ErrNotFound)
+			}
+			err = repo.Update(ctx, uuid.New(), queryVsID["middle"], "middle", "updated")
+			if !errors.Is(err, persistence.ErrNotFound) {
+				t.Fatalf("Update in another conversation returned %v, want %v", err, persistence.ErrNotFound)
+			}
+		})
+	}
+}
+
+func TestMemoryRepoErase(t *testing.T) {
+	embeddingService := embeddingtest.NewFakeService(vectors)
+	tests := append(faissTestBackends(embeddingService, MetricL2),
+		testBackend{name: "bruteforce", newRepo: func(t *testing.T) (persistence.VectorMemoryRepoInterface, vectorStore) {
+			return newTestBruteForceMemoryRepo(t, MetricL2, embeddingService)
+		}},
+		chromemTestBackend(embeddingService, ""),
+	)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo, store := test.newRepo(t)
+			erased, kept := uuid.New(), uuid.New()
+			for _, conversationID := range []uuid.UUID{erased, kept} {
+				err := indexMany(ctx, repo, conversationID)
+				if err != nil {
+					t.Fatalf("IndexMany: %v", err)
+				}
+			}
+
+			count, err := repo.Erase(ctx, erased)
+			if err != nil {
+				t.Fatalf("Erase: %v", err)
+			}
+			if count != len(storeOrder) {
+				t.Fatalf("Erase removed %d memories, want %d", count, len(storeOrder))
+			}
+			sizes := store.IndexSizes()
+			if _, ok := sizes[erased.String()]; ok || len(sizes) != 1 {
+				t.Fatalf("indexes after erase are %v, want only %s", sizes, kept)
+			}
+			got, err := repo.Search(ctx, erased, "query", 10)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if len(got) != 0 {
+				t.Fatalf("Search of an erased conversation returned %v", queriesOf(got))
+			}
+			got, err = repo.Search(ctx, kept, "query", 10)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if len(got) != len(storeOrder) {
+				t.Fatalf("Search of the kept conversation returned %d memories, want %d", len(got), len(storeOrder))
+			}
+
+			count, err = repo.Erase(ctx, erased)
+			if err != nil || count != 0 {
+				t.Fatalf("second Erase returned %d, %v, want nothing left to remove", count, err)
+			}
+		})
+	}
+}
+
+func TestMemoryRepoSearchBeyondIndexSize(t *testing.T) {
+	embeddingService := embeddingtest.NewFakeService(vectors)
+	tests := append(faissTestBackends(embeddingService, MetricL2),
+		testBackend{name: "bruteforce", newRepo: func(t *testing.T) (persistence.VectorMemoryRepoInterface, vectorStore) {
+			return newTestBruteForceMemoryRepo(t, MetricL2, embeddingService)
+		}},
+		chromemTestBackend(embeddingService, ""),
+	)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo, _ := test.newRepo(t)
+			conversationID := uuid.New()
+			err := indexMany(ctx, repo, conversationID)
+			if err != nil {
+				t.Fatalf("IndexMany: %v", err)
+			}
+			// a topK this large would allocate gigabytes if passed to the index as is
+			got, err := repo.Search(ctx, conversationID, "query", 1<<40)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if !slices.Equal(queriesOf(got), []string{"nearest", "middle", "farthest"}) {
+				t.Fatalf("Search ranked %v, want every memory", queriesOf(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/persistence"
+
+	"github.com/google/uuid"
+)
+
+type BruteForceMemoryRepo struct {
+	bruteForceClient *BruteForceClient
+	embeddingClient  embedding.ServiceInterface
+	rdbmsMemoryRepo  persistence.MemoryRepoInterface
+}
+
+func NewBruteForceMemoryRepo(bruteForceClient *BruteForceClient, embeddingClient embedding.ServiceInterface, rdbmsMemoryRepo persistence.MemoryRepoInterface) persistence.VectorMemoryRepoInterface {
+	return &BruteForceMemoryRepo{
+		bruteForceClient: bruteForceClient,
+		embeddingClient:  embeddingClient,
+		rdbmsMemoryRepo:  rdbmsMemoryRepo,
+	}
+}
+
+func (r *BruteForceMemoryRepo) Index(ctx context.Context, conversationID uuid.UUID, memory persistence.VectorMemory) (persistence.VectorMemory, error) {
+	indexed, err := r.IndexMany(ctx, conversationID, []persistence.VectorMemory{memory})
+	if err != nil {
+		return persistence.VectorMemory{}, err
+	}
+	return indexed[0], nil
+}
+
+func (r *BruteForceMemoryRepo) IndexMany(ctx context.Context, conversationID uuid.UUID, memories []persistence.VectorMemory) ([]persistence.VectorMemory, error) {
+	if len(memories) == 0 {
+		return []persistence.VectorMemory{}, nil
+	}
+	// embed first so that a failing provider leaves no row behind
+	embeddings, err := embedMemories(ctx, r.embeddingClient, memories)
+	if err != nil {
+		return nil, fmt.Errorf("bruteforce: error embedding queries, %w", err)
+	}
+	memoryIds, err := r.rdbmsMemoryRepo.InsertMany(ctx, toRows(conversationID, memories, embeddings))
+	if err != nil {
+		return nil, fmt.Errorf("bruteforce: error inserting memories, %w", err)
+	}
+	err = r.bruteForceClient.IndexMany(ctx, conversationID.String(), memoryIds, embeddings)
+	if err != nil {
+		return nil, fmt.Errorf("bruteforce: error indexing memories, %w", err)
+	}
+	return withConversation(conversationID, memories, embeddings), nil
+}
+
+func (r *BruteForceMemoryRepo) Search(ctx context.Context, conversationID uuid.UUID, query string, topK int) ([]persistence.VectorMemory, error) {
+	embedding, err := r.embeddingClient.EmbedOne(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("bruteforce: error embedding query, %w", err)
+	}
+	response, err := r.bruteForceClient.Search(ctx, conversationID.String(), embedding, topK)
+	if errors.Is(err, ErrindexDoesNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bruteforce: error searching index, %w", err)
+	}
+	vectorMemories, err := hydrate(ctx, r.rdbmsMemoryRepo, response.Metric, response.Ids, response.Distances)
+	if err != nil {
+		return nil, fmt.Errorf("bruteforce: error fetching memories, %w", err)
+	}
+	return vectorMemories, nil
+}
+
+func (r *BruteForceMemoryRepo) Size(ctx context.Context, conversationID uuid.UUID) (int, error) {
+	return int(r.bruteForceClient.IndexSize(conversationID.String())), nil
+}
+
+func (r *BruteForceMemoryRepo) Delete(ctx context.Context, conversationID, memoryID uuid.UUID) error {
+	id, err := r.rdbmsMemoryRepo.DeleteOne(ctx, conversationID, memoryID)
+	if err != nil {
+		return fmt.Errorf("bruteforce: error deleting memory, %w", err)
+	}
+	err = r.bruteForceClient.Remove(ctx, conversationID.String(), []int{id})
+	if errors.Is(err, ErrRemoveNotSupported) || errors.Is(err, ErrindexDoesNotExist) {
+		err = r.restore(ctx, conversationID)
+	}
+	if err != nil {
+		return fmt.Errorf("bruteforce: error removing memory from index, %w", err)
+	}
+	return nil
+}
+
+func (r *BruteForceMemoryRepo) Update(ctx context.Context, conversationID, memoryID uuid.UUID, query, response string) error {
+	// embed first so that a failing provider leaves the memory as it was
+	queryEmbedding, err := r.embeddingClient.EmbedOne(ctx, query)
+	if err != nil {
+		return fmt.Errorf("bruteforce: error embedding query, %w", err)
+	}
+	id, err := r.rdbmsMemoryRepo.UpdateOne(ctx, persistence.Memory{
+		UUID:           memoryID,
+		ConversationID: conversationID,
+		Query:          query,
+		Response:       response,
+		Embedding:      queryEmbedding.Vector,
+		EmbeddingModel: queryEmbedding.Model,
+	})
+	if err != nil {
+		return fmt.Errorf("bruteforce: error updating memory, %w", err)
+	}
+	err = r.bruteForceClient.Update(ctx, conversationID.String(), []int{id}, []embedding.Embedding{queryEmbedding})
+	if errors.Is(err, ErrRemoveNotSupported) || errors.Is(err, ErrindexDoesNotExist) {
+		err = r.restore(ctx, conversationID)
+	}
+	if err != nil {
+		return fmt.Errorf("bruteforce: error updating memory in index, %w", err)
+	}
+	return nil
+}
+
+func (r *BruteForceMemoryRepo) Erase(ctx context.Context, conversationID uuid.UUID) (int, error) {
+	erased, err := r.rdbmsMemoryRepo.DeleteManyByConversationID(ctx, conversationID)
+	if err != nil {
+		return 0, fmt.Errorf("bruteforce: error deleting memories, %w", err)
+	}
+	// replacing an index with no vectors drops it
+	err = r.bruteForceClient.Replace(ctx, conversationID.String(), nil, nil)
+	if err != nil {
+		return 0, fmt.Errorf("bruteforce: error dropping index, %w", err)
+	}
+	return erased, nil
+}
+
+func (r *BruteForceMemoryRepo) EraseShared(ctx context.Context, indexID, sourceConversationID uuid.UUID) (int, error) {
+	ids, err := r.rdbmsMemoryRepo.DeleteManyBySource(ctx, indexID, sourceConversationID)
+	if err != nil {
+		return 0, fmt.Errorf("bruteforce: error deleting shared memories, %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	err = r.bruteForceClient.Remove(ctx, indexID.String(), ids)
+	if errors.Is(err, ErrRemoveNotSupported) || errors.Is(err, ErrindexDoesNotExist) {
+		err = r.restore(ctx, indexID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("bruteforce: error removing shared memories from index, %w", err)
+	}
+	return len(ids), nil
+}
+
+func (r *BruteForceMemoryRepo) Reindex(ctx context.Context, conversationIDs []uuid.UUID) ([]persistence.ReindexResult, error) {
+	results, err := reindex(ctx, r.embeddingClient, r.rdbmsMemoryRepo, conversationIDs, r.bruteForceClient.Replace)
+	if err != nil {
+		return results, fmt.Errorf("bruteforce: error reindexing, %w", err)
+	}
+	return results, nil
+}
+
+// restore rebuilds the index of a conversation from its stored embeddings, for
+// changes the index cannot apply in place.
+func (r *BruteForceMemoryRepo) restore(ctx context.Context, conversationID uuid.UUID) error {
+	_, err := reindex(ctx, r.embeddingClient, r.rdbmsMemoryRepo, []uuid.UUID{conversationID}, r.bruteForceClient.Replace)
+	return err
+}
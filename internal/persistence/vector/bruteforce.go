@@ -0,0 +1,475 @@
+package vector
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/persistence"
+)
+
+const bruteForceSuffix = ".vec"
+
+// bruteForceMagic starts every exported file, the byte after it is the format version.
+var bruteForceMagic = [4]byte{'M', 'M', 'V', 'F'}
+
+const bruteForceVersion = 1
+
+var ErrCorruptVectorFile = errors.New("corrupt vector file")
+
+// BruteForceSearchResponse holds the matches nearest first. Distances[i] is
+// the squared L2 distance of Ids[i] under MetricL2 and the inner product,
+// larger is nearer, under MetricInnerProduct and MetricCosine.
+type BruteForceSearchResponse struct {
+	Distances []float32
+	Ids       []int64
+	Metric    Metric
+}
+
+// bruteForceIndex keeps the vectors of a conversation back to back, vector i
+// is vectors[i*dim : (i+1)*dim] and has id ids[i].
+type bruteForceIndex struct {
+	meta    IndexMeta
+	ids     []int64
+	vectors []float32
+}
+
+// remove drops the vectors with the given ids in place, keeping the others in order.
+func (r *bruteForceIndex) remove(ids []int) {
+	removed := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		removed[int64(id)] = true
+	}
+	dim := r.meta.Dim
+	kept := 0
+	for i, id := range r.ids {
+		if removed[id] {
+			continue
+		}
+		r.ids[kept] = id
+		copy(r.vectors[kept*dim:(kept+1)*dim], r.vectors[i*dim:(i+1)*dim])
+		kept++
+	}
+	r.ids = r.ids[:kept]
+	r.vectors = r.vectors[:kept*dim]
+}
+
+// BruteForceClient is an exact in-memory vector store in pure Go, it needs no
+// cgo and compares every vector of a conversation on each search.
+type BruteForceClient struct {
+	mu                    sync.RWMutex
+	metric                Metric
+	namespace             persistence.Namespace
+	conversationIDVsIndex map[string]*bruteForceIndex
+}
+
+// NewBruteForceClient defaults metric to DefaultMetric, namespace is the tenant the
+// indexes belong to.
+func NewBruteForceClient(metric Metric, namespace persistence.Namespace) (*BruteForceClient, error) {
+	metric, err := metric.orDefault(DefaultMetric)
+	if err != nil {
+		return nil, err
+	}
+	err = namespace.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &BruteForceClient{
+		metric:                metric,
+		namespace:             namespace,
+		conversationIDVsIndex: make(map[string]*bruteForceIndex),
+	}, nil
+}
+
+func (r *BruteForceClient) GetNamespace() persistence.Namespace {
+	return r.namespace
+}
+
+// IndexMany adds all embeddings to the index of a conversation, ids[i] is the id of embeddings[i].
+func (r *BruteForceClient) IndexMany(ctx context.Context, conversationID string, ids []int, embeddings []embedding.Embedding) error {
+	if len(ids) != len(embeddings) {
+		return fmt.Errorf("error indexing %d embeddings with %d ids", len(embeddings), len(ids))
+	}
+	if len(embeddings) == 0 {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	index, exists := r.conversationIDVsIndex[conversationID]
+	if !exists {
+		first := embeddings[0]
+		index = &bruteForceIndex{meta: IndexMeta{Model: first.Model, Dim: first.Dim, Metric: r.metric}}
+	}
+	vectors, labels, err := flatten(conversationID, index.meta, ids, embeddings)
+	if err != nil {
+		return err
+	}
+	index.ids = append(index.ids, labels...)
+	index.vectors = append(index.vectors, vectors...)
+	if index.meta.Model == "" {
+		index.meta.Model = embeddings[0].Model
+	}
+	r.conversationIDVsIndex[conversationID] = index
+	return nil
+}
+
+// Replace swaps the vectors of a conversation for the given ones, a
+// conversation without vectors loses its index.
+func (r *BruteForceClient) Replace(ctx context.Context, conversationID string, ids []int, embeddings []embedding.Embedding) error {
+	if len(ids) != len(embeddings) {
+		return fmt.Errorf("error replacing index with %d embeddings and %d ids", len(embeddings), len(ids))
+	}
+	if len(embeddings) == 0 {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.conversationIDVsIndex, conversationID)
+		return nil
+	}
+	first := embeddings[0]
+	index := &bruteForceIndex{meta: IndexMeta{Model: first.Model, Dim: first.Dim, Metric: r.metric}}
+	vectors, labels, err := flatten(conversationID, index.meta, ids, embeddings)
+	if err != nil {
+		return err
+	}
+	index.ids = labels
+	index.vectors = vectors
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.conversationIDVsIndex[conversationID] = index
+	return nil
+}
+
+// Remove drops the vectors with the given ids from the index of a conversation,
+// ids it does not hold are ignored.
+func (r *BruteForceClient) Remove(ctx context.Context, conversationID string, ids []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	index, exists := r.conversationIDVsIndex[conversationID]
+	if !exists {
+		return ErrindexDoesNotExist
+	}
+	index.remove(ids)
+	return nil
+}
+
+// Update swaps the vectors stored under ids for embeddings, ids[i] is the id of embeddings[i].
+func (r *BruteForceClient) Update(ctx context.Context, conversationID string, ids []int, embeddings []embedding.Embedding) error {
+	if len(ids) != len(embeddings) {
+		return fmt.Errorf("error updating %d embeddings with %d ids", len(embeddings), len(ids))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	index, exists := r.conversationIDVsIndex[conversationID]
+	if !exists {
+		return ErrindexDoesNotExist
+	}
+	vectors, labels, err := flatten(conversationID, index.meta, ids, embeddings)
+	if err != nil {
+		return err
+	}
+	index.remove(ids)
+	index.ids = append(index.ids, labels...)
+	index.vectors = append(index.vectors, vectors...)
+	return nil
+}
+
+func (r *BruteForceClient) Search(ctx context.Context, conversationID string, query embedding.Embedding, topK int) (BruteForceSearchResponse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	index, exists := r.conversationIDVsIndex[conversationID]
+	if !exists {
+		return BruteForceSearchResponse{}, ErrindexDoesNotExist
+	}
+	err := index.meta.check(conversationID, query)
+	if err != nil {
+		return BruteForceSearchResponse{}, err
+	}
+	vector := query.Vector
+	if index.meta.Metric == MetricCosine {
+		vector = normalized(vector)
+	}
+	// L2 keeps the smallest distances, the inner product metrics the largest products
+	nearer := func(a, b float32) bool { return a > b }
+	compare := dot
+	if index.meta.Metric == MetricL2 {
+		nearer = func(a, b float32) bool { return a < b }
+		compare = squaredL2
+	}
+	dim := index.meta.Dim
+	topK = min(topK, len(index.ids))
+	top := &topMatches{nearer: nearer, matches: make([]match, 0, max(topK, 0))}
+	for i, id := range index.ids {
+		value := compare(vector, index.vectors[i*dim:(i+1)*dim])
+		if top.Len() < topK {
+			heap.Push(top, match{id: id, value: value})
+			continue
+		}
+		if topK > 0 && nearer(value, top.matches[0].value) {
+			top.matches[0] = match{id: id, value: value}
+			heap.Fix(top, 0)
+		}
+	}
+	response := BruteForceSearchResponse{
+		Distances: make([]float32, top.Len()),
+		Ids:       make([]int64, top.Len()),
+		Metric:    index.meta.Metric,
+	}
+	// the heap pops the farthest match first
+	for i := top.Len() - 1; i >= 0; i-- {
+		match := heap.Pop(top).(match)
+		response.Ids[i] = match.id
+		response.Distances[i] = match.value
+	}
+	return response, nil
+}
+
+// Mount replaces all indexes with the ones in files, files without the .vec
+// suffix are skipped so a snapshot directory can be shared with other stores.
+func (r *BruteForceClient) Mount(dir string, files map[string]io.Reader) error {
+	conversationIDVsIndex := make(map[string]*bruteForceIndex)
+	for fileName, reader := range files {
+		if !strings.HasSuffix(fileName, bruteForceSuffix) {
+			continue
+		}
+		index, err := readBruteForceIndex(reader)
+		if err != nil {
+			return fmt.Errorf("error reading vectors from file %s: %w", fileName, err)
+		}
+		conversationIDVsIndex[strings.TrimSuffix(fileName, bruteForceSuffix)] = index
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.conversationIDVsIndex = conversationIDVsIndex
+	return nil
+}
+
+func (r *BruteForceClient) Export(dir string) ([]os.File, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var files []os.File
+	for conversationID, index := range r.conversationIDVsIndex {
+		filePath := filepath.Join(dir, conversationID+bruteForceSuffix)
+		err := writeBruteForceIndex(filePath, index)
+		if err != nil {
+			return nil, fmt.Errorf("error exporting vectors for conversation id %s: %w", conversationID, err)
+		}
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("error opening file %s: %w", filePath, err)
+		}
+		files = append(files, *file)
+	}
+	return files, nil
+}
+
+// FileNames returns the names of the files Export writes for a conversation.
+func (r *BruteForceClient) FileNames(conversationID string) []string {
+	return []string{conversationID + bruteForceSuffix}
+}
+
+// Meta returns the embedding identity of the index of a conversation.
+func (r *BruteForceClient) Meta(conversationID string) (IndexMeta, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	index, exists := r.conversationIDVsIndex[conversationID]
+	if !exists {
+		return IndexMeta{}, false
+	}
+	return index.meta, true
+}
+
+// IndexSize returns the number of vectors held by the index of conversationID.
+func (r *BruteForceClient) IndexSize(conversationID string) int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	index, exists := r.conversationIDVsIndex[conversationID]
+	if !exists {
+		return 0
+	}
+	return int64(len(index.ids))
+}
+
+// IndexSizes returns the number of vectors held by each conversation index.
+func (r *BruteForceClient) IndexSizes() map[string]int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sizes := make(map[string]int64, len(r.conversationIDVsIndex))
+	for conversationID, index := range r.conversationIDVsIndex {
+		sizes[conversationID] = int64(len(index.ids))
+	}
+	return sizes
+}
+
+// The file format is little endian:
+//
+//	magic "MMVF", version uint8, metric length uint8, metric,
+//	model length uint16, model, dim uint32, count uint64,
+//	count int64 ids, count*dim float32 vectors
+func writeBruteForceIndex(filePath string, index *bruteForceIndex) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+	header := make([]byte, 0, 32+len(index.meta.Metric)+len(index.meta.Model))
+	header = append(header, bruteForceMagic[:]...)
+	header = append(header, bruteForceVersion, byte(len(index.meta.Metric)))
+	header = append(header, index.meta.Metric...)
+	header = binary.LittleEndian.AppendUint16(header, uint16(len(index.meta.Model)))
+	header = append(header, index.meta.Model...)
+	header = binary.LittleEndian.AppendUint32(header, uint32(index.meta.Dim))
+	header = binary.LittleEndian.AppendUint64(header, uint64(len(index.ids)))
+	_, err = w.Write(header)
+	if err != nil {
+		return err
+	}
+	err = binary.Write(w, binary.LittleEndian, index.ids)
+	if err != nil {
+		return err
+	}
+	err = binary.Write(w, binary.LittleEndian, index.vectors)
+	if err != nil {
+		return err
+	}
+	err = w.Flush()
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func readBruteForceIndex(reader io.Reader) (*bruteForceIndex, error) {
+	r := bufio.NewReader(reader)
+	var prefix [6]byte
+	_, err := io.ReadFull(r, prefix[:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptVectorFile, err)
+	}
+	if [4]byte(prefix[:4]) != bruteForceMagic || prefix[4] != bruteForceVersion {
+		return nil, fmt.Errorf("%w: unknown header %q", ErrCorruptVectorFile, prefix[:5])
+	}
+	metric := make([]byte, prefix[5])
+	_, err = io.ReadFull(r, metric)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptVectorFile, err)
+	}
+	var modelLength uint16
+	err = binary.Read(r, binary.LittleEndian, &modelLength)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptVectorFile, err)
+	}
+	model := make([]byte, modelLength)
+	_, err = io.ReadFull(r, model)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptVectorFile, err)
+	}
+	var sizes struct {
+		Dim   uint32
+		Count uint64
+	}
+	err = binary.Read(r, binary.LittleEndian, &sizes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptVectorFile, err)
+	}
+	index := &bruteForceIndex{meta: IndexMeta{Model: string(model), Dim: int(sizes.Dim), Metric: Metric(metric)}}
+	index.meta.Metric, err = index.meta.Metric.orDefault(MetricL2)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptVectorFile, err)
+	}
+	// read in chunks so a corrupt count fails on a short file instead of allocating it upfront
+	index.ids, err = readChunked[int64](r, sizes.Count)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptVectorFile, err)
+	}
+	index.vectors, err = readChunked[float32](r, sizes.Count*uint64(sizes.Dim))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptVectorFile, err)
+	}
+	return index, nil
+}
+
+func readChunked[T int64 | float32](r io.Reader, count uint64) ([]T, error) {
+	const chunk = 1 << 16
+	values := make([]T, 0, min(count, chunk))
+	buffer := make([]T, min(count, chunk))
+	for remaining := count; remaining > 0; {
+		n := min(remaining, chunk)
+		err := binary.Read(r, binary.LittleEndian, buffer[:n])
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, buffer[:n]...)
+		remaining -= n
+	}
+	return values, nil
+}
+
+// dot and squaredL2 keep four independent accumulators so the compiler can
+// pipeline, and vectorize where it does, the multiply adds.
+func dot(a []float32, b []float32) float32 {
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return s0 + s1 + s2 + s3
+}
+
+func squaredL2(a []float32, b []float32) float32 {
+	b = b[:len(a)]
+	var s0, s1, s2, s3 float32
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		d0 := a[i] - b[i]
+		d1 := a[i+1] - b[i+1]
+		d2 := a[i+2] - b[i+2]
+		d3 := a[i+3] - b[i+3]
+		s0 += d0 * d0
+		s1 += d1 * d1
+		s2 += d2 * d2
+		s3 += d3 * d3
+	}
+	for ; i < len(a); i++ {
+		d := a[i] - b[i]
+		s0 += d * d
+	}
+	return s0 + s1 + s2 + s3
+}
+
+type match struct {
+	id    int64
+	value float32
+}
+
+// topMatches is a heap with the farthest of the kept matches on top.
+type topMatches struct {
+	matches []match
+	nearer  func(a, b float32) bool
+}
+
+func (r *topMatches) Len() int           { return len(r.matches) }
+func (r *topMatches) Less(i, j int) bool { return r.nearer(r.matches[j].value, r.matches[i].value) }
+func (r *topMatches) Swap(i, j int)      { r.matches[i], r.matches[j] = r.matches[j], r.matches[i] }
+func (r *topMatches) Push(x any)         { r.matches = append(r.matches, x.(match)) }
+func (r *topMatches) Pop() any {
+	last := r.matches[len(r.matches)-1]
+	r.matches = r.matches[:len(r.matches)-1]
+	return last
+}
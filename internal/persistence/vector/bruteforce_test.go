@@ -0,0 +1,135 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/embedding/embeddingtest"
+	"github.com/haren7/minimal-memory/internal/persistence"
+	"github.com/haren7/minimal-memory/internal/persistence/rdbms"
+	"github.com/haren7/minimal-memory/internal/persistence/rdbms/rdbmstest"
+)
+
+func newTestBruteForceMemoryRepo(t *testing.T, metric Metric, embeddingService embedding.ServiceInterface) (persistence.VectorMemoryRepoInterface, *BruteForceClient) {
+	t.Helper()
+	duckdbClient := rdbmstest.NewDuckDBClient(t)
+	bruteForceClient, err := NewBruteForceClient(metric, "")
+	if err != nil {
+		t.Fatalf("NewBruteForceClient: %v", err)
+	}
+	return NewBruteForceMemoryRepo(bruteForceClient, embeddingService, rdbms.NewFaissMemoryRepo(duckdbClient)), bruteForceClient
+}
+
+func TestBruteForceClientExportMountRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewBruteForceClient(MetricCosine, "")
+	if err != nil {
+		t.Fatalf("NewBruteForceClient: %v", err)
+	}
+	embeddingService := embeddingtest.NewFakeService(unnormalized)
+	var embeddings []embedding.Embedding
+	for _, query := range storeOrder {
+		embedding, err := embeddingService.EmbedOne(ctx, query)
+		if err != nil {
+			t.Fatalf("EmbedOne: %v", err)
+		}
+		embeddings = append(embeddings, embedding)
+	}
+	err = client.IndexMany(ctx, "conversation", []int{7, 8, 9}, embeddings)
+	if err != nil {
+		t.Fatalf("IndexMany: %v", err)
+	}
+	dir := t.TempDir()
+	files, err := client.Export(dir)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	readers := make(map[string]io.Reader)
+	for i := range files {
+		defer files[i].Close()
+		readers[filepath.Base(files[i].Name())] = &files[i]
+	}
+	readers["unrelated.meta.json"] = strings.NewReader("{}")
+
+	mounted, err := NewBruteForceClient(MetricL2, "")
+	if err != nil {
+		t.Fatalf("NewBruteForceClient: %v", err)
+	}
+	err = mounted.Mount(dir, readers)
+	if err != nil {
+		t.Fatalf("Mount: %v", err)
+	}
+	wantMeta, _ := client.Meta("conversation")
+	gotMeta, _ := mounted.Meta("conversation")
+	if gotMeta != wantMeta {
+		t.Fatalf("mounted meta %+v, want %+v", gotMeta, wantMeta)
+	}
+	query, err := embeddingService.EmbedOne(ctx, "query")
+	if err != nil {
+		t.Fatalf("EmbedOne: %v", err)
+	}
+	want, err := client.Search(ctx, "conversation", query, 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	got, err := mounted.Search(ctx, "conversation", query, 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if !slices.Equal(got.Ids, want.Ids) || !slices.Equal(got.Distances, want.Distances) || got.Metric != MetricCosine {
+		t.Fatalf("mounted search returned %+v, want %+v", got, want)
+	}
+}
+
+func TestBruteForceClientMountRejectsCorruptFile(t *testing.T) {
+	ctx := context.Background()
+	client, err := NewBruteForceClient(MetricL2, "")
+	if err != nil {
+		t.Fatalf("NewBruteForceClient: %v", err)
+	}
+	err = client.IndexMany(ctx, "conversation", []int{1}, []embedding.Embedding{{Model: "fake", Dim: 4, Vector: []float32{1, 2, 3, 4}}})
+	if err != nil {
+		t.Fatalf("IndexMany: %v", err)
+	}
+	dir := t.TempDir()
+	files, err := client.Export(dir)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	files[0].Close()
+	bytes, err := os.ReadFile(files[0].Name())
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	tests := []struct {
+		name  string
+		bytes []byte
+	}{
+		{name: "truncated vectors", bytes: bytes[:len(bytes)-1]},
+		{name: "bad magic", bytes: append([]byte("NOPE"), bytes[4:]...)},
+		{name: "empty", bytes: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := client.Mount(dir, map[string]io.Reader{"conversation.vec": strings.NewReader(string(test.bytes))})
+			if !errors.Is(err, ErrCorruptVectorFile) {
+				t.Fatalf("Mount returned %v, want %v", err, ErrCorruptVectorFile)
+			}
+		})
+	}
+}
+
+func queriesOf(memories []persistence.VectorMemory) []string {
+	var queries []string
+	for _, memory := range memories {
+		queries = append(queries, memory.Query)
+	}
+	return queries
+}
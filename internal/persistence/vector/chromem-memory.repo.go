@@ -2,6 +2,8 @@ package vector
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -18,51 +20,75 @@ type memory struct {
 	Respones       string
 	ConversationID uuid.UUID
 	CreatedAt      time.Time
+	Metadata       map[string]any
+	Tags           []string
+	// SourceConversationID is ConversationID unless the memory was shared from another conversation.
+	SourceConversationID uuid.UUID
 }
 
+// ChromemMemoryRepo keeps a chromem collection per conversation. chromem only
+// compares unit vectors, so every metric sees normalized embeddings: MetricL2
+// reports the squared distance between them and MetricInnerProduct and
+// MetricCosine the cosine similarity. Memories are also kept as rows with
+// their embeddings in rdbmsMemoryRepo, like the other backends, so the
+// collections can be rebuilt from them. Searches are served by the collections
+// alone.
 type ChromemMemoryRepo struct {
-	db               *chromem.DB
+	chromemClient    *ChromemClient
 	embeddingService embedding.ServiceInterface
+	rdbmsMemoryRepo  persistence.MemoryRepoInterface
+	metric           Metric
 }
 
-func NewChromemMemoryRepo(db *chromem.DB, embeddingService embedding.ServiceInterface) persistence.VectorMemoryRepoInterface {
+// NewChromemMemoryRepo defaults metric to DefaultMetric.
+func NewChromemMemoryRepo(chromemClient *ChromemClient, embeddingService embedding.ServiceInterface, rdbmsMemoryRepo persistence.MemoryRepoInterface, metric Metric) (persistence.VectorMemoryRepoInterface, error) {
+	metric, err := metric.orDefault(DefaultMetric)
+	if err != nil {
+		return nil, err
+	}
 	return &ChromemMemoryRepo{
-		db:               db,
+		chromemClient:    chromemClient,
 		embeddingService: embeddingService,
+		rdbmsMemoryRepo:  rdbmsMemoryRepo,
+		metric:           metric,
+	}, nil
+}
+
+func (r *ChromemMemoryRepo) Index(ctx context.Context, conversationID uuid.UUID, memory persistence.VectorMemory) (persistence.VectorMemory, error) {
+	indexed, err := r.IndexMany(ctx, conversationID, []persistence.VectorMemory{memory})
+	if err != nil {
+		return persistence.VectorMemory{}, err
 	}
+	return indexed[0], nil
 }
 
-func (r *ChromemMemoryRepo) Index(ctx context.Context, conversationID, memoryID uuid.UUID, query, response string, createdAt time.Time) (persistence.VectorMemory, error) {
-	collection, err := r.db.GetOrCreateCollection(conversationID.String(), nil, nil)
+func (r *ChromemMemoryRepo) IndexMany(ctx context.Context, conversationID uuid.UUID, memories []persistence.VectorMemory) ([]persistence.VectorMemory, error) {
+	if len(memories) == 0 {
+		return []persistence.VectorMemory{}, nil
+	}
+	collection, err := r.chromemClient.GetOrCreateCollection(conversationID.String())
 	if err != nil {
-		return persistence.VectorMemory{}, fmt.Errorf("chromem: error getting or creating collection, %w", err)
+		return nil, fmt.Errorf("chromem: error getting or creating collection, %w", err)
 	}
-	embedding, err := r.embeddingService.EmbedOne(ctx, query)
+	// embed first so that a failing provider leaves no row behind
+	embeddings, err := embedMemories(ctx, r.embeddingService, memories)
 	if err != nil {
-		return persistence.VectorMemory{}, fmt.Errorf("chromem: error embedding query, %w", err)
+		return nil, fmt.Errorf("chromem: error embedding queries, %w", err)
 	}
-	document := chromem.Document{
-		ID:        memoryID.String(),
-		Embedding: embedding.Vector,
-		Metadata: r.transformToMap(memory{
-			UUID:           memoryID,
-			Query:          query,
-			Respones:       response,
-			ConversationID: conversationID,
-			CreatedAt:      createdAt,
-		}),
+	rows := toRows(conversationID, memories, embeddings)
+	documents, err := r.toDocuments(rows, embeddings)
+	if err != nil {
+		return nil, err
 	}
-	err = collection.AddDocument(ctx, document)
+	_, err = r.rdbmsMemoryRepo.InsertMany(ctx, rows)
 	if err != nil {
-		return persistence.VectorMemory{}, fmt.Errorf("chromem: error adding document, %w", err)
+		return nil, fmt.Errorf("chromem: error inserting memories, %w", err)
 	}
-	return persistence.VectorMemory{
-		ID:             memoryID,
-		ConversationID: conversationID,
-		Query:          query,
-		Response:       response,
-		CreatedAt:      createdAt,
-	}, nil
+	err = collection.AddDocuments(ctx, documents, 1)
+	if err != nil {
+		return nil, fmt.Errorf("chromem: error adding documents, %w", err)
+	}
+	return withConversation(conversationID, memories, embeddings), nil
 }
 
 func (r *ChromemMemoryRepo) Search(ctx context.Context, conversationID uuid.UUID, query string, topK int) ([]persistence.VectorMemory, error) {
@@ -70,55 +96,294 @@ func (r *ChromemMemoryRepo) Search(ctx context.Context, conversationID uuid.UUID
 	if err != nil {
 		return nil, fmt.Errorf("chromem: error embedding query, %w", err)
 	}
-	collection := r.db.GetCollection(conversationID.String(), nil)
-	count := collection.Count()
-	if count < topK {
-		topK = count
+	collection := r.chromemClient.GetCollection(conversationID.String())
+	if collection == nil {
+		return nil, nil
 	}
-	result, err := collection.QueryEmbedding(ctx, embedding.Vector, topK, nil, nil)
+	topK = min(topK, collection.Count())
+	if topK <= 0 {
+		return nil, nil
+	}
+	result, err := collection.QueryEmbedding(ctx, normalized(embedding.Vector), topK, nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("chromem: error querying embedding, %w", err)
 	}
-	var memories []memory
-	for _, result := range result {
+	// chromem sorts results by similarity, most similar first
+	var vectorMemories []persistence.VectorMemory
+	for i, result := range result {
 		memory, err := r.transformFromMap(result.Metadata)
 		if err != nil {
 			return nil, fmt.Errorf("chromem: error transforming from map, %w", err)
 		}
-		memories = append(memories, memory)
-	}
-	var vectorMemories []persistence.VectorMemory
-	for _, memory := range memories {
+		distance, score := r.scoreOf(result.Similarity)
 		vectorMemories = append(vectorMemories, persistence.VectorMemory{
-			ID:             memory.UUID,
-			ConversationID: memory.ConversationID,
-			Query:          memory.Query,
-			Response:       memory.Respones,
-			CreatedAt:      memory.CreatedAt,
+			ID:                   memory.UUID,
+			ConversationID:       memory.ConversationID,
+			Query:                memory.Query,
+			Response:             memory.Respones,
+			CreatedAt:            memory.CreatedAt,
+			Metadata:             memory.Metadata,
+			Tags:                 memory.Tags,
+			SourceConversationID: memory.SourceConversationID,
+			Rank:                 i + 1,
+			Distance:             distance,
+			Score:                score,
 		})
 	}
 	return vectorMemories, nil
 }
 
-func (r *ChromemMemoryRepo) transformToMap(data memory) map[string]string {
-	return map[string]string{
+func (r *ChromemMemoryRepo) Size(ctx context.Context, conversationID uuid.UUID) (int, error) {
+	collection := r.chromemClient.GetCollection(conversationID.String())
+	if collection == nil {
+		return 0, nil
+	}
+	return collection.Count(), nil
+}
+
+// Delete and Update look the memory up in its collection, memories stored
+// before chromem kept rows have none to change.
+func (r *ChromemMemoryRepo) Delete(ctx context.Context, conversationID, memoryID uuid.UUID) error {
+	collection, _, err := r.fetchDocument(ctx, conversationID, memoryID)
+	if err != nil {
+		return err
+	}
+	_, err = r.rdbmsMemoryRepo.DeleteOne(ctx, conversationID, memoryID)
+	if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+		return fmt.Errorf("chromem: error deleting memory, %w", err)
+	}
+	err = collection.Delete(ctx, nil, nil, memoryID.String())
+	if err != nil {
+		return fmt.Errorf("chromem: error deleting document, %w", err)
+	}
+	return nil
+}
+
+func (r *ChromemMemoryRepo) Update(ctx context.Context, conversationID, memoryID uuid.UUID, query, response string) error {
+	collection, current, err := r.fetchDocument(ctx, conversationID, memoryID)
+	if err != nil {
+		return err
+	}
+	embedding, err := r.embeddingService.EmbedOne(ctx, query)
+	if err != nil {
+		return fmt.Errorf("chromem: error embedding query, %w", err)
+	}
+	_, err = r.rdbmsMemoryRepo.UpdateOne(ctx, persistence.Memory{
+		UUID:           memoryID,
+		ConversationID: conversationID,
+		Query:          query,
+		Response:       response,
+		Embedding:      embedding.Vector,
+		EmbeddingModel: embedding.Model,
+	})
+	if err != nil && !errors.Is(err, persistence.ErrNotFound) {
+		return fmt.Errorf("chromem: error updating memory, %w", err)
+	}
+	metadata, err := r.transformToMap(memory{
+		UUID:                 memoryID,
+		Query:                query,
+		Respones:             response,
+		ConversationID:       conversationID,
+		CreatedAt:            current.CreatedAt,
+		Metadata:             current.Metadata,
+		Tags:                 current.Tags,
+		SourceConversationID: current.SourceConversationID,
+	})
+	if err != nil {
+		return err
+	}
+	// a document added under an existing id replaces it
+	err = collection.AddDocument(ctx, chromem.Document{
+		ID:        memoryID.String(),
+		Embedding: normalized(embedding.Vector),
+		Metadata:  metadata,
+	})
+	if err != nil {
+		return fmt.Errorf("chromem: error adding document, %w", err)
+	}
+	return nil
+}
+
+func (r *ChromemMemoryRepo) Erase(ctx context.Context, conversationID uuid.UUID) (int, error) {
+	_, err := r.rdbmsMemoryRepo.DeleteManyByConversationID(ctx, conversationID)
+	if err != nil {
+		return 0, fmt.Errorf("chromem: error deleting memories, %w", err)
+	}
+	erased, err := r.chromemClient.DeleteCollection(conversationID.String())
+	if err != nil {
+		return 0, fmt.Errorf("chromem: error deleting collection, %w", err)
+	}
+	return erased, nil
+}
+
+func (r *ChromemMemoryRepo) EraseShared(ctx context.Context, indexID, sourceConversationID uuid.UUID) (int, error) {
+	_, err := r.rdbmsMemoryRepo.DeleteManyBySource(ctx, indexID, sourceConversationID)
+	if err != nil {
+		return 0, fmt.Errorf("chromem: error deleting shared memories, %w", err)
+	}
+	collection := r.chromemClient.GetCollection(indexID.String())
+	if collection == nil {
+		return 0, nil
+	}
+	before := collection.Count()
+	err = collection.Delete(ctx, map[string]string{"sourceConversationId": sourceConversationID.String()}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("chromem: error deleting shared documents, %w", err)
+	}
+	return before - collection.Count(), nil
+}
+
+func (r *ChromemMemoryRepo) fetchDocument(ctx context.Context, conversationID, memoryID uuid.UUID) (*chromem.Collection, memory, error) {
+	collection := r.chromemClient.GetCollection(conversationID.String())
+	if collection == nil {
+		return nil, memory{}, fmt.Errorf("chromem: memory %s not found for conversation id %s, %w", memoryID, conversationID, persistence.ErrNotFound)
+	}
+	document, err := collection.GetByID(ctx, memoryID.String())
+	if err != nil {
+		return nil, memory{}, fmt.Errorf("chromem: memory %s not found for conversation id %s, %w", memoryID, conversationID, persistence.ErrNotFound)
+	}
+	current, err := r.transformFromMap(document.Metadata)
+	if err != nil {
+		return nil, memory{}, err
+	}
+	return collection, current, nil
+}
+
+// Reindex rebuilds collections from their rows, memories stored before
+// chromem kept rows are not in them and are dropped from a rebuilt collection.
+func (r *ChromemMemoryRepo) Reindex(ctx context.Context, conversationIDs []uuid.UUID) ([]persistence.ReindexResult, error) {
+	results, err := reindex(ctx, r.embeddingService, r.rdbmsMemoryRepo, conversationIDs, r.replace)
+	if err != nil {
+		return results, fmt.Errorf("chromem: error reindexing, %w", err)
+	}
+	return results, nil
+}
+
+// replace swaps the collection of a conversation for one holding the rows of
+// ids, embeddings[i] is the embedding of ids[i].
+func (r *ChromemMemoryRepo) replace(ctx context.Context, conversationID string, ids []int, embeddings []embedding.Embedding) error {
+	rows, err := r.rdbmsMemoryRepo.FetchMany(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("error fetching memories, %w", err)
+	}
+	if len(rows) != len(ids) {
+		return fmt.Errorf("got %d memories for %d ids", len(rows), len(ids))
+	}
+	documents, err := r.toDocuments(rows, embeddings)
+	if err != nil {
+		return err
+	}
+	return r.chromemClient.ReplaceCollection(ctx, conversationID, documents)
+}
+
+// toDocuments lays out rows for a collection, embeddings[i] is the embedding
+// of rows[i].
+func (r *ChromemMemoryRepo) toDocuments(rows []persistence.Memory, embeddings []embedding.Embedding) ([]chromem.Document, error) {
+	documents := make([]chromem.Document, len(rows))
+	for i, row := range rows {
+		metadata, err := r.transformToMap(memory{
+			UUID:                 row.UUID,
+			Query:                row.Query,
+			Respones:             row.Response,
+			ConversationID:       row.ConversationID,
+			CreatedAt:            row.CreatedAt,
+			Metadata:             row.Metadata,
+			Tags:                 row.Tags,
+			SourceConversationID: row.SourceConversationID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		documents[i] = chromem.Document{
+			ID:        row.UUID.String(),
+			Embedding: normalized(embeddings[i].Vector),
+			Metadata:  metadata,
+		}
+	}
+	return documents, nil
+}
+
+func (r *ChromemMemoryRepo) scoreOf(similarity float32) (float32, float32) {
+	if r.metric == MetricL2 {
+		// |a-b|^2 = 2 - 2a.b for unit vectors
+		return scoreOf(MetricL2, max(2-2*similarity, 0))
+	}
+	return scoreOf(MetricCosine, similarity)
+}
+
+// transformToMap keeps metadata and tags as JSON, chromem metadata only holds
+// strings. Memories without them, or stored in their own conversation, leave
+// the keys out.
+func (r *ChromemMemoryRepo) transformToMap(data memory) (map[string]string, error) {
+	document := map[string]string{
 		"uuid":           data.UUID.String(),
 		"query":          data.Query,
 		"response":       data.Respones,
 		"conversationId": data.ConversationID.String(),
 		"createdAt":      data.CreatedAt.Format(time.RFC3339),
 	}
+	if data.SourceConversationID != uuid.Nil && data.SourceConversationID != data.ConversationID {
+		document["sourceConversationId"] = data.SourceConversationID.String()
+	}
+	if len(data.Metadata) > 0 {
+		metadata, err := json.Marshal(data.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("chromem: error encoding metadata, %w", err)
+		}
+		document["metadata"] = string(metadata)
+	}
+	if len(data.Tags) > 0 {
+		tags, err := json.Marshal(data.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("chromem: error encoding tags, %w", err)
+		}
+		document["tags"] = string(tags)
+	}
+	return document, nil
 }
 
 func (r *ChromemMemoryRepo) transformFromMap(data map[string]string) (memory, error) {
 	createdAt, err := time.Parse(time.RFC3339, data["createdAt"])
 	if err != nil {
-		return memory{}, fmt.Errorf("chromem: error parsing created at, %w", err)
+		return memory{}, fmt.Errorf("chromem: error parsing created at, %w: %w", ErrCorruptVectorFile, err)
+	}
+	var metadata map[string]any
+	if encoded, ok := data["metadata"]; ok {
+		err = json.Unmarshal([]byte(encoded), &metadata)
+		if err != nil {
+			return memory{}, fmt.Errorf("chromem: error decoding metadata, %w: %w", ErrCorruptVectorFile, err)
+		}
+	}
+	var tags []string
+	if encoded, ok := data["tags"]; ok {
+		err = json.Unmarshal([]byte(encoded), &tags)
+		if err != nil {
+			return memory{}, fmt.Errorf("chromem: error decoding tags, %w: %w", ErrCorruptVectorFile, err)
+		}
+	}
+	conversationID, err := uuid.Parse(data["conversationId"])
+	if err != nil {
+		return memory{}, fmt.Errorf("chromem: error parsing conversation id, %w: %w", ErrCorruptVectorFile, err)
+	}
+	memoryID, err := uuid.Parse(data["uuid"])
+	if err != nil {
+		return memory{}, fmt.Errorf("chromem: error parsing uuid, %w: %w", ErrCorruptVectorFile, err)
+	}
+	sourceConversationID := conversationID
+	if encoded, ok := data["sourceConversationId"]; ok {
+		sourceConversationID, err = uuid.Parse(encoded)
+		if err != nil {
+			return memory{}, fmt.Errorf("chromem: error parsing source conversation id, %w: %w", ErrCorruptVectorFile, err)
+		}
 	}
 	return memory{
-		Query:          data["query"],
-		Respones:       data["response"],
-		ConversationID: uuid.MustParse(data["conversationId"]),
-		CreatedAt:      createdAt,
+		UUID:                 memoryID,
+		Query:                data["query"],
+		Respones:             data["response"],
+		ConversationID:       conversationID,
+		CreatedAt:            createdAt,
+		Metadata:             metadata,
+		Tags:                 tags,
+		SourceConversationID: sourceConversationID,
 	}, nil
 }
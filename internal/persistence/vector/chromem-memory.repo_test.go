@@ -0,0 +1,146 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/embedding/embeddingtest"
+	"github.com/haren7/minimal-memory/internal/persistence"
+	"github.com/haren7/minimal-memory/internal/persistence/rdbms"
+	"github.com/haren7/minimal-memory/internal/persistence/rdbms/rdbmstest"
+
+	"github.com/google/uuid"
+)
+
+func newTestChromemClient(t *testing.T) *ChromemClient {
+	t.Helper()
+	client, err := NewChromem("")
+	if err != nil {
+		t.Fatalf("NewChromem: %v", err)
+	}
+	return client
+}
+
+func newTestChromemMemoryRepo(t *testing.T, metric Metric, embeddingService embedding.ServiceInterface) (persistence.VectorMemoryRepoInterface, *ChromemClient) {
+	t.Helper()
+	duckdbClient := rdbmstest.NewDuckDBClient(t)
+	client := newTestChromemClient(t)
+	repo, err := NewChromemMemoryRepo(client, embeddingService, rdbms.NewFaissMemoryRepo(duckdbClient), metric)
+	if err != nil {
+		t.Fatalf("NewChromemMemoryRepo: %v", err)
+	}
+	return repo, client
+}
+
+func TestNewChromemRejectsInvalidNamespace(t *testing.T) {
+	_, err := NewChromem("../acme")
+	if err == nil {
+		t.Fatalf("NewChromem accepted an invalid namespace")
+	}
+}
+
+func TestChromemMemoryRepoSurvivesExportAndMount(t *testing.T) {
+	ctx := context.Background()
+	embeddingService := embeddingtest.NewFakeService(vectors)
+	repo, client := newTestChromemMemoryRepo(t, "", embeddingService)
+	conversationIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	for _, conversationID := range conversationIDs {
+		err := indexOneByOne(ctx, repo, conversationID)
+		if err != nil {
+			t.Fatalf("store: %v", err)
+		}
+	}
+	want, err := repo.Search(ctx, conversationIDs[1], "query", 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	dir := t.TempDir()
+	files, err := client.Export(dir)
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	if len(files) != len(conversationIDs) {
+		t.Fatalf("Export wrote %d files, want one per conversation", len(files))
+	}
+	readers := make(map[string]io.Reader)
+	for i := range files {
+		defer files[i].Close()
+		readers[filepath.Base(files[i].Name())] = &files[i]
+	}
+	mountedRepo, mounted := newTestChromemMemoryRepo(t, "", embeddingService)
+	err = mounted.Mount(dir, readers)
+	if err != nil {
+		t.Fatalf("Mount: %v", err)
+	}
+	got, err := mountedRepo.Search(ctx, conversationIDs[1], "query", 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("mounted search returned %+v, want %+v", got, want)
+	}
+	for _, size := range mounted.IndexSizes() {
+		if size != int64(len(storeOrder)) {
+			t.Fatalf("mounted collection holds %d documents, want %d", size, len(storeOrder))
+		}
+	}
+}
+
+func TestChromemMemoryRepoSearchRejectsCorruptMetadata(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+	}{
+		{name: "uuid", key: "uuid", value: "not a uuid"},
+		{name: "conversation id", key: "conversationId", value: ""},
+		{name: "source conversation id", key: "sourceConversationId", value: "not a uuid"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo, client := newTestChromemMemoryRepo(t, "", embeddingtest.NewFakeService(vectors))
+			conversationID := uuid.New()
+			err := indexMany(ctx, repo, conversationID)
+			if err != nil {
+				t.Fatalf("IndexMany: %v", err)
+			}
+			// a mounted snapshot can hold documents this repo never wrote
+			collection := client.GetCollection(conversationID.String())
+			results, err := collection.QueryEmbedding(ctx, normalized(vectors["nearest"]), 1, nil, nil)
+			if err != nil {
+				t.Fatalf("QueryEmbedding: %v", err)
+			}
+			document, err := collection.GetByID(ctx, results[0].ID)
+			if err != nil {
+				t.Fatalf("GetByID: %v", err)
+			}
+			document.Metadata[test.key] = test.value
+			err = collection.AddDocument(ctx, document)
+			if err != nil {
+				t.Fatalf("AddDocument: %v", err)
+			}
+			_, err = repo.Search(ctx, conversationID, "query", 10)
+			if !errors.Is(err, ErrCorruptVectorFile) {
+				t.Fatalf("Search returned %v, want %v", err, ErrCorruptVectorFile)
+			}
+		})
+	}
+}
+
+func TestChromemMemoryRepoSearchUnknownConversation(t *testing.T) {
+	repo, _ := newTestChromemMemoryRepo(t, "", embeddingtest.NewFakeService(vectors))
+	results, err := repo.Search(context.Background(), uuid.New(), "query", 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("Search returned %d results for a conversation without memories", len(results))
+	}
+}
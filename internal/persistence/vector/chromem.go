@@ -1,7 +1,161 @@
 package vector
 
-import "github.com/philippgille/chromem-go"
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 
-func NewChromem() *chromem.DB {
-	return chromem.NewDB()
+	"github.com/haren7/minimal-memory/internal/persistence"
+
+	"github.com/philippgille/chromem-go"
+)
+
+const chromemSuffix = ".gob.gz"
+
+// ChromemClient holds a chromem DB with a collection per conversation and
+// snapshots it as one compressed gob file per collection.
+type ChromemClient struct {
+	mu        sync.RWMutex
+	db        *chromem.DB
+	namespace persistence.Namespace
+}
+
+// NewChromem keeps the collections of namespace.
+func NewChromem(namespace persistence.Namespace) (*ChromemClient, error) {
+	err := namespace.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return &ChromemClient{db: chromem.NewDB(), namespace: namespace}, nil
+}
+
+func (r *ChromemClient) GetNamespace() persistence.Namespace {
+	return r.namespace
+}
+
+func (r *ChromemClient) GetOrCreateCollection(conversationID string) (*chromem.Collection, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.db.GetOrCreateCollection(conversationID, nil, nil)
+}
+
+// GetCollection returns nil if the conversation has no collection.
+func (r *ChromemClient) GetCollection(conversationID string) *chromem.Collection {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.db.GetCollection(conversationID, nil)
+}
+
+// DeleteCollection drops the collection of a conversation and returns the
+// number of documents it held, conversations without one hold none.
+func (r *ChromemClient) DeleteCollection(conversationID string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	collection := r.db.GetCollection(conversationID, nil)
+	if collection == nil {
+		return 0, nil
+	}
+	count := collection.Count()
+	err := r.db.DeleteCollection(conversationID)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ReplaceCollection swaps the collection of a conversation for one holding
+// documents, no documents drops it.
+func (r *ChromemClient) ReplaceCollection(ctx context.Context, conversationID string, documents []chromem.Document) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.db.DeleteCollection(conversationID)
+	if err != nil {
+		return err
+	}
+	if len(documents) == 0 {
+		return nil
+	}
+	collection, err := r.db.CreateCollection(conversationID, nil, nil)
+	if err != nil {
+		return err
+	}
+	return collection.AddDocuments(ctx, documents, 1)
+}
+
+// FileNames returns the names of the files Export writes for a conversation.
+func (r *ChromemClient) FileNames(conversationID string) []string {
+	return []string{conversationID + chromemSuffix}
+}
+
+// Mount replaces all collections with the ones in files, files without the
+// .gob.gz suffix are skipped.
+func (r *ChromemClient) Mount(dir string, files map[string]io.Reader) error {
+	db := chromem.NewDB()
+	for fileName, reader := range files {
+		if !strings.HasSuffix(fileName, chromemSuffix) {
+			continue
+		}
+		// chromem needs to seek back after sniffing the compression
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return fmt.Errorf("error reading file %s: %w", fileName, err)
+		}
+		err = db.ImportFromReader(bytes.NewReader(data), "")
+		if err != nil {
+			return fmt.Errorf("error importing collection from file %s: %w", fileName, err)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.db = db
+	return nil
+}
+
+func (r *ChromemClient) Export(dir string) ([]os.File, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var files []os.File
+	for conversationID := range r.db.ListCollections() {
+		filePath := filepath.Join(dir, conversationID+chromemSuffix)
+		err := r.exportCollection(filePath, conversationID)
+		if err != nil {
+			return nil, fmt.Errorf("error exporting collection for conversation id %s: %w", conversationID, err)
+		}
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("error opening file %s: %w", filePath, err)
+		}
+		files = append(files, *file)
+	}
+	return files, nil
+}
+
+// IndexSizes returns the number of documents held by each conversation collection.
+func (r *ChromemClient) IndexSizes() map[string]int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	collections := r.db.ListCollections()
+	sizes := make(map[string]int64, len(collections))
+	for conversationID, collection := range collections {
+		sizes[conversationID] = int64(collection.Count())
+	}
+	return sizes
+}
+
+func (r *ChromemClient) exportCollection(filePath string, conversationID string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	err = r.db.ExportToWriter(file, true, "", conversationID)
+	if err != nil {
+		return err
+	}
+	return file.Close()
 }
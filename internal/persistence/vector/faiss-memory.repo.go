@@ -1,9 +1,13 @@
+//go:build cgo && !nofaiss
+
 package vector
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
+	"log"
+	"sync"
 
 	"github.com/haren7/minimal-memory/internal/embedding"
 	"github.com/haren7/minimal-memory/internal/persistence"
@@ -15,6 +19,8 @@ type FaissMemoryRepo struct {
 	faissClient     *FaissClient
 	embeddingClient embedding.ServiceInterface
 	rdbmsMemoryRepo persistence.MemoryRepoInterface
+	// migrations are the index rebuilds running in the background
+	migrations sync.WaitGroup
 }
 
 func NewFaissMemoryRepo(faissClient *FaissClient, embeddingClient embedding.ServiceInterface, rdbmsMemoryRepo persistence.MemoryRepoInterface) persistence.VectorMemoryRepoInterface {
@@ -25,24 +31,33 @@ func NewFaissMemoryRepo(faissClient *FaissClient, embeddingClient embedding.Serv
 	}
 }
 
-func (r *FaissMemoryRepo) Index(ctx context.Context, conversationID, memoryID uuid.UUID, query, response string, createdAt time.Time) (persistence.VectorMemory, error) {
-	memoryId, err := r.rdbmsMemoryRepo.InsertOne(ctx, conversationID, memoryID, query, response, createdAt)
+func (r *FaissMemoryRepo) Index(ctx context.Context, conversationID uuid.UUID, memory persistence.VectorMemory) (persistence.VectorMemory, error) {
+	indexed, err := r.IndexMany(ctx, conversationID, []persistence.VectorMemory{memory})
 	if err != nil {
-		return persistence.VectorMemory{}, fmt.Errorf("faiss: error inserting memory, %w", err)
+		return persistence.VectorMemory{}, err
 	}
-	embedding, err := r.embeddingClient.EmbedOne(ctx, query)
+	return indexed[0], nil
+}
+
+func (r *FaissMemoryRepo) IndexMany(ctx context.Context, conversationID uuid.UUID, memories []persistence.VectorMemory) ([]persistence.VectorMemory, error) {
+	if len(memories) == 0 {
+		return []persistence.VectorMemory{}, nil
+	}
+	// embed first so that a failing provider leaves no row behind
+	embeddings, err := embedMemories(ctx, r.embeddingClient, memories)
+	if err != nil {
+		return nil, fmt.Errorf("faiss: error embedding queries, %w", err)
+	}
+	memoryIds, err := r.rdbmsMemoryRepo.InsertMany(ctx, toRows(conversationID, memories, embeddings))
 	if err != nil {
-		return persistence.VectorMemory{}, fmt.Errorf("faiss: error embedding query, %w", err)
+		return nil, fmt.Errorf("faiss: error inserting memories, %w", err)
 	}
-	err = r.faissClient.Index(ctx, conversationID.String(), memoryId, embedding)
+	err = r.faissClient.IndexMany(ctx, conversationID.String(), memoryIds, embeddings)
 	if err != nil {
-		return persistence.VectorMemory{}, fmt.Errorf("faiss: error indexing memory, %w", err)
+		return nil, fmt.Errorf("faiss: error indexing memories, %w", err)
 	}
-	return persistence.VectorMemory{
-		ID:       memoryID,
-		Query:    query,
-		Response: response,
-	}, nil
+	r.migrate(ctx, conversationID)
+	return withConversation(conversationID, memories, embeddings), nil
 }
 
 func (r *FaissMemoryRepo) Search(ctx context.Context, conversationID uuid.UUID, query string, topK int) ([]persistence.VectorMemory, error) {
@@ -51,22 +66,142 @@ func (r *FaissMemoryRepo) Search(ctx context.Context, conversationID uuid.UUID,
 		return nil, fmt.Errorf("faiss: error embedding query, %w", err)
 	}
 	faissResponse, err := r.faissClient.Search(ctx, conversationID.String(), embedding, topK)
-	var memoryIds []int
-	for _, id := range faissResponse.Ids {
-		memoryIds = append(memoryIds, int(id))
+	if errors.Is(err, ErrindexDoesNotExist) {
+		return nil, nil
 	}
-	rdbmsMemories, err := r.rdbmsMemoryRepo.FetchMany(ctx, memoryIds)
 	if err != nil {
-		return nil, fmt.Errorf("faiss: error fetching memories, %w", err)
+		return nil, fmt.Errorf("faiss: error searching index, %w", err)
 	}
-	var vectorMemories []persistence.VectorMemory
-	for _, memory := range rdbmsMemories {
-		vectorMemories = append(vectorMemories, persistence.VectorMemory{
-			ID:        memory.UUID,
-			Query:     memory.Query,
-			Response:  memory.Response,
-			CreatedAt: memory.CreatedAt,
-		})
+	vectorMemories, err := hydrate(ctx, r.rdbmsMemoryRepo, faissResponse.Metric, faissResponse.Ids, faissResponse.Distances)
+	if err != nil {
+		return nil, fmt.Errorf("faiss: error fetching memories, %w", err)
 	}
 	return vectorMemories, nil
 }
+
+func (r *FaissMemoryRepo) Size(ctx context.Context, conversationID uuid.UUID) (int, error) {
+	return int(r.faissClient.IndexSize(conversationID.String())), nil
+}
+
+func (r *FaissMemoryRepo) Delete(ctx context.Context, conversationID, memoryID uuid.UUID) error {
+	id, err := r.rdbmsMemoryRepo.DeleteOne(ctx, conversationID, memoryID)
+	if err != nil {
+		return fmt.Errorf("faiss: error deleting memory, %w", err)
+	}
+	err = r.faissClient.Remove(ctx, conversationID.String(), []int{id})
+	if errors.Is(err, ErrRemoveNotSupported) || errors.Is(err, ErrindexDoesNotExist) {
+		err = r.restore(ctx, conversationID)
+	}
+	if err != nil {
+		return fmt.Errorf("faiss: error removing memory from index, %w", err)
+	}
+	return nil
+}
+
+func (r *FaissMemoryRepo) Update(ctx context.Context, conversationID, memoryID uuid.UUID, query, response string) error {
+	// embed first so that a failing provider leaves the memory as it was
+	queryEmbedding, err := r.embeddingClient.EmbedOne(ctx, query)
+	if err != nil {
+		return fmt.Errorf("faiss: error embedding query, %w", err)
+	}
+	id, err := r.rdbmsMemoryRepo.UpdateOne(ctx, persistence.Memory{
+		UUID:           memoryID,
+		ConversationID: conversationID,
+		Query:          query,
+		Response:       response,
+		Embedding:      queryEmbedding.Vector,
+		EmbeddingModel: queryEmbedding.Model,
+	})
+	if err != nil {
+		return fmt.Errorf("faiss: error updating memory, %w", err)
+	}
+	err = r.faissClient.Update(ctx, conversationID.String(), []int{id}, []embedding.Embedding{queryEmbedding})
+	if errors.Is(err, ErrRemoveNotSupported) || errors.Is(err, ErrindexDoesNotExist) {
+		err = r.restore(ctx, conversationID)
+	}
+	if err != nil {
+		return fmt.Errorf("faiss: error updating memory in index, %w", err)
+	}
+	return nil
+}
+
+func (r *FaissMemoryRepo) Erase(ctx context.Context, conversationID uuid.UUID) (int, error) {
+	erased, err := r.rdbmsMemoryRepo.DeleteManyByConversationID(ctx, conversationID)
+	if err != nil {
+		return 0, fmt.Errorf("faiss: error deleting memories, %w", err)
+	}
+	// replacing an index with no vectors drops it
+	err = r.faissClient.Replace(ctx, conversationID.String(), nil, nil)
+	if err != nil {
+		return 0, fmt.Errorf("faiss: error dropping index, %w", err)
+	}
+	return erased, nil
+}
+
+func (r *FaissMemoryRepo) EraseShared(ctx context.Context, indexID, sourceConversationID uuid.UUID) (int, error) {
+	ids, err := r.rdbmsMemoryRepo.DeleteManyBySource(ctx, indexID, sourceConversationID)
+	if err != nil {
+		return 0, fmt.Errorf("faiss: error deleting shared memories, %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	err = r.faissClient.Remove(ctx, indexID.String(), ids)
+	if errors.Is(err, ErrRemoveNotSupported) || errors.Is(err, ErrindexDoesNotExist) {
+		err = r.restore(ctx, indexID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("faiss: error removing shared memories from index, %w", err)
+	}
+	return len(ids), nil
+}
+
+func (r *FaissMemoryRepo) Reindex(ctx context.Context, conversationIDs []uuid.UUID) ([]persistence.ReindexResult, error) {
+	results, err := reindex(ctx, r.embeddingClient, r.rdbmsMemoryRepo, conversationIDs, r.faissClient.Replace)
+	if err != nil {
+		return results, fmt.Errorf("faiss: error reindexing, %w", err)
+	}
+	return results, nil
+}
+
+// restore rebuilds the index of a conversation from its stored embeddings, for
+// changes the index cannot apply in place.
+func (r *FaissMemoryRepo) restore(ctx context.Context, conversationID uuid.UUID) error {
+	_, err := reindex(ctx, r.embeddingClient, r.rdbmsMemoryRepo, []uuid.UUID{conversationID}, r.faissClient.Replace)
+	return err
+}
+
+// migrate moves the index of a conversation off Flat once it crosses the
+// configured size. The new index is trained in the background on the
+// embeddings stored in DuckDB, the memory is already stored and searchable, so
+// a failed migration is only logged and retried once the index has doubled.
+func (r *FaissMemoryRepo) migrate(ctx context.Context, conversationID uuid.UUID) {
+	size, started := r.faissClient.StartMigration(conversationID.String())
+	if !started {
+		return
+	}
+	// the migration outlives the store that started it
+	ctx = context.WithoutCancel(ctx)
+	r.migrations.Go(func() {
+		err := r.rebuild(ctx, conversationID)
+		if errors.Is(err, errMigrationStale) {
+			return
+		}
+		if err != nil {
+			log.Printf("[ERROR] FaissMemoryRepo: Failed to migrate index (conversationID: %s, size: %d) - %v", conversationID, size, err)
+			r.faissClient.DeferMigration(conversationID.String())
+		}
+	})
+}
+
+func (r *FaissMemoryRepo) rebuild(ctx context.Context, conversationID uuid.UUID) error {
+	ids, embeddings, err := embedConversation(ctx, r.embeddingClient, r.rdbmsMemoryRepo, conversationID)
+	if err != nil {
+		return fmt.Errorf("faiss: error embedding memories, %w", err)
+	}
+	err = r.faissClient.Rebuild(ctx, conversationID.String(), ids, embeddings)
+	if err != nil {
+		return fmt.Errorf("faiss: error rebuilding index, %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,360 @@
+//go:build cgo && !nofaiss
+
+package vector
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/embedding/embeddingtest"
+	"github.com/haren7/minimal-memory/internal/persistence"
+	"github.com/haren7/minimal-memory/internal/persistence/rdbms"
+	"github.com/haren7/minimal-memory/internal/persistence/rdbms/rdbmstest"
+
+	"github.com/google/uuid"
+)
+
+func newTestFaissMemoryRepo(t *testing.T, config FaissConfig, embeddingService embedding.ServiceInterface) (persistence.VectorMemoryRepoInterface, *FaissClient) {
+	t.Helper()
+	duckdbClient := rdbmstest.NewDuckDBClient(t)
+	faissClient, err := NewFaissClient(config)
+	if err != nil {
+		t.Fatalf("NewFaissClient: %v", err)
+	}
+	repo := NewFaissMemoryRepo(faissClient, embeddingService, rdbms.NewFaissMemoryRepo(duckdbClient))
+	// cleanups run last first, migrations finish before the database closes
+	t.Cleanup(repo.(*FaissMemoryRepo).migrations.Wait)
+	return repo, faissClient
+}
+
+func TestFaissMemoryRepoSearchRanksNearestFirst(t *testing.T) {
+	tests := []struct {
+		name   string
+		store  func(ctx context.Context, repo persistence.VectorMemoryRepoInterface, conversationID uuid.UUID) error
+		topK   int
+		expect []string
+	}{
+		{name: "index one by one", store: indexOneByOne, topK: 10, expect: []string{"nearest", "middle", "farthest"}},
+		{name: "index many", store: indexMany, topK: 10, expect: []string{"nearest", "middle", "farthest"}},
+		{name: "top k truncates the tail", store: indexOneByOne, topK: 2, expect: []string{"nearest", "middle"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo, _ := newTestFaissMemoryRepo(t, FaissConfig{Metric: MetricL2}, embeddingtest.NewFakeService(vectors))
+			conversationID := uuid.New()
+			err := test.store(ctx, repo, conversationID)
+			if err != nil {
+				t.Fatalf("store: %v", err)
+			}
+			results, err := repo.Search(ctx, conversationID, "query", test.topK)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			var got []string
+			for i, result := range results {
+				got = append(got, result.Query)
+				if result.Rank != i+1 {
+					t.Errorf("result %d has rank %d, want %d", i, result.Rank, i+1)
+				}
+				if want := 1 / (1 + result.Distance); result.Score != want {
+					t.Errorf("result %d has score %v for distance %v, want %v", i, result.Score, result.Distance, want)
+				}
+				if i > 0 && result.Score > results[i-1].Score {
+					t.Errorf("result %d scores %v, higher than result %d with %v", i, result.Score, i-1, results[i-1].Score)
+				}
+			}
+			if !slices.Equal(got, test.expect) {
+				t.Fatalf("Search ranked %v, want %v", got, test.expect)
+			}
+		})
+	}
+}
+
+func TestFaissMemoryRepoMigratesPastThreshold(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      FaissConfig
+		wantFactory string
+	}{
+		{name: "hnsw", config: FaissConfig{Factory: "HNSW8", MigrateAt: 3, EfSearch: 16}, wantFactory: "IDMap,HNSW8"},
+		{name: "ivf trained on stored memories", config: FaissConfig{Factory: "IDMap,IVF1,Flat", MigrateAt: 3, NProbe: 1}, wantFactory: "IDMap,IVF1,Flat"},
+		{name: "inner product", config: FaissConfig{Factory: "HNSW8", Metric: MetricInnerProduct, MigrateAt: 3}, wantFactory: "IDMap,HNSW8"},
+		{name: "below threshold stays flat", config: FaissConfig{Factory: "HNSW8", MigrateAt: 4}, wantFactory: DefaultFactory},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo, faissClient := newTestFaissMemoryRepo(t, test.config, embeddingtest.NewFakeService(vectors))
+			conversationID := uuid.New()
+			err := indexOneByOne(ctx, repo, conversationID)
+			if err != nil {
+				t.Fatalf("store: %v", err)
+			}
+			repo.(*FaissMemoryRepo).migrations.Wait()
+			meta, _ := faissClient.Meta(conversationID.String())
+			if meta.Factory != test.wantFactory {
+				t.Fatalf("index built with %q, want %q", meta.Factory, test.wantFactory)
+			}
+			if size := faissClient.IndexSizes()[conversationID.String()]; size != int64(len(storeOrder)) {
+				t.Fatalf("index holds %d vectors, want %d", size, len(storeOrder))
+			}
+			results, err := repo.Search(ctx, conversationID, "query", 10)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			var got []string
+			for i, result := range results {
+				got = append(got, result.Query)
+				if i > 0 && result.Score > results[i-1].Score {
+					t.Errorf("result %d scores %v, higher than result %d with %v", i, result.Score, i-1, results[i-1].Score)
+				}
+			}
+			if want := []string{"nearest", "middle", "farthest"}; !slices.Equal(got, want) {
+				t.Fatalf("Search ranked %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestFaissClientRebuildDuringWrites(t *testing.T) {
+	embeddings := make([]embedding.Embedding, 4)
+	for i, query := range []string{"nearest", "middle", "farthest", "query"} {
+		embeddings[i] = embedding.Embedding{Model: embeddingtest.FakeModel, Dim: 4, Vector: vectors[query]}
+	}
+	tests := []struct {
+		name        string
+		during      func(ctx context.Context, client *FaissClient) error
+		wantErr     error
+		wantFactory string
+		wantSize    int64
+	}{
+		{name: "insert is carried over", during: func(ctx context.Context, client *FaissClient) error {
+			return client.Index(ctx, "conversation", 4, embeddings[3])
+		}, wantFactory: "IDMap,HNSW8", wantSize: 4},
+		{name: "removal makes it stale", during: func(ctx context.Context, client *FaissClient) error {
+			return client.Remove(ctx, "conversation", []int{1})
+		}, wantErr: errMigrationStale, wantFactory: DefaultFactory, wantSize: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			client, err := NewFaissClient(FaissConfig{Factory: "HNSW8", MigrateAt: 3})
+			if err != nil {
+				t.Fatalf("NewFaissClient: %v", err)
+			}
+			ids := []int{1, 2, 3}
+			err = client.IndexMany(ctx, "conversation", ids, embeddings[:3])
+			if err != nil {
+				t.Fatalf("IndexMany: %v", err)
+			}
+			_, started := client.StartMigration("conversation")
+			if !started {
+				t.Fatalf("StartMigration did not start at the threshold")
+			}
+			if _, again := client.StartMigration("conversation"); again {
+				t.Fatalf("StartMigration started a second migration")
+			}
+			err = test.during(ctx, client)
+			if err != nil {
+				t.Fatalf("write during the rebuild: %v", err)
+			}
+			// the rows read from the database before the write
+			err = client.Rebuild(ctx, "conversation", ids, embeddings[:3])
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Rebuild returned %v, want %v", err, test.wantErr)
+			}
+			meta, _ := client.Meta("conversation")
+			if meta.Factory != test.wantFactory {
+				t.Fatalf("index built with %q, want %q", meta.Factory, test.wantFactory)
+			}
+			if size := client.IndexSizes()["conversation"]; size != test.wantSize {
+				t.Fatalf("index holds %d vectors, want %d", size, test.wantSize)
+			}
+		})
+	}
+}
+
+func TestNewFaissClientRejectsInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config FaissConfig
+	}{
+		{name: "unknown metric", config: FaissConfig{Metric: "manhattan"}},
+		{name: "unknown factory", config: FaissConfig{Factory: "NotAnIndex"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewFaissClient(test.config)
+			if err == nil {
+				t.Fatalf("NewFaissClient(%+v) succeeded", test.config)
+			}
+		})
+	}
+}
+
+func TestFaissClientMountsLegacyIndexesWithDefaultMetric(t *testing.T) {
+	ctx := context.Background()
+	repo, faissClient := newTestFaissMemoryRepo(t, FaissConfig{}, embeddingtest.NewFakeService(vectors))
+	conversationID := uuid.New()
+	err := indexMany(ctx, repo, conversationID)
+	if err != nil {
+		t.Fatalf("IndexMany: %v", err)
+	}
+	files, err := faissClient.Export(t.TempDir())
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	// indexes exported before the meta file existed come without it
+	readers := make(map[string]io.Reader)
+	for i := range files {
+		files[i].Close()
+		if strings.HasSuffix(files[i].Name(), metaSuffix) {
+			continue
+		}
+		file, err := os.Open(files[i].Name())
+		if err != nil {
+			t.Fatalf("Open: %v", err)
+		}
+		defer file.Close()
+		readers[filepath.Base(file.Name())] = file
+	}
+	mounted, err := NewFaissClient(FaissConfig{})
+	if err != nil {
+		t.Fatalf("NewFaissClient: %v", err)
+	}
+	err = mounted.Mount(t.TempDir(), readers)
+	if err != nil {
+		t.Fatalf("Mount: %v", err)
+	}
+	if metric := mounted.conversationIDVsMeta[conversationID.String()].Metric; metric != mounted.metric {
+		t.Fatalf("legacy index mounted with metric %q, new indexes get %q", metric, mounted.metric)
+	}
+}
+
+func TestFaissClientMountReplacesIndexes(t *testing.T) {
+	ctx := context.Background()
+	repo, faissClient := newTestFaissMemoryRepo(t, FaissConfig{}, embeddingtest.NewFakeService(vectors))
+	conversationID := uuid.New()
+	err := indexMany(ctx, repo, conversationID)
+	if err != nil {
+		t.Fatalf("IndexMany: %v", err)
+	}
+	files, err := faissClient.Export(t.TempDir())
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	for i := range files {
+		files[i].Close()
+	}
+	readers := func(t *testing.T, meta string) map[string]io.Reader {
+		readers := make(map[string]io.Reader)
+		for i := range files {
+			if meta != "" && strings.HasSuffix(files[i].Name(), metaSuffix) {
+				readers[filepath.Base(files[i].Name())] = strings.NewReader(meta)
+				continue
+			}
+			file, err := os.Open(files[i].Name())
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			t.Cleanup(func() { file.Close() })
+			readers[filepath.Base(file.Name())] = file
+		}
+		return readers
+	}
+
+	tests := []struct {
+		name    string
+		meta    string
+		wantErr bool
+	}{
+		{name: "remount"},
+		{name: "remount again"},
+		{name: "meta of another dimension", meta: `{"dim": 3}`, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := faissClient.Mount(t.TempDir(), readers(t, test.meta))
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Mount returned %v, want error %v", err, test.wantErr)
+			}
+			// a failed mount keeps the indexes it would have replaced
+			results, err := repo.Search(ctx, conversationID, "query", 10)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if !slices.Equal(queriesOf(results), []string{"nearest", "middle", "farthest"}) {
+				t.Fatalf("Search ranked %v", queriesOf(results))
+			}
+		})
+	}
+}
+
+func TestFaissMemoryRepoSearchUnknownConversation(t *testing.T) {
+	repo, _ := newTestFaissMemoryRepo(t, FaissConfig{Metric: MetricL2}, embeddingtest.NewFakeService(vectors))
+	results, err := repo.Search(context.Background(), uuid.New(), "query", 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(results) != 0 {
+		t.Fatalf("Search returned %d results for a conversation without memories", len(results))
+	}
+}
+
+// faissTestBackends are the FAISS entries of the backend tables, an exact
+// index and an HNSW one that cannot remove vectors in place.
+func faissTestBackends(embeddingService embedding.ServiceInterface, metric Metric) []testBackend {
+	return []testBackend{
+		{name: "faiss", newRepo: func(t *testing.T) (persistence.VectorMemoryRepoInterface, vectorStore) {
+			return newTestFaissMemoryRepo(t, FaissConfig{Metric: metric}, embeddingService)
+		}},
+		{name: "faiss hnsw", newRepo: func(t *testing.T) (persistence.VectorMemoryRepoInterface, vectorStore) {
+			return newTestFaissMemoryRepo(t, FaissConfig{Factory: "HNSW32", Metric: metric}, embeddingService)
+		}},
+	}
+}
+
+func TestBruteForceMemoryRepoMatchesExactFaiss(t *testing.T) {
+	for _, metric := range []Metric{MetricL2, MetricInnerProduct, MetricCosine} {
+		t.Run(string(metric), func(t *testing.T) {
+			ctx := context.Background()
+			embeddingService := embeddingtest.NewFakeService(unnormalized)
+			bruteForceRepo, _ := newTestBruteForceMemoryRepo(t, metric, embeddingService)
+			faissRepo, _ := newTestFaissMemoryRepo(t, FaissConfig{Metric: metric}, embeddingService)
+			conversationID := uuid.New()
+			for _, repo := range []persistence.VectorMemoryRepoInterface{bruteForceRepo, faissRepo} {
+				err := indexMany(ctx, repo, conversationID)
+				if err != nil {
+					t.Fatalf("IndexMany: %v", err)
+				}
+			}
+			for _, topK := range []int{1, 2, 10} {
+				got, err := bruteForceRepo.Search(ctx, conversationID, "query", topK)
+				if err != nil {
+					t.Fatalf("Search: %v", err)
+				}
+				want, err := faissRepo.Search(ctx, conversationID, "query", topK)
+				if err != nil {
+					t.Fatalf("Search: %v", err)
+				}
+				if !slices.Equal(queriesOf(got), queriesOf(want)) {
+					t.Fatalf("top %d ranked %v, faiss ranked %v", topK, queriesOf(got), queriesOf(want))
+				}
+				for i := range got {
+					if math.Abs(float64(got[i].Score-want[i].Score)) > 1e-4 || got[i].Rank != want[i].Rank {
+						t.Errorf("top %d result %d has rank %d score %v, faiss has rank %d score %v", topK, i, got[i].Rank, got[i].Score, want[i].Rank, want[i].Score)
+					}
+				}
+			}
+		})
+	}
+}
@@ -1,99 +1,531 @@
+//go:build cgo && !nofaiss
+
 package vector
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/DataIntelligenceCrew/go-faiss"
 	_ "github.com/NerdMeNot/faiss-go-bindings"
 	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/persistence"
 )
 
-var ErrindexDoesNotExist = errors.New("faiss index does not exist")
+const metaSuffix = ".meta.json"
+
+func faissMetric(metric Metric) int {
+	if metric == MetricL2 {
+		return faiss.MetricL2
+	}
+	return faiss.MetricInnerProduct
+}
 
+// FaissSearchResponse holds the matches nearest first. Distances[i] is the
+// squared L2 distance of Ids[i] under MetricL2 and the inner product, larger
+// is nearer, under MetricInnerProduct and MetricCosine.
 type FaissSearchResponse struct {
 	Distances []float32
 	Ids       []int64
+	Metric    Metric
 }
 
 type FaissClient struct {
+	mu                    sync.RWMutex
 	dir                   string
+	namespace             persistence.Namespace
+	factory               string
+	metric                Metric
+	migrateAt             int64
+	nprobe                int
+	efSearch              int
 	conversationIDVsIndex map[string]*faiss.IndexImpl
+	conversationIDVsMeta  map[string]IndexMeta
+	// conversationIDVsMigrateAt pushes back the migration of conversations whose last attempt failed
+	conversationIDVsMigrateAt map[string]int64
+	// conversationIDVsMigration tracks the indexes being rebuilt in the background
+	conversationIDVsMigration map[string]*migration
 }
 
-func NewFaissClient() *FaissClient {
-	return &FaissClient{
-		conversationIDVsIndex: make(map[string]*faiss.IndexImpl),
+// migration records what happens to an index while it is rebuilt. The vectors
+// added meanwhile go into the new index, a removal makes it stale since it
+// would bring the removed vectors back.
+type migration struct {
+	ids        []int
+	embeddings []embedding.Embedding
+	stale      bool
+}
+
+// errMigrationStale is returned by a Rebuild that raced a removal or update,
+// the next insert starts it again.
+var errMigrationStale = errors.New("index changed while it was rebuilt")
+
+func NewFaissClient(config FaissConfig) (*FaissClient, error) {
+	metric, err := config.Metric.orDefault(DefaultMetric)
+	if err != nil {
+		return nil, err
 	}
+	err = config.Namespace.Validate()
+	if err != nil {
+		return nil, err
+	}
+	factory := config.factory()
+	migrateAt := int64(max(config.MigrateAt, 0))
+	if factory != DefaultFactory && migrateAt == 0 {
+		// probe the factory once so a typo fails at startup instead of on the first memory
+		probe, err := faiss.IndexFactory(8, factory, faissMetric(metric))
+		if err != nil {
+			return nil, fmt.Errorf("error creating index from factory %q - %w", factory, err)
+		}
+		if !probe.IsTrained() {
+			migrateAt = DefaultTrainAt
+		}
+		probe.Delete()
+	}
+	return &FaissClient{
+		namespace:                 config.Namespace,
+		factory:                   factory,
+		metric:                    metric,
+		migrateAt:                 migrateAt,
+		nprobe:                    config.NProbe,
+		efSearch:                  config.EfSearch,
+		conversationIDVsIndex:     make(map[string]*faiss.IndexImpl),
+		conversationIDVsMeta:      make(map[string]IndexMeta),
+		conversationIDVsMigrateAt: make(map[string]int64),
+		conversationIDVsMigration: make(map[string]*migration),
+	}, nil
 }
 
-func (r *FaissClient) Index(ctx context.Context, conversationID string, id int, embedding embedding.Embedding) error {
+func (r *FaissClient) GetNamespace() persistence.Namespace {
+	return r.namespace
+}
+
+func (r *FaissClient) Index(ctx context.Context, conversationID string, id int, vector embedding.Embedding) error {
+	return r.IndexMany(ctx, conversationID, []int{id}, []embedding.Embedding{vector})
+}
+
+// IndexMany adds all embeddings to the index of a conversation with a single
+// AddWithIDs call, ids[i] is the id of embeddings[i].
+func (r *FaissClient) IndexMany(ctx context.Context, conversationID string, ids []int, embeddings []embedding.Embedding) error {
+	if len(ids) != len(embeddings) {
+		return fmt.Errorf("error indexing %d embeddings with %d ids", len(embeddings), len(ids))
+	}
+	if len(embeddings) == 0 {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	first := embeddings[0]
 	index, exists := r.conversationIDVsIndex[conversationID]
 	if !exists {
-		newIndex, err := faiss.IndexFactory(embedding.Dim, "IDMap,Flat", 1)
+		// start exact when a migration is planned, trained factories cannot take vectors yet
+		factory := r.factory
+		if r.migrateAt > 0 {
+			factory = DefaultFactory
+		}
+		newIndex, err := r.newIndex(first.Dim, factory)
 		if err != nil {
-			return fmt.Errorf("error creating idmap + flat index with dim %d - %w", embedding.Dim, err)
+			return err
 		}
 		r.conversationIDVsIndex[conversationID] = newIndex
+		r.conversationIDVsMeta[conversationID] = IndexMeta{Model: first.Model, Dim: first.Dim, Factory: factory, Metric: r.metric}
 		index = newIndex
 	}
-	err := index.AddWithIDs(embedding.Vector, []int64{int64(id)})
+	meta := r.conversationIDVsMeta[conversationID]
+	vectors, labels, err := flatten(conversationID, meta, ids, embeddings)
+	if err != nil {
+		return err
+	}
+	err = index.AddWithIDs(vectors, labels)
+	if err != nil {
+		return fmt.Errorf("error adding %d vectors to index - %w", len(ids), err)
+	}
+	if migration, migrating := r.conversationIDVsMigration[conversationID]; migrating {
+		migration.ids = append(migration.ids, ids...)
+		migration.embeddings = append(migration.embeddings, embeddings...)
+	}
+	if meta.Model == "" {
+		// indexes that predate model tracking adopt the model of their next vectors
+		meta.Model = first.Model
+		r.conversationIDVsMeta[conversationID] = meta
+	}
+	return nil
+}
+
+// StartMigration reports whether the index of a conversation has outgrown
+// Flat and should be rebuilt with the configured factory, along with its size.
+// When it should, the vectors added from then on are recorded for Rebuild, and
+// no other migration of the conversation starts until Rebuild or
+// DeferMigration ends this one.
+func (r *FaissClient) StartMigration(conversationID string) (int64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	index, exists := r.conversationIDVsIndex[conversationID]
+	if !exists || r.migrateAt == 0 {
+		return 0, false
+	}
+	if _, migrating := r.conversationIDVsMigration[conversationID]; migrating {
+		return 0, false
+	}
+	meta := r.conversationIDVsMeta[conversationID]
+	if meta.Factory == r.factory && meta.Metric == r.metric {
+		return 0, false
+	}
+	migrateAt, deferred := r.conversationIDVsMigrateAt[conversationID]
+	if !deferred {
+		migrateAt = r.migrateAt
+	}
+	size := index.Ntotal()
+	if size < migrateAt {
+		return size, false
+	}
+	r.conversationIDVsMigration[conversationID] = &migration{}
+	return size, true
+}
+
+// DeferMigration ends a failed migration and postpones the next one until the
+// index has doubled, so that a failing rebuild is not retried on every insert.
+func (r *FaissClient) DeferMigration(conversationID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.conversationIDVsMigration, conversationID)
+	index, exists := r.conversationIDVsIndex[conversationID]
+	if !exists {
+		return
+	}
+	r.conversationIDVsMigrateAt[conversationID] = 2 * max(index.Ntotal(), 1)
+}
+
+// Rebuild ends the migration StartMigration began by replacing the index of a
+// conversation with one built by the configured factory and metric, training
+// it first if the factory needs it. ids and embeddings are the vectors stored
+// in the database once the migration started. The index is built and trained
+// without the lock, so searches and inserts go on meanwhile, and the vectors
+// inserted since are added before it is swapped in. A removal or update in
+// between fails it with errMigrationStale.
+func (r *FaissClient) Rebuild(ctx context.Context, conversationID string, ids []int, embeddings []embedding.Embedding) error {
+	index, err := r.buildMigration(conversationID, ids, embeddings)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	migration := r.conversationIDVsMigration[conversationID]
+	delete(r.conversationIDVsMigration, conversationID)
+	if err != nil {
+		return err
+	}
+	current, exists := r.conversationIDVsIndex[conversationID]
+	if !exists || migration == nil || migration.stale {
+		index.Delete()
+		return errMigrationStale
+	}
+	// a vector read from the database can have been added to the index after the migration started
+	built := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		built[id] = true
+	}
+	var pendingIDs []int
+	var pendingEmbeddings []embedding.Embedding
+	for i, id := range migration.ids {
+		if !built[id] {
+			pendingIDs = append(pendingIDs, id)
+			pendingEmbeddings = append(pendingEmbeddings, migration.embeddings[i])
+		}
+	}
+	meta := r.conversationIDVsMeta[conversationID]
+	meta.Factory = r.factory
+	meta.Metric = r.metric
+	if len(pendingIDs) > 0 {
+		vectors, labels, err := flatten(conversationID, meta, pendingIDs, pendingEmbeddings)
+		if err == nil {
+			err = index.AddWithIDs(vectors, labels)
+		}
+		if err != nil {
+			index.Delete()
+			return fmt.Errorf("error adding %d vectors inserted during the rebuild - %w", len(pendingIDs), err)
+		}
+	}
+	if index.Ntotal() != current.Ntotal() {
+		index.Delete()
+		return fmt.Errorf("error rebuilding index of %d vectors, the new one holds %d", current.Ntotal(), index.Ntotal())
+	}
+	if meta.Model == "" {
+		meta.Model = embeddings[0].Model
+	}
+	r.conversationIDVsIndex[conversationID] = index
+	r.conversationIDVsMeta[conversationID] = meta
+	delete(r.conversationIDVsMigrateAt, conversationID)
+	current.Delete()
+	return nil
+}
+
+// buildMigration builds the index Rebuild swaps in without holding the lock.
+func (r *FaissClient) buildMigration(conversationID string, ids []int, embeddings []embedding.Embedding) (*faiss.IndexImpl, error) {
+	if len(ids) != len(embeddings) {
+		return nil, fmt.Errorf("error rebuilding with %d embeddings and %d ids", len(embeddings), len(ids))
+	}
+	if len(embeddings) == 0 {
+		return nil, errMigrationStale
+	}
+	meta, exists := r.Meta(conversationID)
+	if !exists {
+		return nil, ErrindexDoesNotExist
+	}
+	meta.Factory = r.factory
+	meta.Metric = r.metric
+	return r.build(conversationID, meta, ids, embeddings)
+}
+
+// Replace swaps the index of a conversation for one built from scratch out of
+// the given vectors, so a lost or corrupt index can be restored from the rows
+// in DuckDB. Unlike Rebuild it needs no current index and takes a new model.
+// The index is built with the configured factory, or Flat while it is below
+// the migration size, and only swapped in once it holds every vector. A
+// conversation without vectors loses its index.
+func (r *FaissClient) Replace(ctx context.Context, conversationID string, ids []int, embeddings []embedding.Embedding) error {
+	if len(ids) != len(embeddings) {
+		return fmt.Errorf("error replacing index with %d embeddings and %d ids", len(embeddings), len(ids))
+	}
+	var index *faiss.IndexImpl
+	var meta IndexMeta
+	if len(embeddings) > 0 {
+		factory := r.factory
+		if r.migrateAt > 0 && int64(len(ids)) < r.migrateAt {
+			factory = DefaultFactory
+		}
+		first := embeddings[0]
+		meta = IndexMeta{Model: first.Model, Dim: first.Dim, Factory: factory, Metric: r.metric}
+		// built outside the lock so searches keep using the current index while training
+		var err error
+		index, err = r.build(conversationID, meta, ids, embeddings)
+		if err != nil {
+			return err
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current, exists := r.conversationIDVsIndex[conversationID]
+	if exists {
+		current.Delete()
+	}
+	r.markStale(conversationID)
+	delete(r.conversationIDVsMigrateAt, conversationID)
+	if index == nil {
+		delete(r.conversationIDVsIndex, conversationID)
+		delete(r.conversationIDVsMeta, conversationID)
+		return nil
+	}
+	r.conversationIDVsIndex[conversationID] = index
+	r.conversationIDVsMeta[conversationID] = meta
+	return nil
+}
+
+// Remove drops the vectors with the given ids from the index of a conversation,
+// ids it does not hold are ignored.
+func (r *FaissClient) Remove(ctx context.Context, conversationID string, ids []int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	index, exists := r.conversationIDVsIndex[conversationID]
+	if !exists {
+		return ErrindexDoesNotExist
+	}
+	r.markStale(conversationID)
+	return r.remove(index, r.conversationIDVsMeta[conversationID], ids)
+}
+
+// Update swaps the vectors stored under ids for embeddings in one step, so a
+// search never sees the index without them. ids[i] is the id of embeddings[i].
+func (r *FaissClient) Update(ctx context.Context, conversationID string, ids []int, embeddings []embedding.Embedding) error {
+	if len(ids) != len(embeddings) {
+		return fmt.Errorf("error updating %d embeddings with %d ids", len(embeddings), len(ids))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	index, exists := r.conversationIDVsIndex[conversationID]
+	if !exists {
+		return ErrindexDoesNotExist
+	}
+	r.markStale(conversationID)
+	meta := r.conversationIDVsMeta[conversationID]
+	// checked before removing so a mismatching embedding leaves the index untouched
+	vectors, labels, err := flatten(conversationID, meta, ids, embeddings)
+	if err != nil {
+		return err
+	}
+	err = r.remove(index, meta, ids)
+	if err != nil {
+		return err
+	}
+	err = index.AddWithIDs(vectors, labels)
 	if err != nil {
-		return fmt.Errorf("error adding vector to index: %d - %w", id, err)
+		return fmt.Errorf("error adding %d vectors to index - %w", len(ids), err)
+	}
+	return nil
+}
+
+// markStale must be called with the lock held.
+func (r *FaissClient) markStale(conversationID string) {
+	if migration, migrating := r.conversationIDVsMigration[conversationID]; migrating {
+		migration.stale = true
+	}
+}
+
+// remove must be called with the lock held.
+func (r *FaissClient) remove(index *faiss.IndexImpl, meta IndexMeta, ids []int) error {
+	if strings.Contains(meta.Factory, "HNSW") {
+		return ErrRemoveNotSupported
+	}
+	labels := make([]int64, len(ids))
+	for i, id := range ids {
+		labels[i] = int64(id)
+	}
+	selector, err := faiss.NewIDSelectorBatch(labels)
+	if err != nil {
+		return fmt.Errorf("error creating id selector - %w", err)
+	}
+	defer selector.Delete()
+	_, err = index.RemoveIDs(selector)
+	if err != nil {
+		return fmt.Errorf("error removing %d vectors from index - %w", len(ids), err)
 	}
 	return nil
 }
 
 func (r *FaissClient) Search(ctx context.Context, conversationID string, query embedding.Embedding, topK int) (FaissSearchResponse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	index, exists := r.conversationIDVsIndex[conversationID]
 	if !exists {
 		return FaissSearchResponse{}, ErrindexDoesNotExist
 	}
-	distances, labels, err := index.Search(query.Vector, int64(topK))
+	meta := r.conversationIDVsMeta[conversationID]
+	err := meta.check(conversationID, query)
+	if err != nil {
+		return FaissSearchResponse{}, err
+	}
+	vector := query.Vector
+	if meta.Metric == MetricCosine {
+		vector = normalized(vector)
+	}
+	// faiss allocates topK results per query, there are never more than the index holds
+	k := min(int64(topK), index.Ntotal())
+	if k <= 0 {
+		return FaissSearchResponse{Metric: meta.Metric}, nil
+	}
+	distances, labels, err := index.Search(vector, k)
 	if err != nil {
 		return FaissSearchResponse{}, fmt.Errorf("error searching index: %w", err)
 	}
-	var validIds []int64
-	for _, label := range labels {
+	// faiss pads missing results with -1, drop them while keeping ids and distances aligned
+	response := FaissSearchResponse{Metric: meta.Metric}
+	for i, label := range labels {
 		if label != -1 {
-			validIds = append(validIds, label)
+			response.Ids = append(response.Ids, label)
+			response.Distances = append(response.Distances, distances[i])
 		}
 	}
-	return FaissSearchResponse{
-		Distances: distances,
-		Ids:       validIds,
-	}, nil
+	return response, nil
 }
 
 func (r *FaissClient) Mount(dir string, files map[string]io.Reader) error {
+	conversationIDVsIndex, conversationIDVsMeta, err := r.readIndexes(dir, files)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// searches hold the read lock, nothing uses the replaced indexes once it is taken
+	deleteIndexes(r.conversationIDVsIndex)
+	for conversationID := range r.conversationIDVsMigration {
+		r.markStale(conversationID)
+	}
+	r.conversationIDVsIndex = conversationIDVsIndex
+	r.conversationIDVsMeta = conversationIDVsMeta
+	r.conversationIDVsMigrateAt = make(map[string]int64)
+	return nil
+}
+
+// readIndexes reads the indexes Mount swaps in, freeing the ones already read
+// when a file fails.
+func (r *FaissClient) readIndexes(dir string, files map[string]io.Reader) (map[string]*faiss.IndexImpl, map[string]IndexMeta, error) {
 	conversationIDVsIndex := make(map[string]*faiss.IndexImpl)
+	conversationIDVsMeta := make(map[string]IndexMeta)
 	for fileName, reader := range files {
+		if strings.HasSuffix(fileName, metaSuffix) {
+			var meta IndexMeta
+			err := json.NewDecoder(reader).Decode(&meta)
+			if err != nil {
+				deleteIndexes(conversationIDVsIndex)
+				return nil, nil, fmt.Errorf("error reading index meta %s: %w", fileName, err)
+			}
+			conversationIDVsMeta[strings.TrimSuffix(fileName, metaSuffix)] = meta
+			continue
+		}
+		if !strings.HasSuffix(fileName, ".index") {
+			continue
+		}
 		conversationID := strings.TrimSuffix(fileName, ".index")
 		writePath := r.getFilePath(dir, conversationID)
 		bytes, err := io.ReadAll(reader)
 		if err != nil {
-			return fmt.Errorf("error reading file %s: %w", fileName, err)
+			deleteIndexes(conversationIDVsIndex)
+			return nil, nil, fmt.Errorf("error reading file %s: %w", fileName, err)
 		}
 		err = os.WriteFile(writePath, bytes, 0644)
 		if err != nil {
-			return fmt.Errorf("error writing file %s: %w", fileName, err)
+			deleteIndexes(conversationIDVsIndex)
+			return nil, nil, fmt.Errorf("error writing file %s: %w", fileName, err)
 		}
 		index, err := faiss.ReadIndex(writePath, 0)
 		if err != nil {
-			return fmt.Errorf("error reading index from file %s: %w", fileName, err)
+			deleteIndexes(conversationIDVsIndex)
+			return nil, nil, fmt.Errorf("error reading index from file %s: %w", fileName, err)
 		}
 		conversationIDVsIndex[conversationID] = index
 	}
-	r.conversationIDVsIndex = conversationIDVsIndex
-	return nil
+	for conversationID, index := range conversationIDVsIndex {
+		meta, exists := conversationIDVsMeta[conversationID]
+		if !exists {
+			// indexes exported before the meta file existed only know their dimension
+			meta = IndexMeta{Dim: index.D()}
+		}
+		if meta.Dim != index.D() {
+			deleteIndexes(conversationIDVsIndex)
+			return nil, nil, fmt.Errorf("index for conversation id %s has dim %d but its meta says %d: %w", conversationID, index.D(), meta.Dim, ErrEmbeddingMismatch)
+		}
+		if meta.Factory == "" {
+			meta.Factory = DefaultFactory
+		}
+		if meta.Metric == "" {
+			meta.Metric = MetricL2
+			if index.MetricType() == faiss.MetricInnerProduct {
+				meta.Metric = MetricInnerProduct
+			}
+		}
+		err := r.setSearchParameters(index, meta.Factory)
+		if err != nil {
+			deleteIndexes(conversationIDVsIndex)
+			return nil, nil, err
+		}
+		conversationIDVsMeta[conversationID] = meta
+	}
+	return conversationIDVsIndex, conversationIDVsMeta, nil
+}
+
+func deleteIndexes(conversationIDVsIndex map[string]*faiss.IndexImpl) {
+	for _, index := range conversationIDVsIndex {
+		index.Delete()
+	}
 }
 
 func (r *FaissClient) Export(dir string) ([]os.File, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	var files []os.File
 	for conversationID, index := range r.conversationIDVsIndex {
 		filePath := r.getFilePath(dir, conversationID)
@@ -106,10 +538,138 @@ func (r *FaissClient) Export(dir string) ([]os.File, error) {
 			return nil, fmt.Errorf("error opening file %s: %w", filePath, err)
 		}
 		files = append(files, *file)
+		metaFile, err := r.exportMeta(dir, conversationID)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, *metaFile)
 	}
 	return files, nil
 }
 
+// FileNames returns the names of the files Export writes for a conversation.
+func (r *FaissClient) FileNames(conversationID string) []string {
+	return []string{conversationID + ".index", conversationID + metaSuffix}
+}
+
+// Meta returns the embedding identity of the index of a conversation.
+func (r *FaissClient) Meta(conversationID string) (IndexMeta, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	meta, exists := r.conversationIDVsMeta[conversationID]
+	return meta, exists
+}
+
+// IndexSize returns the number of vectors held by the index of conversationID.
+func (r *FaissClient) IndexSize(conversationID string) int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	index, exists := r.conversationIDVsIndex[conversationID]
+	if !exists {
+		return 0
+	}
+	return index.Ntotal()
+}
+
+// IndexSizes returns the number of vectors held by each conversation index.
+func (r *FaissClient) IndexSizes() map[string]int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sizes := make(map[string]int64, len(r.conversationIDVsIndex))
+	for conversationID, index := range r.conversationIDVsIndex {
+		sizes[conversationID] = index.Ntotal()
+	}
+	return sizes
+}
+
+func (r *FaissClient) newIndex(dim int, factory string) (*faiss.IndexImpl, error) {
+	index, err := faiss.IndexFactory(dim, factory, faissMetric(r.metric))
+	if err != nil {
+		return nil, fmt.Errorf("error creating %s index with dim %d - %w", factory, dim, err)
+	}
+	err = r.setSearchParameters(index, factory)
+	if err != nil {
+		index.Delete()
+		return nil, err
+	}
+	return index, nil
+}
+
+// build creates the index described by meta holding exactly the given vectors,
+// training it on them first if its factory needs it.
+func (r *FaissClient) build(conversationID string, meta IndexMeta, ids []int, embeddings []embedding.Embedding) (*faiss.IndexImpl, error) {
+	vectors, labels, err := flatten(conversationID, meta, ids, embeddings)
+	if err != nil {
+		return nil, err
+	}
+	index, err := r.newIndex(meta.Dim, meta.Factory)
+	if err != nil {
+		return nil, err
+	}
+	if !index.IsTrained() {
+		err = index.Train(vectors)
+		if err != nil {
+			index.Delete()
+			return nil, fmt.Errorf("error training index on %d vectors - %w", len(ids), err)
+		}
+	}
+	err = index.AddWithIDs(vectors, labels)
+	if err != nil {
+		index.Delete()
+		return nil, fmt.Errorf("error adding %d vectors to index - %w", len(ids), err)
+	}
+	if index.Ntotal() != int64(len(ids)) {
+		index.Delete()
+		return nil, fmt.Errorf("error building index, it holds %d of %d vectors", index.Ntotal(), len(ids))
+	}
+	return index, nil
+}
+
+// setSearchParameters applies nprobe and efSearch to the indexes they exist on,
+// faiss rejects parameters an index does not have.
+func (r *FaissClient) setSearchParameters(index *faiss.IndexImpl, factory string) error {
+	parameters := make(map[string]int)
+	if r.nprobe > 0 && strings.Contains(factory, "IVF") {
+		parameters["nprobe"] = r.nprobe
+	}
+	if r.efSearch > 0 && strings.Contains(factory, "HNSW") {
+		parameters["efSearch"] = r.efSearch
+	}
+	if len(parameters) == 0 {
+		return nil
+	}
+	space, err := faiss.NewParameterSpace()
+	if err != nil {
+		return fmt.Errorf("error creating parameter space - %w", err)
+	}
+	defer space.Delete()
+	for name, value := range parameters {
+		err = space.SetIndexParameter(index, name, float64(value))
+		if err != nil {
+			return fmt.Errorf("error setting %s on %s index - %w", name, factory, err)
+		}
+	}
+	return nil
+}
+
+func (r *FaissClient) exportMeta(dir string, conversationID string) (*os.File, error) {
+	filePath := filepath.Join(dir, conversationID+metaSuffix)
+	meta := r.conversationIDVsMeta[conversationID]
+	bytes, err := json.Marshal(meta)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding index meta for conversation id %s: %w", conversationID, err)
+	}
+	err = os.WriteFile(filePath, bytes, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error writing index meta %s: %w", filePath, err)
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file %s: %w", filePath, err)
+	}
+	return file, nil
+}
+
 func (r *FaissClient) getFilePath(dir string, conversationID string) string {
 	return filepath.Join(dir, fmt.Sprintf("%s.index", conversationID))
 }
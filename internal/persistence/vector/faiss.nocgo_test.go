@@ -0,0 +1,9 @@
+//go:build !cgo || nofaiss
+
+package vector
+
+import "github.com/haren7/minimal-memory/internal/embedding"
+
+func faissTestBackends(embeddingService embedding.ServiceInterface, metric Metric) []testBackend {
+	return nil
+}
@@ -0,0 +1,103 @@
+package vector
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/persistence"
+)
+
+const (
+	// DefaultFactory is an exact index, fine for small conversations.
+	DefaultFactory = "IDMap,Flat"
+	// DefaultTrainAt is the size at which indexes whose factory needs training
+	// are migrated off Flat when FaissConfig.MigrateAt is not set.
+	DefaultTrainAt = 1000
+)
+
+var ErrindexDoesNotExist = errors.New("faiss index does not exist")
+var ErrEmbeddingMismatch = errors.New("embedding does not match index")
+
+// ErrRemoveNotSupported is returned for indexes that cannot drop single vectors,
+// such as HNSW, they have to be rebuilt without them instead.
+var ErrRemoveNotSupported = errors.New("faiss index does not support removing vectors")
+
+// FaissConfig selects the index every conversation is built with.
+type FaissConfig struct {
+	// Factory is a faiss index factory string such as "IDMap,HNSW32",
+	// "IDMap,IVF256,Flat" or "IDMap,IVF256,PQ16", defaults to DefaultFactory.
+	// Indexes need ids, so an IDMap prefix is added when missing.
+	Factory string
+	// Metric defaults to DefaultMetric, MetricCosine builds inner product indexes
+	// over normalized vectors.
+	Metric Metric
+	// MigrateAt is the number of vectors at which a conversation index is moved
+	// from Flat to Factory. Indexes start on Flat whenever it is set, factories
+	// that need training (IVF, PQ) always do and default it to DefaultTrainAt.
+	MigrateAt int
+	// NProbe is the number of IVF lists visited per search, zero keeps the faiss default.
+	NProbe int
+	// EfSearch is the HNSW search depth, zero keeps the faiss default.
+	EfSearch int
+	// Namespace is the tenant the indexes belong to, it selects where they are
+	// snapshotted.
+	Namespace persistence.Namespace
+}
+
+func (r FaissConfig) factory() string {
+	factory := strings.ReplaceAll(r.Factory, " ", "")
+	if factory == "" {
+		return DefaultFactory
+	}
+	if !strings.HasPrefix(factory, "IDMap") {
+		return "IDMap," + factory
+	}
+	return factory
+}
+
+// IndexMeta is the identity of the embeddings an index was built from, it is
+// exported next to each index so that a different model is caught on reload.
+type IndexMeta struct {
+	Model string `json:"model"`
+	Dim   int    `json:"dim"`
+	// Factory is the factory string the index was built with, indexes exported
+	// before it was recorded are DefaultFactory.
+	Factory string `json:"factory,omitempty"`
+	// Metric is the metric the index compares with, indexes exported before it
+	// was recorded are MetricL2 or MetricInnerProduct as faiss reports them.
+	Metric Metric `json:"metric,omitempty"`
+}
+
+// check rejects vectors from another model or of another size, faiss itself
+// would read past the vector or silently mix incomparable embeddings.
+func (r IndexMeta) check(conversationID string, embedding embedding.Embedding) error {
+	if embedding.Dim != r.Dim || len(embedding.Vector) != r.Dim {
+		return fmt.Errorf("index for conversation id %s has dim %d, got %d: %w", conversationID, r.Dim, len(embedding.Vector), ErrEmbeddingMismatch)
+	}
+	if r.Model != "" && embedding.Model != "" && embedding.Model != r.Model {
+		return fmt.Errorf("index for conversation id %s was built with model %s, got %s: %w", conversationID, r.Model, embedding.Model, ErrEmbeddingMismatch)
+	}
+	return nil
+}
+
+// flatten checks embeddings against meta and lays them out for AddWithIDs,
+// normalized under MetricCosine.
+func flatten(conversationID string, meta IndexMeta, ids []int, embeddings []embedding.Embedding) ([]float32, []int64, error) {
+	vectors := make([]float32, 0, len(embeddings)*meta.Dim)
+	labels := make([]int64, len(ids))
+	for i, embedding := range embeddings {
+		err := meta.check(conversationID, embedding)
+		if err != nil {
+			return nil, nil, err
+		}
+		if meta.Metric == MetricCosine {
+			vectors = append(vectors, normalized(embedding.Vector)...)
+		} else {
+			vectors = append(vectors, embedding.Vector...)
+		}
+		labels[i] = int64(ids[i])
+	}
+	return vectors, labels, nil
+}
@@ -0,0 +1,123 @@
+package vector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/persistence"
+
+	"github.com/google/uuid"
+)
+
+// embedBatchSize caps the texts sent in one EmbedMany call, providers reject
+// oversized requests.
+const embedBatchSize = 256
+
+// toRows lays out memories for the rdbms memory repo, embeddings[i] is the
+// embedding of memories[i].
+func toRows(conversationID uuid.UUID, memories []persistence.VectorMemory, embeddings []embedding.Embedding) []persistence.Memory {
+	rows := make([]persistence.Memory, len(memories))
+	for i, memory := range memories {
+		rows[i] = persistence.Memory{
+			UUID:                 memory.ID,
+			ConversationID:       conversationID,
+			Query:                memory.Query,
+			Response:             memory.Response,
+			CreatedAt:            memory.CreatedAt,
+			Metadata:             memory.Metadata,
+			Tags:                 memory.Tags,
+			Embedding:            embeddings[i].Vector,
+			EmbeddingModel:       embeddings[i].Model,
+			SourceConversationID: memory.SourceConversationID,
+		}
+	}
+	return rows
+}
+
+// withConversation returns memories as indexed in conversationID along with
+// their embeddings, so they can be indexed elsewhere without embedding again.
+func withConversation(conversationID uuid.UUID, memories []persistence.VectorMemory, embeddings []embedding.Embedding) []persistence.VectorMemory {
+	indexed := make([]persistence.VectorMemory, len(memories))
+	for i, memory := range memories {
+		memory.ConversationID = conversationID
+		memory.Embedding = embeddings[i].Vector
+		memory.EmbeddingModel = embeddings[i].Model
+		indexed[i] = memory
+	}
+	return indexed
+}
+
+// hydrate fetches the rows of the ids a search returned, nearest first, and
+// scores them. distances[i] is what the index returned for ids[i].
+func hydrate(ctx context.Context, rdbmsMemoryRepo persistence.MemoryRepoInterface, metric Metric, ids []int64, distances []float32) ([]persistence.VectorMemory, error) {
+	memoryIds := make([]int, len(ids))
+	idVsDistance := make(map[int]float32, len(ids))
+	for i, id := range ids {
+		memoryIds[i] = int(id)
+		idVsDistance[int(id)] = distances[i]
+	}
+	rdbmsMemories, err := rdbmsMemoryRepo.FetchMany(ctx, memoryIds)
+	if err != nil {
+		return nil, err
+	}
+	// rdbmsMemories follow the search ranking, nearest first
+	var vectorMemories []persistence.VectorMemory
+	for i, memory := range rdbmsMemories {
+		distance, score := scoreOf(metric, idVsDistance[memory.ID])
+		vectorMemories = append(vectorMemories, persistence.VectorMemory{
+			Rank:                 i + 1,
+			ID:                   memory.UUID,
+			ConversationID:       memory.ConversationID,
+			Query:                memory.Query,
+			Response:             memory.Response,
+			CreatedAt:            memory.CreatedAt,
+			Metadata:             memory.Metadata,
+			Tags:                 memory.Tags,
+			SourceConversationID: memory.SourceConversationID,
+			Distance:             distance,
+			Score:                score,
+		})
+	}
+	return vectorMemories, nil
+}
+
+// embedMemories embeds the queries of the memories that carry no embedding
+// yet, embeddings[i] is the embedding of memories[i].
+func embedMemories(ctx context.Context, embeddingService embedding.ServiceInterface, memories []persistence.VectorMemory) ([]embedding.Embedding, error) {
+	embeddings := make([]embedding.Embedding, len(memories))
+	var queries []string
+	var missing []int
+	for i, memory := range memories {
+		if memory.Embedding != nil {
+			embeddings[i] = embedding.Embedding{Model: memory.EmbeddingModel, Dim: len(memory.Embedding), Vector: memory.Embedding}
+			continue
+		}
+		queries = append(queries, memory.Query)
+		missing = append(missing, i)
+	}
+	embedded, err := embedInBatches(ctx, embeddingService, queries)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range missing {
+		embeddings[i] = embedded[j]
+	}
+	return embeddings, nil
+}
+
+func embedInBatches(ctx context.Context, embeddingService embedding.ServiceInterface, texts []string) ([]embedding.Embedding, error) {
+	embeddings := make([]embedding.Embedding, 0, len(texts))
+	for start := 0; start < len(texts); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(texts))
+		batch, err := embeddingService.EmbedMany(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) != end-start {
+			return nil, fmt.Errorf("got %d embeddings for %d texts", len(batch), end-start)
+		}
+		embeddings = append(embeddings, batch...)
+	}
+	return embeddings, nil
+}
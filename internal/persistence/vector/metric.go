@@ -0,0 +1,72 @@
+package vector
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+type Metric string
+
+const (
+	MetricL2           Metric = "l2"
+	MetricInnerProduct Metric = "ip"
+	// MetricCosine normalizes stored and query vectors to unit length and
+	// compares them by inner product, scores are the cosine similarity in [-1, 1].
+	MetricCosine Metric = "cosine"
+)
+
+// DefaultMetric is the metric of every backend when none is set. Indexes
+// exported before the metric was recorded are L2, so it stays L2 and their
+// scores keep meaning what new ones do. Cosine, the one metric all backends
+// agree on, is opt-in.
+const DefaultMetric = MetricL2
+
+var ErrUnknownMetric = errors.New("unknown vector metric")
+
+func (r Metric) orDefault(fallback Metric) (Metric, error) {
+	switch r {
+	case "":
+		return fallback, nil
+	case MetricL2, MetricInnerProduct, MetricCosine:
+		return r, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnknownMetric, r)
+}
+
+// scoreOf turns what a backend returned for a match into a distance, smaller
+// is nearer, and a score, larger is nearer. value is a squared L2 distance
+// under MetricL2 and an inner product otherwise.
+func scoreOf(metric Metric, value float32) (float32, float32) {
+	switch metric {
+	case MetricCosine:
+		// rounding can push the inner product of unit vectors just past 1
+		similarity := min(max(value, -1), 1)
+		return 1 - similarity, similarity
+	case MetricInnerProduct:
+		return 1 - value, value
+	}
+	return value, l2Score(value)
+}
+
+// l2Score maps a squared L2 distance onto (0, 1], identical vectors score 1.
+func l2Score(distance float32) float32 {
+	return 1 / (1 + max(distance, 0))
+}
+
+// normalized returns a unit length copy of vector, the zero vector is returned as is.
+func normalized(vector []float32) []float32 {
+	var sum float64
+	for _, value := range vector {
+		sum += float64(value) * float64(value)
+	}
+	if sum == 0 {
+		return vector
+	}
+	norm := float32(math.Sqrt(sum))
+	unit := make([]float32, len(vector))
+	for i, value := range vector {
+		unit[i] = value / norm
+	}
+	return unit
+}
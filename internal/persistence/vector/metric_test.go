@@ -0,0 +1,118 @@
+package vector
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/haren7/minimal-memory/internal/embedding/embeddingtest"
+	"github.com/haren7/minimal-memory/internal/persistence"
+
+	"github.com/google/uuid"
+)
+
+// unnormalized scales the test vectors so that only a normalizing backend
+// reports their cosine similarity.
+var unnormalized = map[string][]float32{
+	"query":    {3, 0, 0, 0},
+	"nearest":  {9, 1, 0, 0},
+	"middle":   {0.6, 0.8, 0, 0},
+	"farthest": {0, 0, -2, 2},
+}
+
+func TestCosineScoresAgreeAcrossBackends(t *testing.T) {
+	embeddingService := embeddingtest.NewFakeService(unnormalized)
+	backends := append(faissTestBackends(embeddingService, MetricCosine),
+		testBackend{name: "bruteforce", newRepo: func(t *testing.T) (persistence.VectorMemoryRepoInterface, vectorStore) {
+			return newTestBruteForceMemoryRepo(t, MetricCosine, embeddingService)
+		}},
+		chromemTestBackend(embeddingService, MetricCosine),
+	)
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo, _ := backend.newRepo(t)
+			conversationID := uuid.New()
+			err := indexMany(ctx, repo, conversationID)
+			if err != nil {
+				t.Fatalf("IndexMany: %v", err)
+			}
+			results, err := repo.Search(ctx, conversationID, "query", 10)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if len(results) != len(storeOrder) {
+				t.Fatalf("Search returned %d results, want %d", len(results), len(storeOrder))
+			}
+			for _, result := range results {
+				want := cosine(unnormalized["query"], unnormalized[result.Query])
+				if math.Abs(float64(result.Score-want)) > 1e-5 {
+					t.Errorf("%s scored %v, want cosine %v", result.Query, result.Score, want)
+				}
+				if result.Score < -1 || result.Score > 1 {
+					t.Errorf("%s scored %v, outside [-1, 1]", result.Query, result.Score)
+				}
+				if math.Abs(float64(result.Distance-(1-result.Score))) > 1e-6 {
+					t.Errorf("%s has distance %v for score %v, want 1 - score", result.Query, result.Distance, result.Score)
+				}
+			}
+		})
+	}
+}
+
+func cosine(a []float32, b []float32) float32 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	return float32(dot / math.Sqrt(normA*normB))
+}
+
+// opposed puts farthest on the other side of the query, only a cosine score
+// below zero ranks it last on every backend.
+var opposed = map[string][]float32{
+	"query":    {1, 0, 0, 0},
+	"nearest":  {2, 1, 0, 0},
+	"middle":   {0, 3, 0, 0},
+	"farthest": {-1, -0.2, 0, 0},
+}
+
+func TestCosineRanksOpposedLastAcrossBackends(t *testing.T) {
+	embeddingService := embeddingtest.NewFakeService(opposed)
+	backends := append(faissTestBackends(embeddingService, MetricCosine),
+		testBackend{name: "bruteforce", newRepo: func(t *testing.T) (persistence.VectorMemoryRepoInterface, vectorStore) {
+			return newTestBruteForceMemoryRepo(t, MetricCosine, embeddingService)
+		}},
+		chromemTestBackend(embeddingService, MetricCosine),
+	)
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo, _ := backend.newRepo(t)
+			conversationID := uuid.New()
+			err := indexMany(ctx, repo, conversationID)
+			if err != nil {
+				t.Fatalf("IndexMany: %v", err)
+			}
+			results, err := repo.Search(ctx, conversationID, "query", 10)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if !slices.Equal(queriesOf(results), []string{"nearest", "middle", "farthest"}) {
+				t.Fatalf("Search ranked %v", queriesOf(results))
+			}
+			for _, result := range results {
+				want := cosine(opposed["query"], opposed[result.Query])
+				if math.Abs(float64(result.Score-want)) > 1e-5 {
+					t.Errorf("%s scored %v, want cosine %v", result.Query, result.Score, want)
+				}
+			}
+			if results[2].Score >= 0 {
+				t.Errorf("farthest scored %v, want a negative cosine", results[2].Score)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package vector
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/haren7/minimal-memory/internal/embedding"
+	"github.com/haren7/minimal-memory/internal/persistence"
+
+	"github.com/google/uuid"
+)
+
+// replaceFunc swaps the index of a conversation for one holding exactly ids and
+// embeddings, ids[i] is the id of embeddings[i].
+type replaceFunc func(ctx context.Context, conversationID string, ids []int, embeddings []embedding.Embedding) error
+
+// reindex rebuilds the index of each conversation from its rows in
+// rdbmsMemoryRepo, nil conversationIDs rebuilds every conversation that has rows.
+// Conversations are rebuilt one at a time so only one of them is held in memory,
+// the results of the conversations rebuilt before a failure are returned with it.
+// Memories inserted while a conversation is rebuilt can be missed by its index.
+func reindex(ctx context.Context, embeddingService embedding.ServiceInterface, rdbmsMemoryRepo persistence.MemoryRepoInterface, conversationIDs []uuid.UUID, replace replaceFunc) ([]persistence.ReindexResult, error) {
+	if conversationIDs == nil {
+		var err error
+		conversationIDs, err = rdbmsMemoryRepo.FetchConversationIDs(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	results := make([]persistence.ReindexResult, 0, len(conversationIDs))
+	for _, conversationID := range conversationIDs {
+		ids, embeddings, err := embedConversation(ctx, embeddingService, rdbmsMemoryRepo, conversationID)
+		if err != nil {
+			return results, fmt.Errorf("error embedding memories of conversation id %s, %w", conversationID, err)
+		}
+		err = replace(ctx, conversationID.String(), ids, embeddings)
+		if err != nil {
+			return results, fmt.Errorf("error replacing index of conversation id %s, %w", conversationID, err)
+		}
+		results = append(results, persistence.ReindexResult{ConversationID: conversationID, Memories: len(ids)})
+	}
+	return results, nil
+}
+
+// embedConversation returns the embeddings of every memory a conversation has
+// in rdbmsMemoryRepo, ids[i] is the row id of embeddings[i]. Embeddings stored
+// with the model of embeddingService are reused, only the others are embedded
+// and then stored so the next rebuild needs no provider call.
+func embedConversation(ctx context.Context, embeddingService embedding.ServiceInterface, rdbmsMemoryRepo persistence.MemoryRepoInterface, conversationID uuid.UUID) ([]int, []embedding.Embedding, error) {
+	rows, err := rdbmsMemoryRepo.FetchEmbeddings(ctx, conversationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	model := embeddingService.Model()
+	ids := make([]int, len(rows))
+	embeddings := make([]embedding.Embedding, len(rows))
+	var missing []int
+	var queries []string
+	for i, row := range rows {
+		ids[i] = row.ID
+		if row.EmbeddingModel != model || len(row.Embedding) == 0 {
+			missing = append(missing, i)
+			queries = append(queries, row.Query)
+			continue
+		}
+		embeddings[i] = embedding.Embedding{Model: row.EmbeddingModel, Dim: len(row.Embedding), Vector: row.Embedding}
+	}
+	if len(missing) == 0 {
+		return ids, embeddings, nil
+	}
+	embedded, err := embedInBatches(ctx, embeddingService, queries)
+	if err != nil {
+		return nil, nil, err
+	}
+	updated := make([]persistence.Memory, len(missing))
+	for j, i := range missing {
+		embeddings[i] = embedded[j]
+		updated[j] = persistence.Memory{ID: ids[i], Embedding: embedded[j].Vector, EmbeddingModel: embedded[j].Model}
+	}
+	// the index can be rebuilt without them, a failed update only costs another provider call next time
+	err = rdbmsMemoryRepo.UpdateEmbeddings(ctx, updated)
+	if err != nil {
+		log.Printf("[ERROR] embedConversation: Failed to store embeddings (conversationID: %s, count: %d) - %v", conversationID, len(updated), err)
+	}
+	return ids, embeddings, nil
+}
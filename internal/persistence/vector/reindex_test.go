@@ -0,0 +1,127 @@
+package vector
+
+import (
+	"context"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/haren7/minimal-memory/internal/embedding/embeddingtest"
+	"github.com/haren7/minimal-memory/internal/persistence"
+	"github.com/haren7/minimal-memory/internal/persistence/rdbms"
+	"github.com/haren7/minimal-memory/internal/persistence/rdbms/rdbmstest"
+
+	"github.com/google/uuid"
+)
+
+// vectorStore is the part of the vector clients the reindex tests need.
+type vectorStore interface {
+	Mount(dir string, files map[string]io.Reader) error
+	IndexSizes() map[string]int64
+}
+
+func TestReindexRestoresLostIndexes(t *testing.T) {
+	embeddingService := embeddingtest.NewFakeService(vectors)
+	tests := append(faissTestBackends(embeddingService, MetricL2),
+		testBackend{name: "bruteforce", newRepo: func(t *testing.T) (persistence.VectorMemoryRepoInterface, vectorStore) {
+			return newTestBruteForceMemoryRepo(t, MetricL2, embeddingService)
+		}},
+		chromemTestBackend(embeddingService, MetricL2),
+	)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo, store := test.newRepo(t)
+			conversationIDs := []uuid.UUID{uuid.New(), uuid.New()}
+			for _, conversationID := range conversationIDs {
+				err := indexMany(ctx, repo, conversationID)
+				if err != nil {
+					t.Fatalf("IndexMany: %v", err)
+				}
+			}
+			want, err := repo.Search(ctx, conversationIDs[0], "query", 10)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			// mounting nothing drops every index, as if the files were lost
+			err = store.Mount(t.TempDir(), nil)
+			if err != nil {
+				t.Fatalf("Mount: %v", err)
+			}
+
+			results, err := repo.Reindex(ctx, conversationIDs[:1])
+			if err != nil {
+				t.Fatalf("Reindex: %v", err)
+			}
+			if len(results) != 1 || results[0] != (persistence.ReindexResult{ConversationID: conversationIDs[0], Memories: len(storeOrder)}) {
+				t.Fatalf("Reindex of one conversation returned %+v", results)
+			}
+			if sizes := store.IndexSizes(); len(sizes) != 1 {
+				t.Fatalf("Reindex of one conversation rebuilt %d indexes", len(sizes))
+			}
+			got, err := repo.Search(ctx, conversationIDs[0], "query", 10)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if !slices.Equal(queriesOf(got), queriesOf(want)) {
+				t.Fatalf("reindexed search ranked %v, want %v", queriesOf(got), queriesOf(want))
+			}
+
+			results, err = repo.Reindex(ctx, nil)
+			if err != nil {
+				t.Fatalf("Reindex: %v", err)
+			}
+			if len(results) != len(conversationIDs) {
+				t.Fatalf("Reindex of every conversation returned %+v", results)
+			}
+			for conversationID, size := range store.IndexSizes() {
+				if size != int64(len(storeOrder)) {
+					t.Errorf("index of conversation %s holds %d vectors, want %d", conversationID, size, len(storeOrder))
+				}
+			}
+		})
+	}
+}
+
+func TestReindexReusesStoredEmbeddings(t *testing.T) {
+	ctx := context.Background()
+	duckdbClient := rdbmstest.NewDuckDBClient(t)
+	rdbmsMemoryRepo := rdbms.NewFaissMemoryRepo(duckdbClient)
+	bruteForceClient, err := NewBruteForceClient(MetricL2, "")
+	if err != nil {
+		t.Fatalf("NewBruteForceClient: %v", err)
+	}
+	repo := NewBruteForceMemoryRepo(bruteForceClient, embeddingtest.NewFakeService(vectors), rdbmsMemoryRepo)
+	conversationID := uuid.New()
+	err = indexMany(ctx, repo, conversationID)
+	if err != nil {
+		t.Fatalf("IndexMany: %v", err)
+	}
+	// a memory stored before embeddings were kept is embedded once and then kept
+	_, err = rdbmsMemoryRepo.InsertMany(ctx, []persistence.Memory{{UUID: uuid.New(), ConversationID: conversationID, Query: "query", Response: "ok", CreatedAt: time.Now()}})
+	if err != nil {
+		t.Fatalf("InsertMany: %v", err)
+	}
+	_, err = repo.Reindex(ctx, nil)
+	if err != nil {
+		t.Fatalf("Reindex: %v", err)
+	}
+
+	// the fake knows no text, so any provider call fails the reindex
+	offline := NewBruteForceMemoryRepo(bruteForceClient, embeddingtest.NewFakeService(map[string][]float32{"unrelated": {0, 0, 0, 1}}), rdbmsMemoryRepo)
+	results, err := offline.Reindex(ctx, nil)
+	if err != nil {
+		t.Fatalf("Reindex without the provider: %v", err)
+	}
+	if len(results) != 1 || results[0].Memories != len(storeOrder)+1 {
+		t.Fatalf("Reindex returned %+v, want %d memories", results, len(storeOrder)+1)
+	}
+	got, err := repo.Search(ctx, conversationID, "query", 2)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if !slices.Equal(queriesOf(got), []string{"query", "nearest"}) {
+		t.Fatalf("Search ranked %v after an offline reindex", queriesOf(got))
+	}
+}
@@ -0,0 +1,75 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/haren7/minimal-memory/internal/blobstore"
+	"github.com/haren7/minimal-memory/internal/persistence/vector"
+
+	"github.com/google/uuid"
+)
+
+type bruteForceManager struct {
+	dir              string
+	bucket           string
+	s3               blobstore.BlobStoreInterface
+	bruteForceClient *vector.BruteForceClient
+}
+
+func NewBruteForceManager(bucket string, s3 blobstore.BlobStoreInterface, bruteForceClient *vector.BruteForceClient) Manager {
+	return &bruteForceManager{
+		dir:              bruteForceClient.GetNamespace().Dir("bruteforce"),
+		bucket:           bucket,
+		s3:               s3,
+		bruteForceClient: bruteForceClient,
+	}
+}
+
+func (r *bruteForceManager) Store(ctx context.Context) error {
+	err := os.MkdirAll(r.dir, 0755)
+	if err != nil {
+		return fmt.Errorf("snapshot: error creating bruteforce dir: %w", err)
+	}
+	files, err := r.bruteForceClient.Export(r.dir)
+	if err != nil {
+		return fmt.Errorf("snapshot: error exporting bruteforce: %w", err)
+	}
+	defer closeFiles(files)
+	err = r.s3.Store(ctx, r.bucket, r.dir, files)
+	if err != nil {
+		return fmt.Errorf("snapshot: error storing bruteforce: %w", err)
+	}
+	return nil
+}
+
+func (r *bruteForceManager) Load(ctx context.Context) error {
+	err := os.MkdirAll(r.dir, 0755)
+	if err != nil {
+		return fmt.Errorf("snapshot: error creating bruteforce dir: %w", err)
+	}
+	files, err := r.s3.Retrieve(ctx, r.bucket, r.dir)
+	if err != nil {
+		return fmt.Errorf("snapshot: error retrieving bruteforce: %w", err)
+	}
+	err = r.bruteForceClient.Mount(r.dir, files)
+	if err != nil {
+		return fmt.Errorf("snapshot: error mounting bruteforce: %w", err)
+	}
+	return nil
+}
+
+// Erase stores the remaining indexes before deleting the erased ones, the user
+// and agent indexes lost the memories the erased conversations shared.
+func (r *bruteForceManager) Erase(ctx context.Context, conversationIDs []uuid.UUID) error {
+	err := r.Store(ctx)
+	if err != nil {
+		return fmt.Errorf("snapshot: error erasing bruteforce: %w", err)
+	}
+	err = eraseFiles(ctx, r.s3, r.bucket, r.dir, conversationIDs, r.bruteForceClient.FileNames)
+	if err != nil {
+		return fmt.Errorf("snapshot: error erasing bruteforce: %w", err)
+	}
+	return nil
+}
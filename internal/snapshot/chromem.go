@@ -0,0 +1,75 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/haren7/minimal-memory/internal/blobstore"
+	"github.com/haren7/minimal-memory/internal/persistence/vector"
+
+	"github.com/google/uuid"
+)
+
+type chromemManager struct {
+	dir           string
+	bucket        string
+	s3            blobstore.BlobStoreInterface
+	chromemClient *vector.ChromemClient
+}
+
+func NewChromemManager(bucket string, s3 blobstore.BlobStoreInterface, chromemClient *vector.ChromemClient) Manager {
+	return &chromemManager{
+		dir:           chromemClient.GetNamespace().Dir("chromem"),
+		bucket:        bucket,
+		s3:            s3,
+		chromemClient: chromemClient,
+	}
+}
+
+func (r *chromemManager) Store(ctx context.Context) error {
+	err := os.MkdirAll(r.dir, 0755)
+	if err != nil {
+		return fmt.Errorf("snapshot: error creating chromem dir: %w", err)
+	}
+	files, err := r.chromemClient.Export(r.dir)
+	if err != nil {
+		return fmt.Errorf("snapshot: error exporting chromem: %w", err)
+	}
+	defer closeFiles(files)
+	err = r.s3.Store(ctx, r.bucket, r.dir, files)
+	if err != nil {
+		return fmt.Errorf("snapshot: error storing chromem: %w", err)
+	}
+	return nil
+}
+
+func (r *chromemManager) Load(ctx context.Context) error {
+	err := os.MkdirAll(r.dir, 0755)
+	if err != nil {
+		return fmt.Errorf("snapshot: error creating chromem dir: %w", err)
+	}
+	files, err := r.s3.Retrieve(ctx, r.bucket, r.dir)
+	if err != nil {
+		return fmt.Errorf("snapshot: error retrieving chromem: %w", err)
+	}
+	err = r.chromemClient.Mount(r.dir, files)
+	if err != nil {
+		return fmt.Errorf("snapshot: error mounting chromem: %w", err)
+	}
+	return nil
+}
+
+// Erase stores the remaining indexes before deleting the erased ones, the user
+// and agent indexes lost the memories the erased conversations shared.
+func (r *chromemManager) Erase(ctx context.Context, conversationIDs []uuid.UUID) error {
+	err := r.Store(ctx)
+	if err != nil {
+		return fmt.Errorf("snapshot: error erasing chromem: %w", err)
+	}
+	err = eraseFiles(ctx, r.s3, r.bucket, r.dir, conversationIDs, r.chromemClient.FileNames)
+	if err != nil {
+		return fmt.Errorf("snapshot: error erasing chromem: %w", err)
+	}
+	return nil
+}
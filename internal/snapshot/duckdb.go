@@ -2,10 +2,15 @@ package snapshot
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 
 	"github.com/haren7/minimal-memory/internal/blobstore"
+	"github.com/haren7/minimal-memory/internal/persistence"
 	"github.com/haren7/minimal-memory/internal/persistence/rdbms"
+
+	"github.com/google/uuid"
 )
 
 type duckdbManager struct {
@@ -17,7 +22,7 @@ type duckdbManager struct {
 
 func NewDuckdbManager(bucket string, s3 blobstore.BlobStoreInterface, duckdbClient *rdbms.DuckDBClient) Manager {
 	return &duckdbManager{
-		dir:          "/duckdb",
+		dir:          duckdbClient.GetNamespace().Dir("duckdb"),
 		bucket:       bucket,
 		s3:           s3,
 		duckdbClient: duckdbClient,
@@ -25,10 +30,15 @@ func NewDuckdbManager(bucket string, s3 blobstore.BlobStoreInterface, duckdbClie
 }
 
 func (r *duckdbManager) Store(ctx context.Context) error {
+	err := os.MkdirAll(r.dir, 0755)
+	if err != nil {
+		return fmt.Errorf("snapshot: error creating duckdb dir: %w", err)
+	}
 	files, err := r.duckdbClient.Export(r.dir)
 	if err != nil {
 		return fmt.Errorf("snapshot: error exporting duckdb: %w", err)
 	}
+	defer closeFiles(files)
 	err = r.s3.Store(ctx, r.bucket, r.dir, files)
 	if err != nil {
 		return fmt.Errorf("snapshot: error storing duckdb: %w", err)
@@ -37,6 +47,10 @@ func (r *duckdbManager) Store(ctx context.Context) error {
 }
 
 func (r *duckdbManager) Load(ctx context.Context) error {
+	err := os.MkdirAll(r.dir, 0755)
+	if err != nil {
+		return fmt.Errorf("snapshot: error creating duckdb dir: %w", err)
+	}
 	files, err := r.s3.Retrieve(ctx, r.bucket, r.dir)
 	if err != nil {
 		return fmt.Errorf("snapshot: error retrieving duckdb: %w", err)
@@ -47,3 +61,77 @@ func (r *duckdbManager) Load(ctx context.Context) error {
 	}
 	return nil
 }
+
+// Erase deletes the erased conversations from the stored tables and uploads
+// them again. The stored snapshot is rewritten rather than the local database
+// stored, the local one may never have pulled the other users.
+func (r *duckdbManager) Erase(ctx context.Context, conversationIDs []uuid.UUID) error {
+	err := r.withStored(ctx, func(stored *rdbms.DuckDBClient, dir string) error {
+		err := stored.EraseConversations(ctx, conversationIDs)
+		if err != nil {
+			return err
+		}
+		files, err := stored.Export(dir)
+		if err != nil {
+			return err
+		}
+		defer closeFiles(files)
+		return r.s3.Store(ctx, r.bucket, r.dir, files)
+	})
+	if err != nil {
+		return fmt.Errorf("snapshot: error erasing duckdb: %w", err)
+	}
+	return nil
+}
+
+// UnpulledConversations returns the conversations of the stored snapshot that
+// the local database of duckdbClient does not hold. Erasing a user while some
+// are missing would store vector snapshots built from partial indexes.
+func UnpulledConversations(ctx context.Context, bucket string, s3 blobstore.BlobStoreInterface, duckdbClient *rdbms.DuckDBClient) ([]uuid.UUID, error) {
+	manager := &duckdbManager{dir: duckdbClient.GetNamespace().Dir("duckdb"), bucket: bucket, s3: s3, duckdbClient: duckdbClient}
+	conversationRepo := rdbms.NewConversationRepo(duckdbClient)
+	var unpulled []uuid.UUID
+	err := manager.withStored(ctx, func(stored *rdbms.DuckDBClient, dir string) error {
+		conversationIDs, err := stored.ConversationIDs(ctx)
+		if err != nil {
+			return err
+		}
+		for _, conversationID := range conversationIDs {
+			_, err := conversationRepo.FetchOne(ctx, conversationID)
+			if errors.Is(err, persistence.ErrNotFound) {
+				unpulled = append(unpulled, conversationID)
+			} else if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: error comparing duckdb with the stored snapshot: %w", err)
+	}
+	return unpulled, nil
+}
+
+// withStored mounts the stored snapshot into a scratch in-memory database,
+// leaving the local one untouched. An empty bucket mounts empty tables.
+func (r *duckdbManager) withStored(ctx context.Context, fn func(stored *rdbms.DuckDBClient, dir string) error) error {
+	dir, err := os.MkdirTemp("", "duckdb-snapshot")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	files, err := r.s3.Retrieve(ctx, r.bucket, r.dir)
+	if err != nil {
+		return err
+	}
+	stored, err := rdbms.NewDuckDBClient("")
+	if err != nil {
+		return err
+	}
+	defer stored.GetDB().Close()
+	err = stored.Mount(dir, files)
+	if err != nil {
+		return err
+	}
+	return fn(stored, dir)
+}
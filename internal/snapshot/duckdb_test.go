@@ -0,0 +1,111 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haren7/minimal-memory/internal/persistence"
+	"github.com/haren7/minimal-memory/internal/persistence/rdbms"
+	"github.com/haren7/minimal-memory/internal/persistence/rdbms/rdbmstest"
+
+	"github.com/google/uuid"
+)
+
+type memoryBlobStore struct {
+	objects map[string][]byte
+}
+
+func (r *memoryBlobStore) Store(ctx context.Context, bucket string, prefix string, files []os.File) error {
+	for i := range files {
+		content, err := os.ReadFile(files[i].Name())
+		if err != nil {
+			return err
+		}
+		r.objects[path.Join(prefix, filepath.Base(files[i].Name()))] = content
+	}
+	return nil
+}
+
+func (r *memoryBlobStore) Retrieve(ctx context.Context, bucket string, prefix string) (map[string]io.Reader, error) {
+	files := make(map[string]io.Reader)
+	for key, content := range r.objects {
+		if strings.HasPrefix(key, prefix+"/") {
+			files[strings.TrimPrefix(key, prefix+"/")] = bytes.NewReader(content)
+		}
+	}
+	return files, nil
+}
+
+func (r *memoryBlobStore) Delete(ctx context.Context, bucket string, prefix string, names []string) error {
+	for _, name := range names {
+		delete(r.objects, path.Join(prefix, name))
+	}
+	return nil
+}
+
+func newTestDuckDB(t *testing.T, conversations map[uuid.UUID]string) *rdbms.DuckDBClient {
+	ctx := context.Background()
+	duckdbClient := rdbmstest.NewDuckDBClient(t)
+	for conversationID, user := range conversations {
+		_, err := rdbms.NewConversationRepo(duckdbClient).InsertOne(ctx, "agent", user, conversationID, time.Now())
+		if err != nil {
+			t.Fatalf("InsertOne: %v", err)
+		}
+		_, err = rdbms.NewMemoryRepo(duckdbClient).InsertMany(ctx, []persistence.Memory{{UUID: uuid.New(), ConversationID: conversationID, Query: "q", Response: "r", CreatedAt: time.Now()}})
+		if err != nil {
+			t.Fatalf("InsertMany: %v", err)
+		}
+	}
+	return duckdbClient
+}
+
+func TestDuckdbManagerEraseKeepsUnpulledConversations(t *testing.T) {
+	ctx := context.Background()
+	alice, bob := uuid.New(), uuid.New()
+	s3 := &memoryBlobStore{objects: make(map[string][]byte)}
+	err := NewDuckdbManager("bucket", s3, newTestDuckDB(t, map[uuid.UUID]string{alice: "alice", bob: "bob"})).Store(ctx)
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	// a database that never pulled bob
+	local := newTestDuckDB(t, map[uuid.UUID]string{alice: "alice"})
+	unpulled, err := UnpulledConversations(ctx, "bucket", s3, local)
+	if err != nil {
+		t.Fatalf("UnpulledConversations: %v", err)
+	}
+	if len(unpulled) != 1 || unpulled[0] != bob {
+		t.Fatalf("UnpulledConversations = %v, want [%s]", unpulled, bob)
+	}
+	err = NewDuckdbManager("bucket", s3, local).Erase(ctx, []uuid.UUID{alice})
+	if err != nil {
+		t.Fatalf("Erase: %v", err)
+	}
+
+	pulled := newTestDuckDB(t, nil)
+	err = NewDuckdbManager("bucket", s3, pulled).Load(ctx)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	conversationIDs, err := pulled.ConversationIDs(ctx)
+	if err != nil {
+		t.Fatalf("ConversationIDs: %v", err)
+	}
+	if len(conversationIDs) != 1 || conversationIDs[0] != bob {
+		t.Fatalf("stored conversations = %v, want [%s]", conversationIDs, bob)
+	}
+	memories, err := rdbms.NewMemoryRepo(pulled).Count(ctx)
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if memories != 1 {
+		t.Fatalf("stored memories = %d, want 1", memories)
+	}
+}
@@ -1,11 +1,16 @@
+//go:build cgo && !nofaiss
+
 package snapshot
 
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/haren7/minimal-memory/internal/blobstore"
 	"github.com/haren7/minimal-memory/internal/persistence/vector"
+
+	"github.com/google/uuid"
 )
 
 type faissManager struct {
@@ -17,7 +22,7 @@ type faissManager struct {
 
 func NewFaissManager(bucket string, s3 blobstore.BlobStoreInterface, faissClient *vector.FaissClient) Manager {
 	return &faissManager{
-		dir:         "/faiss",
+		dir:         faissClient.GetNamespace().Dir("faiss"),
 		bucket:      bucket,
 		s3:          s3,
 		faissClient: faissClient,
@@ -25,10 +30,15 @@ func NewFaissManager(bucket string, s3 blobstore.BlobStoreInterface, faissClient
 }
 
 func (r *faissManager) Store(ctx context.Context) error {
+	err := os.MkdirAll(r.dir, 0755)
+	if err != nil {
+		return fmt.Errorf("snapshot: error creating faiss dir: %w", err)
+	}
 	files, err := r.faissClient.Export(r.dir)
 	if err != nil {
 		return fmt.Errorf("snapshot: error exporting faiss: %w", err)
 	}
+	defer closeFiles(files)
 	err = r.s3.Store(ctx, r.bucket, r.dir, files)
 	if err != nil {
 		return fmt.Errorf("snapshot: error storing faiss: %w", err)
@@ -37,6 +47,10 @@ func (r *faissManager) Store(ctx context.Context) error {
 }
 
 func (r *faissManager) Load(ctx context.Context) error {
+	err := os.MkdirAll(r.dir, 0755)
+	if err != nil {
+		return fmt.Errorf("snapshot: error creating faiss dir: %w", err)
+	}
 	files, err := r.s3.Retrieve(ctx, r.bucket, r.dir)
 	if err != nil {
 		return fmt.Errorf("snapshot: error retrieving faiss: %w", err)
@@ -47,3 +61,17 @@ func (r *faissManager) Load(ctx context.Context) error {
 	}
 	return nil
 }
+
+// Erase stores the remaining indexes before deleting the erased ones, the user
+// and agent indexes lost the memories the erased conversations shared.
+func (r *faissManager) Erase(ctx context.Context, conversationIDs []uuid.UUID) error {
+	err := r.Store(ctx)
+	if err != nil {
+		return fmt.Errorf("snapshot: error erasing faiss: %w", err)
+	}
+	err = eraseFiles(ctx, r.s3, r.bucket, r.dir, conversationIDs, r.faissClient.FileNames)
+	if err != nil {
+		return fmt.Errorf("snapshot: error erasing faiss: %w", err)
+	}
+	return nil
+}
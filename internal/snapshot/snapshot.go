@@ -2,9 +2,49 @@ package snapshot
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/haren7/minimal-memory/internal/blobstore"
+
+	"github.com/google/uuid"
 )
 
+// Manager snapshots one backend of one namespace, the dir of the client's
+// namespace is both the local staging dir and the key prefix in the bucket, so
+// namespaces never store or load each other's files.
 type Manager interface {
 	Store(ctx context.Context) error
 	Load(ctx context.Context) error
+	// Erase removes the erased conversations from the stored snapshot, it is
+	// called after their data was deleted locally.
+	Erase(ctx context.Context, conversationIDs []uuid.UUID) error
+}
+
+func closeFiles(files []os.File) {
+	for i := range files {
+		files[i].Close()
+	}
+}
+
+// eraseFiles deletes the files fileNames returns for each conversation from
+// the local dir and from the blob store.
+func eraseFiles(ctx context.Context, s3 blobstore.BlobStoreInterface, bucket, dir string, conversationIDs []uuid.UUID, fileNames func(conversationID string) []string) error {
+	var names []string
+	for _, conversationID := range conversationIDs {
+		names = append(names, fileNames(conversationID.String())...)
+	}
+	for _, name := range names {
+		err := os.Remove(filepath.Join(dir, name))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("snapshot: error removing %s: %w", name, err)
+		}
+	}
+	err := s3.Delete(ctx, bucket, dir, names)
+	if err != nil {
+		return fmt.Errorf("snapshot: error deleting erased files: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,179 @@
+package tokenizer
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// BPE encodes text like tiktoken's encode_ordinary, special tokens such as
+// <|endoftext|> are encoded as plain text.
+type BPE struct {
+	splitter *splitter
+	ranks    map[string]int
+	decoder  map[int]string
+}
+
+// LoadBPE reads a tiktoken ranks file, one base64 token and its rank per line.
+func LoadBPE(reader io.Reader, encoding Encoding) (*BPE, error) {
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("tokenizer: error parsing ranks line %d", line)
+		}
+		token, err := base64.StdEncoding.DecodeString(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("tokenizer: error decoding token on ranks line %d, %w", line, err)
+		}
+		rank, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("tokenizer: error parsing rank on ranks line %d, %w", line, err)
+		}
+		ranks[string(token)] = rank
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("tokenizer: error reading ranks, %w", err)
+	}
+	return NewBPE(ranks, encoding)
+}
+
+// NewBPE needs a rank for every single byte so that any text can be encoded.
+func NewBPE(ranks map[string]int, encoding Encoding) (*BPE, error) {
+	splitter, err := newSplitter(encoding)
+	if err != nil {
+		return nil, err
+	}
+	for b := range 256 {
+		if _, ok := ranks[string([]byte{byte(b)})]; !ok {
+			return nil, fmt.Errorf("tokenizer: ranks have no token for byte %d", b)
+		}
+	}
+	decoder := make(map[int]string, len(ranks))
+	for token, rank := range ranks {
+		decoder[rank] = token
+	}
+	return &BPE{splitter: splitter, ranks: ranks, decoder: decoder}, nil
+}
+
+func (r *BPE) Encode(text string) []int {
+	var tokens []int
+	for _, piece := range r.splitter.split(text) {
+		tokens = r.encodePiece(piece, tokens)
+	}
+	return tokens
+}
+
+// Decode skips unknown tokens, the result is not valid UTF-8 when tokens end
+// inside a character.
+func (r *BPE) Decode(tokens []int) string {
+	var builder strings.Builder
+	for _, token := range tokens {
+		builder.WriteString(r.decoder[token])
+	}
+	return builder.String()
+}
+
+func (r *BPE) Count(text string) int {
+	return len(r.Encode(text))
+}
+
+func (r *BPE) Truncate(text string, maxTokens int) string {
+	tokens := r.Encode(text)
+	if len(tokens) <= maxTokens {
+		return text
+	}
+	return trimPartialRune(r.Decode(tokens[:max(maxTokens, 0)]))
+}
+
+// encodePiece merges the adjacent parts whose joined bytes have the lowest
+// rank until no pair has one, the first pair wins ties as in tiktoken. Pairs
+// wait in a heap, so a long piece takes O(n log n) rather than a rescan of the
+// whole piece per merge.
+func (r *BPE) encodePiece(piece string, tokens []int) []int {
+	if rank, ok := r.ranks[piece]; ok {
+		return append(tokens, rank)
+	}
+	// the part starting at byte i ends at ends[i] and follows the part
+	// starting at starts[i], merged parts are not read again
+	ends := make([]int, len(piece))
+	starts := make([]int, len(piece))
+	for i := range len(piece) {
+		ends[i] = i + 1
+		starts[i] = i - 1
+	}
+	pairs := &mergePairs{}
+	push := func(left int) {
+		if left < 0 || ends[left] >= len(piece) {
+			return
+		}
+		right := ends[left]
+		if rank, ok := r.ranks[piece[left:ends[right]]]; ok {
+			heap.Push(pairs, mergePair{rank: rank, left: left, right: right, end: ends[right]})
+		}
+	}
+	for i := range len(piece) {
+		push(i)
+	}
+	for pairs.Len() > 0 {
+		pair := heap.Pop(pairs).(mergePair)
+		// a pair is stale once either of its parts was merged with another
+		if ends[pair.left] != pair.right || ends[pair.right] != pair.end {
+			continue
+		}
+		ends[pair.left] = pair.end
+		ends[pair.right] = -1
+		if pair.end < len(piece) {
+			starts[pair.end] = pair.left
+		}
+		push(starts[pair.left])
+		push(pair.left)
+	}
+	for i := 0; i < len(piece); i = ends[i] {
+		tokens = append(tokens, r.ranks[piece[i:ends[i]]])
+	}
+	return tokens
+}
+
+type mergePair struct {
+	rank  int
+	left  int
+	right int
+	end   int
+}
+
+// mergePairs is a heap with the lowest ranked and then first pair on top.
+type mergePairs []mergePair
+
+func (r mergePairs) Len() int { return len(r) }
+func (r mergePairs) Less(i, j int) bool {
+	return r[i].rank < r[j].rank || r[i].rank == r[j].rank && r[i].left < r[j].left
+}
+func (r mergePairs) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r *mergePairs) Push(x any)   { *r = append(*r, x.(mergePair)) }
+func (r *mergePairs) Pop() any {
+	last := (*r)[len(*r)-1]
+	*r = (*r)[:len(*r)-1]
+	return last
+}
+
+// trimPartialRune drops the bytes of a character cut off at the end of text.
+func trimPartialRune(text string) string {
+	for len(text) > 0 {
+		char, size := utf8.DecodeLastRuneInString(text)
+		if char != utf8.RuneError || size != 1 {
+			break
+		}
+		text = text[:len(text)-1]
+	}
+	return text
+}
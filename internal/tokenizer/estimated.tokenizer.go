@@ -0,0 +1,47 @@
+package tokenizer
+
+// estimatedBytesPerToken is the share of a piece one token is assumed to
+// cover. BPE tokens of English text average about four bytes, and most pieces
+// encode to fewer tokens than this, so counts err on the high side and a budget
+// is not overrun.
+const estimatedBytesPerToken = 4
+
+// Estimated counts tokens without the ranks of an encoding. It splits text
+// into the pieces of the encoding and counts one token per started four bytes
+// of each piece.
+type Estimated struct {
+	splitter *splitter
+}
+
+func NewEstimated(encoding Encoding) (*Estimated, error) {
+	splitter, err := newSplitter(encoding)
+	if err != nil {
+		return nil, err
+	}
+	return &Estimated{splitter: splitter}, nil
+}
+
+func (r *Estimated) Count(text string) int {
+	count := 0
+	for _, piece := range r.splitter.split(text) {
+		count += estimatePiece(piece)
+	}
+	return count
+}
+
+func (r *Estimated) Truncate(text string, maxTokens int) string {
+	end := 0
+	for _, piece := range r.splitter.split(text) {
+		tokens := estimatePiece(piece)
+		if tokens > maxTokens {
+			return trimPartialRune(text[:end+max(maxTokens, 0)*estimatedBytesPerToken])
+		}
+		maxTokens -= tokens
+		end += len(piece)
+	}
+	return text
+}
+
+func estimatePiece(piece string) int {
+	return (len(piece) + estimatedBytesPerToken - 1) / estimatedBytesPerToken
+}
@@ -0,0 +1,75 @@
+package tokenizer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// whitespace is the Unicode White_Space property that \s matches in tiktoken,
+// RE2's \s only covers ASCII.
+const whitespace = `\t\n\v\f\r\x{85}\p{Z}`
+
+// The patterns are tiktoken's with their last two alternatives, \s+(?!\S)|\s+,
+// reduced to \s+. RE2 has no lookahead, splitter.split applies it instead.
+var (
+	cl100kPattern = regexp.MustCompile(strings.ReplaceAll(
+		`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^WS\p{L}\p{N}]+[\r\n]*|[WS]*[\r\n]+|[WS]+`,
+		"WS", whitespace))
+	o200kPattern = regexp.MustCompile(strings.ReplaceAll(
+		`[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?|[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?|\p{N}{1,3}| ?[^WS\p{L}\p{N}]+[\r\n/]*|[WS]*[\r\n]+|[WS]+`,
+		"WS", whitespace))
+)
+
+// splitter cuts text into the pieces byte pair merges run on, no token spans
+// two pieces.
+type splitter struct {
+	pattern *regexp.Regexp
+}
+
+func newSplitter(encoding Encoding) (*splitter, error) {
+	switch encoding {
+	case EncodingCl100k:
+		return &splitter{pattern: cl100kPattern}, nil
+	case EncodingO200k:
+		return &splitter{pattern: o200kPattern}, nil
+	}
+	return nil, fmt.Errorf("tokenizer: unknown encoding %q", encoding)
+}
+
+func (r *splitter) split(text string) []string {
+	var pieces []string
+	for len(text) > 0 {
+		end := 0
+		// every rune matches one of the alternatives, so matches start at 0
+		if loc := r.pattern.FindStringIndex(text); loc != nil && loc[0] == 0 {
+			end = loc[1]
+		}
+		if end == 0 {
+			_, end = utf8.DecodeRuneInString(text)
+		}
+		// \s+(?!\S) leaves the last space of a run to the word that follows it
+		if end < len(text) && isSpaceRun(text[:end]) {
+			_, size := utf8.DecodeLastRuneInString(text[:end])
+			if size < end {
+				end -= size
+			}
+		}
+		pieces = append(pieces, text[:end])
+		text = text[end:]
+	}
+	return pieces
+}
+
+// isSpaceRun reports whether piece was matched by the final \s+, runs holding
+// a line break are matched by \s*[\r\n]+ before it.
+func isSpaceRun(piece string) bool {
+	for _, char := range piece {
+		if !unicode.IsSpace(char) || char == '\r' || char == '\n' {
+			return false
+		}
+	}
+	return true
+}
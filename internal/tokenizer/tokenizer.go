@@ -0,0 +1,85 @@
+package tokenizer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+type Tokenizer interface {
+	// Count returns the number of tokens text encodes to.
+	Count(text string) int
+	// Truncate returns the longest prefix of text that fits in maxTokens tokens.
+	Truncate(text string, maxTokens int) string
+}
+
+// Encoding names a tiktoken encoding, it decides how text is split before the
+// byte pair merges.
+type Encoding string
+
+const (
+	EncodingCl100k Encoding = "cl100k_base"
+	EncodingO200k  Encoding = "o200k_base"
+)
+
+// modelPrefixes maps model name prefixes to their encoding, longer prefixes
+// come first.
+var modelPrefixes = []struct {
+	prefix   string
+	encoding Encoding
+}{
+	{"gpt-4o", EncodingO200k},
+	{"gpt-4.1", EncodingO200k},
+	{"gpt-4.5", EncodingO200k},
+	{"gpt-5", EncodingO200k},
+	{"chatgpt-4o", EncodingO200k},
+	{"o1", EncodingO200k},
+	{"o3", EncodingO200k},
+	{"o4", EncodingO200k},
+	{"gpt-4", EncodingCl100k},
+	{"gpt-3.5-turbo", EncodingCl100k},
+	{"gpt-35-turbo", EncodingCl100k},
+	{"text-embedding-3", EncodingCl100k},
+	{"text-embedding-ada-002", EncodingCl100k},
+}
+
+// EncodingForModel returns the encoding of an OpenAI model, an empty model is
+// EncodingCl100k.
+func EncodingForModel(model string) (Encoding, error) {
+	if model == "" {
+		return EncodingCl100k, nil
+	}
+	for _, entry := range modelPrefixes {
+		if strings.HasPrefix(model, entry.prefix) {
+			return entry.encoding, nil
+		}
+	}
+	return "", fmt.Errorf("tokenizer: unknown model %q", model)
+}
+
+type Config struct {
+	// Model selects the encoding, e.g. gpt-4o uses o200k_base and gpt-4 or the
+	// text-embedding-3 models cl100k_base. Defaults to cl100k_base.
+	Model string
+	// RanksFile is the tiktoken file of the encoding, such as
+	// cl100k_base.tiktoken. Without it token counts are estimated.
+	RanksFile string
+}
+
+// New builds a BPE tokenizer from config.RanksFile, or an estimating one when
+// it is not set.
+func New(config Config) (Tokenizer, error) {
+	encoding, err := EncodingForModel(config.Model)
+	if err != nil {
+		return nil, err
+	}
+	if config.RanksFile == "" {
+		return NewEstimated(encoding)
+	}
+	file, err := os.Open(config.RanksFile)
+	if err != nil {
+		return nil, fmt.Errorf("tokenizer: error opening ranks file, %w", err)
+	}
+	defer file.Close()
+	return LoadBPE(file, encoding)
+}
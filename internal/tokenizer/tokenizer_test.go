@@ -0,0 +1,293 @@
+package tokenizer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		encoding Encoding
+		text     string
+		expect   []string
+	}{
+		{encoding: EncodingCl100k, text: "Hello, world!", expect: []string{"Hello", ",", " world", "!"}},
+		{encoding: EncodingCl100k, text: "I'm here", expect: []string{"I", "'m", " here"}},
+		{encoding: EncodingCl100k, text: "1234567", expect: []string{"123", "456", "7"}},
+		{encoding: EncodingCl100k, text: "x   y", expect: []string{"x", "  ", " y"}},
+		{encoding: EncodingCl100k, text: "x \ty", expect: []string{"x", " ", "\ty"}},
+		{encoding: EncodingCl100k, text: "x\n\ny", expect: []string{"x", "\n\n", "y"}},
+		{encoding: EncodingCl100k, text: "x  \n  y", expect: []string{"x", "  \n", " ", " y"}},
+		{encoding: EncodingCl100k, text: "end  ", expect: []string{"end", "  "}},
+		{encoding: EncodingCl100k, text: " $100", expect: []string{" $", "100"}},
+		{encoding: EncodingCl100k, text: "héllo wörld", expect: []string{"héllo", " wörld"}},
+		{encoding: EncodingCl100k, text: "a  b", expect: []string{"a", " ", " b"}},
+		{encoding: EncodingCl100k, text: "HelloWorld don't", expect: []string{"HelloWorld", " don", "'t"}},
+		{encoding: EncodingO200k, text: "HelloWorld don't", expect: []string{"Hello", "World", " don't"}},
+		{encoding: EncodingO200k, text: "x   y", expect: []string{"x", "  ", " y"}},
+		{encoding: EncodingO200k, text: "1234567", expect: []string{"123", "456", "7"}},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %q", test.encoding, test.text), func(t *testing.T) {
+			splitter, err := newSplitter(test.encoding)
+			if err != nil {
+				t.Fatalf("newSplitter: %v", err)
+			}
+			got := splitter.split(test.text)
+			if !slices.Equal(got, test.expect) {
+				t.Fatalf("split(%q) = %q, want %q", test.text, got, test.expect)
+			}
+		})
+	}
+}
+
+// byteRanks ranks every byte by its value and then merges in order.
+func byteRanks(merges ...string) map[string]int {
+	ranks := make(map[string]int)
+	for b := range 256 {
+		ranks[string([]byte{byte(b)})] = b
+	}
+	for i, merge := range merges {
+		ranks[merge] = 256 + i
+	}
+	return ranks
+}
+
+func TestBPEEncode(t *testing.T) {
+	tests := []struct {
+		name   string
+		merges []string
+		text   string
+		expect []int
+	}{
+		{name: "bytes without merges", text: "abc", expect: []int{'a', 'b', 'c'}},
+		{name: "lowest rank merges first", merges: []string{"bc", "ab"}, text: "abc", expect: []int{'a', 256}},
+		{name: "merges build on merges", merges: []string{"ab", "abc"}, text: "abc", expect: []int{257}},
+		{name: "first pair wins ties", merges: []string{"aa"}, text: "aaa", expect: []int{256, 'a'}},
+		{name: "pieces are merged apart", merges: []string{"a b"}, text: "a b", expect: []int{'a', ' ', 'b'}},
+		{name: "whole piece", merges: []string{" b"}, text: "a b", expect: []int{'a', 256}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bpe, err := NewBPE(byteRanks(test.merges...), EncodingCl100k)
+			if err != nil {
+				t.Fatalf("NewBPE: %v", err)
+			}
+			got := bpe.Encode(test.text)
+			if !slices.Equal(got, test.expect) {
+				t.Fatalf("Encode(%q) = %v, want %v", test.text, got, test.expect)
+			}
+			if bpe.Decode(got) != test.text {
+				t.Fatalf("Decode(%v) = %q, want %q", got, bpe.Decode(got), test.text)
+			}
+		})
+	}
+}
+
+// scanPiece is the plain tiktoken merge loop, it rescans every pair for
+// each merge.
+func scanPiece(ranks map[string]int, piece string) []int {
+	if rank, ok := ranks[piece]; ok {
+		return []int{rank}
+	}
+	bounds := make([]int, len(piece)+1)
+	for i := range bounds {
+		bounds[i] = i
+	}
+	for len(bounds) > 2 {
+		best, bestRank := -1, math.MaxInt
+		for i := 0; i+2 < len(bounds); i++ {
+			if rank, ok := ranks[piece[bounds[i]:bounds[i+2]]]; ok && rank < bestRank {
+				best, bestRank = i, rank
+			}
+		}
+		if best == -1 {
+			break
+		}
+		bounds = slices.Delete(bounds, best+1, best+2)
+	}
+	var tokens []int
+	for i := 0; i+1 < len(bounds); i++ {
+		tokens = append(tokens, ranks[piece[bounds[i]:bounds[i+1]]])
+	}
+	return tokens
+}
+
+func TestBPEEncodeMatchesPairScan(t *testing.T) {
+	random := rand.New(rand.NewPCG(1, 2))
+	text := func(n int) string {
+		var builder strings.Builder
+		for range n {
+			builder.WriteByte("ab"[random.IntN(2)])
+		}
+		return builder.String()
+	}
+	var merges []string
+	for range 40 {
+		merges = append(merges, text(2+random.IntN(5)))
+	}
+	ranks := byteRanks(merges...)
+	bpe, err := NewBPE(ranks, EncodingCl100k)
+	if err != nil {
+		t.Fatalf("NewBPE: %v", err)
+	}
+	for range 500 {
+		piece := text(1 + random.IntN(40))
+		got := bpe.encodePiece(piece, nil)
+		expect := scanPiece(ranks, piece)
+		if !slices.Equal(got, expect) {
+			t.Fatalf("encodePiece(%q) = %v, want %v", piece, got, expect)
+		}
+	}
+}
+
+func TestBPEEncodeGolden(t *testing.T) {
+	// the excerpts hold the single byte ranks both encodings share and the
+	// merges of these texts, whose pieces are at most two bytes long
+	tests := []struct {
+		encoding Encoding
+		text     string
+		expect   []int
+	}{
+		{encoding: EncodingCl100k, text: "2 + 2 = 4", expect: []int{17, 489, 220, 17, 284, 220, 19}},
+		{encoding: EncodingCl100k, text: "1+1=2", expect: []int{16, 10, 16, 28, 17}},
+		{encoding: EncodingO200k, text: "2 + 2 = 4", expect: []int{17, 659, 220, 17, 314, 220, 19}},
+		{encoding: EncodingO200k, text: "1+1=2", expect: []int{16, 10, 16, 28, 17}},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %q", test.encoding, test.text), func(t *testing.T) {
+			bpe, err := New(Config{Model: modelOf(test.encoding), RanksFile: filepath.Join("testdata", string(test.encoding)+".excerpt.tiktoken")})
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			got := bpe.(*BPE).Encode(test.text)
+			if !slices.Equal(got, test.expect) {
+				t.Fatalf("Encode(%q) = %v, want %v", test.text, got, test.expect)
+			}
+		})
+	}
+}
+
+// TestBPEEncodeGoldenRanks runs against the full ranks files in
+// TIKTOKEN_RANKS_DIR, the ids are tiktoken's.
+func TestBPEEncodeGoldenRanks(t *testing.T) {
+	dir := os.Getenv("TIKTOKEN_RANKS_DIR")
+	if dir == "" {
+		t.Skip("TIKTOKEN_RANKS_DIR is not set")
+	}
+	tests := []struct {
+		encoding Encoding
+		text     string
+		expect   []int
+	}{
+		{encoding: EncodingCl100k, text: "hello world!你好，世界！", expect: []int{15339, 1917, 0, 57668, 53901, 3922, 3574, 244, 98220, 6447}},
+		{encoding: EncodingCl100k, text: "tiktoken is great!", expect: []int{83, 1609, 5963, 374, 2294, 0}},
+		{encoding: EncodingCl100k, text: "antidisestablishmentarianism", expect: []int{519, 85342, 34500, 479, 8997, 2191}},
+		{encoding: EncodingCl100k, text: "2 + 2 = 4", expect: []int{17, 489, 220, 17, 284, 220, 19}},
+		{encoding: EncodingO200k, text: "hello world", expect: []int{24912, 2375}},
+		{encoding: EncodingO200k, text: "2 + 2 = 4", expect: []int{17, 659, 220, 17, 314, 220, 19}},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %q", test.encoding, test.text), func(t *testing.T) {
+			bpe, err := New(Config{Model: modelOf(test.encoding), RanksFile: filepath.Join(dir, string(test.encoding)+".tiktoken")})
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			got := bpe.(*BPE).Encode(test.text)
+			if !slices.Equal(got, test.expect) {
+				t.Fatalf("Encode(%q) = %v, want %v", test.text, got, test.expect)
+			}
+		})
+	}
+}
+
+func modelOf(encoding Encoding) string {
+	if encoding == EncodingO200k {
+		return "gpt-4o"
+	}
+	return "gpt-4"
+}
+
+func TestLoadBPE(t *testing.T) {
+	var lines []string
+	for token, rank := range byteRanks("he", "ll", "hell", "hello") {
+		lines = append(lines, fmt.Sprintf("%s %d", base64.StdEncoding.EncodeToString([]byte(token)), rank))
+	}
+	bpe, err := LoadBPE(strings.NewReader(strings.Join(lines, "\n")+"\n"), EncodingCl100k)
+	if err != nil {
+		t.Fatalf("LoadBPE: %v", err)
+	}
+	if got := bpe.Encode("hello"); !slices.Equal(got, []int{259}) {
+		t.Fatalf("Encode(hello) = %v, want [259]", got)
+	}
+	_, err = LoadBPE(strings.NewReader(base64.StdEncoding.EncodeToString([]byte("a"))+" 0\n"), EncodingCl100k)
+	if err == nil {
+		t.Fatalf("LoadBPE without every byte succeeded")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	bpe, err := NewBPE(byteRanks("ab"), EncodingCl100k)
+	if err != nil {
+		t.Fatalf("NewBPE: %v", err)
+	}
+	estimated, err := NewEstimated(EncodingCl100k)
+	if err != nil {
+		t.Fatalf("NewEstimated: %v", err)
+	}
+	tests := []struct {
+		name      string
+		tokenizer Tokenizer
+		text      string
+		maxTokens int
+		expect    string
+	}{
+		{name: "bpe fits", tokenizer: bpe, text: "ab 🌍", maxTokens: 6, expect: "ab 🌍"},
+		{name: "bpe drops a cut character", tokenizer: bpe, text: "ab 🌍", maxTokens: 3, expect: "ab "},
+		{name: "bpe zero", tokenizer: bpe, text: "ab", maxTokens: 0, expect: ""},
+		{name: "estimated fits", tokenizer: estimated, text: "hello world", maxTokens: 4, expect: "hello world"},
+		{name: "estimated cuts a piece", tokenizer: estimated, text: "hello world", maxTokens: 3, expect: "hello wor"},
+		{name: "estimated drops a cut character", tokenizer: estimated, text: "aé", maxTokens: 0, expect: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.tokenizer.Truncate(test.text, test.maxTokens)
+			if got != test.expect {
+				t.Fatalf("Truncate(%q, %d) = %q, want %q", test.text, test.maxTokens, got, test.expect)
+			}
+			if !utf8.ValidString(got) || test.tokenizer.Count(got) > test.maxTokens {
+				t.Fatalf("Truncate(%q, %d) = %q, %d tokens", test.text, test.maxTokens, got, test.tokenizer.Count(got))
+			}
+		})
+	}
+}
+
+func TestEncodingForModel(t *testing.T) {
+	tests := []struct {
+		model  string
+		expect Encoding
+	}{
+		{model: "", expect: EncodingCl100k},
+		{model: "gpt-4o-mini", expect: EncodingO200k},
+		{model: "gpt-4-turbo", expect: EncodingCl100k},
+		{model: "o3-mini", expect: EncodingO200k},
+		{model: "text-embedding-3-small", expect: EncodingCl100k},
+	}
+	for _, test := range tests {
+		got, err := EncodingForModel(test.model)
+		if err != nil || got != test.expect {
+			t.Fatalf("EncodingForModel(%q) = %q, %v, want %q", test.model, got, err, test.expect)
+		}
+	}
+	if _, err := EncodingForModel("llama-3"); err == nil {
+		t.Fatalf("EncodingForModel(llama-3) succeeded")
+	}
+}
@@ -1,10 +1,114 @@
 package types
 
+import "time"
+
 type RegisterConversationInput struct {
-	Agent string
-	User  string
+	Agent string `json:"agent" jsonschema:"name of the agent taking part in the conversation"`
+	User  string `json:"user" jsonschema:"identifier of the user taking part in the conversation"`
 }
 
 type RegisterConversationOutput struct {
-	ConversationID string
+	ConversationID string `json:"conversation_id"`
+}
+
+// Conversation statuses, closed and archived conversations take no new memories.
+const (
+	ConversationStatusActive   = "active"
+	ConversationStatusClosed   = "closed"
+	ConversationStatusArchived = "archived"
+)
+
+type Conversation struct {
+	ID        string    `json:"id"`
+	Agent     string    `json:"agent"`
+	User      string    `json:"user"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	// MemoryCount is the number of memories the client holds for the conversation.
+	MemoryCount int `json:"memory_count"`
+	// LastActivityAt is when its latest memory was created, or the conversation
+	// itself when it has none.
+	LastActivityAt time.Time `json:"last_activity_at"`
+}
+
+type ListConversationsInput struct {
+	Agent string `json:"agent,omitempty"`
+	User  string `json:"user,omitempty"`
+	// Status lists conversations with that status, empty lists active and
+	// closed ones so archived conversations only show up when asked for.
+	Status string `json:"status,omitempty"`
+	// Limit defaults to 50 and is capped at 500.
+	Limit int `json:"limit,omitempty"`
+	// Cursor is the NextCursor of the previous page, empty starts from the newest conversation.
+	Cursor string `json:"cursor,omitempty"`
+}
+
+type ListConversationsOutput struct {
+	Conversations []Conversation `json:"conversations"`
+	// NextCursor fetches the next page, it is empty on the last one.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type GetConversationInput struct {
+	ConversationID string `json:"conversation_id"`
+}
+
+type GetConversationOutput struct {
+	Conversation Conversation `json:"conversation"`
+}
+
+type CloseConversationInput struct {
+	ConversationID string `json:"conversation_id"`
+}
+
+type CloseConversationOutput struct {
+	ConversationID string `json:"conversation_id"`
+	Status         string `json:"status"`
+}
+
+type ArchiveConversationInput struct {
+	ConversationID string `json:"conversation_id"`
+}
+
+type ArchiveConversationOutput struct {
+	ConversationID string `json:"conversation_id"`
+	Status         string `json:"status"`
+}
+
+type DeleteConversationInput struct {
+	ConversationID string `json:"conversation_id"`
+}
+
+// DeleteConversationOutput reports what was removed along with the conversation.
+type DeleteConversationOutput struct {
+	ConversationID string `json:"conversation_id"`
+	Memories       int    `json:"memories"`
+	VectorMemories int    `json:"vector_memories"`
+	CacheEntries   int    `json:"cache_entries"`
+}
+
+type EraseUserInput struct {
+	User string `json:"user" jsonschema:"identifier of the user whose conversations and memories are erased"`
+	// ConversationIDs are conversations of the user that were already deleted,
+	// usually by erasing the user through the other client, whose memories are
+	// erased too. Conversations that still exist must belong to User.
+	ConversationIDs []string `json:"conversation_ids,omitempty" jsonschema:"conversations of the user that were already deleted"`
+}
+
+// ErasedConversation reports what was removed for one conversation, Memories
+// and VectorMemories count the rows of the memories table and the vector store
+// and CacheEntries the short-term memories.
+type ErasedConversation struct {
+	ConversationID string    `json:"conversation_id"`
+	Agent          string    `json:"agent,omitempty"`
+	CreatedAt      time.Time `json:"created_at,omitzero"`
+	Memories       int       `json:"memories"`
+	VectorMemories int       `json:"vector_memories"`
+	CacheEntries   int       `json:"cache_entries"`
+}
+
+type EraseUserOutput struct {
+	User          string               `json:"user"`
+	Conversations []ErasedConversation `json:"conversations"`
+	ErasedAt      time.Time            `json:"erased_at"`
 }
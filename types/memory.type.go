@@ -2,58 +2,209 @@ package types
 
 import "time"
 
+// Memory scopes. A memory always belongs to its conversation, the user and
+// agent scopes share it with every conversation of the same user or agent.
+const (
+	ScopeConversation = "conversation"
+	ScopeUser         = "user"
+	ScopeAgent        = "agent"
+)
+
 // Semantic Memory
 type SemanticMemory struct {
-	ID        string
-	Query     string
-	Response  string
-	CreatedAt time.Time
+	ID string `json:"id"`
+	// ConversationID is the conversation the memory was stored in, it differs
+	// from the searched one for memories found in the user or agent scope.
+	ConversationID string         `json:"conversation_id"`
+	Query          string         `json:"query"`
+	Response       string         `json:"response"`
+	CreatedAt      time.Time      `json:"created_at"`
+	Metadata       map[string]any `json:"metadata,omitempty"`
+	Tags           []string       `json:"tags,omitempty"`
+	// Rank is the 1-based position among the similar memories, most similar first.
+	Rank int `json:"rank"`
+	// Score is the similarity to the query, higher is more similar.
+	Score float32 `json:"score"`
+	// Distance is the raw vector distance to the query, lower is more similar.
+	Distance float32 `json:"distance"`
+}
+
+// MemoryFilter selects memories by tags and metadata, the empty filter matches
+// every memory.
+type MemoryFilter struct {
+	Tags []string `json:"tags,omitempty" jsonschema:"tags a memory must all carry"`
+	// Metadata values are compared by their JSON encoding, a memory must hold
+	// an equal value under every key.
+	Metadata map[string]any `json:"metadata,omitempty" jsonschema:"values a memory must hold under these metadata keys"`
 }
 
 type StoreSemanticMemoryInput struct {
-	ConversationID string
-	Query          string
-	Response       string
+	ConversationID string `json:"conversation_id" jsonschema:"id returned by register_conversation"`
+	Query          string `json:"query" jsonschema:"the user message to remember"`
+	Response       string `json:"response" jsonschema:"the agent reply to remember"`
+	// Metadata must be encodable as JSON, it is stored as given.
+	Metadata map[string]any `json:"metadata,omitempty" jsonschema:"free-form details such as tool names, message roles or source documents"`
+	Tags     []string       `json:"tags,omitempty" jsonschema:"labels such as topics to filter memories by"`
+	// Scopes share the memory beyond its conversation, each is user or agent.
+	Scopes []string `json:"scopes,omitempty" jsonschema:"also remember this for every conversation of the same user or agent, each one of user or agent"`
 }
 
 type StoreSemanticMemoryOutput struct {
-	MemoryID string
+	MemoryID string `json:"memory_id"`
+}
+
+type SemanticMemoryEntry struct {
+	Query    string `json:"query" jsonschema:"the user message to remember"`
+	Response string `json:"response" jsonschema:"the agent reply to remember"`
+	// CreatedAt backdates historical memories, the zero value means now.
+	CreatedAt time.Time      `json:"created_at,omitzero" jsonschema:"when the exchange happened, defaults to now"`
+	Metadata  map[string]any `json:"metadata,omitempty" jsonschema:"free-form details such as tool names, message roles or source documents"`
+	Tags      []string       `json:"tags,omitempty" jsonschema:"labels such as topics to filter memories by"`
+}
+
+type StoreManySemanticMemoryInput struct {
+	ConversationID string                `json:"conversation_id" jsonschema:"id returned by register_conversation"`
+	Memories       []SemanticMemoryEntry `json:"memories" jsonschema:"the exchanges to remember, oldest first"`
+	// Scopes share every memory of the batch beyond its conversation.
+	Scopes []string `json:"scopes,omitempty" jsonschema:"also remember these for every conversation of the same user or agent, each one of user or agent"`
+}
+
+type StoreManySemanticMemoryOutput struct {
+	MemoryIDs []string `json:"memory_ids"`
 }
 
 type RetrieveSemanticMemoryInput struct {
-	ConversationID string
-	Query          string
-	TopK           int
+	ConversationID string `json:"conversation_id" jsonschema:"id returned by register_conversation"`
+	Query          string `json:"query" jsonschema:"text to search similar memories for"`
+	TopK           int    `json:"top_k,omitempty" jsonschema:"maximum number of similar memories to return, defaults to 10 and is at most 1000"`
+	// MinScore drops similar memories scoring below it, nil keeps every match.
+	MinScore *float32 `json:"min_score,omitempty" jsonschema:"minimum similarity score of returned memories, matches scoring lower are dropped"`
+	// Filter applies to both the recent and the similar memories, similar
+	// memories are still returned up to TopK when enough of them match.
+	Filter MemoryFilter `json:"filter,omitzero" jsonschema:"only return memories with these tags and metadata"`
+	// Scope widens the similarity search to the memories shared with the user
+	// or agent of the conversation, recent memories stay the conversation's.
+	Scope string `json:"scope,omitempty" jsonschema:"where to search similar memories: conversation (default), user or agent"`
+	// MaxTokens replaces the context window size of the recent memories with a
+	// token budget, similar memories are not counted against it.
+	MaxTokens int `json:"max_tokens,omitempty" jsonschema:"return the most recent memories whose queries and responses fit in this many tokens instead of a fixed number"`
+	// TruncateOldest cuts the newest recent memory that does not fit to the
+	// rest of the MaxTokens budget instead of leaving it out.
+	TruncateOldest bool `json:"truncate_oldest,omitempty" jsonschema:"cut the oldest recent memory to fit max_tokens instead of leaving it out"`
 }
 
 type RetrieveSemanticMemoryOutput struct {
-	Memories        []Memory
-	SimilarMemories []SemanticMemory
+	Memories        []Memory         `json:"memories"`
+	SimilarMemories []SemanticMemory `json:"similar_memories"`
+}
+
+type UpdateSemanticMemoryInput struct {
+	ConversationID string `json:"conversation_id"`
+	MemoryID       string `json:"memory_id"`
+	Query          string `json:"query"`
+	Response       string `json:"response"`
+}
+
+type UpdateSemanticMemoryOutput struct {
+	MemoryID string `json:"memory_id"`
+}
+
+type DeleteSemanticMemoryInput struct {
+	ConversationID string `json:"conversation_id"`
+	MemoryID       string `json:"memory_id"`
+}
+
+type DeleteSemanticMemoryOutput struct {
+	MemoryID string `json:"memory_id"`
+}
+
+type ReindexSemanticMemoryInput struct {
+	// ConversationID limits the reindex to one conversation, empty reindexes all of them.
+	ConversationID string `json:"conversation_id,omitempty"`
+}
+
+type ReindexedConversation struct {
+	ConversationID string `json:"conversation_id"`
+	// Memories is the number of memories the rebuilt index holds.
+	Memories int `json:"memories"`
+}
+
+type ReindexSemanticMemoryOutput struct {
+	Conversations []ReindexedConversation `json:"conversations"`
+}
+
+type EmbeddingCacheStatsInput struct{}
+
+// EmbeddingCacheStatsOutput counts how the embeddings asked for since the
+// client started were served, all zero when the cache is disabled.
+type EmbeddingCacheStatsOutput struct {
+	Enabled bool `json:"enabled"`
+	// MemoryHits were served from the in-memory LRU.
+	MemoryHits int64 `json:"memory_hits"`
+	// StoreHits were served from the DuckDB tier after missing the LRU.
+	StoreHits int64 `json:"store_hits"`
+	// Misses had to be embedded by the provider.
+	Misses int64 `json:"misses"`
+	// StoreErrors are failed reads and writes of the DuckDB tier.
+	StoreErrors int64 `json:"store_errors"`
 }
 
 // Short Term Memory
 type Memory struct {
-	ID        string
-	Query     string
-	Response  string
-	CreatedAt time.Time
+	ID        string         `json:"id"`
+	Query     string         `json:"query"`
+	Response  string         `json:"response"`
+	CreatedAt time.Time      `json:"created_at"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	Tags      []string       `json:"tags,omitempty"`
+	// Truncated marks the oldest memory of a MaxTokens budget when it was cut to fit.
+	Truncated bool `json:"truncated,omitempty"`
 }
 
 type StoreShortTermMemoryInput struct {
-	Query          string
-	Response       string
-	ConversationID string
+	Query          string         `json:"query"`
+	Response       string         `json:"response"`
+	ConversationID string         `json:"conversation_id"`
+	Metadata       map[string]any `json:"metadata,omitempty"`
+	Tags           []string       `json:"tags,omitempty"`
 }
 
 type StoreShortTermMemoryOutput struct {
-	MemoryID string
+	MemoryID string `json:"memory_id"`
 }
 
 type RetrieveShortTermMemoryInput struct {
-	TopK           int
-	ConversationID string
+	TopK           int          `json:"top_k,omitempty"`
+	ConversationID string       `json:"conversation_id"`
+	Filter         MemoryFilter `json:"filter,omitzero"`
+	// MaxTokens returns the most recent memories whose queries and responses
+	// fit in that many tokens. TopK then only applies when it is set.
+	MaxTokens int `json:"max_tokens,omitempty"`
+	// TruncateOldest cuts the newest memory that does not fit to the rest of
+	// the MaxTokens budget instead of leaving it out.
+	TruncateOldest bool `json:"truncate_oldest,omitempty"`
 }
 
 type RetrieveShortTermMemoryOutput struct {
-	Memories []Memory
+	Memories []Memory `json:"memories"`
+}
+
+type UpdateShortTermMemoryInput struct {
+	ConversationID string `json:"conversation_id"`
+	MemoryID       string `json:"memory_id"`
+	Query          string `json:"query"`
+	Response       string `json:"response"`
+}
+
+type UpdateShortTermMemoryOutput struct {
+	MemoryID string `json:"memory_id"`
+}
+
+type DeleteShortTermMemoryInput struct {
+	ConversationID string `json:"conversation_id"`
+	MemoryID       string `json:"memory_id"`
+}
+
+type DeleteShortTermMemoryOutput struct {
+	MemoryID string `json:"memory_id"`
 }